@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// subjectKey ключ контекста gin с именем пользователя API из токена
+const subjectKey = "api_subject"
+
+// jwtHeader заголовок токена: подписываем только HS256
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+var (
+	errMalformedToken = errors.New("malformed token")
+	errTokenSignature = errors.New("invalid token signature")
+	errTokenExpired   = errors.New("token expired")
+)
+
+// tokenClaims полезная нагрузка JWT
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// signToken выпускает JWT, подписанный HMAC-SHA256
+func signToken(secret []byte, claims tokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + tokenSignature(secret, unsigned), nil
+}
+
+// parseToken проверяет подпись и срок действия JWT
+func parseToken(secret []byte, token string, now time.Time) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, errMalformedToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(tokenSignature(secret, parts[0]+"."+parts[1]))) {
+		return nil, errTokenSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, errMalformedToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, errTokenExpired
+	}
+	return &claims, nil
+}
+
+// tokenSignature подпись заголовка и нагрузки токена
+func tokenSignature(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// loginRequest учетные данные для получения токена
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// login выдает токен по учетным данным из API_USERNAME и API_PASSWORD
+func (s *Server) login(c *gin.Context) {
+	var request loginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+
+	// Сравниваем оба поля за постоянное время, чтобы не подсказывать, что именно неверно
+	usernameOK := subtle.ConstantTimeCompare([]byte(request.Username), []byte(s.config.Security.APIUsername)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(request.Password), []byte(s.config.Security.APIPassword)) == 1
+	if !usernameOK || !passwordOK {
+		s.logger.Warn("API login failed", "username", request.Username, "ip", c.ClientIP())
+		abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "Invalid username or password", nil)
+		return
+	}
+
+	now := s.now()
+	expiresAt := now.Add(s.config.Security.APITokenTTL)
+	token, err := signToken([]byte(s.config.Security.JWTSecret), tokenClaims{
+		Subject:   request.Username,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.logger.Info("API token issued", "username", request.Username, "ip", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expiresAt.UTC(),
+	})
+}
+
+// authenticate пропускает только запросы с действующим токеном в заголовке Authorization
+func (s *Server) authenticate(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "Missing bearer token", nil)
+		return
+	}
+
+	claims, err := parseToken([]byte(s.config.Security.JWTSecret), token, s.now())
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "Invalid token", map[string]string{"reason": err.Error()})
+		return
+	}
+
+	c.Set(subjectKey, claims.Subject)
+	c.Next()
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec спецификация OpenAPI 3 всех HTTP маршрутов приложения
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// swaggerUIPage страница Swagger UI, загружающая спецификацию с /api/openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Remnawave Telegram Shop API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// RegisterDocs регистрирует спецификацию OpenAPI и страницу Swagger UI.
+// Документация доступна и при выключенном API
+func RegisterDocs(router gin.IRouter) {
+	router.GET("/api/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", OpenAPISpec)
+	})
+	router.GET("/api/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Коды ошибок в теле ответа
+const (
+	CodeValidation   = "VALIDATION_ERROR"
+	CodeNotFound     = "NOT_FOUND"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeInternal     = "INTERNAL_ERROR"
+	CodeUnavailable  = "SERVICE_UNAVAILABLE"
+)
+
+// ErrorResponse тело ответа с ошибкой
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody описание ошибки. Details уточняет поле и причину для ошибок валидации
+type ErrorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// abortWithError прерывает обработку запроса ответом с ошибкой
+func abortWithError(c *gin.Context, status int, code, message string, details map[string]string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: ErrorBody{Code: code, Message: message, Details: details}})
+}
+
+// invalidField отвечает 400 на некорректное значение параметра или поля тела
+func invalidField(c *gin.Context, field, reason string) {
+	abortWithError(c, http.StatusBadRequest, CodeValidation, "Validation failed", map[string]string{
+		"field":  field,
+		"reason": reason,
+	})
+}
+
+// invalidBody отвечает 400 на тело запроса, которое не удалось разобрать
+func invalidBody(c *gin.Context, err error) {
+	abortWithError(c, http.StatusBadRequest, CodeValidation, "Invalid request body", map[string]string{
+		"reason": err.Error(),
+	})
+}
+
+// unprocessable отвечает 422, когда запрос корректен, но действие невозможно
+func unprocessable(c *gin.Context, err error) {
+	abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, err.Error(), nil)
+}
+
+// notFound отвечает 404 для отсутствующего ресурса
+func notFound(c *gin.Context, resource string) {
+	abortWithError(c, http.StatusNotFound, CodeNotFound, resource+" not found", nil)
+}
+
+// internalError логирует ошибку и отвечает 500 без подробностей
+func (s *Server) internalError(c *gin.Context, err error) {
+	s.logger.Error("API request failed", "error", err, "method", c.Request.Method, "path", c.FullPath())
+	abortWithError(c, http.StatusInternalServerError, CodeInternal, "Internal server error", nil)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInitDataMalformed = errors.New("malformed init data")
+	errInitDataSignature = errors.New("invalid init data signature")
+	errInitDataExpired   = errors.New("init data expired")
+)
+
+// initDataUser пользователь Telegram из initData Mini App
+type initDataUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// parseInitData проверяет подпись initData ключом, производным от токена бота,
+// и срок действия auth_date, после чего возвращает пользователя
+func parseInitData(botToken, raw string, now time.Time, ttl time.Duration) (*initDataUser, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, errInitDataMalformed
+	}
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, errInitDataMalformed
+	}
+	if !hmac.Equal([]byte(hash), []byte(initDataSignature(botToken, values))) {
+		return nil, errInitDataSignature
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, errInitDataMalformed
+	}
+	if now.Sub(time.Unix(authDate, 0)) > ttl {
+		return nil, errInitDataExpired
+	}
+
+	var user initDataUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, errInitDataMalformed
+	}
+	return &user, nil
+}
+
+// initDataSignature вычисляет hash initData: HMAC-SHA256 строки проверки,
+// где ключ — HMAC-SHA256 токена бота с ключом "WebAppData"
+func initDataSignature(botToken string, values url.Values) string {
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
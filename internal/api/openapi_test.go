@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openAPIDocument разобранная спецификация для проверок в тестах
+type openAPIDocument struct {
+	Paths map[string]map[string]struct {
+		Responses map[string]json.RawMessage `json:"responses"`
+	} `json:"paths"`
+	raw map[string]interface{}
+}
+
+// ginPathParam параметр пути в нотации gin
+var ginPathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// openAPIPath переводит шаблон маршрута gin в шаблон пути OpenAPI
+func openAPIPath(route string) string {
+	return ginPathParam.ReplaceAllString(route, "{$1}")
+}
+
+func loadOpenAPI(t *testing.T) *openAPIDocument {
+	t.Helper()
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(OpenAPISpec, &doc), "openapi.json must be valid JSON")
+	require.NoError(t, json.Unmarshal(OpenAPISpec, &doc.raw))
+	return &doc
+}
+
+// resolve возвращает объект по локальной ссылке вида #/components/schemas/User
+func (d *openAPIDocument) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var node interface{} = d.raw
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+		if node, ok = object[part]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %q is not an object", ref)
+	}
+	return object, nil
+}
+
+// deref раскрывает цепочку ссылок
+func (d *openAPIDocument) deref(node map[string]interface{}) (map[string]interface{}, error) {
+	for {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node, nil
+		}
+		resolved, err := d.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		node = resolved
+	}
+}
+
+// responseSchema возвращает схему JSON ответа или nil, если ответ без тела
+func (d *openAPIDocument) responseSchema(path, method string, status int) (map[string]interface{}, error) {
+	operation, ok := d.Paths[path][strings.ToLower(method)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s is not documented", method, path)
+	}
+	raw, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s: status %d is not documented", method, path, status)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, err
+	}
+	response, err := d.deref(response)
+	if err != nil {
+		return nil, err
+	}
+	content, _ := response["content"].(map[string]interface{})
+	media, ok := content["application/json"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	schema, _ := media["schema"].(map[string]interface{})
+	return schema, nil
+}
+
+// validate проверяет значение по схеме. Поддерживается подмножество JSON Schema,
+// которое использует openapi.json: свойства, не описанные в схеме, считаются ошибкой
+func (d *openAPIDocument) validate(schema map[string]interface{}, value interface{}, at string) error {
+	schema, err := d.deref(schema)
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", at, value)
+		}
+		return d.validateObject(schema, object, at)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", at, value)
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			if err := d.validate(itemSchema, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", at, value)
+		}
+		return validateFormat(schema["format"], text, at)
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return fmt.Errorf("%s: expected integer, got %v", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", at, value)
+		}
+	case nil:
+	default:
+		return fmt.Errorf("%s: unsupported schema type %v", at, schema["type"])
+	}
+	return nil
+}
+
+func (d *openAPIDocument) validateObject(schema, object map[string]interface{}, at string) error {
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := object[name.(string)]; !ok {
+			return fmt.Errorf("%s: required property %q is missing", at, name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		switch {
+		case ok:
+			if err := d.validate(property, object[name], at+"."+name); err != nil {
+				return err
+			}
+		case hasAdditional:
+			if err := d.validate(additional, object[name], at+"."+name); err != nil {
+				return err
+			}
+		case properties != nil:
+			return fmt.Errorf("%s: property %q is not documented", at, name)
+		}
+	}
+	return nil
+}
+
+func validateFormat(format interface{}, value, at string) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("%s: %q is not date-time", at, value)
+		}
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			return fmt.Errorf("%s: %q is not uuid", at, value)
+		}
+	}
+	return nil
+}
+
+// checkResponse сверяет ответ на запрос к маршруту route с описанием в спецификации
+func checkResponse(t *testing.T, method, route string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	doc := loadOpenAPI(t)
+	require.NotEmpty(t, route, "%s request did not match any route", method)
+
+	schema, err := doc.responseSchema(openAPIPath(route), method, rec.Code)
+	require.NoError(t, err)
+	if schema == nil {
+		return
+	}
+
+	var body interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), "%s %s: body is not JSON", method, route)
+	assert.NoError(t, doc.validate(schema, body, "body"), "%s %s %d: %s", method, route, rec.Code, rec.Body.String())
+}
+
+func TestOpenAPIDocumentsAPIRoutes(t *testing.T) {
+	api := newTestAPI(t)
+	doc := loadOpenAPI(t)
+
+	for _, route := range api.router.Routes() {
+		path := openAPIPath(route.Path)
+		_, ok := doc.Paths[path][strings.ToLower(route.Method)]
+		assert.True(t, ok, "%s %s is missing in openapi.json", route.Method, path)
+	}
+}
+
+func TestOpenAPIReferencesResolve(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				_, err := doc.resolve(ref)
+				assert.NoError(t, err)
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(doc.raw)
+}
+
+func TestOpenAPIValidateRejectsMismatch(t *testing.T) {
+	doc := loadOpenAPI(t)
+	schema := map[string]interface{}{"$ref": "#/components/schemas/User"}
+
+	user := newUserResponse(&models.User{ID: uuid.New(), TelegramID: 100, Username: "alice", CreatedAt: time.Now()})
+	data, err := json.Marshal(user)
+	require.NoError(t, err)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	require.NoError(t, doc.validate(schema, body, "body"))
+
+	body["telegram_id"] = "100"
+	assert.Error(t, doc.validate(schema, body, "body"))
+
+	body["telegram_id"] = 100.0
+	body["password"] = "secret"
+	assert.Error(t, doc.validate(schema, body, "body"))
+
+	delete(body, "password")
+	delete(body, "balance")
+	assert.Error(t, doc.validate(schema, body, "body"))
+}
+
+func TestOpenAPIDocsRoutes(t *testing.T) {
+	api := newTestAPI(t)
+
+	rec := api.do(t, http.MethodGet, "/api/openapi.json", "", nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, string(OpenAPISpec), rec.Body.String())
+
+	rec = api.do(t, http.MethodGet, "/api/docs", "", nil, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/api/openapi.json")
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// paymentStatuses допустимые статусы платежа в фильтре
+var paymentStatuses = map[string]bool{"pending": true, "completed": true, "failed": true, "cancelled": true}
+
+// createPaymentRequest новый платеж в статусе pending
+type createPaymentRequest struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	Description   string    `json:"description"`
+}
+
+// listPayments GET /payments
+func (s *Server) listPayments(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	if status != "" && !paymentStatuses[status] {
+		invalidField(c, "status", "must be one of pending, completed, failed, cancelled")
+		return
+	}
+
+	filter := repositories.AdminListFilter{UserID: userID, Status: status, Method: c.Query("payment_method")}
+	payments, total, err := s.listService.ListPayments(filter, limit, offset)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("payments", mapSlice(payments, newPaymentResponse), total, limit, offset))
+}
+
+// getPayment GET /payments/:id
+func (s *Server) getPayment(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	payment, err := s.paymentService.GetPayment(id)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+	if payment == nil {
+		notFound(c, "Payment")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPaymentResponse(payment))
+}
+
+// createPayment POST /payments
+func (s *Server) createPayment(c *gin.Context) {
+	var request createPaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	request.PaymentMethod = strings.TrimSpace(request.PaymentMethod)
+	switch {
+	case request.UserID == uuid.Nil:
+		invalidField(c, "user_id", "is required")
+		return
+	case request.Amount <= 0:
+		invalidField(c, "amount", "must be positive")
+		return
+	case request.PaymentMethod == "":
+		invalidField(c, "payment_method", "is required")
+		return
+	}
+
+	user, err := s.userService.GetUserByID(request.UserID)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+	if user == nil {
+		notFound(c, "User")
+		return
+	}
+
+	payment, err := s.paymentService.CreatePayment(user.ID, request.Amount, request.PaymentMethod, request.Description)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.audit(c, services.AuditEntry{
+		Action:     models.AuditPaymentCreate,
+		TargetUser: user,
+		TargetType: "payment",
+		TargetID:   payment.ID.String(),
+		After: map[string]interface{}{
+			"amount":         payment.Amount,
+			"payment_method": payment.PaymentMethod,
+			"status":         payment.Status,
+		},
+	})
+	s.logger.Info("API payment created", "api_user", subject(c), "telegram_id", user.TelegramID, "payment_id", payment.ID)
+	c.JSON(http.StatusCreated, newPaymentResponse(payment))
+}
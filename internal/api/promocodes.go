@@ -0,0 +1,227 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// promoCodeTypes типы промокодов, которые можно создать через API
+var promoCodeTypes = map[string]bool{"bonus_days": true, "discount_percent": true, "discount_amount": true}
+
+// createPromoCodeRequest новый промокод. Без code код генерируется случайно
+type createPromoCodeRequest struct {
+	Code        string     `json:"code"`
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	MaxUses     int        `json:"max_uses"`
+	ValidFrom   *time.Time `json:"valid_from"`
+	ValidUntil  *time.Time `json:"valid_until"`
+	Description string     `json:"description"`
+}
+
+// updatePromoCodeRequest изменяемые поля промокода
+type updatePromoCodeRequest struct {
+	IsActive    *bool      `json:"is_active"`
+	MaxUses     *int       `json:"max_uses"`
+	ValidUntil  *time.Time `json:"valid_until"`
+	Description *string    `json:"description"`
+}
+
+// listPromoCodes GET /promocodes
+func (s *Server) listPromoCodes(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	promoCodes, total, err := s.listService.ListPromoCodes(limit, offset)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("promocodes", mapSlice(promoCodes, newPromoCodeResponse), total, limit, offset))
+}
+
+// getPromoCode GET /promocodes/:id
+func (s *Server) getPromoCode(c *gin.Context) {
+	promoCode, ok := s.loadPromoCode(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newPromoCodeResponse(promoCode))
+}
+
+// createPromoCode POST /promocodes
+func (s *Server) createPromoCode(c *gin.Context) {
+	var request createPromoCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+
+	request.Code = strings.ToUpper(strings.TrimSpace(request.Code))
+	minLength, maxLength := s.config.PromoCodes.MinCodeLength, s.config.PromoCodes.MaxCodeLength
+	switch {
+	case request.Code != "" && (len(request.Code) < minLength || len(request.Code) > maxLength):
+		invalidField(c, "code", "length must be from "+strconv.Itoa(minLength)+" to "+strconv.Itoa(maxLength))
+		return
+	case !promoCodeTypes[request.Type]:
+		invalidField(c, "type", "must be one of bonus_days, discount_percent, discount_amount")
+		return
+	case request.Value <= 0:
+		invalidField(c, "value", "must be positive")
+		return
+	case request.Type == "discount_percent" && request.Value > 100:
+		invalidField(c, "value", "must not exceed 100 for discount_percent")
+		return
+	case request.MaxUses < 0:
+		invalidField(c, "max_uses", "must not be negative")
+		return
+	case request.ValidFrom != nil && request.ValidUntil != nil && !request.ValidUntil.After(*request.ValidFrom):
+		invalidField(c, "valid_until", "must be after valid_from")
+		return
+	}
+
+	var promoCode *models.PromoCode
+	var err error
+	if request.Code == "" {
+		promoCode, err = s.promoCodeService.GeneratePromoCode(request.Type, request.Value, request.MaxUses,
+			request.ValidFrom, request.ValidUntil, request.Description, uuid.Nil)
+	} else {
+		if existing, _ := s.promoCodeService.GetPromoCode(request.Code); existing != nil {
+			abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, "Promo code already exists", map[string]string{
+				"field":  "code",
+				"reason": "already exists",
+			})
+			return
+		}
+		promoCode, err = s.promoCodeService.CreatePromoCode(request.Code, request.Type, request.Value, request.MaxUses,
+			request.ValidFrom, request.ValidUntil, request.Description, uuid.Nil)
+	}
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.audit(c, services.AuditEntry{
+		Action:     models.AuditPromoCreate,
+		TargetType: "promo_code",
+		TargetID:   promoCode.Code,
+		After: map[string]interface{}{
+			"type":     promoCode.Type,
+			"value":    promoCode.Value,
+			"max_uses": promoCode.MaxUses,
+		},
+	})
+	s.logger.Info("API promo code created", "api_user", subject(c), "code", promoCode.Code)
+	c.JSON(http.StatusCreated, newPromoCodeResponse(promoCode))
+}
+
+// updatePromoCode PUT /promocodes/:id
+func (s *Server) updatePromoCode(c *gin.Context) {
+	promoCode, ok := s.loadPromoCode(c)
+	if !ok {
+		return
+	}
+
+	var request updatePromoCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if request.MaxUses != nil && *request.MaxUses < 0 {
+		invalidField(c, "max_uses", "must not be negative")
+		return
+	}
+
+	before := promoCodeAuditValue(promoCode)
+	if request.IsActive != nil {
+		promoCode.IsActive = *request.IsActive
+	}
+	if request.MaxUses != nil {
+		promoCode.MaxUses = *request.MaxUses
+	}
+	if request.ValidUntil != nil {
+		promoCode.ValidUntil = request.ValidUntil
+	}
+	if request.Description != nil {
+		promoCode.Description = *request.Description
+	}
+
+	if err := s.promoCodeService.UpdatePromoCode(promoCode); err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.audit(c, services.AuditEntry{
+		Action:     models.AuditPromoUpdate,
+		TargetType: "promo_code",
+		TargetID:   promoCode.Code,
+		Before:     before,
+		After:      promoCodeAuditValue(promoCode),
+	})
+	s.logger.Info("API promo code updated", "api_user", subject(c), "code", promoCode.Code)
+	c.JSON(http.StatusOK, newPromoCodeResponse(promoCode))
+}
+
+// deletePromoCode DELETE /promocodes/:id
+func (s *Server) deletePromoCode(c *gin.Context) {
+	promoCode, ok := s.loadPromoCode(c)
+	if !ok {
+		return
+	}
+
+	if err := s.promoCodeService.DeletePromoCode(promoCode.ID); err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.audit(c, services.AuditEntry{
+		Action:     models.AuditPromoDelete,
+		TargetType: "promo_code",
+		TargetID:   promoCode.Code,
+		Before:     promoCodeAuditValue(promoCode),
+	})
+	s.logger.Info("API promo code deleted", "api_user", subject(c), "code", promoCode.Code)
+	c.Status(http.StatusNoContent)
+}
+
+// promoCodeAuditValue изменяемые поля промокода для журнала аудита
+func promoCodeAuditValue(promoCode *models.PromoCode) map[string]interface{} {
+	return map[string]interface{}{
+		"is_active":   promoCode.IsActive,
+		"max_uses":    promoCode.MaxUses,
+		"valid_until": promoCode.ValidUntil,
+		"description": promoCode.Description,
+	}
+}
+
+// loadPromoCode загружает промокод из параметра пути. При ошибке ответ уже отправлен
+func (s *Server) loadPromoCode(c *gin.Context) (*models.PromoCode, bool) {
+	id, ok := pathID(c)
+	if !ok {
+		return nil, false
+	}
+
+	promoCode, err := s.promoCodeService.GetPromoCodeByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && promoCode == nil) {
+		notFound(c, "Promo code")
+		return nil, false
+	}
+	if err != nil {
+		s.internalError(c, err)
+		return nil, false
+	}
+	return promoCode, true
+}
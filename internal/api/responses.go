@@ -0,0 +1,154 @@
+package api
+
+import (
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// UserResponse пользователь в ответах API
+type UserResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	TelegramID   int64      `json:"telegram_id"`
+	Username     string     `json:"username"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	LanguageCode string     `json:"language_code"`
+	IsBlocked    bool       `json:"is_blocked"`
+	IsAdmin      bool       `json:"is_admin"`
+	AdminRole    string     `json:"admin_role,omitempty"`
+	Balance      float64    `json:"balance"`
+	ReferralCode string     `json:"referral_code"`
+	ReferredBy   *uuid.UUID `json:"referred_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// SubscriptionResponse подписка в ответах API
+type SubscriptionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	ServerID   int       `json:"server_id"`
+	ServerName string    `json:"server_name"`
+	PlanID     int       `json:"plan_id"`
+	PlanName   string    `json:"plan_name"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// PaymentResponse платеж в ответах API
+type PaymentResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	PaymentMethod string     `json:"payment_method"`
+	Status        string     `json:"status"`
+	ExternalID    string     `json:"external_id"`
+	Description   string     `json:"description"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// PromoCodeResponse промокод в ответах API
+type PromoCodeResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Code        string     `json:"code"`
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	MaxUses     int        `json:"max_uses"`
+	UsedCount   int        `json:"used_count"`
+	IsActive    bool       `json:"is_active"`
+	ValidFrom   time.Time  `json:"valid_from"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
+	Description string     `json:"description"`
+	CampaignID  string     `json:"campaign_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// newUserResponse переводит пользователя в ответ API
+func newUserResponse(u *models.User) UserResponse {
+	return UserResponse{
+		ID:           u.ID,
+		TelegramID:   u.TelegramID,
+		Username:     u.Username,
+		FirstName:    u.FirstName,
+		LastName:     u.LastName,
+		LanguageCode: u.LanguageCode,
+		IsBlocked:    u.IsBlocked,
+		IsAdmin:      u.IsAdmin,
+		AdminRole:    u.AdminRole,
+		Balance:      u.Balance,
+		ReferralCode: u.ReferralCode,
+		ReferredBy:   u.ReferredBy,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
+	}
+}
+
+// newSubscriptionResponse переводит подписку в ответ API
+func newSubscriptionResponse(s *models.Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		ServerID:   s.ServerID,
+		ServerName: s.ServerName,
+		PlanID:     s.PlanID,
+		PlanName:   s.PlanName,
+		Status:     s.Status,
+		ExpiresAt:  s.ExpiresAt,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// newPaymentResponse переводит платеж в ответ API
+func newPaymentResponse(p *models.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:            p.ID,
+		UserID:        p.UserID,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		PaymentMethod: p.PaymentMethod,
+		Status:        p.Status,
+		ExternalID:    p.ExternalID,
+		Description:   p.Description,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+		CompletedAt:   p.CompletedAt,
+	}
+}
+
+// newPromoCodeResponse переводит промокод в ответ API
+func newPromoCodeResponse(p *models.PromoCode) PromoCodeResponse {
+	return PromoCodeResponse{
+		ID:          p.ID,
+		Code:        p.Code,
+		Type:        p.Type,
+		Value:       p.Value,
+		MaxUses:     p.MaxUses,
+		UsedCount:   p.UsedCount,
+		IsActive:    p.IsActive,
+		ValidFrom:   p.ValidFrom,
+		ValidUntil:  p.ValidUntil,
+		Description: p.Description,
+		CampaignID:  p.CampaignID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// mapSlice переводит список моделей в список ответов API
+func mapSlice[M any, R any](items []M, convert func(*M) R) []R {
+	result := make([]R, len(items))
+	for i := range items {
+		result[i] = convert(&items[i])
+	}
+	return result
+}
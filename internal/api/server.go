@@ -0,0 +1,168 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Параметры пагинации списков
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Server REST API администрирования магазина под /api/v1
+type Server struct {
+	config              *config.Config
+	logger              logger.Logger
+	userService         services.UserService
+	subscriptionService services.SubscriptionService
+	paymentService      services.PaymentService
+	promoCodeService    services.IPromoCodeService
+	statsService        services.IStatsService
+	listService         services.IAdminListService
+	auditService        services.IAuditService
+
+	// now текущее время, подменяется в тестах
+	now func() time.Time
+}
+
+// NewServer создает REST API
+func NewServer(
+	config *config.Config,
+	logger logger.Logger,
+	userService services.UserService,
+	subscriptionService services.SubscriptionService,
+	paymentService services.PaymentService,
+	promoCodeService services.IPromoCodeService,
+	statsService services.IStatsService,
+	listService services.IAdminListService,
+	auditService services.IAuditService,
+) *Server {
+	return &Server{
+		config:              config,
+		logger:              logger,
+		userService:         userService,
+		subscriptionService: subscriptionService,
+		paymentService:      paymentService,
+		promoCodeService:    promoCodeService,
+		statsService:        statsService,
+		listService:         listService,
+		auditService:        auditService,
+		now:                 time.Now,
+	}
+}
+
+// Enabled сообщает, заданы ли секрет подписи токенов и пароль API
+func (s *Server) Enabled() bool {
+	return s.config.Security.JWTSecret != "" && s.config.Security.APIPassword != ""
+}
+
+// Register регистрирует маршруты API. Все маршруты, кроме получения токена,
+// требуют заголовок Authorization: Bearer <token>
+func (s *Server) Register(router gin.IRouter) {
+	v1 := router.Group("/api/v1")
+	v1.POST("/auth/login", s.login)
+
+	authorized := v1.Group("", s.authenticate)
+
+	authorized.GET("/users", s.listUsers)
+	authorized.GET("/users/:id", s.getUser)
+	authorized.PUT("/users/:id", s.updateUser)
+	authorized.POST("/users/:id/block", s.blockUser)
+	authorized.POST("/users/:id/unblock", s.unblockUser)
+	authorized.POST("/users/:id/balance", s.adjustBalance)
+
+	authorized.GET("/subscriptions", s.listSubscriptions)
+	authorized.GET("/subscriptions/:id", s.getSubscription)
+	authorized.POST("/subscriptions", s.createSubscription)
+	authorized.PUT("/subscriptions/:id", s.updateSubscription)
+
+	authorized.GET("/payments", s.listPayments)
+	authorized.GET("/payments/:id", s.getPayment)
+	authorized.POST("/payments", s.createPayment)
+
+	authorized.GET("/promocodes", s.listPromoCodes)
+	authorized.GET("/promocodes/:id", s.getPromoCode)
+	authorized.POST("/promocodes", s.createPromoCode)
+	authorized.PUT("/promocodes/:id", s.updatePromoCode)
+	authorized.DELETE("/promocodes/:id", s.deletePromoCode)
+
+	authorized.GET("/stats", s.getStats)
+	authorized.GET("/stats/revenue", s.getRevenue)
+}
+
+// audit записывает изменение, выполненное учетной записью API, в журнал аудита
+func (s *Server) audit(c *gin.Context, entry services.AuditEntry) {
+	entry.ActorName = subject(c)
+	// Ошибка уже залогирована сервисом, изменение не откатываем
+	_ = s.auditService.Record(entry)
+}
+
+// pagination разбирает limit и offset. При ошибке ответ уже отправлен
+func pagination(c *gin.Context) (limit, offset int, ok bool) {
+	limit, offset = defaultLimit, 0
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > maxLimit {
+			invalidField(c, "limit", "must be an integer from 1 to "+strconv.Itoa(maxLimit))
+			return 0, 0, false
+		}
+		limit = value
+	}
+	if raw := c.Query("offset"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			invalidField(c, "offset", "must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = value
+	}
+	return limit, offset, true
+}
+
+// pageResponse тело ответа со страницей списка под ключом key
+func pageResponse(key string, items interface{}, total int64, limit, offset int) gin.H {
+	return gin.H{
+		key:      items,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+}
+
+// pathID разбирает UUID из параметра пути id. При ошибке ответ уже отправлен
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		invalidField(c, "id", "must be a UUID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// queryUserID разбирает необязательный фильтр user_id. При ошибке ответ уже отправлен
+func queryUserID(c *gin.Context) (*uuid.UUID, bool) {
+	raw := c.Query("user_id")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		invalidField(c, "user_id", "must be a UUID")
+		return nil, false
+	}
+	return &id, true
+}
+
+// subject возвращает имя пользователя API из токена для логов
+func subject(c *gin.Context) string {
+	return c.GetString(subjectKey)
+}
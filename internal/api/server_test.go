@@ -0,0 +1,502 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Моки встраивают интерфейс сервиса и переопределяют только методы,
+// которые вызывает API; вызов остальных приводит к панике в тесте
+
+type mockUserService struct {
+	services.UserService
+	mock.Mock
+}
+
+func (m *mockUserService) GetUserByID(id uuid.UUID) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *mockUserService) ApplyChanges(userID uuid.UUID, changes repositories.UserChanges) (*models.User, error) {
+	args := m.Called(userID, changes)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type mockSubscriptionService struct {
+	services.SubscriptionService
+	mock.Mock
+}
+
+func (m *mockSubscriptionService) CreateSubscription(userID uuid.UUID, serverID, planID int) (*models.Subscription, error) {
+	args := m.Called(userID, serverID, planID)
+	subscription, _ := args.Get(0).(*models.Subscription)
+	return subscription, args.Error(1)
+}
+
+type mockPromoCodeService struct {
+	services.IPromoCodeService
+	mock.Mock
+}
+
+func (m *mockPromoCodeService) GetPromoCode(code string) (*models.PromoCode, error) {
+	args := m.Called(code)
+	promoCode, _ := args.Get(0).(*models.PromoCode)
+	return promoCode, args.Error(1)
+}
+
+func (m *mockPromoCodeService) CreatePromoCode(code, promoType string, value float64, maxUses int, validFrom, validUntil *time.Time, description string, createdBy uuid.UUID) (*models.PromoCode, error) {
+	args := m.Called(code, promoType, value, maxUses)
+	promoCode, _ := args.Get(0).(*models.PromoCode)
+	return promoCode, args.Error(1)
+}
+
+type mockStatsService struct {
+	services.IStatsService
+	mock.Mock
+}
+
+func (m *mockStatsService) Revenue(periodKey string, from, to *time.Time, now time.Time) (*services.PeriodStats, error) {
+	args := m.Called(periodKey, from, to)
+	stats, _ := args.Get(0).(*services.PeriodStats)
+	return stats, args.Error(1)
+}
+
+type mockListService struct {
+	services.IAdminListService
+	mock.Mock
+}
+
+func (m *mockListService) ListUsers(filter repositories.AdminListFilter, limit, offset int) ([]models.User, int64, error) {
+	args := m.Called(filter, limit, offset)
+	return args.Get(0).([]models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *mockListService) ListPayments(filter repositories.AdminListFilter, limit, offset int) ([]models.Payment, int64, error) {
+	args := m.Called(filter, limit, offset)
+	return args.Get(0).([]models.Payment), args.Get(1).(int64), args.Error(2)
+}
+
+type mockAuditService struct {
+	services.IAuditService
+	mock.Mock
+}
+
+func (m *mockAuditService) Record(entry services.AuditEntry) error {
+	return m.Called(entry).Error(0)
+}
+
+// recorded возвращает записи аудита, переданные в Record
+func (m *mockAuditService) recorded() []services.AuditEntry {
+	var entries []services.AuditEntry
+	for _, call := range m.Calls {
+		if call.Method == "Record" {
+			entries = append(entries, call.Arguments.Get(0).(services.AuditEntry))
+		}
+	}
+	return entries
+}
+
+type testAPI struct {
+	router        *gin.Engine
+	server        *Server
+	users         *mockUserService
+	subscriptions *mockSubscriptionService
+	promoCodes    *mockPromoCodeService
+	stats         *mockStatsService
+	lists         *mockListService
+	audit         *mockAuditService
+	now           time.Time
+
+	// route шаблон маршрута последнего запроса, например /api/v1/users/:id
+	route string
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Security.JWTSecret = "test-secret"
+	cfg.Security.APIUsername = "admin"
+	cfg.Security.APIPassword = "password"
+	cfg.Security.APITokenTTL = time.Hour
+	cfg.PromoCodes.MinCodeLength = 3
+	cfg.PromoCodes.MaxCodeLength = 20
+
+	api := &testAPI{
+		router:        gin.New(),
+		users:         &mockUserService{},
+		subscriptions: &mockSubscriptionService{},
+		promoCodes:    &mockPromoCodeService{},
+		stats:         &mockStatsService{},
+		lists:         &mockListService{},
+		audit:         &mockAuditService{},
+		now:           time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	api.server = NewServer(cfg, logger.New("error"), api.users, api.subscriptions, nil, api.promoCodes, api.stats, api.lists, api.audit)
+	api.audit.On("Record", mock.Anything).Return(nil)
+	api.server.now = func() time.Time { return api.now }
+	api.router.Use(func(c *gin.Context) { api.route = c.FullPath() })
+	api.server.Register(api.router)
+	RegisterDocs(api.router)
+	return api
+}
+
+// do выполняет запрос и разбирает JSON ответа в out, если out задан
+func (a *testAPI) do(t *testing.T, method, path, token string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.route = ""
+	a.router.ServeHTTP(rec, req)
+	checkResponse(t, method, a.route, rec)
+
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec
+}
+
+// login получает токен по тестовым учетным данным
+func (a *testAPI) login(t *testing.T) string {
+	t.Helper()
+	var response struct {
+		Token     string    `json:"token"`
+		TokenType string    `json:"token_type"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	rec := a.do(t, http.MethodPost, "/api/v1/auth/login", "", loginRequest{Username: "admin", Password: "password"}, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, a.now.Add(time.Hour), response.ExpiresAt)
+	return response.Token
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	token, err := signToken(secret, tokenClaims{Subject: "admin", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()})
+	require.NoError(t, err)
+
+	claims, err := parseToken(secret, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Subject)
+
+	_, err = parseToken([]byte("other"), token, now)
+	assert.ErrorIs(t, err, errTokenSignature)
+	_, err = parseToken(secret, token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, errTokenExpired)
+	_, err = parseToken(secret, "not.a.token", now)
+	assert.ErrorIs(t, err, errMalformedToken)
+}
+
+func TestAuth(t *testing.T) {
+	api := newTestAPI(t)
+
+	var failure ErrorResponse
+	rec := api.do(t, http.MethodPost, "/api/v1/auth/login", "", loginRequest{Username: "admin", Password: "wrong"}, &failure)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, CodeUnauthorized, failure.Error.Code)
+
+	rec = api.do(t, http.MethodGet, "/api/v1/users", "", nil, &failure)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, CodeUnauthorized, failure.Error.Code)
+
+	token := api.login(t)
+	api.now = api.now.Add(2 * time.Hour)
+	rec = api.do(t, http.MethodGet, "/api/v1/users", token, nil, &failure)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, errTokenExpired.Error(), failure.Error.Details["reason"])
+}
+
+func TestListUsers(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	users := []models.User{{ID: uuid.New(), TelegramID: 100, Username: "alice", Balance: 10}}
+	api.lists.On("ListUsers", repositories.AdminListFilter{Search: "ali"}, 5, 10).Return(users, int64(11), nil)
+
+	var page struct {
+		Users  []UserResponse `json:"users"`
+		Total  int64          `json:"total"`
+		Limit  int            `json:"limit"`
+		Offset int            `json:"offset"`
+	}
+	rec := api.do(t, http.MethodGet, "/api/v1/users?search=@ali&limit=5&offset=10", token, nil, &page)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(11), page.Total)
+	assert.Equal(t, 5, page.Limit)
+	assert.Equal(t, 10, page.Offset)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "alice", page.Users[0].Username)
+
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodGet, "/api/v1/users?limit=1000", token, nil, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, CodeValidation, failure.Error.Code)
+	assert.Equal(t, "limit", failure.Error.Details["field"])
+}
+
+func TestGetUser(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	missing := uuid.New()
+	api.users.On("GetUserByID", missing).Return(nil, nil)
+
+	var failure ErrorResponse
+	rec := api.do(t, http.MethodGet, "/api/v1/users/"+missing.String(), token, nil, &failure)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, CodeNotFound, failure.Error.Code)
+
+	rec = api.do(t, http.MethodGet, "/api/v1/users/123", token, nil, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "id", failure.Error.Details["field"])
+}
+
+func TestAdjustBalance(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	user := &models.User{ID: uuid.New(), TelegramID: 100, Balance: 50}
+	updated := *user
+	updated.Balance = 150
+	api.users.On("GetUserByID", user.ID).Return(user, nil)
+	api.users.On("ApplyChanges", user.ID, repositories.UserChanges{BalanceDelta: 100}).Return(&updated, nil)
+
+	var response UserResponse
+	rec := api.do(t, http.MethodPost, "/api/v1/users/"+user.ID.String()+"/balance", token, balanceRequest{Amount: 100, Reason: "bonus"}, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 150.0, response.Balance)
+
+	entries := api.audit.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditBalanceChange, entries[0].Action)
+	assert.Equal(t, "admin", entries[0].ActorName)
+	assert.Equal(t, map[string]float64{"balance": 50}, entries[0].Before)
+	assert.Equal(t, map[string]float64{"balance": 150, "amount": 100}, entries[0].After)
+	assert.Equal(t, "bonus", entries[0].Reason)
+
+	// Списание больше баланса отклоняется условным обновлением
+	api.users.On("ApplyChanges", user.ID, repositories.UserChanges{BalanceDelta: -80}).Return(nil, services.ErrUserInsufficientBalance)
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodPost, "/api/v1/users/"+user.ID.String()+"/balance", token, balanceRequest{Amount: -80}, &failure)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, CodeValidation, failure.Error.Code)
+	assert.Equal(t, "amount", failure.Error.Details["field"])
+	assert.Len(t, api.audit.recorded(), 1)
+}
+
+func TestUpdateUser(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	user := &models.User{ID: uuid.New(), TelegramID: 100, FirstName: "Иван", Balance: 50}
+	api.users.On("GetUserByID", user.ID).Return(user, nil)
+
+	// Некорректный запрос отклоняется до каких-либо изменений
+	var failure ErrorResponse
+	rec := api.do(t, http.MethodPut, "/api/v1/users/"+user.ID.String(), token, map[string]interface{}{
+		"first_name": "Петр", "is_blocked": true, "balance": -1,
+	}, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "balance", failure.Error.Details["field"])
+	api.users.AssertNotCalled(t, "ApplyChanges", mock.Anything, mock.Anything)
+
+	// Имя, блокировка и баланс применяются одним вызовом
+	firstName, blocked := "Петр", true
+	changes := repositories.UserChanges{FirstName: &firstName, IsBlocked: &blocked, BalanceDelta: -20}
+	updated := *user
+	updated.FirstName, updated.IsBlocked, updated.Balance = firstName, true, 30
+	api.users.On("ApplyChanges", user.ID, changes).Return(&updated, nil).Once()
+
+	var response UserResponse
+	rec = api.do(t, http.MethodPut, "/api/v1/users/"+user.ID.String(), token, map[string]interface{}{
+		"first_name": "Петр", "is_blocked": true, "balance": 30, "reason": "жалоба",
+	}, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Петр", response.FirstName)
+	assert.Equal(t, 30.0, response.Balance)
+	api.users.AssertNumberOfCalls(t, "ApplyChanges", 1)
+
+	var actions []string
+	for _, entry := range api.audit.recorded() {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "жалоба", entry.Reason)
+	}
+	assert.Equal(t, []string{models.AuditUserUpdate, models.AuditUserBlock, models.AuditBalanceChange}, actions)
+
+	// Списание больше баланса не применяет и остальные изменения
+	api.users.On("ApplyChanges", user.ID, changes).Return(nil, services.ErrUserInsufficientBalance).Once()
+	rec = api.do(t, http.MethodPut, "/api/v1/users/"+user.ID.String(), token, map[string]interface{}{
+		"first_name": "Петр", "is_blocked": true, "balance": 30,
+	}, &failure)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "balance", failure.Error.Details["field"])
+	assert.Len(t, api.audit.recorded(), 3)
+}
+
+func TestBlockUser(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	user := &models.User{ID: uuid.New(), TelegramID: 100}
+	blocked := true
+	api.users.On("GetUserByID", user.ID).Return(user, nil)
+	api.users.On("ApplyChanges", user.ID, repositories.UserChanges{IsBlocked: &blocked}).Return(&models.User{ID: user.ID, TelegramID: 100, IsBlocked: true}, nil)
+
+	var response UserResponse
+	rec := api.do(t, http.MethodPost, "/api/v1/users/"+user.ID.String()+"/block", token, blockRequest{Reason: "спам"}, &response)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, response.IsBlocked)
+
+	entries := api.audit.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditUserBlock, entries[0].Action)
+	assert.Equal(t, map[string]bool{"is_blocked": false}, entries[0].Before)
+	assert.Equal(t, map[string]bool{"is_blocked": true}, entries[0].After)
+	assert.Equal(t, "спам", entries[0].Reason)
+}
+
+func TestListPaymentsFilter(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	userID := uuid.New()
+	filter := repositories.AdminListFilter{UserID: &userID, Status: "completed", Method: "stars"}
+	api.lists.On("ListPayments", filter, defaultLimit, 0).Return([]models.Payment{{ID: uuid.New(), UserID: userID, Amount: 199, Status: "completed"}}, int64(1), nil)
+
+	var page struct {
+		Payments []PaymentResponse `json:"payments"`
+		Total    int64             `json:"total"`
+	}
+	rec := api.do(t, http.MethodGet, "/api/v1/payments?user_id="+userID.String()+"&status=completed&payment_method=stars", token, nil, &page)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 199.0, page.Payments[0].Amount)
+
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodGet, "/api/v1/payments?status=paid", token, nil, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "status", failure.Error.Details["field"])
+}
+
+func TestCreateSubscription(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	user := &models.User{ID: uuid.New(), TelegramID: 100}
+	api.users.On("GetUserByID", user.ID).Return(user, nil)
+	api.subscriptions.On("CreateSubscription", user.ID, 1, 2).Return(&models.Subscription{
+		ID: uuid.New(), UserID: user.ID, ServerID: 1, PlanID: 2, Status: "active",
+	}, nil)
+
+	var response SubscriptionResponse
+	rec := api.do(t, http.MethodPost, "/api/v1/subscriptions", token, createSubscriptionRequest{UserID: user.ID, ServerID: 1, PlanID: 2}, &response)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "active", response.Status)
+
+	entries := api.audit.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditSubscriptionCreate, entries[0].Action)
+	assert.Equal(t, response.ID.String(), entries[0].TargetID)
+
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodPost, "/api/v1/subscriptions", token, map[string]int{"server_id": 1}, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "user_id", failure.Error.Details["field"])
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	api.promoCodes.On("GetPromoCode", "EXISTS").Return(&models.PromoCode{Code: "EXISTS"}, nil)
+	api.promoCodes.On("GetPromoCode", "SPRING").Return(nil, nil)
+	api.promoCodes.On("CreatePromoCode", "SPRING", "discount_percent", 15.0, 100).Return(&models.PromoCode{
+		ID: uuid.New(), Code: "SPRING", Type: "discount_percent", Value: 15, MaxUses: 100, IsActive: true,
+	}, nil)
+
+	var response PromoCodeResponse
+	rec := api.do(t, http.MethodPost, "/api/v1/promocodes", token, createPromoCodeRequest{
+		Code: "spring", Type: "discount_percent", Value: 15, MaxUses: 100,
+	}, &response)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "SPRING", response.Code)
+
+	entries := api.audit.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditPromoCreate, entries[0].Action)
+	assert.Equal(t, "SPRING", entries[0].TargetID)
+
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodPost, "/api/v1/promocodes", token, createPromoCodeRequest{
+		Code: "exists", Type: "bonus_days", Value: 7,
+	}, &failure)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "code", failure.Error.Details["field"])
+
+	rec = api.do(t, http.MethodPost, "/api/v1/promocodes", token, createPromoCodeRequest{
+		Code: "percent", Type: "discount_percent", Value: 150,
+	}, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "value", failure.Error.Details["field"])
+}
+
+func TestRevenue(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login(t)
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	api.stats.On("Revenue", "week", &from, &to).Return(&services.PeriodStats{
+		From: from, To: to, Revenue: 300, Payments: 3,
+		Methods: []services.MethodRevenue{{Method: "stars", Amount: 100, Count: 1}, {Method: "yookassa", Amount: 200, Count: 2}},
+		Points:  []services.RevenuePoint{{Start: from, Total: 100, Count: 1}, {Start: from.AddDate(0, 0, 1), Total: 200, Count: 2}},
+	}, nil)
+
+	var response RevenueResponse
+	rec := api.do(t, http.MethodGet, "/api/v1/stats/revenue?period=week&start_date=2026-03-01T00:00:00Z&end_date=2026-03-03T00:00:00Z", token, nil, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 300.0, response.Revenue)
+	assert.Equal(t, 100.0, response.AveragePayment)
+	assert.Equal(t, map[string]float64{"stars": 100, "yookassa": 200}, response.ByMethod)
+	assert.Len(t, response.Points, 2)
+
+	var failure ErrorResponse
+	rec = api.do(t, http.MethodGet, "/api/v1/stats/revenue?period=decade", token, nil, &failure)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "period", failure.Error.Details["field"])
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevenueResponse выручка за интервал
+type RevenueResponse struct {
+	Period         string             `json:"period"`
+	StartDate      time.Time          `json:"start_date"`
+	EndDate        time.Time          `json:"end_date"`
+	Revenue        float64            `json:"revenue"`
+	PaymentsCount  int64              `json:"payments_count"`
+	AveragePayment float64            `json:"average_payment"`
+	ByMethod       map[string]float64 `json:"by_method"`
+	Points         []RevenuePoint     `json:"points"`
+}
+
+// RevenuePoint выручка за шаг интервала
+type RevenuePoint struct {
+	Start         time.Time `json:"start"`
+	Revenue       float64   `json:"revenue"`
+	PaymentsCount int64     `json:"payments_count"`
+}
+
+// getStats GET /stats
+func (s *Server) getStats(c *gin.Context) {
+	overview, err := s.statsService.Overview(s.now())
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// getRevenue GET /stats/revenue. Период задает шаг разбивки и интервал по
+// умолчанию; start_date и end_date принимают RFC 3339 или ГГГГ-ММ-ДД
+func (s *Server) getRevenue(c *gin.Context) {
+	periodKey := c.DefaultQuery("period", "month")
+	if _, err := services.GetStatsPeriod(periodKey); err != nil {
+		invalidField(c, "period", "must be one of day, week, month, year")
+		return
+	}
+
+	from, ok := queryDate(c, "start_date")
+	if !ok {
+		return
+	}
+	to, ok := queryDate(c, "end_date")
+	if !ok {
+		return
+	}
+
+	stats, err := s.statsService.Revenue(periodKey, from, to, s.now())
+	if errors.Is(err, services.ErrInvalidStatsRange) {
+		invalidField(c, "end_date", "must be after start_date")
+		return
+	}
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	response := RevenueResponse{
+		Period:        periodKey,
+		StartDate:     stats.From,
+		EndDate:       stats.To,
+		Revenue:       stats.Revenue,
+		PaymentsCount: stats.Payments,
+		ByMethod:      make(map[string]float64, len(stats.Methods)),
+		Points:        make([]RevenuePoint, len(stats.Points)),
+	}
+	if stats.Payments > 0 {
+		response.AveragePayment = stats.Revenue / float64(stats.Payments)
+	}
+	for _, method := range stats.Methods {
+		response.ByMethod[method.Method] = method.Amount
+	}
+	for i, point := range stats.Points {
+		response.Points[i] = RevenuePoint{Start: point.Start, Revenue: point.Total, PaymentsCount: point.Count}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// queryDate разбирает необязательную дату из параметра запроса. Дата без
+// времени означает начало суток по местному времени. При ошибке ответ уже отправлен
+func queryDate(c *gin.Context, name string) (*time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, time.Local); err == nil {
+		return &t, true
+	}
+	invalidField(c, name, "must be RFC 3339 or YYYY-MM-DD")
+	return nil, false
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"net/http"
+
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// subscriptionStatuses допустимые статусы подписки в фильтре
+var subscriptionStatuses = map[string]bool{"active": true, "expired": true, "cancelled": true, "suspended": true}
+
+// createSubscriptionRequest новая подписка на сервер и план Remnawave
+type createSubscriptionRequest struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ServerID int       `json:"server_id"`
+	PlanID   int       `json:"plan_id"`
+}
+
+// updateSubscriptionRequest изменение подписки: отмена и продление или
+// сокращение на несколько дней
+type updateSubscriptionRequest struct {
+	Status *string `json:"status"`
+	Days   *int    `json:"days"`
+	Reason string  `json:"reason"`
+}
+
+// listSubscriptions GET /subscriptions
+func (s *Server) listSubscriptions(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	if status != "" && !subscriptionStatuses[status] {
+		invalidField(c, "status", "must be one of active, expired, cancelled, suspended")
+		return
+	}
+
+	filter := repositories.AdminListFilter{UserID: userID, Status: status}
+	subscriptions, total, err := s.listService.ListSubscriptions(filter, limit, offset)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("subscriptions", mapSlice(subscriptions, newSubscriptionResponse), total, limit, offset))
+}
+
+// getSubscription GET /subscriptions/:id
+func (s *Server) getSubscription(c *gin.Context) {
+	subscription, ok := s.loadSubscription(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newSubscriptionResponse(subscription))
+}
+
+// createSubscription POST /subscriptions
+func (s *Server) createSubscription(c *gin.Context) {
+	var request createSubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	switch {
+	case request.UserID == uuid.Nil:
+		invalidField(c, "user_id", "is required")
+		return
+	case request.ServerID <= 0:
+		invalidField(c, "server_id", "must be positive")
+		return
+	case request.PlanID <= 0:
+		invalidField(c, "plan_id", "must be positive")
+		return
+	}
+
+	user, err := s.userService.GetUserByID(request.UserID)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+	if user == nil {
+		notFound(c, "User")
+		return
+	}
+
+	subscription, err := s.subscriptionService.CreateSubscription(user.ID, request.ServerID, request.PlanID)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	s.audit(c, services.AuditEntry{
+		Action:     models.AuditSubscriptionCreate,
+		TargetUser: user,
+		TargetType: "subscription",
+		TargetID:   subscription.ID.String(),
+		After: map[string]interface{}{
+			"server_id":  subscription.ServerID,
+			"plan_id":    subscription.PlanID,
+			"expires_at": subscription.ExpiresAt,
+		},
+	})
+	s.logger.Info("API subscription created", "api_user", subject(c), "telegram_id", user.TelegramID,
+		"subscription_id", subscription.ID)
+	c.JSON(http.StatusCreated, newSubscriptionResponse(subscription))
+}
+
+// updateSubscription PUT /subscriptions/:id
+func (s *Server) updateSubscription(c *gin.Context) {
+	subscription, ok := s.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	var request updateSubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if request.Status != nil && *request.Status != "cancelled" {
+		invalidField(c, "status", "only cancelled can be set")
+		return
+	}
+	if request.Days != nil && *request.Days == 0 {
+		invalidField(c, "days", "must not be zero")
+		return
+	}
+
+	user, err := s.userService.GetUserByID(subscription.UserID)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+	entry := services.AuditEntry{
+		TargetUser: user,
+		TargetType: "subscription",
+		TargetID:   subscription.ID.String(),
+		Reason:     request.Reason,
+	}
+
+	if request.Days != nil {
+		expiresBefore := subscription.ExpiresAt
+		updated, err := s.subscriptionService.AdjustDays(subscription.ID, *request.Days)
+		if err != nil {
+			s.internalError(c, err)
+			return
+		}
+		subscription = updated
+
+		entry.Action = models.AuditSubscriptionDays
+		entry.Before = map[string]interface{}{"expires_at": expiresBefore}
+		entry.After = map[string]interface{}{"expires_at": subscription.ExpiresAt, "days": *request.Days}
+		s.audit(c, entry)
+	}
+	if request.Status != nil && subscription.Status != *request.Status {
+		if err := s.subscriptionService.CancelSubscription(subscription.ID); err != nil {
+			s.internalError(c, err)
+			return
+		}
+
+		entry.Action = models.AuditSubscriptionCancel
+		entry.Before = map[string]string{"status": subscription.Status}
+		entry.After = map[string]string{"status": *request.Status}
+		s.audit(c, entry)
+		subscription.Status = *request.Status
+	}
+
+	s.logger.Info("API subscription updated", "api_user", subject(c), "subscription_id", subscription.ID,
+		"status", subscription.Status, "expires_at", subscription.ExpiresAt)
+	c.JSON(http.StatusOK, newSubscriptionResponse(subscription))
+}
+
+// loadSubscription загружает подписку из параметра пути. При ошибке ответ уже отправлен
+func (s *Server) loadSubscription(c *gin.Context) (*models.Subscription, bool) {
+	id, ok := pathID(c)
+	if !ok {
+		return nil, false
+	}
+
+	subscription, err := s.subscriptionService.GetSubscription(id)
+	if err != nil {
+		s.internalError(c, err)
+		return nil, false
+	}
+	if subscription == nil {
+		notFound(c, "Subscription")
+		return nil, false
+	}
+	return subscription, true
+}
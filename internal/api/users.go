@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updateUserRequest изменяемые поля пользователя. Баланс задается итоговым
+// значением, разница зачисляется или списывается как при ручной корректировке
+type updateUserRequest struct {
+	FirstName *string  `json:"first_name"`
+	LastName  *string  `json:"last_name"`
+	IsBlocked *bool    `json:"is_blocked"`
+	Balance   *float64 `json:"balance"`
+	Reason    string   `json:"reason"`
+}
+
+// balanceRequest корректировка баланса: положительная сумма — пополнение, отрицательная — списание
+type balanceRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// blockRequest необязательная причина блокировки
+type blockRequest struct {
+	Reason string `json:"reason"`
+}
+
+// listUsers GET /users
+func (s *Server) listUsers(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	filter := repositories.AdminListFilter{Search: strings.TrimPrefix(strings.TrimSpace(c.Query("search")), "@")}
+	users, total, err := s.listService.ListUsers(filter, limit, offset)
+	if err != nil {
+		s.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("users", mapSlice(users, newUserResponse), total, limit, offset))
+}
+
+// getUser GET /users/:id
+func (s *Server) getUser(c *gin.Context) {
+	user, ok := s.loadUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// updateUser PUT /users/:id
+func (s *Server) updateUser(c *gin.Context) {
+	user, ok := s.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var request updateUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if request.Balance != nil && *request.Balance < 0 {
+		invalidField(c, "balance", "must not be negative")
+		return
+	}
+
+	var changes repositories.UserChanges
+	if request.FirstName != nil && *request.FirstName != user.FirstName {
+		changes.FirstName = request.FirstName
+	}
+	if request.LastName != nil && *request.LastName != user.LastName {
+		changes.LastName = request.LastName
+	}
+	if request.IsBlocked != nil && *request.IsBlocked != user.IsBlocked {
+		changes.IsBlocked = request.IsBlocked
+	}
+	if request.Balance != nil {
+		changes.BalanceDelta = *request.Balance - user.Balance
+	}
+	if changes == (repositories.UserChanges{}) {
+		c.JSON(http.StatusOK, newUserResponse(user))
+		return
+	}
+
+	updated, ok := s.applyChanges(c, user, changes, request.Reason, "balance")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(updated))
+}
+
+// blockUser POST /users/:id/block
+func (s *Server) blockUser(c *gin.Context) {
+	s.toggleBlock(c, true)
+}
+
+// unblockUser POST /users/:id/unblock
+func (s *Server) unblockUser(c *gin.Context) {
+	s.toggleBlock(c, false)
+}
+
+// toggleBlock блокирует или разблокирует пользователя с необязательной причиной
+func (s *Server) toggleBlock(c *gin.Context, blocked bool) {
+	user, ok := s.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var request blockRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			invalidBody(c, err)
+			return
+		}
+	}
+
+	if user.IsBlocked == blocked {
+		c.JSON(http.StatusOK, newUserResponse(user))
+		return
+	}
+	updated, ok := s.applyChanges(c, user, repositories.UserChanges{IsBlocked: &blocked}, request.Reason, "")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(updated))
+}
+
+// adjustBalance POST /users/:id/balance
+func (s *Server) adjustBalance(c *gin.Context) {
+	user, ok := s.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var request balanceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if request.Amount == 0 {
+		invalidField(c, "amount", "must not be zero")
+		return
+	}
+
+	updated, ok := s.applyChanges(c, user, repositories.UserChanges{BalanceDelta: request.Amount}, request.Reason, "amount")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(updated))
+}
+
+// loadUser загружает пользователя из параметра пути. При ошибке ответ уже отправлен
+func (s *Server) loadUser(c *gin.Context) (*models.User, bool) {
+	id, ok := pathID(c)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := s.userService.GetUserByID(id)
+	if err != nil {
+		s.internalError(c, err)
+		return nil, false
+	}
+	if user == nil {
+		notFound(c, "User")
+		return nil, false
+	}
+	return user, true
+}
+
+// applyChanges применяет изменения пользователя одной транзакцией и записывает
+// их в журнал аудита. balanceField — поле запроса, на которое указывает ошибка
+// списания больше баланса. При ошибке ответ уже отправлен
+func (s *Server) applyChanges(c *gin.Context, user *models.User, changes repositories.UserChanges, reason, balanceField string) (*models.User, bool) {
+	updated, err := s.userService.ApplyChanges(user.ID, changes)
+	switch {
+	case errors.Is(err, services.ErrUserInsufficientBalance):
+		abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, "Insufficient balance", map[string]string{
+			"field":  balanceField,
+			"reason": "exceeds current balance",
+		})
+		return nil, false
+	case errors.Is(err, services.ErrUserNotFound):
+		notFound(c, "User")
+		return nil, false
+	case err != nil:
+		s.internalError(c, err)
+		return nil, false
+	}
+
+	if changes.FirstName != nil || changes.LastName != nil {
+		s.audit(c, services.AuditEntry{
+			Action:     models.AuditUserUpdate,
+			TargetUser: updated,
+			Before:     map[string]string{"first_name": user.FirstName, "last_name": user.LastName},
+			After:      map[string]string{"first_name": updated.FirstName, "last_name": updated.LastName},
+			Reason:     reason,
+		})
+	}
+	if changes.IsBlocked != nil {
+		action := models.AuditUserUnblock
+		if updated.IsBlocked {
+			action = models.AuditUserBlock
+		}
+		s.audit(c, services.AuditEntry{
+			Action:     action,
+			TargetUser: updated,
+			Before:     map[string]bool{"is_blocked": user.IsBlocked},
+			After:      map[string]bool{"is_blocked": updated.IsBlocked},
+			Reason:     reason,
+		})
+	}
+	if changes.BalanceDelta != 0 {
+		s.audit(c, services.AuditEntry{
+			Action:     models.AuditBalanceChange,
+			TargetUser: updated,
+			Before:     map[string]float64{"balance": updated.Balance - changes.BalanceDelta},
+			After:      map[string]float64{"balance": updated.Balance, "amount": changes.BalanceDelta},
+			Reason:     reason,
+		})
+	}
+
+	s.logger.Info("API user changed", "api_user", subject(c), "telegram_id", user.TelegramID,
+		"is_blocked", updated.IsBlocked, "balance_delta", changes.BalanceDelta, "reason", reason)
+	return updated, true
+}
@@ -0,0 +1,418 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// webAppUserKey ключ контекста gin с пользователем Mini App
+const webAppUserKey = "webapp_user"
+
+// initDataScheme схема заголовка Authorization с initData Mini App
+const initDataScheme = "tma "
+
+// WebApp API Telegram Mini App под /api/webapp. Запросы подписываются
+// initData, который Telegram передает Mini App при открытии
+type WebApp struct {
+	config              *config.Config
+	logger              logger.Logger
+	userService         services.UserService
+	subscriptionService services.SubscriptionService
+	purchaseService     services.IPurchaseService
+	topUpService        services.ITopUpService
+	referralService     services.IReferralService
+	partnerService      services.IPartnerService
+	maintenanceService  services.IMaintenanceService
+	bundle              *i18n.Bundle
+
+	// now текущее время, подменяется в тестах
+	now func() time.Time
+}
+
+// NewWebApp создает API Mini App
+func NewWebApp(
+	config *config.Config,
+	logger logger.Logger,
+	userService services.UserService,
+	subscriptionService services.SubscriptionService,
+	purchaseService services.IPurchaseService,
+	topUpService services.ITopUpService,
+	referralService services.IReferralService,
+	partnerService services.IPartnerService,
+	maintenanceService services.IMaintenanceService,
+	bundle *i18n.Bundle,
+) *WebApp {
+	return &WebApp{
+		config:              config,
+		logger:              logger,
+		userService:         userService,
+		subscriptionService: subscriptionService,
+		purchaseService:     purchaseService,
+		topUpService:        topUpService,
+		referralService:     referralService,
+		partnerService:      partnerService,
+		maintenanceService:  maintenanceService,
+		bundle:              bundle,
+		now:                 time.Now,
+	}
+}
+
+// Register регистрирует маршруты Mini App. Все маршруты требуют заголовок
+// Authorization: tma <initData>
+func (w *WebApp) Register(router gin.IRouter) {
+	group := router.Group("/api/webapp", w.authenticate)
+
+	group.GET("/me", w.getMe)
+	group.GET("/subscriptions", w.listSubscriptions)
+	group.GET("/access", w.getAccess)
+	group.GET("/balance", w.getBalance)
+	group.GET("/tariffs", w.listTariffs)
+	group.POST("/purchase", w.purchase)
+	group.POST("/topup", w.createTopUp)
+	group.GET("/referrals", w.getReferrals)
+}
+
+// authenticate проверяет initData и загружает пользователя, создавая его при
+// первом открытии Mini App
+func (w *WebApp) authenticate(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, initDataScheme) {
+		abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "Missing init data", nil)
+		return
+	}
+
+	from, err := parseInitData(w.config.BotToken, strings.TrimPrefix(header, initDataScheme), w.now(), w.config.MiniApp.InitDataTTL)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "Invalid init data", map[string]string{"reason": err.Error()})
+		return
+	}
+
+	// Во время технических работ Mini App закрыт, как и бот, кроме администраторов.
+	// Проверка идет до обращения к базе данных, которая может быть недоступна
+	if w.maintenanceService.IsEnabled() && !w.userService.IsAdmin(from.ID) {
+		text := w.maintenanceService.Message(w.bundle.For(from.LanguageCode).T("maintenance.message"))
+		abortWithError(c, http.StatusServiceUnavailable, CodeUnavailable, text, nil)
+		return
+	}
+
+	user, err := w.userService.CreateOrGetUser(from.ID, from.Username, from.FirstName, from.LastName, from.LanguageCode)
+	if err != nil {
+		w.internalError(c, err)
+		return
+	}
+	if user.IsBlocked {
+		abortWithError(c, http.StatusForbidden, CodeForbidden, "User is blocked", nil)
+		return
+	}
+
+	c.Set(webAppUserKey, user)
+	c.Next()
+}
+
+// currentUser возвращает пользователя Mini App из контекста
+func currentUser(c *gin.Context) *models.User {
+	return c.MustGet(webAppUserKey).(*models.User)
+}
+
+// WebAppUserResponse профиль пользователя Mini App
+type WebAppUserResponse struct {
+	TelegramID   int64   `json:"telegram_id"`
+	Username     string  `json:"username"`
+	FirstName    string  `json:"first_name"`
+	LastName     string  `json:"last_name"`
+	LanguageCode string  `json:"language_code"`
+	Balance      float64 `json:"balance"`
+	ReferralCode string  `json:"referral_code"`
+}
+
+// AccessResponse ссылка подписки и трафик из Remnawave
+type AccessResponse struct {
+	SubscriptionURL string          `json:"subscription_url"`
+	Status          string          `json:"status"`
+	ExpireAt        *time.Time      `json:"expire_at,omitempty"`
+	OnlineAt        *time.Time      `json:"online_at,omitempty"`
+	Traffic         TrafficResponse `json:"traffic"`
+}
+
+// TrafficResponse расход трафика. Лимит 0 означает безлимитный трафик
+type TrafficResponse struct {
+	UsedBytes  int64  `json:"used_bytes"`
+	LimitBytes int64  `json:"limit_bytes"`
+	Strategy   string `json:"strategy"`
+}
+
+// BalanceResponse баланс и условия пополнения
+type BalanceResponse struct {
+	Balance      float64 `json:"balance"`
+	Currency     string  `json:"currency"`
+	StarsEnabled bool    `json:"stars_enabled"`
+	StarsRate    float64 `json:"stars_rate"`
+	MinTopUp     float64 `json:"min_top_up"`
+	MaxTopUp     float64 `json:"max_top_up"`
+}
+
+// TariffResponse тариф с ценой для пользователя
+type TariffResponse struct {
+	Key          string  `json:"key"`
+	Name         string  `json:"name"`
+	Emoji        string  `json:"emoji"`
+	DurationDays int     `json:"duration_days"`
+	Price        float64 `json:"price"`
+	Discount     float64 `json:"discount"`
+	Total        float64 `json:"total"`
+	// PromoCode промокод ожидающей скидки, DiscountNote — почему она не применена
+	PromoCode    string `json:"promo_code,omitempty"`
+	DiscountNote string `json:"discount_note,omitempty"`
+}
+
+// PurchaseResponse результат покупки тарифа
+type PurchaseResponse struct {
+	Tariff  TariffResponse `json:"tariff"`
+	Balance float64        `json:"balance"`
+}
+
+// TopUpResponse счет на пополнение, который Mini App открывает через openInvoice
+type TopUpResponse struct {
+	PaymentID  uuid.UUID `json:"payment_id"`
+	InvoiceURL string    `json:"invoice_url"`
+	Amount     float64   `json:"amount"`
+	Stars      int       `json:"stars"`
+}
+
+// ReferralsResponse реферальная программа пользователя
+type ReferralsResponse struct {
+	ReferralCode string               `json:"referral_code"`
+	ReferralLink string               `json:"referral_link"`
+	Invited      int                  `json:"invited"`
+	Earned       float64              `json:"earned"`
+	Partner      *models.PartnerStats `json:"partner,omitempty"`
+}
+
+// purchaseRequest покупка тарифа с баланса
+type purchaseRequest struct {
+	Tariff string `json:"tariff"`
+}
+
+// topUpRequest пополнение баланса на сумму в рублях
+type topUpRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// getMe GET /me
+func (w *WebApp) getMe(c *gin.Context) {
+	user := currentUser(c)
+	c.JSON(http.StatusOK, WebAppUserResponse{
+		TelegramID:   user.TelegramID,
+		Username:     user.Username,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		LanguageCode: user.LanguageCode,
+		Balance:      user.Balance,
+		ReferralCode: user.ReferralCode,
+	})
+}
+
+// listSubscriptions GET /subscriptions
+func (w *WebApp) listSubscriptions(c *gin.Context) {
+	subscriptions, err := w.subscriptionService.GetUserSubscriptions(currentUser(c).ID)
+	if err != nil {
+		w.internalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": mapSlice(subscriptions, newSubscriptionResponse)})
+}
+
+// getAccess GET /access. Без активной подписки Remnawave не запрашивается
+func (w *WebApp) getAccess(c *gin.Context) {
+	user := currentUser(c)
+	active, err := w.subscriptionService.GetActiveSubscriptions(user.ID)
+	if err != nil {
+		w.internalError(c, err)
+		return
+	}
+	if len(active) == 0 {
+		notFound(c, "Active subscription")
+		return
+	}
+
+	access, err := w.subscriptionService.GetAccess(user.TelegramID)
+	if err != nil {
+		w.logger.Error("Failed to get subscription access", "error", err, "telegram_id", user.TelegramID)
+		abortWithError(c, http.StatusServiceUnavailable, CodeUnavailable, "Subscription service unavailable", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, AccessResponse{
+		SubscriptionURL: access.SubscriptionURL,
+		Status:          access.Status,
+		ExpireAt:        access.ExpireAt,
+		OnlineAt:        access.OnlineAt,
+		Traffic: TrafficResponse{
+			UsedBytes:  access.UsedTrafficBytes,
+			LimitBytes: access.TrafficLimitBytes,
+			Strategy:   access.TrafficLimitStrategy,
+		},
+	})
+}
+
+// getBalance GET /balance
+func (w *WebApp) getBalance(c *gin.Context) {
+	settings := w.config.Settings()
+	c.JSON(http.StatusOK, BalanceResponse{
+		Balance:      currentUser(c).Balance,
+		Currency:     "RUB",
+		StarsEnabled: settings.Payments.StarsEnabled,
+		StarsRate:    settings.Payments.StarsRate,
+		MinTopUp:     w.config.MiniApp.MinTopUp,
+		MaxTopUp:     w.config.MiniApp.MaxTopUp,
+	})
+}
+
+// listTariffs GET /tariffs. Цены учитывают ожидающую скидку по промокоду
+func (w *WebApp) listTariffs(c *gin.Context) {
+	user := currentUser(c)
+	tariffs := make([]TariffResponse, 0, len(models.Tariffs()))
+	for _, tariff := range models.Tariffs() {
+		quote, err := w.purchaseService.Quote(user.ID, tariff.Key)
+		if err != nil {
+			w.internalError(c, err)
+			return
+		}
+		tariffs = append(tariffs, newTariffResponse(quote))
+	}
+	c.JSON(http.StatusOK, gin.H{"tariffs": tariffs})
+}
+
+// purchase POST /purchase
+func (w *WebApp) purchase(c *gin.Context) {
+	var request purchaseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+
+	user := currentUser(c)
+	quote, err := w.purchaseService.Quote(user.ID, request.Tariff)
+	if errors.Is(err, services.ErrPurchaseUnknownTariff) {
+		invalidField(c, "tariff", "unknown tariff")
+		return
+	}
+	if err != nil {
+		w.internalError(c, err)
+		return
+	}
+
+	balance, err := w.purchaseService.Purchase(user, quote)
+	switch {
+	case errors.Is(err, services.ErrPurchaseInsufficientBalance):
+		abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, "Insufficient balance", map[string]string{
+			"field":  "tariff",
+			"reason": "total exceeds current balance",
+		})
+		return
+	case errors.Is(err, services.ErrPurchaseDiscountUsed):
+		abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, "Discount already used", map[string]string{
+			"field":  "tariff",
+			"reason": "promo code discount was applied to another purchase, request the price again",
+		})
+		return
+	case err != nil:
+		w.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PurchaseResponse{Tariff: newTariffResponse(quote), Balance: balance})
+}
+
+// createTopUp POST /topup
+func (w *WebApp) createTopUp(c *gin.Context) {
+	var request topUpRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+
+	invoice, err := w.topUpService.CreateInvoice(currentUser(c).ID, request.Amount)
+	switch {
+	case errors.Is(err, services.ErrTopUpAmount):
+		invalidField(c, "amount", "must be within min_top_up and max_top_up")
+		return
+	case errors.Is(err, services.ErrTopUpDisabled):
+		abortWithError(c, http.StatusUnprocessableEntity, CodeValidation, "Telegram Stars top up is disabled", nil)
+		return
+	case err != nil:
+		w.internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, TopUpResponse{
+		PaymentID:  invoice.Payment.ID,
+		InvoiceURL: invoice.URL,
+		Amount:     invoice.Payment.Amount,
+		Stars:      invoice.Stars,
+	})
+}
+
+// getReferrals GET /referrals
+func (w *WebApp) getReferrals(c *gin.Context) {
+	user := currentUser(c)
+	referrals, err := w.userService.GetReferrals(user.ID)
+	if err != nil {
+		w.internalError(c, err)
+		return
+	}
+
+	response := ReferralsResponse{
+		ReferralCode: user.ReferralCode,
+		ReferralLink: w.referralService.ReferralLink(user),
+		Invited:      len(referrals),
+		Earned:       user.ReferralBonusEarned,
+	}
+	if w.partnerService.IsEnabled() {
+		stats, err := w.partnerService.GetStats(user.ID)
+		if err != nil {
+			w.internalError(c, err)
+			return
+		}
+		response.Partner = stats
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// newTariffResponse переводит расчет стоимости тарифа в ответ API
+func newTariffResponse(quote *services.PurchaseQuote) TariffResponse {
+	response := TariffResponse{
+		Key:          quote.Tariff.Key,
+		Name:         quote.Tariff.Name,
+		Emoji:        quote.Tariff.Emoji,
+		DurationDays: quote.Tariff.DurationDays,
+		Price:        quote.Tariff.Price,
+		Discount:     quote.Discount,
+		Total:        quote.Total,
+	}
+	if quote.DiscountUsage != nil {
+		response.PromoCode = quote.DiscountUsage.PromoCode.Code
+	}
+	if quote.DiscountErr != nil {
+		response.DiscountNote = quote.DiscountErr.Error()
+	}
+	return response
+}
+
+// internalError логирует ошибку и отвечает 500 без подробностей
+func (w *WebApp) internalError(c *gin.Context, err error) {
+	w.logger.Error("Mini App request failed", "error", err, "method", c.Request.Method, "path", c.FullPath())
+	abortWithError(c, http.StatusInternalServerError, CodeInternal, "Internal server error", nil)
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotToken = "123456:test-token"
+
+func (m *mockUserService) CreateOrGetUser(telegramID int64, username, firstName, lastName, languageCode string) (*models.User, error) {
+	args := m.Called(telegramID)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *mockUserService) IsAdmin(telegramID int64) bool {
+	return m.Called(telegramID).Bool(0)
+}
+
+// fakeMaintenanceService режим обслуживания, переключаемый тестом
+type fakeMaintenanceService struct {
+	services.IMaintenanceService
+	enabled bool
+}
+
+func (m *fakeMaintenanceService) IsEnabled() bool {
+	return m.enabled
+}
+
+func (m *fakeMaintenanceService) Message(defaultText string) string {
+	return defaultText
+}
+
+func (m *mockSubscriptionService) GetActiveSubscriptions(userID uuid.UUID) ([]models.Subscription, error) {
+	args := m.Called(userID)
+	subscriptions, _ := args.Get(0).([]models.Subscription)
+	return subscriptions, args.Error(1)
+}
+
+type mockPurchaseService struct {
+	services.IPurchaseService
+	mock.Mock
+}
+
+func (m *mockPurchaseService) Quote(userID uuid.UUID, tariffKey string) (*services.PurchaseQuote, error) {
+	args := m.Called(userID, tariffKey)
+	quote, _ := args.Get(0).(*services.PurchaseQuote)
+	return quote, args.Error(1)
+}
+
+func (m *mockPurchaseService) Purchase(user *models.User, quote *services.PurchaseQuote) (float64, error) {
+	args := m.Called(user, quote)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+type mockTopUpService struct {
+	services.ITopUpService
+	mock.Mock
+}
+
+func (m *mockTopUpService) CreateInvoice(userID uuid.UUID, amount float64) (*services.TopUpInvoice, error) {
+	args := m.Called(userID, amount)
+	invoice, _ := args.Get(0).(*services.TopUpInvoice)
+	return invoice, args.Error(1)
+}
+
+type testWebApp struct {
+	router        *gin.Engine
+	users         *mockUserService
+	subscriptions *mockSubscriptionService
+	purchases     *mockPurchaseService
+	topUps        *mockTopUpService
+	maintenance   *fakeMaintenanceService
+	user          *models.User
+	now           time.Time
+	route         string
+}
+
+func newTestWebApp(t *testing.T) *testWebApp {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{BotToken: testBotToken}
+	cfg.MiniApp.InitDataTTL = time.Hour
+	bundle, err := i18n.Load("ru", []string{"ru", "en"})
+	require.NoError(t, err)
+
+	w := &testWebApp{
+		router:        gin.New(),
+		users:         &mockUserService{},
+		subscriptions: &mockSubscriptionService{},
+		purchases:     &mockPurchaseService{},
+		topUps:        &mockTopUpService{},
+		maintenance:   &fakeMaintenanceService{},
+		user:          &models.User{ID: uuid.New(), TelegramID: 42, Username: "alice", Balance: 100, ReferralCode: "REF42"},
+		now:           time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	webApp := NewWebApp(cfg, logger.New("error"), w.users, w.subscriptions, w.purchases, w.topUps, nil, nil, w.maintenance, bundle)
+	webApp.now = func() time.Time { return w.now }
+	w.router.Use(func(c *gin.Context) { w.route = c.FullPath() })
+	webApp.Register(w.router)
+	return w
+}
+
+// signInitData формирует initData, подписанную токеном бота
+func signInitData(botToken string, user initDataUser, authDate time.Time) string {
+	data, _ := json.Marshal(user)
+	values := url.Values{}
+	values.Set("user", string(data))
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("query_id", "AAHdF6IQAAAAAN0XohDhrOrc")
+	values.Set("hash", initDataSignature(botToken, values))
+	return values.Encode()
+}
+
+// do выполняет запрос от имени тестового пользователя с валидной initData
+func (w *testWebApp) do(t *testing.T, method, path string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	w.users.On("CreateOrGetUser", w.user.TelegramID).Return(w.user, nil).Maybe()
+	initData := signInitData(testBotToken, initDataUser{ID: w.user.TelegramID, Username: w.user.Username}, w.now)
+	return w.doWithAuth(t, method, path, "tma "+initData, body, out)
+}
+
+func (w *testWebApp) doWithAuth(t *testing.T, method, path, authorization string, body interface{}, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	w.route = ""
+	w.router.ServeHTTP(rec, req)
+	checkResponse(t, method, w.route, rec)
+
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec
+}
+
+func TestParseInitData(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	raw := signInitData(testBotToken, initDataUser{ID: 42, FirstName: "Alice", LanguageCode: "en"}, now)
+
+	user, err := parseInitData(testBotToken, raw, now.Add(time.Minute), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), user.ID)
+	assert.Equal(t, "Alice", user.FirstName)
+	assert.Equal(t, "en", user.LanguageCode)
+
+	_, err = parseInitData("654321:other-token", raw, now, time.Hour)
+	assert.ErrorIs(t, err, errInitDataSignature)
+
+	_, err = parseInitData(testBotToken, raw, now.Add(2*time.Hour), time.Hour)
+	assert.ErrorIs(t, err, errInitDataExpired)
+
+	values, _ := url.ParseQuery(raw)
+	values.Set("user", `{"id":1}`)
+	_, err = parseInitData(testBotToken, values.Encode(), now, time.Hour)
+	assert.ErrorIs(t, err, errInitDataSignature, "подмена пользователя ломает подпись")
+
+	_, err = parseInitData(testBotToken, "auth_date=1", now, time.Hour)
+	assert.ErrorIs(t, err, errInitDataMalformed)
+}
+
+func TestWebAppAuthentication(t *testing.T) {
+	w := newTestWebApp(t)
+
+	rec := w.doWithAuth(t, http.MethodGet, "/api/webapp/me", "", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	expired := signInitData(testBotToken, initDataUser{ID: 42}, w.now.Add(-2*time.Hour))
+	var response ErrorResponse
+	rec = w.doWithAuth(t, http.MethodGet, "/api/webapp/me", "tma "+expired, nil, &response)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, errInitDataExpired.Error(), response.Error.Details["reason"])
+
+	w.user.IsBlocked = true
+	rec = w.do(t, http.MethodGet, "/api/webapp/me", nil, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWebAppMaintenance(t *testing.T) {
+	w := newTestWebApp(t)
+	w.maintenance.enabled = true
+	w.users.On("IsAdmin", w.user.TelegramID).Return(false).Once()
+
+	var response ErrorResponse
+	rec := w.do(t, http.MethodGet, "/api/webapp/me", nil, &response)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, CodeUnavailable, response.Error.Code)
+	assert.Contains(t, response.Error.Message, "технические работы")
+	w.users.AssertNotCalled(t, "CreateOrGetUser", w.user.TelegramID)
+
+	// Администраторы пользуются Mini App и во время работ
+	w.users.On("IsAdmin", w.user.TelegramID).Return(true).Once()
+	rec = w.do(t, http.MethodGet, "/api/webapp/me", nil, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWebAppMe(t *testing.T) {
+	w := newTestWebApp(t)
+
+	var response WebAppUserResponse
+	rec := w.do(t, http.MethodGet, "/api/webapp/me", nil, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(42), response.TelegramID)
+	assert.Equal(t, 100.0, response.Balance)
+	assert.Equal(t, "REF42", response.ReferralCode)
+}
+
+func TestWebAppAccessWithoutSubscription(t *testing.T) {
+	w := newTestWebApp(t)
+	w.subscriptions.On("GetActiveSubscriptions", w.user.ID).Return([]models.Subscription{}, nil)
+
+	rec := w.do(t, http.MethodGet, "/api/webapp/access", nil, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	w.subscriptions.AssertNotCalled(t, "GetAccess", mock.Anything)
+}
+
+func TestWebAppTariffs(t *testing.T) {
+	w := newTestWebApp(t)
+	for _, tariff := range models.Tariffs() {
+		w.purchases.On("Quote", w.user.ID, tariff.Key).Return(&services.PurchaseQuote{Tariff: tariff, Total: tariff.Price}, nil)
+	}
+
+	var response struct {
+		Tariffs []TariffResponse `json:"tariffs"`
+	}
+	rec := w.do(t, http.MethodGet, "/api/webapp/tariffs", nil, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, response.Tariffs, len(models.Tariffs()))
+	assert.Equal(t, models.Tariffs()[0].Key, response.Tariffs[0].Key)
+}
+
+func TestWebAppPurchase(t *testing.T) {
+	w := newTestWebApp(t)
+	tariff := models.Tariffs()[0]
+	quote := &services.PurchaseQuote{Tariff: tariff, Total: 60}
+	w.purchases.On("Quote", w.user.ID, tariff.Key).Return(quote, nil)
+	w.purchases.On("Quote", w.user.ID, "unknown").Return(nil, services.ErrPurchaseUnknownTariff)
+
+	rec := w.do(t, http.MethodPost, "/api/webapp/purchase", purchaseRequest{Tariff: "unknown"}, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	w.purchases.On("Purchase", w.user, quote).Return(0.0, services.ErrPurchaseInsufficientBalance).Once()
+	rec = w.do(t, http.MethodPost, "/api/webapp/purchase", purchaseRequest{Tariff: tariff.Key}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	w.purchases.On("Purchase", w.user, quote).Return(0.0, services.ErrPurchaseDiscountUsed).Once()
+	rec = w.do(t, http.MethodPost, "/api/webapp/purchase", purchaseRequest{Tariff: tariff.Key}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// Баланс в ответе берется из базы, а не вычисляется по устаревшему профилю
+	w.purchases.On("Purchase", w.user, quote).Return(15.0, nil).Once()
+	var response PurchaseResponse
+	rec = w.do(t, http.MethodPost, "/api/webapp/purchase", purchaseRequest{Tariff: tariff.Key}, &response)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 15.0, response.Balance)
+	w.purchases.AssertExpectations(t)
+}
+
+func TestWebAppTopUp(t *testing.T) {
+	w := newTestWebApp(t)
+	payment := &models.Payment{ID: uuid.New(), Amount: 150}
+	w.topUps.On("CreateInvoice", w.user.ID, 150.0).Return(&services.TopUpInvoice{Payment: payment, URL: "https://t.me/$invoice", Stars: 100}, nil)
+	w.topUps.On("CreateInvoice", w.user.ID, 1.0).Return(nil, services.ErrTopUpAmount)
+
+	var response TopUpResponse
+	rec := w.do(t, http.MethodPost, "/api/webapp/topup", topUpRequest{Amount: 150}, &response)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, payment.ID, response.PaymentID)
+	assert.Equal(t, 100, response.Stars)
+
+	rec = w.do(t, http.MethodPost, "/api/webapp/topup", topUpRequest{Amount: 1}, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,17 +10,23 @@ import (
 	"syscall"
 	"time"
 
+	"remnawave-tg-shop/internal/api"
 	"remnawave-tg-shop/internal/bot"
+	"remnawave-tg-shop/internal/bot/fsm"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
 	"remnawave-tg-shop/internal/database"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+	"remnawave-tg-shop/internal/models"
 
 	"remnawave-tg-shop/internal/repositories"
 	"remnawave-tg-shop/internal/services"
 	"remnawave-tg-shop/internal/services/remnawave"
+	"remnawave-tg-shop/internal/telegram"
 
 	"github.com/gin-gonic/gin"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // App представляет основное приложение
@@ -29,6 +36,23 @@ type App struct {
 	db     *database.Database
 	bot    *bot.Bot
 	server *http.Server
+
+	// Проверки зависимостей для автоматического режима обслуживания
+	maintenance *services.MaintenanceService
+	// Проверки готовности и оповещение администраторов о сбоях компонентов
+	health *services.HealthService
+	// Настройки, изменяемые из админ-панели
+	settings *services.SettingsService
+	// Выгрузки данных, отдаваемые по ссылке
+	exports *services.ExportService
+	// REST API администрирования
+	api *api.Server
+	// API Telegram Mini App
+	webApp *api.WebApp
+
+	// Транспорт обновлений Telegram (long polling или webhook)
+	transport  telegram.Transport
+	dispatcher *telegram.Dispatcher
 }
 
 // New создает новое приложение
@@ -41,6 +65,12 @@ func New(cfg *config.Config, log logger.Logger) *App {
 
 // Run запускает приложение
 func (a *App) Run() error {
+	// Загружаем переводы: отсутствующий в одном из языков ключ останавливает запуск
+	bundle, err := i18n.Load(a.config.Localization.DefaultLanguage, a.config.Localization.SupportedLanguages)
+	if err != nil {
+		return fmt.Errorf("failed to load translations: %w", err)
+	}
+
 	// Инициализируем базу данных
 	db, err := database.New(a.config, a.logger)
 	if err != nil {
@@ -55,6 +85,22 @@ func (a *App) Run() error {
 	promoCodeRepo := repositories.NewPromoCodeRepository(db.DB)
 	notificationRepo := repositories.NewNotificationRepository(db.DB)
 	activityLogRepo := repositories.NewActivityLogRepository(db.DB)
+	partnerRepo := repositories.NewPartnerRepository(db.DB)
+	withdrawalRepo := repositories.NewWithdrawalRepository(db.DB)
+	auditRepo := repositories.NewAdminAuditRepository(db.DB)
+	statsRepo := repositories.NewStatsRepository(db.DB)
+	exportRepo := repositories.NewExportRepository(db.DB)
+	adminListRepo := repositories.NewAdminListRepository(db.DB)
+
+	// Фоновые задачи отмечаются в heartbeat, чтобы /health/ready заметил остановку планировщика
+	heartbeat := services.NewHeartbeat()
+
+	// Накладываем настройки из админ-панели на конфигурацию из окружения
+	settingsService := services.NewSettingsService(repositories.NewSettingRepository(db.DB), heartbeat, a.config, a.logger)
+	if err := settingsService.Load(); err != nil {
+		a.logger.Warn("Failed to load settings, using environment values", "error", err)
+	}
+	a.settings = settingsService
 
 	// Создаем клиент Remnawave
 	remnawaveClient := remnawave.NewClient(
@@ -63,21 +109,79 @@ func (a *App) Run() error {
 		a.config.Remnawave.SecretKey,
 	)
 
+	// Создаем общий клиент Telegram
+	telegramClient, err := telegram.NewClient(a.config.BotToken, a.config.BotRateLimit, a.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create telegram client: %w", err)
+	}
+
 	// Создаем сервисы
 	userService := services.NewUserService(userRepo, remnawaveClient, a.logger, a.config)
 	subscriptionService := services.NewSubscriptionService(subscriptionRepo, remnawaveClient, a.logger)
-	paymentService := services.NewPaymentService(paymentRepo, userService, a.logger)
-	promoCodeService := services.NewPromoCodeService(promoCodeRepo, a.config)
-	notificationService := services.NewNotificationService(notificationRepo, userRepo, subscriptionRepo, a.config)
+	promoCodeService := services.NewPromoCodeService(promoCodeRepo, userRepo, a.config)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, subscriptionRepo, telegramClient, bundle, a.config)
+	partnerService := services.NewPartnerService(partnerRepo, withdrawalRepo, userRepo, notificationService, a.config, a.logger)
+	paymentService := services.NewPaymentService(paymentRepo, userService, partnerService, a.logger)
 	activityLogService := services.NewActivityLogService(activityLogRepo, a.config)
+	withdrawalService := services.NewWithdrawalService(withdrawalRepo, notificationService, activityLogService, a.config, a.logger)
+	referralService := services.NewReferralService(userRepo, userService, notificationService, activityLogService, a.config, a.logger, telegramClient.Username())
+	giftService := services.NewGiftService(promoCodeRepo, promoCodeService, notificationService, activityLogService, a.config, a.logger, telegramClient.Username())
+	auditService := services.NewAuditService(auditRepo, telegramClient, a.config, a.logger)
+	statsService := services.NewStatsService(statsRepo, a.logger)
+	a.exports = services.NewExportService(exportRepo, a.config, a.logger)
+	adminListService := services.NewAdminListService(adminListRepo, a.logger)
+	a.api = api.NewServer(a.config, a.logger, userService, subscriptionService, paymentService, promoCodeService, statsService, adminListService, auditService)
+	purchaseService := services.NewPurchaseService(subscriptionRepo, promoCodeService, referralService, a.logger)
+	topUpService := services.NewTopUpService(paymentService, telegramClient, a.config, a.logger)
+	// Метрики пула соединений и активных подписок собираются при каждом запросе /metrics
+	if sqlDB, err := db.DB.DB(); err == nil {
+		if err := metrics.RegisterDB(sqlDB, a.config.Database.Name); err != nil {
+			a.logger.Warn("Failed to register database metrics", "error", err)
+		}
+	}
+	if err := metrics.RegisterActiveSubscriptions(func() ([]models.TariffCount, error) {
+		return statsRepo.ActiveByTariff(time.Now())
+	}); err != nil {
+		a.logger.Warn("Failed to register subscription metrics", "error", err)
+	}
+	a.maintenance = services.NewMaintenanceService([]services.HealthCheck{
+		{Name: "database", Check: db.Health},
+		{Name: "remnawave", Check: remnawaveClient.Health},
+	}, telegramClient, activityLogService, heartbeat, a.config, a.logger)
+	a.webApp = api.NewWebApp(a.config, a.logger, userService, subscriptionService, purchaseService, topUpService, referralService, partnerService, a.maintenance, bundle)
+	// Результаты внешних проверок кэшируются на интервал самопроверки
+	a.health = services.NewHealthService([]services.HealthCheck{
+		{Name: "database", Check: db.Health, Critical: true},
+		{Name: "remnawave", Check: remnawaveClient.Health, CacheTTL: a.config.Monitoring.HealthCheckInterval},
+		{Name: "telegram", Check: telegramClient.Health, CacheTTL: a.config.Monitoring.HealthCheckInterval},
+		{Name: "scheduler", Check: heartbeat.Check},
+	}, telegramClient, a.config, a.logger)
+
+	// Хранилище многошаговых диалогов: postgres нужен при нескольких репликах
+	var sessions fsm.Store = fsm.NewMemoryStore()
+	if a.config.BotSessionStore == "postgres" {
+		postgresStore := fsm.NewPostgresStore(repositories.NewBotSessionRepository(db.DB))
+		if err := postgresStore.Cleanup(); err != nil {
+			a.logger.Warn("Failed to cleanup expired bot sessions", "error", err)
+		}
+		sessions = postgresStore
+	}
 
 	// Создаем бота
-	telegramBot, err := bot.NewBot(a.config, a.logger, userService, subscriptionService, paymentService, promoCodeService, notificationService, activityLogService)
+	telegramBot, err := bot.NewBot(a.config, a.logger, telegramClient, userService, subscriptionService, paymentService, promoCodeService, notificationService, activityLogService, referralService, partnerService, withdrawalService, giftService, settingsService, a.maintenance, auditService, statsService, a.exports, purchaseService, topUpService, bundle, sessions)
 	if err != nil {
 		return fmt.Errorf("failed to create bot: %w", err)
 	}
 	a.bot = telegramBot
 
+	// Выбираем транспорт обновлений: webhook, если задан BOT_WEBHOOK_URL
+	a.dispatcher = telegram.NewDispatcher(a.config.BotUpdateWorkers, a.bot.HandleUpdate, router.ChatID, a.logger)
+	if a.config.BotWebhookURL != "" {
+		a.transport = telegram.NewWebhook(telegramClient, a.config.BotWebhookURL, a.config.BotWebhookSecret, a.config.BotToken, a.dispatcher, a.logger)
+	} else {
+		a.transport = telegram.NewLongPoller(telegramClient, a.dispatcher, a.logger)
+	}
+
 	// Настраиваем HTTP сервер для дополнительных endpoints
 	if err := a.setupHTTPServer(); err != nil {
 		return fmt.Errorf("failed to setup HTTP server: %w", err)
@@ -91,13 +195,20 @@ func (a *App) Run() error {
 		}
 	}()
 
-	// Запускаем бота в отдельной горутине
+	// Запускаем получение обновлений в отдельной горутине
 	go func() {
-		if err := a.bot.Start(); err != nil {
+		if err := a.transport.Start(); err != nil {
 			a.logger.Error("Failed to start bot", "error", err)
 		}
 	}()
 
+	// Запускаем периодические проверки Remnawave и базы данных
+	a.maintenance.Start()
+	// Подхватываем изменения настроек, сделанные на других репликах
+	a.settings.Start()
+	// Сообщаем администраторам об отказе и восстановлении компонентов
+	a.health.Start()
+
 	a.logger.Info("Application started successfully")
 
 	// Ожидаем сигнал завершения
@@ -118,6 +229,13 @@ func (a *App) Run() error {
 		}
 	}
 
+	// Останавливаем получение обновлений и дожидаемся их обработки
+	a.transport.Stop()
+	a.dispatcher.Stop()
+	a.maintenance.Stop()
+	a.settings.Stop()
+	a.health.Stop()
+
 	// Закрываем базу данных
 	if err := a.db.Close(); err != nil {
 		a.logger.Error("Database close failed", "error", err)
@@ -144,37 +262,44 @@ func (a *App) setupHTTPServer() error {
 			"time":   time.Now().UTC(),
 		})
 	})
+	// Пробы живости и готовности
+	router.GET("/health/live", a.handleLiveness)
+	router.GET("/health/ready", a.handleReadiness)
 
 	// Webhook endpoints
-	router.POST("/webhook", a.handleTelegramWebhook)
+	if webhook, ok := a.transport.(*telegram.Webhook); ok {
+		router.POST(webhook.Path(), gin.WrapH(webhook))
+	}
 	router.POST("/tribute-webhook", a.handleTributeWebhook)
 	router.POST("/yookassa-webhook", a.handleYooKassaWebhook)
 
-	a.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", a.config.Server.Port),
-		Handler: router,
+	// Файлы выгрузок, превышающие лимит загрузки в Telegram
+	router.GET("/exports/:token", a.handleExportDownload)
+
+	// Метрики Prometheus
+	if a.config.Monitoring.MetricsEnabled {
+		router.GET("/metrics", a.handleMetrics)
 	}
 
-	return nil
-}
+	// Спецификация OpenAPI и Swagger UI
+	api.RegisterDocs(router)
 
-// handleTelegramWebhook обрабатывает webhook от Telegram
-func (a *App) handleTelegramWebhook(c *gin.Context) {
-	var update tgbotapi.Update
-	if err := c.ShouldBindJSON(&update); err != nil {
-		a.logger.Error("Failed to parse Telegram webhook", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
-		return
+	// API Telegram Mini App
+	a.webApp.Register(router)
+
+	// REST API администрирования
+	if a.api.Enabled() {
+		a.api.Register(router)
+	} else {
+		a.logger.Info("REST API disabled: set JWT_SECRET and API_PASSWORD to enable")
 	}
 
-	// Передаем обновление в бот
-	if err := a.bot.HandleUpdate(update); err != nil {
-		a.logger.Error("Failed to handle Telegram update", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process update"})
-		return
+	a.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", a.config.Server.Port),
+		Handler: router,
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	return nil
 }
 
 // handleTributeWebhook обрабатывает webhook от Tribute
@@ -240,3 +365,46 @@ func (a *App) handleYooKassaWebhook(c *gin.Context) {
 	// Пока что просто возвращаем OK
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// handleExportDownload отдает файл выгрузки по токену из ссылки
+func (a *App) handleExportDownload(c *gin.Context) {
+	path, name, err := a.exports.Open(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
+
+// handleMetrics отдает метрики Prometheus. При заданном METRICS_TOKEN
+// без верного Bearer токена отвечает 401
+func (a *App) handleMetrics(c *gin.Context) {
+	if token := a.config.Monitoring.MetricsToken; token != "" {
+		header := c.GetHeader("Authorization")
+		if subtle.ConstantTimeCompare([]byte(header), []byte("Bearer "+token)) != 1 {
+			c.String(http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// handleLiveness отвечает, пока процесс способен обслуживать HTTP запросы
+func (a *App) handleLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+		"time":   time.Now().UTC(),
+	})
+}
+
+// handleReadiness проверяет зависимости. Недоступность критичного компонента
+// возвращает 503, некритичного — 200 со статусом degraded
+func (a *App) handleReadiness(c *gin.Context) {
+	report := a.health.Check()
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"remnawave-tg-shop/internal/api"
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHTTPRoutesDocumented проверяет, что каждый маршрут HTTP сервера описан
+// в openapi.json. Webhook и REST API включены, чтобы зарегистрировались все маршруты
+func TestHTTPRoutesDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Security.JWTSecret = "secret"
+	cfg.Security.APIPassword = "password"
+	cfg.Monitoring.MetricsEnabled = true
+	log := logger.New("error")
+
+	a := New(cfg, log)
+	a.api = api.NewServer(cfg, log, nil, nil, nil, nil, nil, nil, nil)
+	a.webApp = api.NewWebApp(cfg, log, nil, nil, nil, nil, nil, nil, nil, nil)
+	a.transport = telegram.NewWebhook(nil, "https://example.com/webhook", "", "token", nil, log)
+	require.NoError(t, a.setupHTTPServer())
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(api.OpenAPISpec, &spec))
+
+	param := regexp.MustCompile(`:([A-Za-z_]+)`)
+	routes := a.server.Handler.(*gin.Engine).Routes()
+	require.NotEmpty(t, routes)
+	for _, route := range routes {
+		path := param.ReplaceAllString(route.Path, "{$1}")
+		_, ok := spec.Paths[path][strings.ToLower(route.Method)]
+		assert.True(t, ok, "%s %s is missing in openapi.json", route.Method, path)
+	}
+}
+
+func TestMetricsToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Monitoring.MetricsEnabled = true
+	cfg.Monitoring.MetricsToken = "scrape-token"
+	log := logger.New("error")
+
+	a := New(cfg, log)
+	a.api = api.NewServer(cfg, log, nil, nil, nil, nil, nil, nil, nil)
+	a.webApp = api.NewWebApp(cfg, log, nil, nil, nil, nil, nil, nil, nil, nil)
+	a.transport = telegram.NewLongPoller(nil, nil, log)
+	require.NoError(t, a.setupHTTPServer())
+
+	rec := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-token")
+	rec = httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
+
+func TestReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	log := logger.New("error")
+
+	var dbErr error
+	a := New(cfg, log)
+	a.api = api.NewServer(cfg, log, nil, nil, nil, nil, nil, nil, nil)
+	a.webApp = api.NewWebApp(cfg, log, nil, nil, nil, nil, nil, nil, nil, nil)
+	a.transport = telegram.NewLongPoller(nil, nil, log)
+	a.health = services.NewHealthService([]services.HealthCheck{
+		{Name: "database", Check: func() error { return dbErr }, Critical: true},
+		{Name: "remnawave", Check: func() error { return errors.New("connection refused") }},
+	}, telegram.NewFakeMessenger(), cfg, log)
+	require.NoError(t, a.setupHTTPServer())
+
+	var report services.HealthReport
+	rec := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, services.HealthDegraded, report.Status)
+	assert.Equal(t, services.HealthDown, report.Components["remnawave"].Status)
+
+	dbErr = errors.New("ping failed")
+	rec = httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
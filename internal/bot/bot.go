@@ -1,32 +1,41 @@
 package bot
 
 import (
-	"fmt"
+	"errors"
+	"strconv"
 	"strings"
-	"time"
 
+	"remnawave-tg-shop/internal/bot/fsm"
 	"remnawave-tg-shop/internal/bot/handlers/callbacks"
 	"remnawave-tg-shop/internal/bot/handlers/commands"
 	"remnawave-tg-shop/internal/bot/handlers/messages"
 	"remnawave-tg-shop/internal/bot/middleware"
-	"remnawave-tg-shop/internal/bot/utils"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/logger"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"gopkg.in/telebot.v3"
+	"github.com/mymmrac/telego"
 )
 
 // Bot представляет телеграм-бота
 type Bot struct {
-	api                 *telebot.Bot
+	router              *router.Router
 	config              *config.Config
 	logger              logger.Logger
+	bundle              *i18n.Bundle
 	userService         services.UserService
 	subscriptionService services.SubscriptionService
 	paymentService      services.PaymentService
+	promoCodeService    services.IPromoCodeService
+	referralService     services.IReferralService
+	partnerService      services.IPartnerService
+	maintenanceService  services.IMaintenanceService
+	purchaseService     services.IPurchaseService
+	topUpService        services.ITopUpService
 
 	// Обработчики команд
 	startHandler *commands.StartHandler
@@ -34,508 +43,468 @@ type Bot struct {
 	adminHandler *commands.AdminHandler
 
 	// Обработчики callback'ов
-	balanceHandler   *callbacks.BalanceHandler
-	promoCodeHandler *callbacks.PromoCodeHandler
+	balanceHandler    *callbacks.BalanceHandler
+	promoCodeHandler  *callbacks.PromoCodeHandler
+	withdrawalHandler *callbacks.WithdrawalHandler
+	giftHandler       *callbacks.GiftHandler
 
 	// Обработчики сообщений
 	textHandler *messages.TextHandler
 
 	// Middleware
-	authMiddleware *middleware.AuthMiddleware
+	maintenanceMiddleware *middleware.MaintenanceMiddleware
+	authMiddleware        *middleware.AuthMiddleware
 }
 
 // NewBot создает нового бота
-func NewBot(cfg *config.Config, log logger.Logger, userService services.UserService, subscriptionService services.SubscriptionService, paymentService services.PaymentService, promoCodeService services.IPromoCodeService, notificationService services.INotificationService, activityLogService services.IActivityLogService) (*Bot, error) {
-	pref := telebot.Settings{
-		Token: cfg.BotToken,
-		// Используем Long Polling для простоты
-		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
-	}
-
-	api, err := telebot.NewBot(pref)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create bot API: %w", err)
-	}
-
+func NewBot(cfg *config.Config, log logger.Logger, messenger telegram.Messenger, userService services.UserService, subscriptionService services.SubscriptionService, paymentService services.PaymentService, promoCodeService services.IPromoCodeService, notificationService services.INotificationService, activityLogService services.IActivityLogService, referralService services.IReferralService, partnerService services.IPartnerService, withdrawalService services.IWithdrawalService, giftService services.IGiftService, settingsService services.ISettingsService, maintenanceService services.IMaintenanceService, auditService services.IAuditService, statsService services.IStatsService, exportService services.IExportService, purchaseService services.IPurchaseService, topUpService services.ITopUpService, bundle *i18n.Bundle, sessions fsm.Store) (*Bot, error) {
 	// Создаем обработчики
-	startHandler := commands.NewStartHandler(cfg, userService, subscriptionService)
+	startHandler := commands.NewStartHandler(cfg, log, userService, subscriptionService, referralService, partnerService, giftService)
 	helpHandler := commands.NewHelpHandler(cfg)
-	adminHandler := commands.NewAdminHandler(cfg, userService, subscriptionService, paymentService, promoCodeService, notificationService, activityLogService)
+	adminHandler := commands.NewAdminHandler(cfg, userService, subscriptionService, paymentService, promoCodeService, notificationService, activityLogService, withdrawalService, settingsService, auditService, statsService, exportService)
 	balanceHandler := callbacks.NewBalanceHandler(cfg, userService)
-	promoCodeHandler := callbacks.NewPromoCodeHandler(cfg, userService, promoCodeService, activityLogService)
+	promoCodeHandler := callbacks.NewPromoCodeHandler(cfg, log, userService, promoCodeService, giftService, activityLogService)
+	withdrawalHandler := callbacks.NewWithdrawalHandler(cfg, partnerService, withdrawalService)
+	giftHandler := callbacks.NewGiftHandler(cfg, giftService)
 	textHandler := messages.NewTextHandler(cfg)
-	authMiddleware := middleware.NewAuthMiddleware(userService, log)
+	maintenanceMiddleware := middleware.NewMaintenanceMiddleware(maintenanceService, userService, bundle)
+	authMiddleware := middleware.NewAuthMiddleware(userService, bundle, log)
 
 	bot := &Bot{
-		api:                 api,
-		config:              cfg,
-		logger:              log,
-		userService:         userService,
-		subscriptionService: subscriptionService,
-		paymentService:      paymentService,
-		startHandler:        startHandler,
-		helpHandler:         helpHandler,
-		adminHandler:        adminHandler,
-		balanceHandler:      balanceHandler,
-		promoCodeHandler:    promoCodeHandler,
-		textHandler:         textHandler,
-		authMiddleware:      authMiddleware,
+		router:                router.New(messenger, log),
+		config:                cfg,
+		logger:                log,
+		bundle:                bundle,
+		userService:           userService,
+		subscriptionService:   subscriptionService,
+		paymentService:        paymentService,
+		promoCodeService:      promoCodeService,
+		referralService:       referralService,
+		partnerService:        partnerService,
+		maintenanceService:    maintenanceService,
+		purchaseService:       purchaseService,
+		topUpService:          topUpService,
+		startHandler:          startHandler,
+		helpHandler:           helpHandler,
+		adminHandler:          adminHandler,
+		balanceHandler:        balanceHandler,
+		promoCodeHandler:      promoCodeHandler,
+		withdrawalHandler:     withdrawalHandler,
+		giftHandler:           giftHandler,
+		textHandler:           textHandler,
+		maintenanceMiddleware: maintenanceMiddleware,
+		authMiddleware:        authMiddleware,
 	}
 
+	// Подключаем хранилище многошаговых диалогов
+	bot.router.Sessions(sessions, cfg.BotSessionTimeout)
+
 	// Регистрируем обработчики
-	bot.setupHandlers()
+	bot.setupRoutes()
 
 	return bot, nil
 }
 
-// Start запускает бота
-func (b *Bot) Start() error {
-	b.logger.Info("Starting Telegram bot with Telebot...")
-	b.api.Start()
-	return nil
-}
-
-// HandleUpdate обрабатывает обновления для webhook
-func (b *Bot) HandleUpdate(update interface{}) error {
-	// Преобразуем обновление в формат telebot
-	if tgbotUpdate, ok := update.(tgbotapi.Update); ok {
-		// Обрабатываем обновление напрямую
-		return b.processUpdate(tgbotUpdate)
-	}
-	return nil
-}
-
-// processUpdate обрабатывает обновление
-func (b *Bot) processUpdate(update tgbotapi.Update) error {
-	b.logger.Info("Processing update", "update_id", update.UpdateID)
-
-	// Обрабатываем сообщения
-	if update.Message != nil {
-		return b.handleMessage(update.Message)
+// HandleUpdate обрабатывает обновление независимо от транспорта
+func (b *Bot) HandleUpdate(update telego.Update) {
+	if err := b.router.HandleUpdate(update); err != nil {
+		b.logger.Error("Failed to handle update", "update_id", update.UpdateID, "error", err)
 	}
-
-	// Обрабатываем callback queries
-	if update.CallbackQuery != nil {
-		return b.handleCallbackQuery(update.CallbackQuery)
-	}
-
-	return nil
 }
 
-// handleMessage обрабатывает сообщения
-func (b *Bot) handleMessage(message *tgbotapi.Message) error {
-	b.logger.Info("Handling message", "chat_id", message.Chat.ID, "text", message.Text)
+// setupRoutes настраивает обработчики команд и callback'ов
+func (b *Bot) setupRoutes() {
+	r := b.router
 
-	// Получаем пользователя
-	user, err := utils.GetOrCreateUser(message.From, b.userService)
-	if err != nil {
-		b.logger.Error("Failed to get user", "error", err)
-		return err
-	}
-
-	// Обрабатываем команды
-	if message.IsCommand() {
-		command := message.Command()
-		args := message.CommandArguments()
+	// Middleware режима технических работ, логирования и аутентификации
+	r.Use(b.maintenanceMiddleware.Handle)
+	r.Use(b.authMiddleware.Handle)
 
-		switch command {
-		case "start":
-			return b.startHandler.Handle(message, user, args)
-		case "help":
-			return b.helpHandler.Handle(message, user, args)
-		case "admin":
-			return b.adminHandler.Handle(message, user, args)
-		case "promo":
-			// Обрабатываем команду промокода
-			return b.promoCodeHandler.HandlePromoCodeMessage(message, user)
-		default:
-			return b.handleUnknownCommand(message, user, args)
-		}
-	}
+	// Команды
+	r.Command("start", b.startHandler.Handle)
+	r.Command("help", b.helpHandler.Handle)
+	r.Command("admin", b.adminHandler.Handle)
+	r.Command("promo", b.promoCodeHandler.HandlePromoCodeMessage)
+	r.Command("cancel", b.handleCancel)
+	r.UnknownCommand(b.handleUnknownCommand)
 
-	// Обрабатываем обычные сообщения
-	return b.textHandler.Handle(message, user)
+	// Callback queries
+	r.Callback("balance", b.balanceHandler.Handle)
+	r.Callback("buy_subscription", b.handleBuySubscription)
+	r.CallbackPrefix("subscription:", b.handleSubscriptionSelection)
+	r.Callback("payment_tribute", b.handleTributePayment)
+	r.Callback("payment_stars", b.handleStarsPayment)
+	r.CallbackPrefix("payment_stars:", b.handleStarsAmount)
+	r.Callback("start", b.startHandler.Handle)
+	r.Callback("support", b.handleSupport)
+	r.Callback("language", b.handleLanguage)
+	r.CallbackPrefix("language:", b.handleLanguageSelection)
+	r.Callback("status", b.handleStatus)
+	r.Callback("referrals", b.handleReferrals)
+	r.Callback("partner", b.handlePartnerStats)
+	r.Callback("trial", b.handleTrial)
+	r.CallbackPrefix("admin:", b.handleAdminCallback)
+	r.CallbackPrefix("promo_code:", b.promoCodeHandler.Handle)
+	r.CallbackPrefix("withdraw:", b.withdrawalHandler.Handle)
+	r.Callback("gift", b.giftHandler.Handle)
+	r.CallbackPrefix("gift:", b.giftHandler.Handle)
+
+	// Оплата счетов в Telegram Stars
+	r.PreCheckout(b.handlePreCheckout)
+	r.SuccessfulPayment(b.handleSuccessfulPayment)
+
+	// Шаги многошаговых диалогов
+	r.Step(callbacks.StepPromoCodeInput, b.promoCodeHandler.HandlePromoCodeInputStep)
+	r.Step(commands.StepFindUser, b.adminHandler.HandleFindUserStep)
+	r.Step(commands.StepBalanceAddUser, b.adminHandler.HandleBalanceAddUserStep)
+	r.Step(commands.StepBalanceAddAmount, b.adminHandler.HandleBalanceAddAmountStep)
+	r.Step(commands.StepPromoCreateCode, b.adminHandler.HandlePromoCreateCodeStep)
+	r.Step(commands.StepPromoCreateType, b.adminHandler.HandlePromoCreateTypeStep)
+	r.Step(commands.StepPromoCreateValue, b.adminHandler.HandlePromoCreateValueStep)
+	r.Step(commands.StepPromoCreateMaxUses, b.adminHandler.HandlePromoCreateMaxUsesStep)
+	r.Step(commands.StepPromoBatch, b.adminHandler.HandlePromoBatchStep)
+	r.Step(commands.StepPromoRulesMinAmount, b.adminHandler.HandlePromoMinAmountStep)
+	r.Step(commands.StepWithdrawalReject, b.adminHandler.HandleWithdrawalRejectStep)
+	r.Step(commands.StepSettingValue, b.adminHandler.HandleSettingValueStep)
+	r.Step(commands.StepRoleGrantUser, b.adminHandler.HandleGrantRoleUserStep)
+	r.Step(commands.StepUserBalance, b.adminHandler.HandleUserBalanceStep)
+	r.Step(commands.StepSubscriptionDays, b.adminHandler.HandleSubscriptionDaysStep)
+	r.Step(commands.StepUserMessage, b.adminHandler.HandleUserMessageStep)
+	r.Step(callbacks.StepWithdrawAmount, b.withdrawalHandler.HandleAmountStep)
+	r.Step(callbacks.StepWithdrawMethod, b.withdrawalHandler.HandleMethodStep)
+	r.Step(callbacks.StepWithdrawDetails, b.withdrawalHandler.HandleDetailsStep)
+
+	// Текстовые сообщения
+	r.Text(b.textHandler.Handle)
 }
 
-// handleCallbackQuery обрабатывает callback queries
-func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) error {
-	b.logger.Info("Handling callback query", "chat_id", query.Message.Chat.ID, "data", query.Data)
-
-	// Получаем пользователя
-	user, err := utils.GetOrCreateUser(query.From, b.userService)
-	if err != nil {
-		b.logger.Error("Failed to get user", "error", err)
-		return err
+// handleBuySubscription обрабатывает callback для покупки подписки
+func (b *Bot) handleBuySubscription(c *router.Context) error {
+	text := c.T("buy.title") + "\n\n"
+	for _, tariff := range models.Tariffs() {
+		text += tariff.GetButtonText(c.Locale) + "\n"
 	}
-
-	// Обрабатываем callback query
-	return b.handleCallbackQueryData(query, user)
-}
-
-// handleCallbackQueryData обрабатывает данные callback query
-func (b *Bot) handleCallbackQueryData(query *tgbotapi.CallbackQuery, user *models.User) error {
-	data := query.Data
-
-	// Обрабатываем различные типы callback'ов
-	switch {
-	case data == "balance":
-		return b.balanceHandler.Handle(query, user)
-	case data == "buy_subscription":
-		return b.handleBuySubscription(query, user)
-	case strings.HasPrefix(data, "subscription:"):
-		return b.handleSubscriptionSelection(query, user)
-	case data == "payment_tribute":
-		return b.handleTributePayment(query, user)
-	case data == "payment_stars":
-		return b.handleStarsPayment(query, user)
-	case data == "payment_yookassa":
-		return b.handleYooKassaPayment(query, user)
-	case data == "payment_cryptopay":
-		return b.handleCryptoPayPayment(query, user)
-	case data == "start":
-		return b.handleStartCallback(query, user)
-	case data == "support":
-		return b.handleSupport(query, user)
-	case data == "language":
-		return b.handleLanguage(query, user)
-	case data == "status":
-		return b.handleStatus(query, user)
-	case data == "referrals":
-		return b.handleReferrals(query, user)
-	case data == "trial":
-		return b.handleTrial(query, user)
-	case strings.HasPrefix(data, "admin:"):
-		return b.handleAdminCallback(query, user)
-	case strings.HasPrefix(data, "promo_code:"):
-		return b.promoCodeHandler.Handle(query, user)
-	default:
-		b.logger.Info("Unknown callback data", "data", data)
-		return nil
+	text += "\n"
+	if usage, err := b.promoCodeService.GetPendingDiscount(c.User.ID); err == nil && usage != nil {
+		text += c.T("buy.pending_discount", "code", usage.PromoCode.Code) + "\n\n"
 	}
-}
-
-// handleBuySubscription обрабатывает callback для покупки подписки
-func (b *Bot) handleBuySubscription(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	text := "🚀 Выберите тарифный план:\n\n"
-	text += "📦 Basic (30 дней) - 299₽\n"
-	text += "⭐ Premium (90 дней) - 799₽\n"
-	text += "💎 Pro (365 дней) - 2499₽\n\n"
-	text += "Выберите подходящий тариф:"
+	text += c.T("buy.choose")
 
 	// Создаем клавиатуру с тарифами
-	keyboard := b.createSubscriptionKeyboard()
+	keyboard := b.createSubscriptionKeyboard(c.Locale)
 
 	// Отправляем сообщение
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+	return b.send(c, text, keyboard)
 }
 
-// createMainMenuKeyboard создает главное меню
-func (b *Bot) createMainMenuKeyboard(user *models.User) tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+// createMainMenuKeyboard создает главное меню на языке пользователя
+func (b *Bot) createMainMenuKeyboard(user *models.User, l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Баланс
-	balanceText := fmt.Sprintf("💰 Баланс %.0f₽", user.Balance)
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData(balanceText, "balance"),
+	balanceText := l.T("menu.balance", "balance", user.Balance)
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: balanceText, CallbackData: "balance"},
 	})
 
-	// Купить
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🚀 Купить", "buy_subscription"),
+	// Купить и Подарить
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("menu.buy"), CallbackData: "buy_subscription"},
+		{Text: l.T("menu.gift"), CallbackData: "gift"},
+	})
+
+	// Mini App с подписками, балансом и покупкой
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("menu.subscription"), WebApp: &telego.WebAppInfo{URL: b.config.MiniApp.URL}},
 	})
 
 	// Рефералы и Промокод
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🎁 Рефералы", "referrals"),
-		tgbotapi.NewInlineKeyboardButtonData("🎟️ Промокод", "promo_code:menu"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("menu.referrals"), CallbackData: "referrals"},
+		{Text: l.T("menu.promo_code"), CallbackData: "promo_code:menu"},
 	})
 
 	// Язык и Статус
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🌐 Язык", "language"),
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статус", "status"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("menu.language"), CallbackData: "language"},
+		{Text: l.T("menu.status"), CallbackData: "status"},
 	})
 
 	// Поддержка
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🆘 Поддержка", "support"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("menu.support"), CallbackData: "support"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // createSubscriptionKeyboard создает клавиатуру с тарифами подписки
-func (b *Bot) createSubscriptionKeyboard() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (b *Bot) createSubscriptionKeyboard(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Тарифы
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📦 Basic (30 дней) - 299₽", "subscription:basic"),
-	})
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("⭐ Premium (90 дней) - 799₽", "subscription:premium"),
-	})
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("💎 Pro (365 дней) - 2499₽", "subscription:pro"),
-	})
+	for _, tariff := range models.Tariffs() {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: tariff.GetButtonText(l), CallbackData: "subscription:" + tariff.Key},
+		})
+	}
 
 	// Кнопка "Назад"
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "start"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("common.back"), CallbackData: "start"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // handleSubscriptionSelection обрабатывает выбор тарифа подписки
-func (b *Bot) handleSubscriptionSelection(query *tgbotapi.CallbackQuery, user *models.User) error {
-	data := query.Data
-	parts := strings.Split(data, ":")
+func (b *Bot) handleSubscriptionSelection(c *router.Context) error {
+	user := c.User
+
+	parts := strings.Split(c.Data, ":")
 	if len(parts) < 2 {
-		return b.handleBuySubscription(query, user)
-	}
-
-	plan := parts[1]
-
-	// Определяем параметры тарифа
-	var duration int
-	var price float64
-	var planName string
-
-	switch plan {
-	case "basic":
-		duration = 30
-		price = 299
-		planName = "Basic"
-	case "premium":
-		duration = 90
-		price = 799
-		planName = "Premium"
-	case "pro":
-		duration = 365
-		price = 2499
-		planName = "Pro"
-	default:
-		return b.handleBuySubscription(query, user)
+		return b.handleBuySubscription(c)
+	}
+
+	// Рассчитываем стоимость тарифа с учетом ожидающей скидки по промокоду
+	quote, err := b.purchaseService.Quote(user.ID, parts[1])
+	if err != nil {
+		return b.handleBuySubscription(c)
+	}
+	price, discount, total := quote.Tariff.Price, quote.Discount, quote.Total
+	planName := quote.Tariff.Name
+	discountUsage := quote.DiscountUsage
+
+	// Если условия промокода не выполнены, скидка остается до следующей покупки
+	var discountNote string
+	if quote.DiscountErr != nil {
+		reason := c.T("common.error")
+		if key := services.PromoCodeErrorKey(quote.DiscountErr); key != "" {
+			reason = c.T(key, "amount", discountUsage.PromoCode.MinPurchaseAmount)
+		} else {
+			b.logger.Error("Failed to check promo code discount", "user_id", user.ID, "error", quote.DiscountErr)
+		}
+		discountNote = c.T("purchase.discount_not_applied", "code", discountUsage.PromoCode.Code, "reason", reason) + "\n"
 	}
 
 	// Проверяем баланс пользователя
-	if user.Balance < price {
-		text := "❌ Недостаточно средств на балансе!\n\n"
-		text += fmt.Sprintf("💰 Ваш баланс: %.0f₽\n", user.Balance)
-		text += fmt.Sprintf("💳 Стоимость: %.0f₽\n\n", price)
-		text += "Пополните баланс для покупки подписки."
+	if user.Balance < total {
+		text := c.T("purchase.insufficient") + "\n\n"
+		text += c.T("purchase.balance", "balance", user.Balance) + "\n"
+		text += c.T("purchase.price", "price", price) + "\n"
+		if discount > 0 {
+			text += c.T("purchase.discount", "code", discountUsage.PromoCode.Code, "discount", discount) + "\n"
+			text += c.T("purchase.to_pay", "total", total) + "\n"
+		}
+		text += discountNote
+		text += "\n" + c.T("purchase.top_up_hint")
 
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("💰 Пополнить баланс", "balance"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "buy_subscription"),
-			),
-		)
+		keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{{Text: c.T("purchase.top_up"), CallbackData: "balance"}},
+			{{Text: c.T("common.back"), CallbackData: "buy_subscription"}},
+		}}
 
-		return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+		return b.send(c, text, keyboard)
 	}
 
-	// Создаем подписку (конвертируем дни в месяцы)
-	durationMonths := duration / 30
-	if durationMonths < 1 {
-		durationMonths = 1
+	// Создаем подписку и списываем средства с баланса
+	if _, err := b.purchaseService.Purchase(user, quote); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPurchaseInsufficientBalance):
+			return b.send(c, c.T("purchase.insufficient"), nil)
+		case errors.Is(err, services.ErrPurchaseDiscountUsed):
+			return b.send(c, c.T("purchase.discount_used"), nil)
+		case errors.Is(err, services.ErrPurchaseCharge):
+			return b.send(c, c.T("purchase.charge_failed"), nil)
+		}
+		return b.send(c, c.T("purchase.create_failed"), nil)
 	}
 
-	err := b.subscriptionService.CreateSubscriptionByPlan(user.ID, planName, durationMonths, int(price))
-	if err != nil {
-		b.logger.Error("Failed to create subscription", "error", err, "user_id", user.ID, "plan", plan)
-		text := "❌ Ошибка при создании подписки. Попробуйте позже."
-		return utils.SendMessage(query.Message.Chat.ID, text, b.config.BotToken)
+	// Отправляем подтверждение
+	text := c.T("purchase.success", "plan", planName) + "\n\n"
+	text += c.T("purchase.duration", "duration", c.N("units.days", quote.Tariff.DurationDays)) + "\n"
+	text += c.T("purchase.cost", "price", price) + "\n"
+	if discount > 0 {
+		text += c.T("purchase.discount", "code", discountUsage.PromoCode.Code, "discount", discount) + "\n"
+		text += c.T("purchase.paid", "total", total) + "\n"
 	}
+	text += discountNote
+	text += c.T("purchase.config_hint")
 
-	// Списываем средства с баланса
-	err = b.userService.SubtractBalance(user.ID, price)
-	if err != nil {
-		b.logger.Error("Failed to subtract balance", "error", err, "user_id", user.ID, "amount", price)
-		text := "❌ Ошибка при списании средств. Попробуйте позже."
-		return utils.SendMessage(query.Message.Chat.ID, text, b.config.BotToken)
-	}
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+	}}
 
-	// Отправляем подтверждение
-	text := fmt.Sprintf("✅ Подписка %s успешно активирована!\n\n", planName)
-	text += fmt.Sprintf("📅 Срок действия: %d дней\n", duration)
-	text += fmt.Sprintf("💰 Стоимость: %.0f₽\n", price)
-	text += "🔒 Используйте кнопку 'Моя подписка' для получения конфигурации VPN."
+	return b.send(c, text, keyboard)
+}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Главное меню", "start"),
-		),
-	)
+// handlePreCheckout подтверждает оплату счета на пополнение, если платеж
+// еще ожидает оплаты и сумма совпадает
+func (b *Bot) handlePreCheckout(c *router.Context) error {
+	query := c.Update.PreCheckoutQuery
+	if _, err := b.topUpService.CheckInvoice(c.User.ID, query.InvoicePayload, query.Currency, query.TotalAmount); err != nil {
+		b.logger.Warn("Pre-checkout rejected", "error", err, "user_id", c.User.ID, "payload", query.InvoicePayload)
+		return c.Messenger.AnswerPreCheckoutQuery(query.ID, false, c.T("payment.stars.invalid"))
+	}
+	return c.Messenger.AnswerPreCheckoutQuery(query.ID, true, "")
+}
 
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+// handleSuccessfulPayment зачисляет оплаченный счет на баланс
+func (b *Bot) handleSuccessfulPayment(c *router.Context) error {
+	paid := c.Message.SuccessfulPayment
+	payment, err := b.topUpService.CompleteInvoice(c.User.ID, paid.InvoicePayload, paid.Currency, paid.TotalAmount, paid.TelegramPaymentChargeID)
+	if err != nil {
+		b.logger.Error("Failed to complete stars payment", "error", err, "user_id", c.User.ID,
+			"payload", paid.InvoicePayload, "charge_id", paid.TelegramPaymentChargeID)
+		return b.send(c, c.T("payment.stars.failed", "charge_id", paid.TelegramPaymentChargeID), nil)
+	}
+	// Меню показывает баланс после зачисления
+	user := c.User
+	if updated, err := b.userService.GetUserByID(user.ID); err == nil && updated != nil {
+		user = updated
+	}
+	return b.send(c, c.T("payment.stars.paid", "amount", payment.Amount), b.createMainMenuKeyboard(user, c.Locale))
 }
 
 // handleTributePayment обрабатывает платеж через Tribute
-func (b *Bot) handleTributePayment(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	text := "💎 *Пополнение через Tribute*\n\n"
-	text += "Для пополнения баланса перейдите по ссылке:\n\n"
-	text += "🔗 " + b.config.Payments.Tribute.AppURL + "\n\n"
-	text += "После успешного платежа средства будут автоматически зачислены на ваш баланс."
+func (b *Bot) handleTributePayment(c *router.Context) error {
+	appURL := b.config.Settings().Payments.Tribute.AppURL
+	text := c.T("payment.tribute.text", "url", appURL)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("💎 Перейти к оплате", b.config.Payments.Tribute.AppURL),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "balance"),
-		),
-	)
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("payment.tribute.button"), URL: appURL}},
+		{{Text: c.T("common.back"), CallbackData: "balance"}},
+	}}
 
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+	return b.send(c, text, keyboard)
 }
 
-// handleStarsPayment обрабатывает платеж через Telegram Stars
-func (b *Bot) handleStarsPayment(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	text := "⭐ *Пополнение через Telegram Stars*\n\n"
-	text += "Функция пополнения через Telegram Stars временно недоступна.\n"
-	text += "Используйте другие способы оплаты."
+// starsTopUpAmounts суммы пополнения в рублях, которые бот предлагает для
+// оплаты звездами. Суммы вне границ пополнения Mini App не показываются
+var starsTopUpAmounts = []float64{100, 300, 500, 1000, 3000}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "balance"),
-		),
-	)
+// handleStarsPayment показывает суммы пополнения через Telegram Stars
+func (b *Bot) handleStarsPayment(c *router.Context) error {
+	if !b.config.Settings().Payments.StarsEnabled {
+		return b.send(c, c.T("payment.stars.disabled"), b.backToBalanceKeyboard(c))
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	for _, amount := range starsTopUpAmounts {
+		if amount < b.config.MiniApp.MinTopUp || amount > b.config.MiniApp.MaxTopUp {
+			continue
+		}
+		rows = append(rows, []telego.InlineKeyboardButton{{
+			Text:         c.T("payment.stars.amount", "amount", amount, "stars", b.topUpService.Stars(amount)),
+			CallbackData: "payment_stars:" + strconv.FormatFloat(amount, 'f', -1, 64),
+		}})
+	}
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: c.T("common.back"), CallbackData: "balance"}})
 
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+	return b.send(c, c.T("payment.stars.text"), &telego.InlineKeyboardMarkup{InlineKeyboard: rows})
 }
 
-// handleYooKassaPayment обрабатывает платеж через ЮKassa
-func (b *Bot) handleYooKassaPayment(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	text := "💳 *Пополнение через ЮKassa*\n\n"
-	text += "Функция пополнения через ЮKassa временно недоступна.\n"
-	text += "Используйте другие способы оплаты."
+// handleStarsAmount выставляет счет в Telegram Stars на выбранную сумму
+func (b *Bot) handleStarsAmount(c *router.Context) error {
+	amount, err := strconv.ParseFloat(strings.TrimPrefix(c.Data, "payment_stars:"), 64)
+	if err != nil {
+		return b.handleStarsPayment(c)
+	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "balance"),
-		),
-	)
+	invoice, err := b.topUpService.CreateInvoice(c.User.ID, amount)
+	switch {
+	case errors.Is(err, services.ErrTopUpDisabled):
+		return b.send(c, c.T("payment.stars.disabled"), b.backToBalanceKeyboard(c))
+	case errors.Is(err, services.ErrTopUpAmount):
+		return b.handleStarsPayment(c)
+	case err != nil:
+		b.logger.Error("Failed to create stars invoice", "error", err, "user_id", c.User.ID, "amount", amount)
+		return b.send(c, c.T("common.error"), b.backToBalanceKeyboard(c))
+	}
 
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("payment.stars.pay", "stars", invoice.Stars), URL: invoice.URL}},
+		{{Text: c.T("common.back"), CallbackData: "payment_stars"}},
+	}}
+	return b.send(c, c.T("payment.stars.invoice", "amount", invoice.Payment.Amount, "stars", invoice.Stars), keyboard)
 }
 
-// handleCryptoPayPayment обрабатывает платеж через CryptoPay
-func (b *Bot) handleCryptoPayPayment(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	text := "₿ *Пополнение через CryptoPay*\n\n"
-	text += "Функция пополнения через CryptoPay временно недоступна.\n"
-	text += "Используйте другие способы оплаты."
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "balance"),
-		),
-	)
-
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, b.config.BotToken)
+// backToBalanceKeyboard клавиатура с возвратом к экрану баланса
+func (b *Bot) backToBalanceKeyboard(c *router.Context) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("common.back"), CallbackData: "balance"}},
+	}}
 }
 
-// handleStartCallback обрабатывает callback для главного меню
-func (b *Bot) handleStartCallback(query *tgbotapi.CallbackQuery, user *models.User) error {
-	// Создаем сообщение как для команды /start
-	message := &tgbotapi.Message{
-		Chat: query.Message.Chat,
-		From: query.From,
+// send показывает экран: редактирует сообщение callback'а или отправляет новое
+func (b *Bot) send(c *router.Context, text string, keyboard *telego.InlineKeyboardMarkup) error {
+	// При редактировании меню оставляем путь назад
+	if keyboard == nil && c.Callback != nil {
+		keyboard = &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+		}}
 	}
-
-	return b.startHandler.Handle(message, user, "")
+	return c.Respond(text, telegram.Plain(keyboard))
 }
 
 // handleUnknownCommand обрабатывает неизвестные команды
-func (b *Bot) handleUnknownCommand(message *tgbotapi.Message, _ *models.User, _ string) error {
-	text := "❓ Неизвестная команда. Используйте /help для получения списка команд."
-	return utils.SendMessage(message.Chat.ID, text, b.config.BotToken)
+func (b *Bot) handleUnknownCommand(c *router.Context) error {
+	return b.send(c, c.T("common.unknown_command"), nil)
 }
 
-// setupHandlers настраивает обработчики команд и callback'ов
-func (b *Bot) setupHandlers() {
-	// Middleware для логирования и аутентификации
-	b.api.Use(b.authMiddleware.Handle)
-
-	// Команды
-	b.api.Handle("/start", b.handleStartCommand)
-	b.api.Handle("/help", b.handleHelpCommand)
+// handleSupport обрабатывает callback для поддержки
+func (b *Bot) handleSupport(c *router.Context) error {
+	user := c.User
 
-	// Callback queries
-	b.api.Handle("\fbalance", b.handleBalanceCallback)
-	b.api.Handle("\fstart", b.handleStartCallbackTelebot)
+	message := c.T("support.text", "telegram_id", user.TelegramID)
 
-	// Text messages
-	b.api.Handle(telebot.OnText, b.handleTextMessage)
+	keyboard := b.createMainMenuKeyboard(user, c.Locale)
+	return b.send(c, message, keyboard)
 }
 
-// Обработчики для telebot (для совместимости)
-func (b *Bot) handleStartCommand(c telebot.Context) error {
-	user := c.Get("user").(*models.User)
-	message := &tgbotapi.Message{
-		Chat: &tgbotapi.Chat{ID: c.Message().Chat.ID},
-		From: &tgbotapi.User{ID: c.Message().Sender.ID},
+// handleLanguage показывает выбор языка интерфейса
+func (b *Bot) handleLanguage(c *router.Context) error {
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, lang := range b.bundle.Supported() {
+		// Название языка показываем на нем самом
+		label := b.bundle.For(lang).T("language.name")
+		if lang == c.Locale.Lang() {
+			label = "✅ " + label
+		}
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: "language:" + lang},
+		})
 	}
-	return b.startHandler.Handle(message, user, "")
-}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: c.T("common.back"), CallbackData: "start"},
+	})
 
-func (b *Bot) handleHelpCommand(c telebot.Context) error {
-	user := c.Get("user").(*models.User)
-	message := &tgbotapi.Message{
-		Chat: &tgbotapi.Chat{ID: c.Message().Chat.ID},
-		From: &tgbotapi.User{ID: c.Message().Sender.ID},
-	}
-	return b.helpHandler.Handle(message, user, "")
+	return b.send(c, c.T("language.choose"), &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
 }
 
-func (b *Bot) handleBalanceCallback(c telebot.Context) error {
-	user := c.Get("user").(*models.User)
-	query := &tgbotapi.CallbackQuery{
-		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: c.Message().Chat.ID}},
-		From:    &tgbotapi.User{ID: c.Message().Sender.ID},
-		Data:    c.Callback().Data,
-	}
-	return b.balanceHandler.Handle(query, user)
-}
+// handleLanguageSelection сохраняет выбранный язык и показывает главное меню на нем
+func (b *Bot) handleLanguageSelection(c *router.Context) error {
+	user := c.User
 
-func (b *Bot) handleStartCallbackTelebot(c telebot.Context) error {
-	user := c.Get("user").(*models.User)
-	query := &tgbotapi.CallbackQuery{
-		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: c.Message().Chat.ID}},
-		From:    &tgbotapi.User{ID: c.Message().Sender.ID},
-		Data:    c.Callback().Data,
+	if err := b.userService.SetLanguage(user, c.Args); err != nil {
+		if errors.Is(err, services.ErrUnsupportedLanguage) {
+			return c.Answer(c.T("language.unsupported"), true)
+		}
+		b.logger.Error("Failed to set user language", "error", err, "user_id", user.ID, "language", c.Args)
+		return b.send(c, c.T("language.error"), nil)
 	}
-	return b.handleStartCallback(query, user)
-}
-
-// handleSupport обрабатывает callback для поддержки
-func (b *Bot) handleSupport(query *tgbotapi.CallbackQuery, user *models.User) error {
-	message := "🆘 **Поддержка**\n\n" +
-		"Если у вас возникли вопросы или проблемы, обратитесь к администратору:\n\n" +
-		"• Напишите в личные сообщения администратору\n" +
-		"• Опишите вашу проблему подробно\n" +
-		"• Укажите ваш Telegram ID: `" + fmt.Sprintf("%d", user.TelegramID) + "`\n\n" +
-		"Мы постараемся ответить как можно скорее! 🚀"
-
-	keyboard := b.createMainMenuKeyboard(user)
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
-}
 
-// handleLanguage обрабатывает callback для смены языка
-func (b *Bot) handleLanguage(query *tgbotapi.CallbackQuery, user *models.User) error {
-	message := "🌐 **Выбор языка**\n\n" +
-		"В данный момент доступен только русский язык.\n" +
-		"В будущих версиях будут добавлены другие языки."
+	c.Locale = b.bundle.For(user.PreferredLanguages()...)
+	message := c.T("language.changed") + "\n\n" + c.T("menu.prompt")
 
-	keyboard := b.createMainMenuKeyboard(user)
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.createMainMenuKeyboard(user, c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleStatus обрабатывает callback для статуса
-func (b *Bot) handleStatus(query *tgbotapi.CallbackQuery, user *models.User) error {
+func (b *Bot) handleStatus(c *router.Context) error {
+	user := c.User
+
 	// Получаем активные подписки пользователя
 	subscriptions, err := b.subscriptionService.GetActiveSubscriptions(user.ID)
 	if err != nil {
@@ -543,30 +512,32 @@ func (b *Bot) handleStatus(query *tgbotapi.CallbackQuery, user *models.User) err
 		subscriptions = []models.Subscription{}
 	}
 
-	message := "📊 **Ваш статус**\n\n"
-	message += fmt.Sprintf("💰 Баланс: %.0f₽\n", user.Balance)
-	message += fmt.Sprintf("👤 Telegram ID: `%d`\n", user.TelegramID)
-	message += fmt.Sprintf("📅 Регистрация: %s\n\n", user.CreatedAt.Format("02.01.2006"))
+	message := c.T("status.title") + "\n\n"
+	message += c.T("status.balance", "balance", user.Balance) + "\n"
+	message += c.T("status.telegram_id", "telegram_id", user.TelegramID) + "\n"
+	message += c.T("status.registered", "date", user.CreatedAt.Format("02.01.2006")) + "\n\n"
 
 	if len(subscriptions) > 0 {
-		message += "🔒 **Активные подписки:**\n"
+		message += c.T("status.active") + "\n"
 		for _, sub := range subscriptions {
-			message += fmt.Sprintf("• %s (%s) - до %s\n",
-				sub.ServerName,
-				sub.PlanName,
-				sub.ExpiresAt.Format("02.01.2006 15:04"))
+			message += c.T("status.subscription",
+				"server", sub.ServerName,
+				"plan", sub.PlanName,
+				"expires", sub.ExpiresAt.Format("02.01.2006 15:04"),
+			) + "\n"
 		}
 	} else {
-		message += "❌ **Нет активных подписок**\n"
-		message += "Используйте кнопку \"🚀 Купить\" для приобретения подписки."
+		message += c.T("status.none")
 	}
 
-	keyboard := b.createMainMenuKeyboard(user)
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.createMainMenuKeyboard(user, c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleReferrals обрабатывает callback для рефералов
-func (b *Bot) handleReferrals(query *tgbotapi.CallbackQuery, user *models.User) error {
+func (b *Bot) handleReferrals(c *router.Context) error {
+	user := c.User
+
 	// Получаем рефералов пользователя
 	referrals, err := b.userService.GetReferrals(user.ID)
 	if err != nil {
@@ -574,165 +545,369 @@ func (b *Bot) handleReferrals(query *tgbotapi.CallbackQuery, user *models.User)
 		referrals = []models.User{}
 	}
 
-	message := "🎁 **Реферальная программа**\n\n"
-	message += fmt.Sprintf("Ваш реферальный код: `%s`\n\n", user.ReferralCode)
-	message += "Приглашайте друзей и получайте бонусы!\n\n"
-	message += fmt.Sprintf("👥 Приглашено пользователей: %d\n", len(referrals))
+	message := c.T("referrals.title") + "\n\n"
+	if link := b.referralService.ReferralLink(user); link != "" {
+		message += c.T("referrals.link", "link", link) + "\n\n"
+	}
+	message += c.T("referrals.code", "code", user.ReferralCode) + "\n\n"
+	message += c.T("referrals.invite") + "\n"
+	message += b.referralRewardText(c.Locale)
+	message += "\n" + c.T("referrals.invited", "count", len(referrals)) + "\n"
+	message += c.T("referrals.earned", "amount", user.ReferralBonusEarned) + "\n"
+	if b.partnerService.IsEnabled() {
+		message += b.partnerCommissionText(c.Locale)
+	}
 
 	if len(referrals) > 0 {
-		message += "\n**Ваши рефералы:**\n"
+		message += "\n" + c.T("referrals.list") + "\n"
 		for i, ref := range referrals {
 			if i >= 10 { // Показываем только первых 10
-				message += fmt.Sprintf("... и еще %d пользователей\n", len(referrals)-10)
+				message += c.T("referrals.more", "users", c.N("units.users", len(referrals)-10)) + "\n"
 				break
 			}
-			username := "Без имени"
+			username := c.T("referrals.no_name")
 			if ref.Username != "" {
 				username = "@" + ref.Username
 			} else if ref.FirstName != "" {
 				username = ref.FirstName
 			}
-			message += fmt.Sprintf("• %s (ID: %d)\n", username, ref.TelegramID)
+			message += c.T("referrals.item", "name", username, "telegram_id", ref.TelegramID) + "\n"
 		}
 	}
 
-	keyboard := b.createMainMenuKeyboard(user)
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.createMainMenuKeyboard(user, c.Locale)
+	if b.partnerService.IsEnabled() {
+		keyboard = &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{{Text: c.T("referrals.partner_button"), CallbackData: "partner"}},
+			{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+		}}
+	}
+	return b.send(c, message, keyboard)
+}
+
+// handlePartnerStats показывает статистику партнера
+func (b *Bot) handlePartnerStats(c *router.Context) error {
+	user := c.User
+
+	if !b.partnerService.IsEnabled() {
+		return b.handleReferrals(c)
+	}
+
+	stats, err := b.partnerService.GetStats(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get partner stats", "error", err, "user_id", user.ID)
+		return b.send(c, c.T("partner.error"), nil)
+	}
+
+	message := c.T("partner.title") + "\n\n"
+	message += c.T("partner.clicks", "count", stats.Clicks) + "\n"
+	message += c.T("partner.registrations", "count", stats.Registrations) + "\n"
+	message += c.T("partner.conversions", "count", stats.Conversions, "rate", stats.GetConversionRate()) + "\n"
+	if b.config.Settings().Partner.Level2Percent > 0 {
+		message += c.T("partner.level2", "count", stats.Level2Users) + "\n"
+	}
+	message += "\n"
+	message += c.T("partner.total", "amount", stats.TotalEarned) + "\n"
+	message += c.T("partner.held", "amount", stats.HeldAmount) + "\n"
+	if stats.Frozen > 0 {
+		message += c.T("partner.frozen", "amount", stats.Frozen) + "\n"
+	}
+	if stats.Withdrawn > 0 {
+		message += c.T("partner.withdrawn", "amount", stats.Withdrawn) + "\n"
+	}
+	message += c.T("partner.available", "amount", stats.Available) + "\n\n"
+	message += b.partnerCommissionText(c.Locale)
+
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("partner.withdraw"), CallbackData: "withdraw:start"}},
+		{{Text: c.T("partner.requests"), CallbackData: "withdraw:list"}},
+		{{Text: c.T("common.back"), CallbackData: "referrals"}},
+	}}
+	return b.send(c, message, keyboard)
+}
+
+// partnerCommissionText описывает условия партнерской программы
+func (b *Bot) partnerCommissionText(l *i18n.Localizer) string {
+	partner := b.config.Settings().Partner
+	text := "\n" + l.T("partner.commission", "percent", partner.Level1Percent)
+	if partner.Level2Percent > 0 {
+		text += l.T("partner.commission_level2", "percent", partner.Level2Percent)
+	}
+	text += l.T("partner.commission_hold", "duration", l.N("units.days", partner.HoldDays)) + "\n"
+	return text
+}
+
+// referralRewardText описывает награды реферальной программы
+func (b *Bot) referralRewardText(l *i18n.Localizer) string {
+	referral := b.config.Settings().Referral
+	if referral.RewardType == "days" {
+		return l.T("referrals.reward_days", "duration", l.N("units.days", referral.BonusDays)) + "\n"
+	}
+	return l.T("referrals.reward_balance", "referrer", referral.ReferrerBonus, "referred", referral.ReferredBonus) + "\n"
 }
 
 // handleTrial обрабатывает callback для пробного периода
-func (b *Bot) handleTrial(query *tgbotapi.CallbackQuery, user *models.User) error {
+func (b *Bot) handleTrial(c *router.Context) error {
+	user := c.User
+	keyboard := b.createMainMenuKeyboard(user, c.Locale)
+
 	// Проверяем, использовал ли пользователь пробный период
 	hasUsedTrial, err := b.subscriptionService.HasUsedTrial(user.ID)
 	if err != nil {
 		b.logger.Error("Failed to check trial usage", "error", err)
-		message := "❌ Произошла ошибка при проверке пробного периода."
-		keyboard := b.createMainMenuKeyboard(user)
-		return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+		return b.send(c, c.T("trial.error"), keyboard)
 	}
 
 	if hasUsedTrial {
-		message := "🎁 **Пробный период**\n\n" +
-			"Вы уже использовали пробный период.\n" +
-			"Используйте кнопку \"🚀 Купить\" для приобретения подписки."
-		keyboard := b.createMainMenuKeyboard(user)
-		return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+		return b.send(c, c.T("trial.used"), keyboard)
 	}
 
 	// Здесь должна быть логика активации пробного периода
-	message := "🎁 **Пробный период**\n\n" +
-		"Функция пробного периода будет реализована в следующих версиях.\n" +
-		"Используйте кнопку \"🚀 Купить\" для приобретения подписки."
-
-	keyboard := b.createMainMenuKeyboard(user)
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	return b.send(c, c.T("trial.unavailable"), keyboard)
 }
 
 // handleAdminCallback обрабатывает callback'ы админ-панели
-func (b *Bot) handleAdminCallback(query *tgbotapi.CallbackQuery, user *models.User) error {
-	// Проверяем, является ли пользователь админом
-	if !b.userService.IsAdmin(user.TelegramID) {
-		message := "❌ У вас нет прав администратора"
-		keyboard := b.createMainMenuKeyboard(user)
-		return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+func (b *Bot) handleAdminCallback(c *router.Context) error {
+	user := c.User
+
+	// Проверяем, есть ли у пользователя роль в админ-панели
+	role := b.userService.GetRole(user.TelegramID)
+	if role == "" {
+		return c.Answer(c.T("admin.no_rights"), true)
 	}
 
-	data := query.Data
-	action := strings.TrimPrefix(data, "admin:")
+	action := c.Args
 
-	// Создаем сообщение для админ-обработчика
-	message := &tgbotapi.Message{
-		Chat: &tgbotapi.Chat{ID: query.Message.Chat.ID},
-		From: &tgbotapi.User{ID: query.From.ID},
+	// Проверяем право роли на действие; действия без правила запрещены
+	permission, known := commands.CallbackPermission(action)
+	if !known {
+		return c.Answer(c.T("admin.no_rights"), true)
+	}
+	if permission != "" && !models.RoleHasPermission(role, permission) {
+		return c.Answer(commands.PermissionDeniedText(permission), true)
 	}
 
 	// Обрабатываем различные действия админ-панели
 	switch action {
 	case "main":
-		return b.adminHandler.Handle(message, user, "")
+		return b.adminHandler.Run(c, "")
 	case "stats":
-		return b.adminHandler.Handle(message, user, "stats")
+		return b.adminHandler.Run(c, "stats")
 	case "users":
-		return b.adminHandler.Handle(message, user, "users")
-	case "find_user":
-		return b.handleAdminFindUser(query, user)
+		return b.adminHandler.Run(c, "users")
+	case "find_user", "search_user_id", "search_username":
+		return b.adminHandler.StartFindUser(c)
+	case "balance_add":
+		return b.adminHandler.StartBalanceAdd(c)
+	case "promo_create":
+		return b.adminHandler.StartPromoCreate(c)
+	case "promo_batch":
+		return b.adminHandler.StartPromoBatch(c)
+	case "promo_stats":
+		return b.adminHandler.ShowPromoCampaigns(c)
 	case "balance":
-		return b.handleAdminBalance(query, user)
+		return b.handleAdminBalance(c)
 	case "promo":
-		return b.handleAdminPromo(query, user)
+		return b.handleAdminPromo(c)
 	case "notify":
-		return b.handleAdminNotify(query, user)
+		return b.handleAdminNotify(c)
 	case "logs":
-		return b.handleAdminLogs(query, user)
+		return b.handleAdminLogs(c)
 	case "settings":
-		return b.handleAdminSettings(query, user)
+		return b.handleAdminSettings(c)
+	case "maintenance_toggle":
+		return b.handleMaintenanceToggle(c)
+	case "withdrawals":
+		return b.adminHandler.ShowWithdrawals(c)
+	case "roles":
+		return b.adminHandler.ShowRoles(c)
+	case "role_grant":
+		return b.adminHandler.StartGrantRole(c)
+	case "export":
+		return b.adminHandler.ShowExportMenu(c)
 	default:
-		message := "❌ Неизвестное действие админ-панели"
-		keyboard := b.adminHandler.GetAdminKeyboard().CreateMainMenu()
-		return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+		if promoType, ok := strings.CutPrefix(action, "promo_type:"); ok {
+			return b.adminHandler.HandlePromoCreateType(c, promoType)
+		}
+		if data, ok := strings.CutPrefix(action, "export_run:"); ok {
+			return b.adminHandler.RunExport(c, data)
+		}
+		if data, ok := strings.CutPrefix(action, "stats_chart:"); ok {
+			return b.adminHandler.ShowStatsChart(c, data)
+		}
+		if period, ok := strings.CutPrefix(action, "stats:"); ok {
+			return b.adminHandler.ShowStats(c, period)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_info:"); ok {
+			return b.adminHandler.ShowUserCard(c, telegramID)
+		}
+		if data, ok := strings.CutPrefix(action, "user_subscriptions:"); ok {
+			return b.adminHandler.ShowUserSubscriptions(c, data)
+		}
+		if data, ok := strings.CutPrefix(action, "user_payments:"); ok {
+			return b.adminHandler.ShowUserPayments(c, data)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_referrals:"); ok {
+			return b.adminHandler.ShowUserReferrals(c, telegramID)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_balance:"); ok {
+			return b.adminHandler.StartUserBalance(c, telegramID)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "toggle_block:"); ok {
+			return b.adminHandler.ToggleUserBlock(c, telegramID)
+		}
+		if subscriptionID, ok := strings.CutPrefix(action, "usub_days:"); ok {
+			return b.adminHandler.StartSubscriptionDays(c, subscriptionID)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_tariff:"); ok {
+			return b.adminHandler.ShowGrantTariff(c, telegramID)
+		}
+		if data, ok := strings.CutPrefix(action, "user_grant:"); ok {
+			return b.adminHandler.GrantTariff(c, data)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_trial_reset:"); ok {
+			return b.adminHandler.ResetUserTrial(c, telegramID)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "user_message:"); ok {
+			return b.adminHandler.StartUserMessage(c, telegramID)
+		}
+		if data, ok := strings.CutPrefix(action, "audit:"); ok {
+			return b.adminHandler.ShowAudit(c, data)
+		}
+		if campaignID, ok := strings.CutPrefix(action, "pcamp:"); ok {
+			return b.adminHandler.ShowPromoCampaign(c, campaignID)
+		}
+		if campaignID, ok := strings.CutPrefix(action, "pcsv:"); ok {
+			return b.adminHandler.ExportPromoCampaign(c, campaignID)
+		}
+		if id, ok := strings.CutPrefix(action, "prules:"); ok {
+			return b.adminHandler.ShowPromoRules(c, id)
+		}
+		if data, ok := strings.CutPrefix(action, "prule:"); ok {
+			return b.adminHandler.TogglePromoRule(c, data)
+		}
+		if group, ok := strings.CutPrefix(action, "settings_"); ok {
+			return b.adminHandler.ShowSettingsGroup(c, group)
+		}
+		if key, ok := strings.CutPrefix(action, "set:"); ok {
+			return b.adminHandler.ShowSetting(c, key)
+		}
+		if key, ok := strings.CutPrefix(action, "set_toggle:"); ok {
+			return b.adminHandler.ToggleSetting(c, key)
+		}
+		if key, ok := strings.CutPrefix(action, "set_edit:"); ok {
+			return b.adminHandler.StartEditSetting(c, key)
+		}
+		if key, ok := strings.CutPrefix(action, "set_reset:"); ok {
+			return b.adminHandler.ResetSetting(c, key)
+		}
+		if telegramID, ok := strings.CutPrefix(action, "role_user:"); ok {
+			return b.adminHandler.ShowStaffMember(c, telegramID)
+		}
+		if data, ok := strings.CutPrefix(action, "role_set:"); ok {
+			return b.adminHandler.SetRole(c, data)
+		}
+		if id, ok := strings.CutPrefix(action, "wd:"); ok {
+			return b.adminHandler.ShowWithdrawal(c, id)
+		}
+		if id, ok := strings.CutPrefix(action, "wd_approve:"); ok {
+			return b.adminHandler.ApproveWithdrawal(c, id)
+		}
+		if id, ok := strings.CutPrefix(action, "wd_reject:"); ok {
+			return b.adminHandler.StartRejectWithdrawal(c, id)
+		}
+		if id, ok := strings.CutPrefix(action, "wd_paid:"); ok {
+			return b.adminHandler.MarkWithdrawalPaid(c, id)
+		}
+
+		keyboard := b.adminHandler.GetAdminKeyboard().CreateMainMenu(c.Locale)
+		return b.send(c, c.T("admin.unknown_action"), keyboard)
 	}
 }
 
-// handleAdminFindUser обрабатывает поиск пользователя
-func (b *Bot) handleAdminFindUser(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "🔍 *Поиск пользователя*\n\n"
-	message += "Выберите способ поиска:"
+// handleCancel прерывает активный диалог
+func (b *Bot) handleCancel(c *router.Context) error {
+	// Роутер уже удалил диалог из хранилища, c.Session хранит прерванный шаг
+	if c.Session == nil {
+		return c.Send(c.T("common.nothing_to_cancel"), nil)
+	}
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreateUserManagementMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.createMainMenuKeyboard(c.User, c.Locale)
+	if strings.HasPrefix(c.Session.Step, "admin:") {
+		keyboard = b.adminHandler.GetAdminKeyboard().CreateMainMenu(c.Locale)
+	}
+
+	return c.Send(c.T("common.cancelled"), telegram.Plain(keyboard))
 }
 
 // handleAdminBalance обрабатывает управление балансом
-func (b *Bot) handleAdminBalance(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "💰 *Управление балансом*\n\n"
-	message += "Выберите операцию:"
+func (b *Bot) handleAdminBalance(c *router.Context) error {
+	message := c.T("admin.balance")
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreateBalanceMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.adminHandler.GetAdminKeyboard().CreateBalanceMenu(c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleAdminPromo обрабатывает управление промокодами
-func (b *Bot) handleAdminPromo(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "🎟️ *Управление промокодами*\n\n"
-	message += "Выберите действие:"
+func (b *Bot) handleAdminPromo(c *router.Context) error {
+	message := c.T("admin.promo")
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreatePromoCodeMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.adminHandler.GetAdminKeyboard().CreatePromoCodeMenu(c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleAdminNotify обрабатывает уведомления
-func (b *Bot) handleAdminNotify(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "📢 *Уведомления*\n\n"
-	message += "Выберите тип уведомления:"
+func (b *Bot) handleAdminNotify(c *router.Context) error {
+	message := c.T("admin.notify")
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreateNotificationMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.adminHandler.GetAdminKeyboard().CreateNotificationMenu(c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleAdminLogs обрабатывает логи
-func (b *Bot) handleAdminLogs(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "📋 *Логи активности*\n\n"
-	message += "Выберите тип логов:"
+func (b *Bot) handleAdminLogs(c *router.Context) error {
+	message := c.T("admin.logs")
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreateLogsMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	keyboard := b.adminHandler.GetAdminKeyboard().CreateLogsMenu(c.Locale)
+	return b.send(c, message, keyboard)
 }
 
 // handleAdminSettings обрабатывает настройки
-func (b *Bot) handleAdminSettings(query *tgbotapi.CallbackQuery, _ *models.User) error {
-	message := "⚙️ *Настройки бота*\n\n"
-	message += "Выберите раздел настроек:"
+func (b *Bot) handleAdminSettings(c *router.Context) error {
+	status := b.maintenanceService.Status()
 
-	keyboard := b.adminHandler.GetAdminKeyboard().CreateSettingsMenu()
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, message, keyboard, b.config.BotToken)
+	var message strings.Builder
+	message.WriteString(c.T("admin.settings"))
+	message.WriteString("\n\n")
+	switch {
+	case status.Manual:
+		message.WriteString(c.T("admin.maintenance.manual"))
+	case status.Auto:
+		message.WriteString(c.T("admin.maintenance.auto", "failures", status.Failures))
+	default:
+		message.WriteString(c.T("admin.maintenance.off"))
+	}
+	if status.AutoEnable {
+		message.WriteString("\n" + c.T("admin.maintenance.auto_enabled"))
+	}
+	if status.LastError != "" {
+		message.WriteString("\n" + c.T("admin.maintenance.last_error", "error", status.LastError))
+	}
+
+	keyboard := b.adminHandler.GetAdminKeyboard().CreateSettingsMenu(c.Locale, status.Manual)
+	return b.send(c, message.String(), keyboard)
 }
 
-func (b *Bot) handleTextMessage(c telebot.Context) error {
-	user := c.Get("user").(*models.User)
-	message := &tgbotapi.Message{
-		Chat: &tgbotapi.Chat{ID: c.Message().Chat.ID},
-		From: &tgbotapi.User{ID: c.Message().Sender.ID},
-		Text: c.Message().Text,
-	}
-	return b.textHandler.Handle(message, user)
+// handleMaintenanceToggle включает или выключает техработы вручную
+func (b *Bot) handleMaintenanceToggle(c *router.Context) error {
+	enabled := !b.maintenanceService.Status().Manual
+	b.maintenanceService.SetManual(enabled, c.User.ID)
+	b.adminHandler.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditMaintenanceToggle,
+		TargetType: "maintenance",
+		TargetID:   "manual",
+		Before:     map[string]bool{"enabled": !enabled},
+		After:      map[string]bool{"enabled": enabled},
+	})
+	return b.handleAdminSettings(c)
 }
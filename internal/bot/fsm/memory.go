@@ -0,0 +1,74 @@
+package fsm
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore хранилище сессий в памяти процесса
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[int64]Session
+}
+
+// Убеждаемся, что MemoryStore реализует Store
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore создает новое хранилище сессий в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[int64]Session),
+	}
+}
+
+// Get возвращает копию активной сессии пользователя или nil
+func (s *MemoryStore) Get(userID int64) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[userID]
+	if !ok {
+		return nil, nil
+	}
+	if session.Expired(time.Now()) {
+		delete(s.sessions, userID)
+		return nil, nil
+	}
+
+	return copySession(session), nil
+}
+
+// Save сохраняет копию сессии и удаляет истекшие
+func (s *MemoryStore) Save(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for userID, existing := range s.sessions {
+		if existing.Expired(now) {
+			delete(s.sessions, userID)
+		}
+	}
+
+	s.sessions[session.UserID] = *copySession(*session)
+	return nil
+}
+
+// Delete удаляет сессию пользователя
+func (s *MemoryStore) Delete(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, userID)
+	return nil
+}
+
+// copySession копирует сессию вместе с данными
+func copySession(session Session) *Session {
+	data := make(map[string]string, len(session.Data))
+	for key, value := range session.Data {
+		data[key] = value
+	}
+	session.Data = data
+	return &session
+}
@@ -0,0 +1,43 @@
+package fsm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_SaveGetDelete(t *testing.T) {
+	store := NewMemoryStore()
+
+	session := NewSession(42, "admin:balance_add:user", time.Minute)
+	session.Set("telegram_id", "100")
+	require.NoError(t, store.Save(session))
+
+	// Изменения после сохранения не попадают в хранилище
+	session.Set("telegram_id", "200")
+
+	loaded, err := store.Get(42)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "admin:balance_add:user", loaded.Step)
+	assert.Equal(t, "100", loaded.Get("telegram_id"))
+
+	require.NoError(t, store.Delete(42))
+	loaded, err = store.Get(42)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestMemoryStore_ExpiredSession(t *testing.T) {
+	store := NewMemoryStore()
+
+	session := NewSession(42, "promo_code:input", time.Minute)
+	session.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, store.Save(session))
+
+	loaded, err := store.Get(42)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
@@ -0,0 +1,79 @@
+package fsm
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+)
+
+// PostgresStore хранилище сессий в PostgreSQL, общее для всех реплик бота
+type PostgresStore struct {
+	repo repositories.BotSessionRepository
+}
+
+// Убеждаемся, что PostgresStore реализует Store
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore создает новое хранилище сессий в PostgreSQL
+func NewPostgresStore(repo repositories.BotSessionRepository) *PostgresStore {
+	return &PostgresStore{repo: repo}
+}
+
+// Get возвращает активную сессию пользователя или nil
+func (s *PostgresStore) Get(userID int64) (*Session, error) {
+	record, err := s.repo.GetByTelegramID(userID)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	session := &Session{
+		UserID:    record.TelegramID,
+		Step:      record.Step,
+		Data:      make(map[string]string),
+		ExpiresAt: record.ExpiresAt,
+	}
+	if session.Expired(time.Now()) {
+		return nil, s.repo.Delete(userID)
+	}
+
+	if record.Data != "" {
+		if err := json.Unmarshal([]byte(record.Data), &session.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode session data: %w", err)
+		}
+	}
+
+	return session, nil
+}
+
+// Save сохраняет сессию пользователя
+func (s *PostgresStore) Save(session *Session) error {
+	data := session.Data
+	if data == nil {
+		data = map[string]string{}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode session data: %w", err)
+	}
+
+	return s.repo.Save(&models.BotSession{
+		TelegramID: session.UserID,
+		Step:       session.Step,
+		Data:       string(encoded),
+		ExpiresAt:  session.ExpiresAt,
+	})
+}
+
+// Delete удаляет сессию пользователя
+func (s *PostgresStore) Delete(userID int64) error {
+	return s.repo.Delete(userID)
+}
+
+// Cleanup удаляет все истекшие сессии
+func (s *PostgresStore) Cleanup() error {
+	return s.repo.DeleteExpired(time.Now())
+}
@@ -0,0 +1,65 @@
+package fsm
+
+import "time"
+
+// DefaultTimeout время жизни диалога по умолчанию
+const DefaultTimeout = 10 * time.Minute
+
+// Session состояние многошагового диалога пользователя
+type Session struct {
+	// UserID Telegram ID пользователя
+	UserID int64
+	// Step текущий шаг диалога
+	Step string
+	// Data данные, собранные на предыдущих шагах
+	Data map[string]string
+	// ExpiresAt время, после которого диалог считается брошенным
+	ExpiresAt time.Time
+}
+
+// NewSession создает новую сессию на первом шаге диалога
+func NewSession(userID int64, step string, timeout time.Duration) *Session {
+	session := &Session{
+		UserID: userID,
+		Data:   make(map[string]string),
+	}
+	session.Next(step, timeout)
+	return session
+}
+
+// Get возвращает собранное значение по ключу
+func (s *Session) Get(key string) string {
+	return s.Data[key]
+}
+
+// Set сохраняет значение, собранное на шаге
+func (s *Session) Set(key, value string) {
+	if s.Data == nil {
+		s.Data = make(map[string]string)
+	}
+	s.Data[key] = value
+}
+
+// Next переводит диалог на следующий шаг и продлевает его время жизни
+func (s *Session) Next(step string, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	s.Step = step
+	s.ExpiresAt = time.Now().Add(timeout)
+}
+
+// Expired проверяет, истекло ли время жизни диалога
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && now.After(s.ExpiresAt)
+}
+
+// Store хранилище сессий диалогов
+type Store interface {
+	// Get возвращает активную сессию пользователя или nil
+	Get(userID int64) (*Session, error)
+	// Save сохраняет сессию
+	Save(session *Session) error
+	// Delete завершает диалог пользователя
+	Delete(userID int64) error
+}
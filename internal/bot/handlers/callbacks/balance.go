@@ -1,13 +1,13 @@
 package callbacks
 
 import (
-	"fmt"
-	"remnawave-tg-shop/internal/bot/utils"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
-	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mymmrac/telego"
 )
 
 // BalanceHandler обрабатывает callback для баланса
@@ -25,50 +25,38 @@ func NewBalanceHandler(config *config.Config, userService services.UserService)
 }
 
 // Handle обрабатывает callback для баланса
-func (h *BalanceHandler) Handle(query *tgbotapi.CallbackQuery, user *models.User) error {
-	text := fmt.Sprintf("💰 Ваш баланс: %.0f₽\n\n", user.Balance)
-	text += "Выберите способ пополнения:"
+func (h *BalanceHandler) Handle(c *router.Context) error {
+	text := c.T("balance.text", "balance", c.User.Balance)
 
 	// Создаем клавиатуру с методами оплаты
-	keyboard := h.createPaymentKeyboard()
+	keyboard := h.createPaymentKeyboard(c.Locale)
 
 	// Отправляем сообщение
-	return utils.SendMessageWithKeyboard(query.Message.Chat.ID, text, keyboard, h.config.BotToken)
+	return c.Respond(text, telegram.Plain(keyboard))
 }
 
 // createPaymentKeyboard создает клавиатуру с методами оплаты
-func (h *BalanceHandler) createPaymentKeyboard() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (h *BalanceHandler) createPaymentKeyboard(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Кнопка "Назад"
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "start"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("common.back"), CallbackData: "start"},
 	})
 
 	// Методы оплаты (если включены)
-	if h.config.Payments.StarsEnabled {
-		keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("⭐ Telegram Stars", "payment_stars"),
+	payments := h.config.Settings().Payments
+	if payments.StarsEnabled {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "⭐ Telegram Stars", CallbackData: "payment_stars"},
 		})
 	}
 
-	if h.config.Payments.TributeEnabled {
-		keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("💎 Tribute", "payment_tribute"),
+	if payments.TributeEnabled {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "💎 Tribute", CallbackData: "payment_tribute"},
 		})
 	}
 
-	if h.config.Payments.YooKassaEnabled {
-		keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("💳 ЮKassa", "payment_yookassa"),
-		})
-	}
-
-	if h.config.Payments.CryptoPayEnabled {
-		keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("₿ CryptoPay", "payment_cryptopay"),
-		})
-	}
-
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
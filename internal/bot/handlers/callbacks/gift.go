@@ -0,0 +1,175 @@
+package callbacks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// giftListLimit количество подарков в списке пользователя
+const giftListLimit = 10
+
+// GiftHandler обрабатывает callback'и покупки подарочных подписок
+type GiftHandler struct {
+	config      *config.Config
+	giftService services.IGiftService
+}
+
+// NewGiftHandler создает новый GiftHandler
+func NewGiftHandler(config *config.Config, giftService services.IGiftService) *GiftHandler {
+	return &GiftHandler{
+		config:      config,
+		giftService: giftService,
+	}
+}
+
+// Handle обрабатывает callback'и вида gift[:<действие>[:<тариф>]]
+func (h *GiftHandler) Handle(c *router.Context) error {
+	parts := strings.Split(c.Data, ":")
+	if len(parts) < 2 {
+		return h.showTariffs(c)
+	}
+
+	switch parts[1] {
+	case "list":
+		return h.showGifts(c)
+	case "tariff":
+		if len(parts) < 3 {
+			return h.showTariffs(c)
+		}
+		return h.confirmPurchase(c, parts[2])
+	case "buy":
+		if len(parts) < 3 {
+			return h.showTariffs(c)
+		}
+		return h.purchase(c, parts[2])
+	default:
+		return h.showTariffs(c)
+	}
+}
+
+// showTariffs показывает тарифы, доступные для подарка
+func (h *GiftHandler) showTariffs(c *router.Context) error {
+	text := c.T("gift.title") + "\n\n"
+	text += c.T("gift.intro") + "\n\n"
+	text += c.T("gift.balance", "balance", c.User.Balance)
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, tariff := range models.Tariffs() {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: tariff.GetButtonText(c.Locale), CallbackData: "gift:tariff:" + tariff.Key},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: c.T("gift.my_gifts"), CallbackData: "gift:list"},
+	})
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: c.T("common.back"), CallbackData: "start"},
+	})
+
+	return c.Respond(text, telegram.Plain(&telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}))
+}
+
+// confirmPurchase показывает подтверждение покупки подарка
+func (h *GiftHandler) confirmPurchase(c *router.Context, tariffKey string) error {
+	tariff, ok := models.GetTariff(tariffKey)
+	if !ok {
+		return h.showTariffs(c)
+	}
+
+	text := c.T("gift.confirm") + "\n\n"
+	text += c.T("gift.tariff", "name", tariff.Name) + "\n"
+	text += c.T("gift.duration", "duration", c.N("units.days", tariff.DurationDays)) + "\n"
+	text += c.T("gift.price", "price", tariff.Price) + "\n"
+	text += c.T("gift.balance", "balance", c.User.Balance) + "\n"
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	if c.User.Balance < tariff.Price {
+		text += "\n" + c.T("gift.insufficient")
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: c.T("purchase.top_up"), CallbackData: "balance"},
+		})
+	} else {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: c.T("gift.pay", "price", tariff.Price), CallbackData: "gift:buy:" + tariff.Key},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: c.T("common.back"), CallbackData: "gift"},
+	})
+
+	return c.Respond(text, telegram.Plain(&telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}))
+}
+
+// purchase оплачивает подарок и показывает код и ссылку
+func (h *GiftHandler) purchase(c *router.Context, tariffKey string) error {
+	promoCode, err := h.giftService.PurchaseGift(c.User.ID, tariffKey)
+	if err != nil {
+		text := c.T("gift.failed")
+		switch {
+		case errors.Is(err, services.ErrGiftInsufficientBalance):
+			text = c.T("gift.insufficient_error")
+		case errors.Is(err, services.ErrGiftUnknownTariff):
+			text = c.T("gift.unknown_tariff")
+		}
+		keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{{Text: c.T("purchase.top_up"), CallbackData: "balance"}},
+			{{Text: c.T("common.back"), CallbackData: "gift"}},
+		}}
+		return c.Respond(text, telegram.Plain(keyboard))
+	}
+
+	text := c.T("gift.paid") + "\n\n"
+	text += c.T("gift.plan", "plan", promoCode.PlanName, "duration", c.N("units.days", int(promoCode.Value))) + "\n\n"
+	text += c.T("gift.code", "code", promoCode.Code) + "\n"
+	text += c.T("gift.link", "link", h.giftService.GiftLink(promoCode)) + "\n\n"
+	text += c.T("gift.paid_hint")
+
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("gift.my_gifts"), CallbackData: "gift:list"}},
+		{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+	}}
+	return c.Respond(text, telegram.Plain(keyboard))
+}
+
+// showGifts показывает подарки, купленные пользователем
+func (h *GiftHandler) showGifts(c *router.Context) error {
+	gifts, err := h.giftService.GetUserGifts(c.User.ID, giftListLimit, 0)
+	if err != nil {
+		return c.Respond(c.T("gift.list_error"), telegram.Plain(h.backKeyboard(c)))
+	}
+
+	text := c.T("gift.my_gifts") + "\n\n"
+	if len(gifts) == 0 {
+		text += c.T("gift.list_empty")
+	}
+	for i := range gifts {
+		gift := &gifts[i]
+		status := c.T("gift.pending")
+		if gift.UsedCount > 0 {
+			status = c.T("gift.redeemed")
+		}
+		text += c.T("gift.item", "date", gift.CreatedAt.Format("02.01.2006"), "plan", gift.PlanName,
+			"duration", c.N("units.days", int(gift.Value)), "status", status) + "\n"
+		if gift.UsedCount == 0 {
+			text += fmt.Sprintf("  %s\n", h.giftService.GiftLink(gift))
+		}
+	}
+
+	return c.Respond(text, telegram.Plain(h.backKeyboard(c)))
+}
+
+// backKeyboard создает клавиатуру возврата к подаркам
+func (h *GiftHandler) backKeyboard(c *router.Context) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("common.back"), CallbackData: "gift"}},
+	}}
+}
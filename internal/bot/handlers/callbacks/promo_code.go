@@ -1,231 +1,222 @@
 package callbacks
 
 import (
-	"fmt"
-	"remnawave-tg-shop/internal/bot/utils"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
 	"strings"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
+	"github.com/mymmrac/telego"
 )
 
 // PromoCodeHandler обрабатывает callback'и для промокодов
 type PromoCodeHandler struct {
 	config             *config.Config
+	logger             logger.Logger
 	userService        services.UserService
 	promoCodeService   services.IPromoCodeService
+	giftService        services.IGiftService
 	activityLogService services.IActivityLogService
 }
 
 // NewPromoCodeHandler создает новый PromoCodeHandler
 func NewPromoCodeHandler(
 	config *config.Config,
+	log logger.Logger,
 	userService services.UserService,
 	promoCodeService services.IPromoCodeService,
+	giftService services.IGiftService,
 	activityLogService services.IActivityLogService,
 ) *PromoCodeHandler {
 	return &PromoCodeHandler{
 		config:             config,
+		logger:             log,
 		userService:        userService,
 		promoCodeService:   promoCodeService,
+		giftService:        giftService,
 		activityLogService: activityLogService,
 	}
 }
 
 // Handle обрабатывает callback для промокодов
-func (h *PromoCodeHandler) Handle(query *tgbotapi.CallbackQuery, user *models.User) error {
-	data := query.Data
+func (h *PromoCodeHandler) Handle(c *router.Context) error {
+	data := c.Data
 
 	// Парсим данные callback'а
 	parts := strings.Split(data, ":")
 	if len(parts) < 2 {
-		return h.showPromoCodeMenu(query, user)
+		return h.showPromoCodeMenu(c)
 	}
 
 	action := parts[1]
 
 	switch action {
 	case "menu":
-		return h.showPromoCodeMenu(query, user)
+		return h.showPromoCodeMenu(c)
 	case "apply":
 		if len(parts) < 3 {
-			return h.showPromoCodeInput(query, user)
+			return h.showPromoCodeInput(c)
 		}
 		code := parts[2]
-		return h.applyPromoCode(query, user, code)
+		return h.applyPromoCode(c, code)
 	case "input":
-		return h.showPromoCodeInput(query, user)
+		return h.showPromoCodeInput(c)
 	default:
-		return h.showPromoCodeMenu(query, user)
+		return h.showPromoCodeMenu(c)
 	}
 }
 
 // showPromoCodeMenu показывает меню промокодов
-func (h *PromoCodeHandler) showPromoCodeMenu(query *tgbotapi.CallbackQuery, user *models.User) error {
-	text := "🎟️ *Промокоды*\n\n"
-	text += "Введите промокод для получения бонусов!\n\n"
-	text += "Доступные типы промокодов:\n"
-	text += "• 🎁 Бонусные дни подписки\n"
-	text += "• 💰 Скидка на покупку\n"
-	text += "• 🎯 Специальные предложения\n\n"
-	text += "Нажмите кнопку ниже, чтобы ввести промокод:"
+func (h *PromoCodeHandler) showPromoCodeMenu(c *router.Context) error {
+	text := c.T("promo_code.menu")
 
 	// Создаем клавиатуру
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 Ввести промокод", "promo_code:input"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "start"),
-		),
-	)
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("promo_code.enter"), CallbackData: "promo_code:input"}},
+		{{Text: c.T("common.back"), CallbackData: "start"}},
+	}}
 
 	// Отправляем сообщение
-	msg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = &keyboard
+	return c.Respond(text, telegram.Markdown(keyboard))
+}
 
-	bot, err := tgbotapi.NewBotAPI(h.config.BotToken)
-	if err != nil {
+// StepPromoCodeInput шаг диалога ввода промокода
+const StepPromoCodeInput = "promo_code:input"
+
+// showPromoCodeInput показывает форму ввода промокода
+func (h *PromoCodeHandler) showPromoCodeInput(c *router.Context) error {
+	// Следующее текстовое сообщение будет принято как промокод
+	if err := c.StartStep(StepPromoCodeInput); err != nil {
 		return err
 	}
 
-	_, err = bot.Send(msg)
-	return err
-}
-
-// showPromoCodeInput показывает форму ввода промокода
-func (h *PromoCodeHandler) showPromoCodeInput(query *tgbotapi.CallbackQuery, user *models.User) error {
-	text := "📝 *Ввод промокода*\n\n"
-	text += "Отправьте промокод в следующем сообщении.\n\n"
-	text += "Пример: `PROMO2024` или `BONUS50`\n\n"
-	text += "⚠️ Промокод можно использовать только один раз!"
+	text := c.T("promo_code.input")
 
 	// Создаем клавиатуру
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Назад к промокодам", "promo_code:menu"),
-		),
-	)
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("promo_code.back"), CallbackData: "promo_code:menu"}},
+	}}
 
 	// Отправляем сообщение
-	msg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = &keyboard
-
-	bot, err := tgbotapi.NewBotAPI(h.config.BotToken)
-	if err != nil {
-		return err
-	}
-
-	_, err = bot.Send(msg)
-	return err
+	return c.Respond(text, telegram.Markdown(keyboard))
 }
 
 // applyPromoCode применяет промокод
-func (h *PromoCodeHandler) applyPromoCode(query *tgbotapi.CallbackQuery, user *models.User, code string) error {
+func (h *PromoCodeHandler) applyPromoCode(c *router.Context, code string) error {
 	// Логируем попытку применения промокода
-	h.activityLogService.LogPromoCode(user.ID, uuid.Nil, code, "", "")
+	h.activityLogService.LogPromoCode(c.User.ID, uuid.Nil, code, "", "")
 
 	// Применяем промокод
-	promoCode, err := h.promoCodeService.ApplyPromoCode(user.ID, code)
+	usage, err := h.promoCodeService.ApplyPromoCode(c.User.ID, code)
 	if err != nil {
-		text := "❌ *Ошибка применения промокода*\n\n"
-		text += fmt.Sprintf("Причина: %s\n\n", err.Error())
-		text += "Проверьте правильность введенного кода и попробуйте снова."
+		text := c.T("promo_code.error", "reason", h.errorReason(c, err))
 
 		// Создаем клавиатуру
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🔄 Попробовать снова", "promo_code:input"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "start"),
-			),
-		)
+		keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{{Text: c.T("promo_code.retry"), CallbackData: "promo_code:input"}},
+			{{Text: c.T("common.back"), CallbackData: "start"}},
+		}}
 
 		// Отправляем сообщение
-		msg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = &keyboard
-
-		bot, err := tgbotapi.NewBotAPI(h.config.BotToken)
-		if err != nil {
-			return err
-		}
-
-		_, err = bot.Send(msg)
-		return err
+		return c.Respond(text, telegram.Markdown(keyboard))
 	}
+	h.giftService.NotifyRedeemed(usage, c.User)
 
 	// Промокод успешно применен
-	text := "✅ *Промокод успешно применен!*\n\n"
-	text += fmt.Sprintf("🎟️ Код: `%s`\n", promoCode.Code)
-	text += fmt.Sprintf("📝 Тип: %s\n", promoCode.GetTypeText())
-	text += fmt.Sprintf("💎 Значение: %.2f\n", promoCode.Value)
-
-	if promoCode.Description != "" {
-		text += fmt.Sprintf("📄 Описание: %s\n", promoCode.Description)
-	}
-
-	text += "\n🎉 Бонус добавлен к вашему аккаунту!"
+	text := promoCodeAppliedText(c, usage)
 
 	// Создаем клавиатуру
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🎟️ Еще промокод", "promo_code:input"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Главное меню", "start"),
-		),
-	)
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("promo_code.another"), CallbackData: "promo_code:input"}},
+		{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+	}}
 
 	// Отправляем сообщение
-	msg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = &keyboard
+	return c.Respond(text, telegram.Markdown(keyboard))
+}
 
-	bot, err := tgbotapi.NewBotAPI(h.config.BotToken)
-	if err != nil {
+// HandlePromoCodeInputStep применяет промокод, введенный после нажатия "Ввести промокод"
+func (h *PromoCodeHandler) HandlePromoCodeInputStep(c *router.Context) error {
+	if err := c.FinishStep(); err != nil {
 		return err
 	}
-
-	_, err = bot.Send(msg)
-	return err
+	return h.applyPromoCode(c, strings.TrimSpace(c.Text()))
 }
 
 // HandlePromoCodeMessage обрабатывает текстовое сообщение с промокодом
-func (h *PromoCodeHandler) HandlePromoCodeMessage(message *tgbotapi.Message, user *models.User) error {
-	code := strings.TrimSpace(message.Text)
+func (h *PromoCodeHandler) HandlePromoCodeMessage(c *router.Context) error {
+	code := c.Args
+	if c.Command == "" {
+		code = c.Text()
+	}
+	code = strings.TrimSpace(code)
+
+	// /promo без кода открывает диалог ввода
+	if code == "" {
+		return h.showPromoCodeInput(c)
+	}
 
 	// Логируем попытку применения промокода
-	h.activityLogService.LogPromoCode(user.ID, uuid.Nil, code, "", "")
+	h.activityLogService.LogPromoCode(c.User.ID, uuid.Nil, code, "", "")
 
 	// Применяем промокод
-	promoCode, err := h.promoCodeService.ApplyPromoCode(user.ID, code)
+	usage, err := h.promoCodeService.ApplyPromoCode(c.User.ID, code)
 	if err != nil {
-		text := "❌ *Ошибка применения промокода*\n\n"
-		text += fmt.Sprintf("Причина: %s\n\n", err.Error())
-		text += "Проверьте правильность введенного кода и попробуйте снова.\n\n"
-		text += "Для ввода нового промокода используйте команду /promo"
+		text := c.T("promo_code.error", "reason", h.errorReason(c, err)) + "\n\n"
+		text += c.T("promo_code.command_hint")
 
-		return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+		return c.Send(text, nil)
 	}
+	h.giftService.NotifyRedeemed(usage, c.User)
 
 	// Промокод успешно применен
-	text := "✅ *Промокод успешно применен!*\n\n"
-	text += fmt.Sprintf("🎟️ Код: `%s`\n", promoCode.Code)
-	text += fmt.Sprintf("📝 Тип: %s\n", promoCode.GetTypeText())
-	text += fmt.Sprintf("💎 Значение: %.2f\n", promoCode.Value)
+	return c.Send(promoCodeAppliedText(c, usage), nil)
+}
+
+// errorReason возвращает причину отказа в промокоде на языке пользователя.
+// Внутренние ошибки логируются и показываются общим текстом
+func (h *PromoCodeHandler) errorReason(c *router.Context, err error) string {
+	if key := services.PromoCodeErrorKey(err); key != "" {
+		return c.T(key)
+	}
+	h.logger.Error("Failed to apply promo code", "user_id", c.User.ID, "error", err)
+	return c.T("common.error")
+}
+
+// promoCodeAppliedText описывает результат применения промокода
+func promoCodeAppliedText(c *router.Context, usage *models.PromoCodeUsage) string {
+	promoCode := usage.PromoCode
+
+	text := c.T("promo_code.applied") + "\n\n"
+	text += c.T("promo_code.code", "code", promoCode.Code) + "\n"
+	text += c.T("promo_code.type_line", "type", promoCode.GetTypeText(c.Locale)) + "\n"
 
 	if promoCode.Description != "" {
-		text += fmt.Sprintf("📄 Описание: %s\n", promoCode.Description)
+		text += c.T("promo_code.description", "description", promoCode.Description) + "\n"
 	}
 
-	text += "\n🎉 Бонус добавлен к вашему аккаунту!"
+	switch promoCode.Type {
+	case "bonus_days":
+		duration := c.N("units.days", int(promoCode.Value))
+		if usage.Subscription != nil {
+			text += "\n" + c.T("promo_code.bonus_days_until", "duration", duration, "date", usage.Subscription.ExpiresAt.Format("02.01.2006"))
+		} else {
+			text += "\n" + c.T("promo_code.bonus_days", "duration", duration)
+		}
+	case "discount_percent", "discount_amount":
+		text += "\n" + c.T("promo_code."+promoCode.Type, "value", promoCode.Value)
+	case models.PromoCodeTypeGift:
+		duration := c.N("units.days", int(promoCode.Value))
+		if usage.Subscription != nil {
+			text += "\n" + c.T("promo_code.gift_until", "plan", promoCode.PlanName, "duration", duration, "date", usage.Subscription.ExpiresAt.Format("02.01.2006"))
+		} else {
+			text += "\n" + c.T("promo_code.gift", "plan", promoCode.PlanName, "duration", duration)
+		}
+	}
 
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	return text
 }
@@ -0,0 +1,232 @@
+package callbacks
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// Шаги диалога вывода средств
+const (
+	StepWithdrawAmount  = "withdraw:amount"
+	StepWithdrawMethod  = "withdraw:method"
+	StepWithdrawDetails = "withdraw:details"
+)
+
+// withdrawalListLimit количество заявок в списке пользователя
+const withdrawalListLimit = 10
+
+// WithdrawalHandler обрабатывает callback'и вывода партнерских средств
+type WithdrawalHandler struct {
+	config            *config.Config
+	partnerService    services.IPartnerService
+	withdrawalService services.IWithdrawalService
+}
+
+// NewWithdrawalHandler создает новый WithdrawalHandler
+func NewWithdrawalHandler(
+	config *config.Config,
+	partnerService services.IPartnerService,
+	withdrawalService services.IWithdrawalService,
+) *WithdrawalHandler {
+	return &WithdrawalHandler{
+		config:            config,
+		partnerService:    partnerService,
+		withdrawalService: withdrawalService,
+	}
+}
+
+// Handle обрабатывает callback'и вида withdraw:<действие>
+func (h *WithdrawalHandler) Handle(c *router.Context) error {
+	if !h.partnerService.IsEnabled() {
+		return c.Answer(c.T("withdrawal.disabled"), true)
+	}
+
+	parts := strings.Split(c.Data, ":")
+	if len(parts) < 2 {
+		return h.startWithdrawal(c)
+	}
+
+	switch parts[1] {
+	case "list":
+		return h.showRequests(c)
+	case "method":
+		if len(parts) < 3 {
+			return h.startWithdrawal(c)
+		}
+		return h.selectMethod(c, parts[2])
+	default:
+		return h.startWithdrawal(c)
+	}
+}
+
+// startWithdrawal показывает доступную сумму и запрашивает сумму вывода
+func (h *WithdrawalHandler) startWithdrawal(c *router.Context) error {
+	stats, err := h.partnerService.GetStats(c.User.ID)
+	if err != nil {
+		return c.Respond(c.T("withdrawal.stats_error"), telegram.Plain(h.backKeyboard(c)))
+	}
+
+	text := c.T("withdrawal.title") + "\n\n"
+	text += c.T("withdrawal.available", "amount", stats.Available) + "\n"
+	if stats.Frozen > 0 {
+		text += c.T("withdrawal.frozen", "amount", stats.Frozen) + "\n"
+	}
+	text += c.T("withdrawal.minimum", "amount", h.withdrawalService.MinAmount()) + "\n\n"
+
+	if stats.Available < h.withdrawalService.MinAmount() || stats.Available <= 0 {
+		text += c.T("withdrawal.not_enough")
+		return c.Respond(text, telegram.Plain(h.backKeyboard(c)))
+	}
+
+	if err := c.StartStep(StepWithdrawAmount); err != nil {
+		return err
+	}
+
+	text += c.T("withdrawal.amount_prompt")
+
+	return c.Respond(text, telegram.Plain(h.backKeyboard(c)))
+}
+
+// HandleAmountStep запоминает сумму и предлагает выбрать способ выплаты
+func (h *WithdrawalHandler) HandleAmountStep(c *router.Context) error {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(c.Text()), ",", "."), 64)
+	if err != nil || amount <= 0 {
+		return c.Send(c.T("withdrawal.invalid_amount"), nil)
+	}
+	if amount < h.withdrawalService.MinAmount() {
+		return c.Send(c.T("withdrawal.below_minimum", "amount", h.withdrawalService.MinAmount()), nil)
+	}
+
+	c.Session.Set("amount", strconv.FormatFloat(amount, 'f', 2, 64))
+	if err := c.NextStep(StepWithdrawMethod); err != nil {
+		return err
+	}
+
+	text := c.T("withdrawal.amount", "amount", amount) + "\n\n"
+	text += c.T("withdrawal.choose_method")
+
+	return c.Send(text, telegram.Plain(h.methodKeyboard(c)))
+}
+
+// HandleMethodStep просит выбрать способ выплаты кнопкой
+func (h *WithdrawalHandler) HandleMethodStep(c *router.Context) error {
+	return c.Send(c.T("withdrawal.use_buttons"), telegram.Plain(h.methodKeyboard(c)))
+}
+
+// selectMethod запоминает способ выплаты и запрашивает реквизиты
+func (h *WithdrawalHandler) selectMethod(c *router.Context, method string) error {
+	if c.Session == nil || c.Session.Step != StepWithdrawMethod {
+		return c.Respond(c.T("withdrawal.expired"), telegram.Plain(h.backKeyboard(c)))
+	}
+	if method != "card" && method != "crypto" {
+		return c.Respond(c.T("withdrawal.use_buttons"), telegram.Plain(h.methodKeyboard(c)))
+	}
+
+	c.Session.Set("method", method)
+	if err := c.NextStep(StepWithdrawDetails); err != nil {
+		return err
+	}
+
+	text := c.T("withdrawal.details_prompt") + "\n\n"
+	text += c.T("withdrawal.details_"+method) + "\n"
+	text += c.T("withdrawal.cancel_hint")
+
+	return c.Respond(text, telegram.Plain(h.backKeyboard(c)))
+}
+
+// HandleDetailsStep создает заявку на вывод из собранных данных
+func (h *WithdrawalHandler) HandleDetailsStep(c *router.Context) error {
+	details := strings.TrimSpace(c.Text())
+	if details == "" || len(details) > 255 {
+		return c.Send(c.T("withdrawal.invalid_details"), nil)
+	}
+
+	method := c.Session.Get("method")
+	amount, err := strconv.ParseFloat(c.Session.Get("amount"), 64)
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	if err != nil {
+		return c.Send(c.T("withdrawal.broken"), telegram.Plain(h.backKeyboard(c)))
+	}
+
+	request, err := h.withdrawalService.CreateRequest(c.User.ID, amount, method, details)
+	if err != nil {
+		return c.Send(withdrawalErrorText(c, err), telegram.Plain(h.backKeyboard(c)))
+	}
+
+	text := c.T("withdrawal.created") + "\n\n"
+	text += c.T("withdrawal.amount", "amount", request.Amount) + "\n"
+	text += c.T("withdrawal.method_line", "method", request.GetMethodText(c.Locale)) + "\n"
+	text += c.T("withdrawal.details", "details", request.Details) + "\n\n"
+	text += c.T("withdrawal.frozen_hint")
+
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("partner.requests"), CallbackData: "withdraw:list"}},
+		{{Text: c.T("common.main_menu"), CallbackData: "start"}},
+	}}
+	return c.Send(text, telegram.Plain(keyboard))
+}
+
+// showRequests показывает последние заявки пользователя
+func (h *WithdrawalHandler) showRequests(c *router.Context) error {
+	requests, err := h.withdrawalService.GetUserRequests(c.User.ID, withdrawalListLimit, 0)
+	if err != nil {
+		return c.Respond(c.T("withdrawal.list_error"), telegram.Plain(h.backKeyboard(c)))
+	}
+
+	text := c.T("withdrawal.list_title") + "\n\n"
+	if len(requests) == 0 {
+		text += c.T("withdrawal.list_empty")
+	}
+	for _, request := range requests {
+		text += c.T("withdrawal.item", "date", request.CreatedAt.Format("02.01.2006"), "amount", request.Amount,
+			"method", strings.ToLower(request.GetMethodText(c.Locale)), "status", request.GetStatusText(c.Locale)) + "\n"
+		if request.Status == models.WithdrawalStatusRejected && request.Comment != "" {
+			text += c.T("withdrawal.reason", "reason", request.Comment) + "\n"
+		}
+	}
+
+	return c.Respond(text, telegram.Plain(h.backKeyboard(c)))
+}
+
+// methodKeyboard создает клавиатуру выбора способа выплаты
+func (h *WithdrawalHandler) methodKeyboard(c *router.Context) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("withdrawal.card_button"), CallbackData: "withdraw:method:card"}},
+		{{Text: c.T("withdrawal.crypto_button"), CallbackData: "withdraw:method:crypto"}},
+		{{Text: c.T("withdrawal.cancel"), CallbackData: "partner"}},
+	}}
+}
+
+// backKeyboard создает клавиатуру возврата к партнерской статистике
+func (h *WithdrawalHandler) backKeyboard(c *router.Context) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: c.T("common.back"), CallbackData: "partner"}},
+	}}
+}
+
+// withdrawalErrorText возвращает понятное пользователю описание ошибки
+func withdrawalErrorText(c *router.Context, err error) string {
+	switch {
+	case errors.Is(err, services.ErrWithdrawalInsufficientFunds):
+		return c.T("withdrawal.error.insufficient_funds")
+	case errors.Is(err, services.ErrWithdrawalBelowMinimum):
+		return c.T("withdrawal.error.below_minimum")
+	case errors.Is(err, services.ErrWithdrawalInvalidMethod):
+		return c.T("withdrawal.error.invalid_method")
+	case errors.Is(err, services.ErrWithdrawalEmptyDetails):
+		return c.T("withdrawal.error.empty_details")
+	default:
+		return c.T("withdrawal.error.failed")
+	}
+}
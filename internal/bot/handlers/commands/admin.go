@@ -1,16 +1,20 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"remnawave-tg-shop/internal/bot/keyboards"
-	"remnawave-tg-shop/internal/bot/utils"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
 	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
 	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
 	"strconv"
 	"strings"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mymmrac/telego"
 )
 
 // AdminHandler обрабатывает админские команды
@@ -22,6 +26,11 @@ type AdminHandler struct {
 	promoCodeService    services.IPromoCodeService
 	notificationService services.INotificationService
 	activityLogService  services.IActivityLogService
+	withdrawalService   services.IWithdrawalService
+	settingsService     services.ISettingsService
+	auditService        services.IAuditService
+	statsService        services.IStatsService
+	exportService       services.IExportService
 	adminKeyboard       *keyboards.AdminMenuKeyboard
 }
 
@@ -34,6 +43,11 @@ func NewAdminHandler(
 	promoCodeService services.IPromoCodeService,
 	notificationService services.INotificationService,
 	activityLogService services.IActivityLogService,
+	withdrawalService services.IWithdrawalService,
+	settingsService services.ISettingsService,
+	auditService services.IAuditService,
+	statsService services.IStatsService,
+	exportService services.IExportService,
 ) *AdminHandler {
 	return &AdminHandler{
 		config:              config,
@@ -43,80 +57,86 @@ func NewAdminHandler(
 		promoCodeService:    promoCodeService,
 		notificationService: notificationService,
 		activityLogService:  activityLogService,
+		withdrawalService:   withdrawalService,
+		settingsService:     settingsService,
+		auditService:        auditService,
+		statsService:        statsService,
+		exportService:       exportService,
 		adminKeyboard:       keyboards.NewAdminMenuKeyboard(),
 	}
 }
 
 // Handle обрабатывает админские команды
-func (h *AdminHandler) Handle(message *tgbotapi.Message, user *models.User, args string) error {
-	// Проверяем, является ли пользователь админом
-	if !h.userService.IsAdmin(user.TelegramID) {
-		return utils.SendMessage(message.Chat.ID, "❌ У вас нет прав администратора", h.config.BotToken)
+func (h *AdminHandler) Handle(c *router.Context) error {
+	return h.Run(c, c.Args)
+}
+
+// Run выполняет админскую команду с указанными аргументами
+func (h *AdminHandler) Run(c *router.Context, args string) error {
+	// Проверяем, есть ли у пользователя роль в админ-панели
+	role := h.userService.GetRole(c.User.TelegramID)
+	if role == "" {
+		return h.send(c, "❌ У вас нет прав администратора", nil)
 	}
 
 	// Логируем команду
-	h.activityLogService.LogCommand(user.ID, "admin", args, "", "")
+	h.activityLogService.LogCommand(c.User.ID, "admin", args, "", "")
 
 	// Парсим команду
 	parts := strings.Fields(args)
 	if len(parts) == 0 {
-		return h.showAdminMenu(message, user)
+		return h.showAdminMenu(c)
 	}
 
 	command := parts[0]
 	commandArgs := strings.Join(parts[1:], " ")
 
+	// Проверяем право роли на команду
+	if permission, ok := commandPermissions[command]; ok && !models.RoleHasPermission(role, permission) {
+		return h.send(c, PermissionDeniedText(permission), nil)
+	}
+
 	switch command {
 	case "stats":
-		return h.showStats(message, user)
+		return h.ShowStats(c, commandArgs)
 	case "users":
-		return h.showUsers(message, user, commandArgs)
+		return h.showUsers(c, commandArgs)
 	case "user":
-		return h.showUser(message, user, commandArgs)
+		return h.showUser(c, commandArgs)
 	case "block":
-		return h.blockUser(message, user, commandArgs)
+		return h.blockUser(c, commandArgs)
 	case "unblock":
-		return h.unblockUser(message, user, commandArgs)
+		return h.unblockUser(c, commandArgs)
 	case "balance":
-		return h.manageBalance(message, user, commandArgs)
+		return h.manageBalance(c, commandArgs)
 	case "promo":
-		return h.managePromoCodes(message, user, commandArgs)
+		return h.managePromoCodes(c, commandArgs)
 	case "notify":
-		return h.sendNotification(message, user, commandArgs)
+		return h.sendNotification(c, commandArgs)
 	case "logs":
-		return h.showLogs(message, user, commandArgs)
+		return h.showLogs(c, commandArgs)
+	case "audit":
+		return h.showUserAudit(c, commandArgs)
+	case "export":
+		return h.exportCommand(c, commandArgs)
 	case "help":
-		return h.showAdminHelp(message, user)
+		return h.showAdminHelp(c)
 	default:
-		return h.showAdminMenu(message, user)
+		return h.showAdminMenu(c)
 	}
 }
 
 // showAdminMenu показывает главное меню админ-панели
-func (h *AdminHandler) showAdminMenu(message *tgbotapi.Message, _ *models.User) error {
+func (h *AdminHandler) showAdminMenu(c *router.Context) error {
 	text := "🔧 *Админ-панель*\n\n"
 	text += "Выберите раздел для управления ботом:"
 
-	keyboard := h.adminKeyboard.CreateMainMenu()
-	return utils.SendMessageWithKeyboard(message.Chat.ID, text, keyboard, h.config.BotToken)
-}
-
-// showStats показывает статистику
-func (h *AdminHandler) showStats(message *tgbotapi.Message, _ *models.User) error {
-	// Получаем статистику (здесь нужно будет реализовать методы в сервисах)
-	text := "📊 *Статистика бота*\n\n"
-	text += "👥 Пользователи: 0\n"
-	text += "🔒 Активные подписки: 0\n"
-	text += "💰 Общая выручка: 0₽\n"
-	text += "📈 Выручка сегодня: 0₽\n"
-	text += "🎟️ Промокоды: 0\n"
-	text += "📢 Уведомления: 0"
-
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	keyboard := h.adminKeyboard.CreateMainMenu(c.Locale)
+	return h.send(c, text, keyboard)
 }
 
 // showUsers показывает список пользователей
-func (h *AdminHandler) showUsers(message *tgbotapi.Message, _ *models.User, searchQuery string) error {
+func (h *AdminHandler) showUsers(c *router.Context, searchQuery string) error {
 	text := "👥 *Список пользователей*\n\n"
 
 	if searchQuery != "" {
@@ -129,135 +149,170 @@ func (h *AdminHandler) showUsers(message *tgbotapi.Message, _ *models.User, sear
 		text += "Список пользователей будет здесь..."
 	}
 
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	return h.send(c, text, nil)
 }
 
 // showUser показывает информацию о пользователе
-func (h *AdminHandler) showUser(message *tgbotapi.Message, _ *models.User, userIDStr string) error {
+func (h *AdminHandler) showUser(c *router.Context, userIDStr string) error {
 	if userIDStr == "" {
-		return utils.SendMessage(message.Chat.ID, "❌ Укажите ID пользователя", h.config.BotToken)
+		return h.send(c, "❌ Укажите ID пользователя", nil)
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Неверный формат ID пользователя", h.config.BotToken)
+		return h.send(c, "❌ Неверный формат ID пользователя", nil)
 	}
 
 	targetUser, err := h.userService.GetUser(userID)
-	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Пользователь не найден", h.config.BotToken)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", nil)
 	}
 
-	text := "👤 *Информация о пользователе*\n\n"
-	text += fmt.Sprintf("🆔 ID: %d\n", targetUser.TelegramID)
-	text += fmt.Sprintf("👤 Имя: %s\n", targetUser.GetFullName())
-	text += fmt.Sprintf("📱 Username: @%s\n", targetUser.Username)
-	text += fmt.Sprintf("🌐 Язык: %s\n", targetUser.LanguageCode)
-	text += fmt.Sprintf("💰 Баланс: %.2f₽\n", targetUser.Balance)
-	text += fmt.Sprintf("🔗 Реферальный код: %s\n", targetUser.ReferralCode)
-	text += fmt.Sprintf("🚫 Заблокирован: %t\n", targetUser.IsBlocked)
-	text += fmt.Sprintf("👑 Админ: %t\n", targetUser.IsAdmin)
-	text += fmt.Sprintf("📅 Регистрация: %s\n", targetUser.CreatedAt.Format("02.01.2006 15:04"))
-
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	return h.showUserCard(c, targetUser, "")
 }
 
-// blockUser блокирует пользователя
-func (h *AdminHandler) blockUser(message *tgbotapi.Message, user *models.User, userIDStr string) error {
+// blockUser блокирует пользователя. args — <id> [причина]
+func (h *AdminHandler) blockUser(c *router.Context, args string) error {
+	return h.setUserBlocked(c, args, true)
+}
+
+// unblockUser разблокирует пользователя. args — <id> [причина]
+func (h *AdminHandler) unblockUser(c *router.Context, args string) error {
+	return h.setUserBlocked(c, args, false)
+}
+
+// setUserBlocked меняет блокировку пользователя по аргументам команды
+func (h *AdminHandler) setUserBlocked(c *router.Context, args string, blocked bool) error {
+	userIDStr, reason, _ := strings.Cut(strings.TrimSpace(args), " ")
 	if userIDStr == "" {
-		return utils.SendMessage(message.Chat.ID, "❌ Укажите ID пользователя", h.config.BotToken)
+		return h.send(c, "❌ Укажите ID пользователя", nil)
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Неверный формат ID пользователя", h.config.BotToken)
+		return h.send(c, "❌ Неверный формат ID пользователя", nil)
 	}
 
-	if err := h.userService.BlockUser(userID); err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Ошибка при блокировке пользователя", h.config.BotToken)
+	targetUser, err := h.userService.GetUser(userID)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", nil)
 	}
 
-	// Логируем действие
-	h.activityLogService.LogActivity(user.ID, "admin_action", map[string]interface{}{
-		"action":         "block_user",
-		"target_user_id": userID,
-	}, "", "")
-
-	return utils.SendMessage(message.Chat.ID, "✅ Пользователь заблокирован", h.config.BotToken)
+	text, _ := h.applyBlock(c, targetUser, blocked, strings.TrimSpace(reason))
+	return h.send(c, text, nil)
 }
 
-// unblockUser разблокирует пользователя
-func (h *AdminHandler) unblockUser(message *tgbotapi.Message, user *models.User, userIDStr string) error {
-	if userIDStr == "" {
-		return utils.SendMessage(message.Chat.ID, "❌ Укажите ID пользователя", h.config.BotToken)
-	}
+// applyBlock блокирует или разблокирует пользователя и записывает действие в аудит.
+// Возвращает текст результата и признак успеха
+func (h *AdminHandler) applyBlock(c *router.Context, targetUser *models.User, blocked bool, reason string) (string, bool) {
+	wasBlocked := targetUser.IsBlocked
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Неверный формат ID пользователя", h.config.BotToken)
+	var err error
+	action, auditAction := "block_user", models.AuditUserBlock
+	if blocked {
+		err = h.userService.BlockUser(targetUser.TelegramID)
+	} else {
+		action, auditAction = "unblock_user", models.AuditUserUnblock
+		err = h.userService.UnblockUser(targetUser.TelegramID)
 	}
-
-	if err := h.userService.UnblockUser(userID); err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Ошибка при разблокировке пользователя", h.config.BotToken)
+	if err != nil {
+		if blocked {
+			return "❌ Ошибка при блокировке пользователя", false
+		}
+		return "❌ Ошибка при разблокировке пользователя", false
 	}
+	targetUser.IsBlocked = blocked
 
 	// Логируем действие
-	h.activityLogService.LogActivity(user.ID, "admin_action", map[string]interface{}{
-		"action":         "unblock_user",
-		"target_user_id": userID,
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
+		"action":         action,
+		"target_user_id": targetUser.TelegramID,
 	}, "", "")
-
-	return utils.SendMessage(message.Chat.ID, "✅ Пользователь разблокирован", h.config.BotToken)
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     auditAction,
+		TargetUser: targetUser,
+		Before:     map[string]bool{"is_blocked": wasBlocked},
+		After:      map[string]bool{"is_blocked": blocked},
+		Reason:     reason,
+	})
+
+	if blocked {
+		return "✅ Пользователь заблокирован", true
+	}
+	return "✅ Пользователь разблокирован", true
 }
 
-// manageBalance управляет балансом пользователя
-func (h *AdminHandler) manageBalance(message *tgbotapi.Message, user *models.User, args string) error {
+// manageBalance управляет балансом пользователя. args — <id> <сумма> [причина]
+func (h *AdminHandler) manageBalance(c *router.Context, args string) error {
 	parts := strings.Fields(args)
 	if len(parts) < 2 {
-		return utils.SendMessage(message.Chat.ID, "❌ Использование: /admin balance <id> <сумма>", h.config.BotToken)
+		return h.send(c, "❌ Использование: /admin balance <id> <сумма> [причина]", nil)
 	}
 
 	userID, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Неверный формат ID пользователя", h.config.BotToken)
+		return h.send(c, "❌ Неверный формат ID пользователя", nil)
 	}
 
 	amount, err := strconv.ParseFloat(parts[1], 64)
 	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Неверный формат суммы", h.config.BotToken)
+		return h.send(c, "❌ Неверный формат суммы", nil)
 	}
 
 	targetUser, err := h.userService.GetUser(userID)
-	if err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Пользователь не найден", h.config.BotToken)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", nil)
+	}
+
+	text, _ := h.changeBalance(c, targetUser, amount, strings.Join(parts[2:], " "))
+	return h.send(c, text, nil)
+}
+
+// changeBalance пополняет баланс на amount или списывает при отрицательной сумме
+// и записывает действие в аудит. Баланс меняется условным обновлением в базе,
+// поэтому списание не уводит его в минус при одновременном зачислении платежа.
+// Возвращает текст результата и признак успеха
+func (h *AdminHandler) changeBalance(c *router.Context, targetUser *models.User, amount float64, reason string) (string, bool) {
+	updated, err := h.userService.ApplyChanges(targetUser.ID, repositories.UserChanges{BalanceDelta: amount})
+	switch {
+	case errors.Is(err, services.ErrUserInsufficientBalance):
+		return "❌ Недостаточно средств на балансе пользователя", false
+	case errors.Is(err, services.ErrUserNotFound):
+		return "❌ Пользователь не найден", false
+	case err != nil:
+		if amount > 0 {
+			return "❌ Ошибка при пополнении баланса", false
+		}
+		return "❌ Ошибка при списании с баланса", false
 	}
 
 	var text string
 	if amount > 0 {
-		if err := h.userService.AddBalance(targetUser.ID, amount); err != nil {
-			return utils.SendMessage(message.Chat.ID, "❌ Ошибка при пополнении баланса", h.config.BotToken)
-		}
 		text = fmt.Sprintf("✅ Баланс пользователя пополнен на %.2f₽", amount)
 	} else {
-		amount = -amount // Делаем положительным для вычитания
-		if err := h.userService.SubtractBalance(targetUser.ID, amount); err != nil {
-			return utils.SendMessage(message.Chat.ID, "❌ Ошибка при списании с баланса", h.config.BotToken)
-		}
-		text = fmt.Sprintf("✅ С баланса пользователя списано %.2f₽", amount)
+		text = fmt.Sprintf("✅ С баланса пользователя списано %.2f₽", -amount)
 	}
+	targetUser.Balance = updated.Balance
 
 	// Логируем действие
-	h.activityLogService.LogActivity(user.ID, "admin_action", map[string]interface{}{
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
 		"action":         "manage_balance",
-		"target_user_id": userID,
-		"amount":         amount,
+		"target_user_id": targetUser.TelegramID,
+		"amount":         math.Abs(amount),
 	}, "", "")
-
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditBalanceChange,
+		TargetUser: targetUser,
+		Before:     map[string]float64{"balance": updated.Balance - amount},
+		After:      map[string]float64{"balance": updated.Balance, "amount": amount},
+		Reason:     reason,
+	})
+
+	return text, true
 }
 
 // managePromoCodes управляет промокодами
-func (h *AdminHandler) managePromoCodes(message *tgbotapi.Message, _ *models.User, _ string) error {
+func (h *AdminHandler) managePromoCodes(c *router.Context, _ string) error {
 	text := "🎟️ *Управление промокодами*\n\n"
 	text += "Доступные команды:\n"
 	text += "• `/admin promo create <код> <тип> <значение> <макс_использований>` - Создать промокод\n"
@@ -268,31 +323,37 @@ func (h *AdminHandler) managePromoCodes(message *tgbotapi.Message, _ *models.Use
 	text += "• `discount_percent` - Скидка в процентах\n"
 	text += "• `discount_amount` - Скидка в рублях"
 
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	return h.send(c, text, nil)
 }
 
 // sendNotification отправляет уведомление
-func (h *AdminHandler) sendNotification(message *tgbotapi.Message, user *models.User, notificationText string) error {
+func (h *AdminHandler) sendNotification(c *router.Context, notificationText string) error {
 	if notificationText == "" {
-		return utils.SendMessage(message.Chat.ID, "❌ Укажите текст уведомления", h.config.BotToken)
+		return h.send(c, "❌ Укажите текст уведомления", nil)
 	}
 
 	// Отправляем уведомление всем пользователям
-	if err := h.notificationService.SendBulkNotification("admin_message", "Сообщение от администратора", notificationText, h.config.BotToken); err != nil {
-		return utils.SendMessage(message.Chat.ID, "❌ Ошибка при отправке уведомления", h.config.BotToken)
+	if err := h.notificationService.SendBulkNotification("admin_message", "Сообщение от администратора", notificationText); err != nil {
+		return h.send(c, "❌ Ошибка при отправке уведомления", nil)
 	}
 
 	// Логируем действие
-	h.activityLogService.LogActivity(user.ID, "admin_action", map[string]interface{}{
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
 		"action":  "send_notification",
 		"message": notificationText,
 	}, "", "")
-
-	return utils.SendMessage(message.Chat.ID, "✅ Уведомление отправлено всем пользователям", h.config.BotToken)
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditBroadcast,
+		TargetType: "broadcast",
+		TargetID:   "all",
+		After:      map[string]string{"message": notificationText},
+	})
+
+	return h.send(c, "✅ Уведомление отправлено всем пользователям", nil)
 }
 
 // showLogs показывает логи активности
-func (h *AdminHandler) showLogs(message *tgbotapi.Message, _ *models.User, userIDStr string) error {
+func (h *AdminHandler) showLogs(c *router.Context, userIDStr string) error {
 	text := "📋 *Логи активности*\n\n"
 
 	if userIDStr != "" {
@@ -305,23 +366,23 @@ func (h *AdminHandler) showLogs(message *tgbotapi.Message, _ *models.User, userI
 		text += "Последние логи будут здесь..."
 	}
 
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+	return h.send(c, text, nil)
 }
 
 // showAdminHelp показывает справку по админским командам
-func (h *AdminHandler) showAdminHelp(message *tgbotapi.Message, _ *models.User) error {
+func (h *AdminHandler) showAdminHelp(c *router.Context) error {
 	text := "❓ *Справка по админским командам*\n\n"
 	text += "🔧 *Основные команды:*\n"
 	text += "`/admin` - Главное меню админ-панели\n"
-	text += "`/admin stats` - Статистика бота\n\n"
+	text += "`/admin stats [day|week|month|year]` - Статистика бота\n\n"
 	text += "👥 *Управление пользователями:*\n"
 	text += "`/admin users` - Список всех пользователей\n"
 	text += "`/admin users <поиск>` - Поиск пользователей\n"
 	text += "`/admin user <id>` - Информация о пользователе\n"
-	text += "`/admin block <id>` - Заблокировать пользователя\n"
-	text += "`/admin unblock <id>` - Разблокировать пользователя\n\n"
+	text += "`/admin block <id> [причина]` - Заблокировать пользователя\n"
+	text += "`/admin unblock <id> [причина]` - Разблокировать пользователя\n\n"
 	text += "💰 *Управление балансом:*\n"
-	text += "`/admin balance <id> <сумма>` - Изменить баланс\n"
+	text += "`/admin balance <id> <сумма> [причина]` - Изменить баланс\n"
 	text += "Положительная сумма - пополнение, отрицательная - списание\n\n"
 	text += "🎟️ *Промокоды:*\n"
 	text += "`/admin promo` - Управление промокодами\n\n"
@@ -329,9 +390,21 @@ func (h *AdminHandler) showAdminHelp(message *tgbotapi.Message, _ *models.User)
 	text += "`/admin notify <сообщение>` - Отправить всем\n\n"
 	text += "📋 *Логи:*\n"
 	text += "`/admin logs` - Все логи\n"
-	text += "`/admin logs <id>` - Логи пользователя"
+	text += "`/admin logs <id>` - Логи пользователя\n"
+	text += "`/admin audit <id>` - Действия администраторов с пользователем\n\n"
+	text += "📤 *Выгрузки:*\n"
+	text += "`/admin export <набор> [csv|xlsx] [период] [status=..] [method=..]` - Выгрузить данные"
+
+	return h.send(c, text, nil)
+}
 
-	return utils.SendMessage(message.Chat.ID, text, h.config.BotToken)
+// send показывает ответ администратору, редактируя сообщение при навигации кнопками
+func (h *AdminHandler) send(c *router.Context, text string, keyboard *telego.InlineKeyboardMarkup) error {
+	// При редактировании меню оставляем путь назад
+	if keyboard == nil && c.Callback != nil {
+		keyboard = h.adminKeyboard.CreateBackMenu(c.Locale)
+	}
+	return c.Respond(text, telegram.Plain(keyboard))
 }
 
 // GetAdminKeyboard возвращает клавиатуру админ-панели
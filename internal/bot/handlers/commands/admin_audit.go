@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/mymmrac/telego"
+)
+
+// auditPageSize количество записей аудита на странице
+const auditPageSize = 10
+
+// auditFilterAll фильтр без ограничения по действиям
+const auditFilterAll = "all"
+
+// auditFilters фильтры журнала аудита по группам действий в порядке отображения
+var auditFilters = []struct {
+	key     string
+	title   string
+	actions []string
+}{
+	{auditFilterAll, "Все", nil},
+	{"balance", "💰 Баланс", []string{models.AuditBalanceChange, models.AuditPaymentCreate}},
+	{"block", "🚫 Блокировки", []string{models.AuditUserBlock, models.AuditUserUnblock}},
+	{"users", "✏️ Профили", []string{models.AuditUserUpdate}},
+	{"broadcast", "📢 Сообщения", []string{models.AuditBroadcast, models.AuditUserMessage}},
+	{"roles", "👮 Роли", []string{models.AuditRoleChange}},
+	{"settings", "⚙️ Настройки", []string{models.AuditSettingChange, models.AuditMaintenanceToggle}},
+	{"withdrawals", "💸 Выводы", []string{models.AuditWithdrawalApprove, models.AuditWithdrawalReject, models.AuditWithdrawalPaid}},
+	{"promo", "🎟️ Промокоды", []string{models.AuditPromoCreate, models.AuditPromoBatch, models.AuditPromoUpdate, models.AuditPromoDelete}},
+	{"subscriptions", "⏱ Подписки", []string{
+		models.AuditSubscriptionDays, models.AuditTariffGrant, models.AuditTrialReset,
+		models.AuditSubscriptionCreate, models.AuditSubscriptionCancel,
+	}},
+	{"exports", "📤 Выгрузки", []string{models.AuditDataExport}},
+}
+
+// RecordAudit записывает действие текущего администратора в журнал аудита
+func (h *AdminHandler) RecordAudit(c *router.Context, entry services.AuditEntry) {
+	entry.Actor = c.User
+	// Ошибка уже залогирована сервисом, действие администратора не откатываем
+	_ = h.auditService.Record(entry)
+}
+
+// ShowAudit показывает журнал аудита. data — <фильтр>:<страница>
+func (h *AdminHandler) ShowAudit(c *router.Context, data string) error {
+	filterKey, pageStr, _ := strings.Cut(data, ":")
+	page, _ := strconv.Atoi(pageStr)
+	if page < 0 {
+		page = 0
+	}
+
+	var filter repositories.AdminAuditFilter
+	found := false
+	for _, f := range auditFilters {
+		if f.key == filterKey {
+			filter.Actions = f.actions
+			found = true
+		}
+	}
+	if !found {
+		filterKey = auditFilterAll
+	}
+
+	entries, total, err := h.auditService.List(filter, auditPageSize, page*auditPageSize)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении журнала аудита", h.auditBackMenu())
+	}
+
+	text := "🛡 Журнал действий администраторов\n\n"
+	text += auditEntriesText(entries, total)
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	var row []telego.InlineKeyboardButton
+	for _, f := range auditFilters {
+		label := f.title
+		if f.key == filterKey {
+			label = "✅ " + label
+		}
+		row = append(row, telego.InlineKeyboardButton{Text: label, CallbackData: fmt.Sprintf("admin:audit:%s:0", f.key)})
+		if len(row) == 2 {
+			keyboardRows = append(keyboardRows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboardRows = append(keyboardRows, row)
+	}
+
+	var navigation []telego.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, telego.InlineKeyboardButton{Text: "◀️", CallbackData: fmt.Sprintf("admin:audit:%s:%d", filterKey, page-1)})
+	}
+	if int64((page+1)*auditPageSize) < total {
+		navigation = append(navigation, telego.InlineKeyboardButton{Text: "▶️", CallbackData: fmt.Sprintf("admin:audit:%s:%d", filterKey, page+1)})
+	}
+	if len(navigation) > 0 {
+		keyboardRows = append(keyboardRows, navigation)
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:logs"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// showUserAudit показывает действия администраторов, выполненные пользователем или над ним
+func (h *AdminHandler) showUserAudit(c *router.Context, userIDStr string) error {
+	if userIDStr == "" {
+		return h.send(c, "❌ Укажите ID пользователя", nil)
+	}
+
+	telegramID, err := strconv.ParseInt(strings.TrimSpace(userIDStr), 10, 64)
+	if err != nil {
+		return h.send(c, "❌ Неверный формат ID пользователя", nil)
+	}
+
+	targetUser, err := h.userService.GetUser(telegramID)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", nil)
+	}
+
+	entries, total, err := h.auditService.List(repositories.AdminAuditFilter{UserID: &targetUser.ID}, auditPageSize, 0)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении журнала аудита", nil)
+	}
+
+	text := fmt.Sprintf("🛡 Аудит: %s (%d)\n\n", targetUser.GetFullName(), targetUser.TelegramID)
+	text += auditEntriesText(entries, total)
+
+	return h.send(c, text, nil)
+}
+
+// auditEntriesText форматирует страницу журнала аудита
+func auditEntriesText(entries []models.AdminAuditLog, total int64) string {
+	if len(entries) == 0 {
+		return "Записей нет."
+	}
+
+	text := fmt.Sprintf("Всего записей: %d\n\n", total)
+	for _, entry := range entries {
+		text += fmt.Sprintf("🕒 %s %s\n", entry.CreatedAt.Format("02.01.2006 15:04"), models.GetAuditActionText(entry.Action))
+		text += fmt.Sprintf("👮 %s", entry.ActorTitle())
+		if entry.TargetUser != nil {
+			text += fmt.Sprintf(" → 👤 %s (%d)", entry.TargetUser.GetFullName(), entry.TargetUser.TelegramID)
+		} else if entry.TargetID != "" {
+			text += fmt.Sprintf(" → %s", entry.TargetID)
+		}
+		text += "\n"
+		if entry.Before != "" || entry.After != "" {
+			text += fmt.Sprintf("📌 %s → %s\n", auditValueText(entry.Before), auditValueText(entry.After))
+		}
+		if entry.Reason != "" {
+			text += fmt.Sprintf("💬 %s\n", entry.Reason)
+		}
+		text += "\n"
+	}
+
+	return text
+}
+
+// auditValueText сокращает значение до или после изменения для списка
+func auditValueText(value string) string {
+	if value == "" {
+		return "—"
+	}
+	if runes := []rune(value); len(runes) > 80 {
+		return string(runes[:80]) + "…"
+	}
+	return value
+}
+
+// auditBackMenu клавиатура возврата к журналу аудита
+func (h *AdminHandler) auditBackMenu() *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "🔙 Назад", CallbackData: "admin:logs"}},
+	}}
+}
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/mymmrac/telego"
+)
+
+// Шаги диалогов админ-панели
+const (
+	StepFindUser           = "admin:find_user"
+	StepBalanceAddUser     = "admin:balance_add:user"
+	StepBalanceAddAmount   = "admin:balance_add:amount"
+	StepPromoCreateCode    = "admin:promo_create:code"
+	StepPromoCreateType    = "admin:promo_create:type"
+	StepPromoCreateValue   = "admin:promo_create:value"
+	StepPromoCreateMaxUses = "admin:promo_create:max_uses"
+)
+
+// promoTypes типы промокодов, доступные при создании из бота
+var promoTypes = []string{"bonus_days", "discount_percent", "discount_amount"}
+
+// StartFindUser начинает поиск пользователя по ID или username
+func (h *AdminHandler) StartFindUser(c *router.Context) error {
+	if err := c.StartStep(StepFindUser); err != nil {
+		return err
+	}
+
+	text := "🔍 Поиск пользователя\n\n"
+	text += "Отправьте Telegram ID или username пользователя.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:users"))
+}
+
+// HandleFindUserStep ищет пользователя по введенному ID или username
+func (h *AdminHandler) HandleFindUserStep(c *router.Context) error {
+	if !h.can(c, models.PermUsersRead) {
+		return c.FinishStep()
+	}
+
+	query := strings.TrimPrefix(strings.TrimSpace(c.Text()), "@")
+	if query == "" {
+		return h.send(c, "❌ Отправьте ID или username пользователя", nil)
+	}
+
+	if telegramID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		targetUser, err := h.userService.GetUser(telegramID)
+		if err != nil || targetUser == nil {
+			return h.send(c, "❌ Пользователь не найден. Попробуйте еще раз или /cancel", nil)
+		}
+		if err := c.FinishStep(); err != nil {
+			return err
+		}
+		return h.showUserCard(c, targetUser, "")
+	}
+
+	users, err := h.userService.SearchUsers(query, 10)
+	if err != nil || len(users) == 0 {
+		return h.send(c, "❌ Пользователи не найдены. Попробуйте еще раз или /cancel", nil)
+	}
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	if len(users) == 1 {
+		return h.showUserCard(c, &users[0], "")
+	}
+
+	text := fmt.Sprintf("🔍 Найдено пользователей: %d\n\n", len(users))
+	text += "Выберите пользователя, чтобы открыть карточку."
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, user := range users {
+		label := fmt.Sprintf("%d — %s", user.TelegramID, user.GetFullName())
+		if user.Username != "" {
+			label += fmt.Sprintf(" (@%s)", user.Username)
+		}
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: fmt.Sprintf("admin:user_info:%d", user.TelegramID)},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:users"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// StartBalanceAdd начинает пополнение баланса пользователя
+func (h *AdminHandler) StartBalanceAdd(c *router.Context) error {
+	if err := c.StartStep(StepBalanceAddUser); err != nil {
+		return err
+	}
+
+	text := "➕ Пополнение баланса\n\n"
+	text += "Отправьте Telegram ID пользователя.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:balance"))
+}
+
+// HandleBalanceAddUserStep запоминает пользователя и запрашивает сумму
+func (h *AdminHandler) HandleBalanceAddUserStep(c *router.Context) error {
+	if !h.can(c, models.PermBalanceWrite) {
+		return c.FinishStep()
+	}
+
+	telegramID, err := strconv.ParseInt(strings.TrimSpace(c.Text()), 10, 64)
+	if err != nil {
+		return h.send(c, "❌ Неверный формат ID пользователя. Попробуйте еще раз или /cancel", nil)
+	}
+
+	targetUser, err := h.userService.GetUser(telegramID)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден. Попробуйте еще раз или /cancel", nil)
+	}
+
+	c.Session.Set("telegram_id", strconv.FormatInt(telegramID, 10))
+	if err := c.NextStep(StepBalanceAddAmount); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("👤 %s\n", targetUser.GetFullName())
+	text += fmt.Sprintf("💰 Текущий баланс: %.2f₽\n\n", targetUser.Balance)
+	text += "Отправьте сумму пополнения в рублях."
+
+	return h.send(c, text, nil)
+}
+
+// HandleBalanceAddAmountStep пополняет баланс на введенную сумму
+func (h *AdminHandler) HandleBalanceAddAmountStep(c *router.Context) error {
+	if !h.can(c, models.PermBalanceWrite) {
+		return c.FinishStep()
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(c.Text()), ",", "."), 64)
+	if err != nil || amount <= 0 {
+		return h.send(c, "❌ Введите положительную сумму. Попробуйте еще раз или /cancel", nil)
+	}
+
+	telegramID, err := strconv.ParseInt(c.Session.Get("telegram_id"), 10, 64)
+	if err != nil {
+		c.FinishStep()
+		return h.send(c, "❌ Диалог поврежден, начните заново", h.adminKeyboard.CreateBalanceMenu(c.Locale))
+	}
+
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	targetUser, err := h.userService.GetUser(telegramID)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateBalanceMenu(c.Locale))
+	}
+
+	if text, ok := h.changeBalance(c, targetUser, amount, ""); !ok {
+		return h.send(c, text, h.adminKeyboard.CreateBalanceMenu(c.Locale))
+	}
+
+	text := fmt.Sprintf("✅ Баланс пользователя %d пополнен на %.2f₽", telegramID, amount)
+	return h.send(c, text, h.adminKeyboard.CreateBalanceMenu(c.Locale))
+}
+
+// StartPromoCreate начинает создание промокода
+func (h *AdminHandler) StartPromoCreate(c *router.Context) error {
+	if err := c.StartStep(StepPromoCreateCode); err != nil {
+		return err
+	}
+
+	text := "➕ Создание промокода\n\n"
+	text += "Шаг 1/4. Отправьте код промокода, например SUMMER2024.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:promo"))
+}
+
+// HandlePromoCreateCodeStep запоминает код и предлагает выбрать тип
+func (h *AdminHandler) HandlePromoCreateCodeStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(c.Text()))
+	if len(code) < 3 || len(code) > 50 || strings.ContainsAny(code, " \t\n") {
+		return h.send(c, "❌ Код должен быть от 3 до 50 символов без пробелов. Попробуйте еще раз или /cancel", nil)
+	}
+
+	if existing, _ := h.promoCodeService.GetPromoCode(code); existing != nil {
+		return h.send(c, "❌ Промокод с таким кодом уже существует. Введите другой код или /cancel", nil)
+	}
+
+	c.Session.Set("code", code)
+	if err := c.NextStep(StepPromoCreateType); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("🎟️ Код: %s\n\n", code)
+	text += "Шаг 2/4. Выберите тип промокода:"
+
+	return h.send(c, text, h.promoTypeKeyboard(c))
+}
+
+// HandlePromoCreateType обрабатывает выбор типа промокода кнопкой
+func (h *AdminHandler) HandlePromoCreateType(c *router.Context, promoType string) error {
+	if c.Session == nil || c.Session.Step != StepPromoCreateType || !isPromoType(promoType) {
+		return h.send(c, "❌ Диалог создания промокода истек, начните заново", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	c.Session.Set("type", promoType)
+	if err := c.NextStep(StepPromoCreateValue); err != nil {
+		return err
+	}
+
+	promo := models.PromoCode{Code: c.Session.Get("code"), Type: promoType}
+	text := fmt.Sprintf("🎟️ Код: %s\n", promo.Code)
+	text += fmt.Sprintf("📝 Тип: %s\n\n", promo.GetTypeText(c.Locale))
+	text += "Шаг 3/4. Отправьте значение промокода."
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:promo"))
+}
+
+// HandlePromoCreateTypeStep принимает тип промокода, введенный текстом
+func (h *AdminHandler) HandlePromoCreateTypeStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	promoType := strings.TrimSpace(c.Text())
+	if !isPromoType(promoType) {
+		return h.send(c, "❌ Выберите тип промокода кнопкой", h.promoTypeKeyboard(c))
+	}
+
+	return h.HandlePromoCreateType(c, promoType)
+}
+
+// HandlePromoCreateValueStep запоминает значение промокода
+func (h *AdminHandler) HandlePromoCreateValueStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(c.Text()), ",", "."), 64)
+	if err != nil || value <= 0 {
+		return h.send(c, "❌ Введите положительное число. Попробуйте еще раз или /cancel", nil)
+	}
+	if c.Session.Get("type") == "discount_percent" && value > 100 {
+		return h.send(c, "❌ Скидка не может превышать 100%. Попробуйте еще раз или /cancel", nil)
+	}
+
+	c.Session.Set("value", strconv.FormatFloat(value, 'f', -1, 64))
+	if err := c.NextStep(StepPromoCreateMaxUses); err != nil {
+		return err
+	}
+
+	text := "Шаг 4/4. Отправьте максимальное количество использований.\n"
+	text += "0 — без ограничений."
+
+	return h.send(c, text, nil)
+}
+
+// HandlePromoCreateMaxUsesStep создает промокод из собранных данных
+func (h *AdminHandler) HandlePromoCreateMaxUsesStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	maxUses, err := strconv.Atoi(strings.TrimSpace(c.Text()))
+	if err != nil || maxUses < 0 {
+		return h.send(c, "❌ Введите целое число не меньше 0. Попробуйте еще раз или /cancel", nil)
+	}
+
+	code := c.Session.Get("code")
+	promoType := c.Session.Get("type")
+	value, err := strconv.ParseFloat(c.Session.Get("value"), 64)
+	if err != nil {
+		c.FinishStep()
+		return h.send(c, "❌ Диалог поврежден, начните заново", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	promoCode, err := h.promoCodeService.CreatePromoCode(code, promoType, value, maxUses, nil, nil, "", c.User.ID)
+	if err != nil {
+		return h.send(c, fmt.Sprintf("❌ Ошибка при создании промокода: %s", err.Error()), h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	// Логируем действие
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
+		"action":        "create_promo_code",
+		"promo_code_id": promoCode.ID,
+		"code":          promoCode.Code,
+	}, "", "")
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditPromoCreate,
+		TargetType: "promo_code",
+		TargetID:   promoCode.Code,
+		After: map[string]interface{}{
+			"type":     promoCode.Type,
+			"value":    promoCode.Value,
+			"max_uses": promoCode.MaxUses,
+		},
+	})
+
+	text := "✅ Промокод создан\n\n"
+	text += fmt.Sprintf("🎟️ Код: %s\n", promoCode.Code)
+	text += fmt.Sprintf("📝 Тип: %s\n", promoCode.GetTypeText(c.Locale))
+	text += fmt.Sprintf("💎 Значение: %.2f\n", promoCode.Value)
+	if promoCode.MaxUses > 0 {
+		text += fmt.Sprintf("🔢 Использований: %d\n", promoCode.MaxUses)
+	} else {
+		text += "🔢 Использований: без ограничений\n"
+	}
+
+	keyboard := h.adminKeyboard.CreatePromoCodeMenu(c.Locale)
+	keyboard.InlineKeyboard = append([][]telego.InlineKeyboardButton{
+		{{Text: "⚙️ Условия применения", CallbackData: "admin:prules:" + promoCode.ID.String()}},
+	}, keyboard.InlineKeyboard...)
+
+	return h.send(c, text, keyboard)
+}
+
+// promoTypeKeyboard создает клавиатуру выбора типа промокода
+func (h *AdminHandler) promoTypeKeyboard(c *router.Context) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, promoType := range promoTypes {
+		promo := models.PromoCode{Type: promoType}
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: promo.GetTypeText(c.Locale), CallbackData: "admin:promo_type:" + promoType},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "❌ Отмена", CallbackData: "admin:promo"},
+	})
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
+}
+
+// isPromoType проверяет, что тип промокода поддерживается
+func isPromoType(promoType string) bool {
+	for _, t := range promoTypes {
+		if t == promoType {
+			return true
+		}
+	}
+	return false
+}
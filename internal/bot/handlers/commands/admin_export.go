@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/export"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// exportPresetPrevMonth готовый фильтр кнопок: оплаченные платежи за прошлый месяц
+const exportPresetPrevMonth = "prev_month"
+
+// exportDatasetTitles названия наборов данных в меню и подписях к файлам
+var exportDatasetTitles = map[string]string{
+	services.ExportUsers:         "👥 Пользователи",
+	services.ExportPayments:      "💳 Платежи",
+	services.ExportSubscriptions: "🔒 Подписки",
+	services.ExportPromoUsages:   "🎟️ Использования промокодов",
+}
+
+// exportUsage подсказка по команде выгрузки
+const exportUsage = "Использование: /admin export <users|payments|subscriptions|promo> [csv|xlsx] " +
+	"[ГГГГ-ММ | ГГГГ-ММ-ДД [ГГГГ-ММ-ДД]] [status=<статус>] [method=<способ>]\n\n" +
+	"Пример: /admin export payments xlsx 2026-03 status=completed method=stars"
+
+// ShowExportMenu показывает меню выгрузок данных
+func (h *AdminHandler) ShowExportMenu(c *router.Context) error {
+	text := "📤 Выгрузки данных\n\n"
+	text += "Выберите набор данных и формат. Файл до лимита Telegram придет документом, " +
+		"более крупный — ссылкой на скачивание.\n\n"
+	text += "Фильтры по периоду, статусу и способу оплаты доступны командой:\n" + exportUsage
+
+	row := func(title, dataset, preset string) []telego.InlineKeyboardButton {
+		suffix := ""
+		if preset != "" {
+			suffix = ":" + preset
+		}
+		return []telego.InlineKeyboardButton{
+			{Text: title + " CSV", CallbackData: "admin:export_run:" + dataset + ":" + export.FormatCSV + suffix},
+			{Text: title + " XLSX", CallbackData: "admin:export_run:" + dataset + ":" + export.FormatXLSX + suffix},
+		}
+	}
+
+	keyboard := &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		row(exportDatasetTitles[services.ExportUsers], services.ExportUsers, ""),
+		row(exportDatasetTitles[services.ExportPayments], services.ExportPayments, ""),
+		row("💳 Оплаты за прошлый месяц", services.ExportPayments, exportPresetPrevMonth),
+		row(exportDatasetTitles[services.ExportSubscriptions], services.ExportSubscriptions, ""),
+		row("🎟️ Промокоды", services.ExportPromoUsages, ""),
+		{{Text: "🔙 Назад", CallbackData: "admin:main"}},
+	}}
+
+	return h.send(c, text, keyboard)
+}
+
+// RunExport запускает выгрузку с кнопки меню. Формат данных: <набор>:<формат>[:<фильтр>]
+func (h *AdminHandler) RunExport(c *router.Context, data string) error {
+	parts := strings.Split(data, ":")
+	if len(parts) < 2 {
+		return h.send(c, "❌ Некорректная выгрузка", nil)
+	}
+
+	request := services.ExportRequest{Dataset: parts[0], Format: parts[1]}
+	if len(parts) > 2 && parts[2] == exportPresetPrevMonth {
+		now := time.Now()
+		to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		from := to.AddDate(0, -1, 0)
+		request.Filter = repositories.ExportFilter{From: &from, To: &to, Status: "completed"}
+	}
+
+	return h.runExport(c, request)
+}
+
+// exportCommand обрабатывает /admin export с фильтрами
+func (h *AdminHandler) exportCommand(c *router.Context, args string) error {
+	request, err := parseExportArgs(strings.Fields(args), time.Local)
+	if err != nil {
+		return h.send(c, "❌ "+err.Error()+"\n\n"+exportUsage, nil)
+	}
+	return h.runExport(c, request)
+}
+
+// runExport формирует файл и отправляет его документом или ссылкой
+func (h *AdminHandler) runExport(c *router.Context, request services.ExportRequest) error {
+	if err := c.Send("⏳ Готовлю выгрузку…", nil); err != nil {
+		return err
+	}
+
+	result, err := h.exportService.Export(request)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownExportDataset):
+			return c.Send("❌ Неизвестный набор данных. Доступно: users, payments, subscriptions, promo", nil)
+		case errors.Is(err, export.ErrUnknownFormat):
+			return c.Send("❌ Неизвестный формат. Доступно: csv, xlsx", nil)
+		case errors.Is(err, services.ErrExportTooLarge):
+			return c.Send("❌ Файл слишком большой для отправки в Telegram. Задайте EXPORT_PUBLIC_URL, "+
+				"чтобы получать крупные выгрузки ссылкой, или сузьте период", nil)
+		default:
+			return c.Send("❌ Ошибка при формировании выгрузки", nil)
+		}
+	}
+
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditDataExport,
+		TargetType: "export",
+		TargetID:   request.Dataset,
+		After: map[string]interface{}{
+			"format": request.Format,
+			"filter": exportFilterText(request.Filter),
+			"rows":   result.Rows,
+		},
+	})
+
+	caption := fmt.Sprintf("%s: %d строк\n%s", exportDatasetTitles[request.Dataset], result.Rows, exportFilterText(request.Filter))
+
+	if result.URL != "" {
+		return c.Send(fmt.Sprintf("%s\nРазмер: %.1f МБ — больше лимита Telegram\n\n📥 Скачать: %s\nСсылка действует до %s",
+			caption, float64(result.Size)/(1<<20), result.URL, result.ExpiresAt.Format("02.01.2006 15:04")), nil)
+	}
+
+	defer h.exportService.Discard(result)
+	file, err := os.Open(result.Path)
+	if err != nil {
+		return c.Send("❌ Ошибка при отправке выгрузки", nil)
+	}
+	defer file.Close()
+
+	return c.SendDocument(telegram.FileFromDisk(result.FileName, file), caption, nil)
+}
+
+// parseExportArgs разбирает аргументы /admin export. Месяц ГГГГ-ММ задает
+// весь месяц, даты ГГГГ-ММ-ДД — период с первой по вторую включительно
+func parseExportArgs(args []string, loc *time.Location) (services.ExportRequest, error) {
+	if len(args) == 0 {
+		return services.ExportRequest{}, errors.New("не указан набор данных")
+	}
+
+	request := services.ExportRequest{Dataset: strings.ToLower(args[0]), Format: export.FormatCSV}
+	var dates []time.Time
+	for _, arg := range args[1:] {
+		lower := strings.ToLower(arg)
+		switch {
+		case export.IsFormat(lower):
+			request.Format = lower
+		case strings.HasPrefix(lower, "status="):
+			request.Filter.Status = strings.TrimPrefix(lower, "status=")
+		case strings.HasPrefix(lower, "method="):
+			request.Filter.Method = strings.TrimPrefix(lower, "method=")
+		default:
+			if month, err := time.ParseInLocation("2006-01", arg, loc); err == nil && len(dates) == 0 {
+				to := month.AddDate(0, 1, 0)
+				request.Filter.From, request.Filter.To = &month, &to
+				dates = append(dates, month, to)
+				continue
+			}
+			day, err := time.ParseInLocation("2006-01-02", arg, loc)
+			if err != nil || len(dates) >= 2 {
+				return services.ExportRequest{}, fmt.Errorf("непонятный аргумент %q", arg)
+			}
+			dates = append(dates, day)
+		}
+	}
+
+	if request.Filter.From == nil && len(dates) > 0 {
+		from := dates[0]
+		request.Filter.From = &from
+		if len(dates) == 2 {
+			// Конечная дата включается в период целиком
+			to := dates[1].AddDate(0, 0, 1)
+			if !to.After(from) {
+				return services.ExportRequest{}, errors.New("конец периода раньше начала")
+			}
+			request.Filter.To = &to
+		}
+	}
+
+	return request, nil
+}
+
+// exportFilterText описывает фильтр выгрузки для подписи и журнала
+func exportFilterText(filter repositories.ExportFilter) string {
+	var parts []string
+	switch {
+	case filter.From != nil && filter.To != nil:
+		parts = append(parts, fmt.Sprintf("период %s — %s",
+			filter.From.Format("02.01.2006"), filter.To.AddDate(0, 0, -1).Format("02.01.2006")))
+	case filter.From != nil:
+		parts = append(parts, "с "+filter.From.Format("02.01.2006"))
+	}
+	if filter.Status != "" {
+		parts = append(parts, "статус "+filter.Status)
+	}
+	if filter.Method != "" {
+		parts = append(parts, "способ "+models.PaymentMethodText(filter.Method))
+	}
+	if len(parts) == 0 {
+		return "Без фильтров"
+	}
+	return "Фильтр: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+)
+
+// commandPermissions права, необходимые для команд /admin <команда>
+var commandPermissions = map[string]string{
+	"stats":   models.PermStatsRead,
+	"users":   models.PermUsersRead,
+	"user":    models.PermUsersRead,
+	"block":   models.PermUserBlock,
+	"unblock": models.PermUserBlock,
+	"balance": models.PermBalanceWrite,
+	"promo":   models.PermPromoManage,
+	"notify":  models.PermBroadcastSend,
+	"logs":    models.PermLogsRead,
+	"audit":   models.PermLogsRead,
+	"export":  models.PermDataExport,
+}
+
+// callbackPermissions права, необходимые для callback'ов admin:<действие>.
+// Пустое право означает, что действие доступно любой роли
+var callbackPermissions = map[string]string{
+	"main":               "",
+	"stats":              models.PermStatsRead,
+	"users":              models.PermUsersRead,
+	"list_users":         models.PermUsersRead,
+	"user_stats":         models.PermStatsRead,
+	"find_user":          models.PermUsersRead,
+	"search_user_id":     models.PermUsersRead,
+	"search_username":    models.PermUsersRead,
+	"balance":            models.PermBalanceWrite,
+	"balance_add":        models.PermBalanceWrite,
+	"promo":              models.PermPromoManage,
+	"promo_create":       models.PermPromoManage,
+	"promo_batch":        models.PermPromoManage,
+	"promo_stats":        models.PermPromoManage,
+	"notify":             models.PermBroadcastSend,
+	"logs":               models.PermLogsRead,
+	"settings":           models.PermSettingsManage,
+	"maintenance_toggle": models.PermSettingsManage,
+	"withdrawals":        models.PermWithdrawalsManage,
+	"roles":              models.PermRolesManage,
+	"role_grant":         models.PermRolesManage,
+	"export":             models.PermDataExport,
+}
+
+// callbackPrefixPermissions права для callback'ов с параметром после префикса
+var callbackPrefixPermissions = []struct {
+	prefix     string
+	permission string
+}{
+	{"stats:", models.PermStatsRead},
+	{"stats_chart:", models.PermStatsRead},
+	{"export_run:", models.PermDataExport},
+	{"balance_", models.PermBalanceWrite},
+	{"logs_", models.PermLogsRead},
+	{"notify_", models.PermBroadcastSend},
+	{"promo_type:", models.PermPromoManage},
+	{"pcamp:", models.PermPromoManage},
+	{"pcsv:", models.PermPromoManage},
+	{"prules:", models.PermPromoManage},
+	{"prule:", models.PermPromoManage},
+	{"settings_", models.PermSettingsManage},
+	{"set:", models.PermSettingsManage},
+	{"set_", models.PermSettingsManage},
+	{"wd:", models.PermWithdrawalsManage},
+	{"wd_", models.PermWithdrawalsManage},
+	{"role_", models.PermRolesManage},
+	{"audit:", models.PermLogsRead},
+	{"user_info:", models.PermUsersRead},
+	{"user_subscriptions:", models.PermUsersRead},
+	{"user_payments:", models.PermUsersRead},
+	{"user_referrals:", models.PermUsersRead},
+	{"user_balance:", models.PermBalanceWrite},
+	{"toggle_block:", models.PermUserBlock},
+	{"usub_days:", models.PermSubscriptionsEdit},
+	{"user_tariff:", models.PermSubscriptionsEdit},
+	{"user_grant:", models.PermSubscriptionsEdit},
+	{"user_trial_reset:", models.PermSubscriptionsEdit},
+	{"user_message:", models.PermUserMessage},
+}
+
+// CallbackPermission возвращает право, необходимое для callback'а админ-панели.
+// Пустая строка означает, что достаточно любой роли; ok равен false для
+// действий без правила, которые запрещены всем ролям
+func CallbackPermission(action string) (permission string, ok bool) {
+	if permission, ok := callbackPermissions[action]; ok {
+		return permission, true
+	}
+	for _, p := range callbackPrefixPermissions {
+		if strings.HasPrefix(action, p.prefix) {
+			return p.permission, true
+		}
+	}
+	return "", false
+}
+
+// PermissionDeniedText сообщение об отсутствии права
+func PermissionDeniedText(permission string) string {
+	return "❌ Недостаточно прав: нужно право " + permission
+}
+
+// can проверяет право текущего пользователя
+func (h *AdminHandler) can(c *router.Context, permission string) bool {
+	return h.userService.HasPermission(c.User.TelegramID, permission)
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// StepPromoBatch шаг ввода параметров пакетной генерации промокодов
+const StepPromoBatch = "admin:promo_batch"
+
+// promoCampaignsPageSize количество кампаний в списке
+const promoCampaignsPageSize = 10
+
+// StartPromoBatch запрашивает параметры пакетной генерации
+func (h *AdminHandler) StartPromoBatch(c *router.Context) error {
+	if err := c.StartStep(StepPromoBatch); err != nil {
+		return err
+	}
+
+	text := "📦 Пакетная генерация промокодов\n\n"
+	text += "Отправьте параметры одной строкой:\n"
+	text += "<количество> <тип> <значение> [префикс] [до ГГГГ-ММ-ДД] [кампания]\n\n"
+	text += "Пример: 500 bonus_days 30 BLOGGER- 2026-12-31 blogger-dec\n"
+	text += "Пропустить параметр можно знаком «-».\n\n"
+	text += "Типы: bonus_days, discount_percent, discount_amount.\n"
+	text += "Коды одноразовые, файл CSV придет после генерации.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:promo"))
+}
+
+// HandlePromoBatchStep генерирует пакет промокодов и отправляет его файлом
+func (h *AdminHandler) HandlePromoBatchStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	batch, err := parsePromoBatch(c.Text())
+	if err != nil {
+		return h.send(c, fmt.Sprintf("❌ %s. Попробуйте еще раз или /cancel", err.Error()), nil)
+	}
+	batch.CreatedBy = c.User.ID
+
+	promoCodes, err := h.promoCodeService.GenerateBatch(batch)
+	if err != nil {
+		return h.send(c, fmt.Sprintf("❌ %s. Попробуйте еще раз или /cancel", err.Error()), nil)
+	}
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	campaignID := promoCodes[0].CampaignID
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
+		"action":      "generate_promo_batch",
+		"campaign_id": campaignID,
+		"count":       len(promoCodes),
+		"type":        batch.Type,
+		"value":       batch.Value,
+	}, "", "")
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditPromoBatch,
+		TargetType: "promo_campaign",
+		TargetID:   campaignID,
+		After: map[string]interface{}{
+			"count": len(promoCodes),
+			"type":  batch.Type,
+			"value": batch.Value,
+		},
+	})
+
+	if err := h.sendCampaignCSV(c, campaignID); err != nil {
+		return err
+	}
+
+	text := "✅ Промокоды сгенерированы\n\n"
+	text += fmt.Sprintf("🏷️ Кампания: %s\n", campaignID)
+	text += fmt.Sprintf("🔢 Кодов: %d\n", len(promoCodes))
+	text += fmt.Sprintf("📝 Тип: %s, значение: %s\n", promoCodes[0].GetTypeText(c.Locale), strconv.FormatFloat(batch.Value, 'f', -1, 64))
+	if batch.ValidUntil != nil {
+		text += fmt.Sprintf("📅 Действуют до: %s\n", batch.ValidUntil.Format("02.01.2006"))
+	}
+
+	return h.send(c, text, h.campaignMenu(campaignID))
+}
+
+// ShowPromoCampaigns показывает список кампаний промокодов
+func (h *AdminHandler) ShowPromoCampaigns(c *router.Context) error {
+	campaigns, err := h.promoCodeService.GetCampaigns(promoCampaignsPageSize, 0)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении кампаний", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	text := "📊 Кампании промокодов\n\n"
+	if len(campaigns) == 0 {
+		text += "Кампаний пока нет. Создайте пакет кнопкой «📦 Пакетная генерация»."
+	} else {
+		text += fmt.Sprintf("Последние %d кампаний:\n", len(campaigns))
+	}
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, campaign := range campaigns {
+		label := fmt.Sprintf("%s · %d/%d", campaign.CampaignID, campaign.RedeemedCodes, campaign.Codes)
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: "admin:pcamp:" + campaign.CampaignID},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 К промокодам", CallbackData: "admin:promo"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ShowPromoCampaign показывает статистику погашений кампании
+func (h *AdminHandler) ShowPromoCampaign(c *router.Context, campaignID string) error {
+	stats, err := h.promoCodeService.GetCampaignStats(campaignID)
+	if err != nil {
+		return h.sendCampaignError(c, err)
+	}
+
+	return h.send(c, campaignStatsText(stats), h.campaignMenu(campaignID))
+}
+
+// ExportPromoCampaign отправляет промокоды кампании файлом CSV
+func (h *AdminHandler) ExportPromoCampaign(c *router.Context, campaignID string) error {
+	if err := h.sendCampaignCSV(c, campaignID); err != nil {
+		return h.sendCampaignError(c, err)
+	}
+	return c.Answer("📄 Файл отправлен", false)
+}
+
+// sendCampaignCSV отправляет файл с промокодами кампании
+func (h *AdminHandler) sendCampaignCSV(c *router.Context, campaignID string) error {
+	data, err := h.promoCodeService.ExportCampaignCSV(campaignID)
+	if err != nil {
+		return err
+	}
+
+	document := telegram.FileFromBytes(campaignID+".csv", data)
+	return c.SendDocument(document, fmt.Sprintf("🎟️ Промокоды кампании %s", campaignID), nil)
+}
+
+// sendCampaignError сообщает об ошибке работы с кампанией
+func (h *AdminHandler) sendCampaignError(c *router.Context, err error) error {
+	if errors.Is(err, services.ErrPromoCampaignNotFound) {
+		return h.send(c, "❌ Кампания не найдена", h.campaignsBackMenu())
+	}
+	return h.send(c, "❌ Ошибка при получении кампании", h.campaignsBackMenu())
+}
+
+// campaignMenu создает клавиатуру кампании
+func (h *AdminHandler) campaignMenu(campaignID string) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{
+			{Text: "🔄 Обновить", CallbackData: "admin:pcamp:" + campaignID},
+			{Text: "📄 CSV", CallbackData: "admin:pcsv:" + campaignID},
+		},
+		{{Text: "🔙 К кампаниям", CallbackData: "admin:promo_stats"}},
+	}}
+}
+
+// campaignsBackMenu создает клавиатуру возврата к списку кампаний
+func (h *AdminHandler) campaignsBackMenu() *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "🔙 К кампаниям", CallbackData: "admin:promo_stats"}},
+	}}
+}
+
+// campaignStatsText форматирует статистику кампании
+func campaignStatsText(stats *models.PromoCampaignStats) string {
+	text := fmt.Sprintf("📊 Кампания %s\n\n", stats.CampaignID)
+	text += fmt.Sprintf("📅 Создана: %s\n", stats.CreatedAt.Format("02.01.2006 15:04"))
+	text += fmt.Sprintf("🔢 Кодов: %d\n", stats.Codes)
+	text += fmt.Sprintf("✅ Использовано кодов: %d (%.1f%%)\n", stats.RedeemedCodes, stats.GetRedemptionRate())
+	text += fmt.Sprintf("🔁 Всего использований: %d\n", stats.Uses)
+	text += fmt.Sprintf("👥 Пользователей: %d\n", stats.Users)
+	if stats.Discounts > 0 {
+		text += fmt.Sprintf("💸 Сумма скидок: %.2f₽\n", stats.Discounts)
+	}
+	return text
+}
+
+// parsePromoBatch разбирает строку параметров пакетной генерации
+func parsePromoBatch(input string) (services.PromoCodeBatch, error) {
+	fields := strings.Fields(input)
+	if len(fields) < 3 || len(fields) > 6 {
+		return services.PromoCodeBatch{}, fmt.Errorf("нужно от 3 до 6 параметров")
+	}
+
+	count, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return services.PromoCodeBatch{}, fmt.Errorf("количество должно быть целым числом")
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(fields[2], ",", "."), 64)
+	if err != nil {
+		return services.PromoCodeBatch{}, fmt.Errorf("значение должно быть числом")
+	}
+
+	batch := services.PromoCodeBatch{
+		Count:   count,
+		Type:    fields[1],
+		Value:   value,
+		MaxUses: 1,
+	}
+	optional := func(i int) string {
+		if i < len(fields) && fields[i] != "-" {
+			return fields[i]
+		}
+		return ""
+	}
+
+	batch.Prefix = optional(3)
+	if date := optional(4); date != "" {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return services.PromoCodeBatch{}, fmt.Errorf("дата должна быть в формате ГГГГ-ММ-ДД")
+		}
+		// Коды действуют до конца указанного дня
+		validUntil := day.AddDate(0, 0, 1)
+		batch.ValidUntil = &validUntil
+	}
+	batch.CampaignID = optional(5)
+
+	return batch, nil
+}
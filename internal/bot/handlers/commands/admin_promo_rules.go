@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/mymmrac/telego"
+)
+
+// StepPromoRulesMinAmount шаг ввода минимальной суммы покупки для промокода
+const StepPromoRulesMinAmount = "admin:promo_rules:min_amount"
+
+// promoRulePlans тарифы, которые можно выбрать в условиях промокода
+var promoRulePlans = []string{"basic", "premium", "pro"}
+
+// promoPerUserSteps значения лимита на пользователя, перебираемые кнопкой; 0 — без ограничений
+var promoPerUserSteps = []int{1, 2, 3, 5, 0}
+
+// ShowPromoRules показывает условия применения промокода
+func (h *AdminHandler) ShowPromoRules(c *router.Context, id string) error {
+	promoCode, err := h.getPromoCode(id)
+	if err != nil {
+		return h.send(c, "❌ Промокод не найден", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	return h.send(c, promoRulesText(promoCode), h.promoRulesMenu(promoCode))
+}
+
+// TogglePromoRule переключает условие промокода. Данные имеют вид <id>:<условие>
+func (h *AdminHandler) TogglePromoRule(c *router.Context, data string) error {
+	id, rule, _ := strings.Cut(data, ":")
+	promoCode, err := h.getPromoCode(id)
+	if err != nil {
+		return h.send(c, "❌ Промокод не найден", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	switch {
+	case rule == "nu":
+		promoCode.NewUsersOnly = !promoCode.NewUsersOnly
+	case rule == "fp":
+		promoCode.FirstPurchaseOnly = !promoCode.FirstPurchaseOnly
+	case rule == "rf":
+		promoCode.NotCombinableWithReferral = !promoCode.NotCombinableWithReferral
+	case rule == "pu":
+		promoCode.MaxUsesPerUser = nextPerUserLimit(promoCode.MaxUsesPerUser)
+	case rule == "ma":
+		return h.startPromoMinAmount(c, promoCode)
+	case strings.HasPrefix(rule, "pl:") && isPromoRulePlan(strings.TrimPrefix(rule, "pl:")):
+		promoCode.AllowedPlans = togglePlan(promoCode.GetAllowedPlans(), strings.TrimPrefix(rule, "pl:"))
+	default:
+		return h.send(c, promoRulesText(promoCode), h.promoRulesMenu(promoCode))
+	}
+
+	if err := h.promoCodeService.UpdateRules(promoCode); err != nil {
+		return h.send(c, fmt.Sprintf("❌ Ошибка при сохранении условий: %s", err.Error()), h.promoRulesMenu(promoCode))
+	}
+	h.logPromoRules(c, promoCode)
+
+	return h.send(c, promoRulesText(promoCode), h.promoRulesMenu(promoCode))
+}
+
+// startPromoMinAmount запрашивает минимальную сумму покупки
+func (h *AdminHandler) startPromoMinAmount(c *router.Context, promoCode *models.PromoCode) error {
+	if err := c.StartStep(StepPromoRulesMinAmount); err != nil {
+		return err
+	}
+	c.Session.Set("promo_code_id", promoCode.ID.String())
+	if err := c.NextStep(StepPromoRulesMinAmount); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("💵 Минимальная сумма покупки для %s\n\n", promoCode.Code)
+	text += "Отправьте сумму в рублях, 0 — без ограничения.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:prules:"+promoCode.ID.String()))
+}
+
+// HandlePromoMinAmountStep сохраняет минимальную сумму покупки
+func (h *AdminHandler) HandlePromoMinAmountStep(c *router.Context) error {
+	if !h.can(c, models.PermPromoManage) {
+		return c.FinishStep()
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(c.Text()), ",", "."), 64)
+	if err != nil || amount < 0 {
+		return h.send(c, "❌ Введите число не меньше 0. Попробуйте еще раз или /cancel", nil)
+	}
+
+	id := c.Session.Get("promo_code_id")
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	promoCode, err := h.getPromoCode(id)
+	if err != nil {
+		return h.send(c, "❌ Промокод не найден", h.adminKeyboard.CreatePromoCodeMenu(c.Locale))
+	}
+
+	promoCode.MinPurchaseAmount = amount
+	if err := h.promoCodeService.UpdateRules(promoCode); err != nil {
+		return h.send(c, fmt.Sprintf("❌ Ошибка при сохранении условий: %s", err.Error()), h.promoRulesMenu(promoCode))
+	}
+	h.logPromoRules(c, promoCode)
+
+	return h.send(c, "✅ Условия сохранены\n\n"+promoRulesText(promoCode), h.promoRulesMenu(promoCode))
+}
+
+// getPromoCode получает промокод по строковому ID
+func (h *AdminHandler) getPromoCode(id string) (*models.PromoCode, error) {
+	promoCodeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	promoCode, err := h.promoCodeService.GetPromoCodeByID(promoCodeID)
+	if err != nil {
+		return nil, err
+	}
+	if promoCode == nil {
+		return nil, fmt.Errorf("promo code not found")
+	}
+	return promoCode, nil
+}
+
+// logPromoRules записывает изменение условий промокода в журнал действий
+func (h *AdminHandler) logPromoRules(c *router.Context, promoCode *models.PromoCode) {
+	h.activityLogService.LogActivity(c.User.ID, "admin_action", map[string]interface{}{
+		"action":                       "update_promo_code_rules",
+		"promo_code_id":                promoCode.ID,
+		"code":                         promoCode.Code,
+		"max_uses_per_user":            promoCode.MaxUsesPerUser,
+		"new_users_only":               promoCode.NewUsersOnly,
+		"first_purchase_only":          promoCode.FirstPurchaseOnly,
+		"allowed_plans":                promoCode.AllowedPlans,
+		"min_purchase_amount":          promoCode.MinPurchaseAmount,
+		"not_combinable_with_referral": promoCode.NotCombinableWithReferral,
+	}, "", "")
+}
+
+// promoRulesMenu создает клавиатуру настройки условий промокода
+func (h *AdminHandler) promoRulesMenu(promoCode *models.PromoCode) *telego.InlineKeyboardMarkup {
+	prefix := "admin:prule:" + promoCode.ID.String() + ":"
+
+	perUser := "без ограничений"
+	if promoCode.MaxUsesPerUser > 0 {
+		perUser = strconv.Itoa(promoCode.MaxUsesPerUser)
+	}
+
+	var planRow []telego.InlineKeyboardButton
+	for _, plan := range promoRulePlans {
+		label := plan
+		if promoCode.AllowedPlans != "" && promoCode.AllowsPlan(plan) {
+			label = "✅ " + plan
+		}
+		planRow = append(planRow, telego.InlineKeyboardButton{Text: label, CallbackData: prefix + "pl:" + plan})
+	}
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "👤 На пользователя: " + perUser, CallbackData: prefix + "pu"}},
+		{{Text: checkMark(promoCode.NewUsersOnly) + " Только новым", CallbackData: prefix + "nu"}},
+		{{Text: checkMark(promoCode.FirstPurchaseOnly) + " Только первая покупка", CallbackData: prefix + "fp"}},
+		{{Text: checkMark(promoCode.NotCombinableWithReferral) + " Не с реферальным бонусом", CallbackData: prefix + "rf"}},
+		planRow,
+		{{Text: fmt.Sprintf("💵 Мин. сумма: %.0f₽", promoCode.MinPurchaseAmount), CallbackData: prefix + "ma"}},
+		{{Text: "🔙 К промокодам", CallbackData: "admin:promo"}},
+	}}
+}
+
+// promoRulesText форматирует условия применения промокода
+func promoRulesText(promoCode *models.PromoCode) string {
+	text := fmt.Sprintf("⚙️ Условия промокода %s\n\n", promoCode.Code)
+	if promoCode.MaxUsesPerUser > 0 {
+		text += fmt.Sprintf("👤 Использований на пользователя: %d\n", promoCode.MaxUsesPerUser)
+	} else {
+		text += "👤 Использований на пользователя: без ограничений\n"
+	}
+	text += fmt.Sprintf("🆕 Только новым пользователям: %s\n", yesNo(promoCode.NewUsersOnly))
+	text += fmt.Sprintf("🛒 Только на первую покупку: %s\n", yesNo(promoCode.FirstPurchaseOnly))
+	text += fmt.Sprintf("🤝 Не совмещается с реферальным бонусом: %s\n", yesNo(promoCode.NotCombinableWithReferral))
+	if plans := promoCode.GetAllowedPlans(); len(plans) > 0 {
+		text += fmt.Sprintf("📦 Тарифы: %s\n", strings.Join(plans, ", "))
+	} else {
+		text += "📦 Тарифы: все\n"
+	}
+	if promoCode.MinPurchaseAmount > 0 {
+		text += fmt.Sprintf("💵 Минимальная сумма покупки: %.2f₽\n", promoCode.MinPurchaseAmount)
+	} else {
+		text += "💵 Минимальная сумма покупки: нет\n"
+	}
+
+	return text
+}
+
+// nextPerUserLimit возвращает следующее значение лимита на пользователя
+func nextPerUserLimit(current int) int {
+	for i, limit := range promoPerUserSteps {
+		if limit == current {
+			return promoPerUserSteps[(i+1)%len(promoPerUserSteps)]
+		}
+	}
+	return promoPerUserSteps[0]
+}
+
+// togglePlan добавляет тариф в список или убирает его оттуда
+func togglePlan(plans []string, plan string) string {
+	var result []string
+	found := false
+	for _, p := range plans {
+		if strings.EqualFold(p, plan) {
+			found = true
+			continue
+		}
+		result = append(result, p)
+	}
+	if !found {
+		result = append(result, plan)
+	}
+	return strings.Join(result, ",")
+}
+
+// isPromoRulePlan проверяет, что тариф можно выбрать в условиях промокода
+func isPromoRulePlan(plan string) bool {
+	for _, p := range promoRulePlans {
+		if p == plan {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMark возвращает отметку включенного условия
+func checkMark(enabled bool) string {
+	if enabled {
+		return "✅"
+	}
+	return "▫️"
+}
+
+// yesNo возвращает "да" или "нет"
+func yesNo(value bool) string {
+	if value {
+		return "да"
+	}
+	return "нет"
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/mymmrac/telego"
+)
+
+// StepRoleGrantUser шаг ввода пользователя, которому назначается роль
+const StepRoleGrantUser = "admin:role_grant"
+
+// roleRevoke значение в callback'е, отзывающее роль
+const roleRevoke = "none"
+
+// ShowRoles показывает сотрудников с ролями
+func (h *AdminHandler) ShowRoles(c *router.Context) error {
+	staff, err := h.userService.GetStaff()
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении списка сотрудников", nil)
+	}
+
+	text := "👮 Роли сотрудников\n\n"
+	for _, id := range h.config.Admin.TelegramIDs {
+		text += fmt.Sprintf("%s — %d (ADMIN_TELEGRAM_IDS)\n", models.GetRoleText(models.RoleOwner), id)
+	}
+	text += "\nВыберите сотрудника, чтобы изменить роль, или назначьте роль новому."
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, user := range staff {
+		if h.isConfigOwner(user.TelegramID) {
+			continue
+		}
+		label := fmt.Sprintf("%s · %s", models.GetRoleText(user.EffectiveRole()), user.GetFullName())
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: fmt.Sprintf("admin:role_user:%d", user.TelegramID)},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "➕ Назначить роль", CallbackData: "admin:role_grant"},
+	})
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:main"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// StartGrantRole запрашивает пользователя для назначения роли
+func (h *AdminHandler) StartGrantRole(c *router.Context) error {
+	if err := c.StartStep(StepRoleGrantUser); err != nil {
+		return err
+	}
+
+	text := "➕ Назначение роли\n\n"
+	text += "Отправьте Telegram ID или @username пользователя.\n"
+	text += "Пользователь должен хотя бы раз запустить бота.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:roles"))
+}
+
+// HandleGrantRoleUserStep находит пользователя и показывает выбор роли
+func (h *AdminHandler) HandleGrantRoleUserStep(c *router.Context) error {
+	if !h.can(c, models.PermRolesManage) {
+		return c.FinishStep()
+	}
+
+	query := strings.TrimSpace(c.Text())
+	var target *models.User
+	if telegramID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		target, _ = h.userService.GetUser(telegramID)
+	} else if username, ok := strings.CutPrefix(query, "@"); ok && username != "" {
+		users, _ := h.userService.SearchUsers(username, 10)
+		for i := range users {
+			if strings.EqualFold(users[i].Username, username) {
+				target = &users[i]
+				break
+			}
+		}
+	}
+	if target == nil {
+		return h.send(c, "❌ Пользователь не найден. Попробуйте еще раз или /cancel", nil)
+	}
+
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+
+	return h.showStaffMember(c, target)
+}
+
+// ShowStaffMember показывает роль сотрудника и кнопки ее изменения
+func (h *AdminHandler) ShowStaffMember(c *router.Context, telegramIDStr string) error {
+	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
+	if err != nil {
+		return h.send(c, "❌ Пользователь не найден", h.rolesBackMenu())
+	}
+
+	target, err := h.userService.GetUser(telegramID)
+	if err != nil || target == nil {
+		return h.send(c, "❌ Пользователь не найден", h.rolesBackMenu())
+	}
+
+	return h.showStaffMember(c, target)
+}
+
+// SetRole назначает или отзывает роль. data — <telegram_id>:<роль|none>
+func (h *AdminHandler) SetRole(c *router.Context, data string) error {
+	telegramIDStr, role, ok := strings.Cut(data, ":")
+	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
+	if !ok || err != nil {
+		return h.send(c, "❌ Некорректный запрос", h.rolesBackMenu())
+	}
+	if role == roleRevoke {
+		role = ""
+	}
+
+	if telegramID == c.User.TelegramID {
+		return h.send(c, "❌ Нельзя изменить собственную роль", h.rolesBackMenu())
+	}
+
+	oldRole := h.userService.GetRole(telegramID)
+	target, err := h.userService.SetRole(telegramID, role)
+	if err != nil {
+		text := "❌ Не удалось изменить роль. Попробуйте позже."
+		if errors.Is(err, services.ErrUnknownRole) || errors.Is(err, services.ErrOwnerByConfig) || errors.Is(err, services.ErrUserNotFound) {
+			text = "❌ " + err.Error()
+		}
+		return h.send(c, text, h.rolesBackMenu())
+	}
+
+	h.activityLogService.LogActivity(c.User.ID, "admin_role_changed", map[string]interface{}{
+		"target_user_id": target.ID,
+		"telegram_id":    target.TelegramID,
+		"old_role":       oldRole,
+		"new_role":       role,
+	}, "", "")
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditRoleChange,
+		TargetUser: target,
+		Before:     map[string]string{"role": oldRole},
+		After:      map[string]string{"role": role},
+	})
+
+	return h.showStaffMember(c, target)
+}
+
+// showStaffMember формирует карточку сотрудника
+func (h *AdminHandler) showStaffMember(c *router.Context, target *models.User) error {
+	role := target.EffectiveRole()
+	if h.isConfigOwner(target.TelegramID) {
+		role = models.RoleOwner
+	}
+
+	text := fmt.Sprintf("👤 %s\n", target.GetFullName())
+	text += fmt.Sprintf("🆔 Telegram ID: %d\n", target.TelegramID)
+	if target.Username != "" {
+		text += fmt.Sprintf("📱 Username: @%s\n", target.Username)
+	}
+	text += fmt.Sprintf("🎭 Роль: %s\n", models.GetRoleText(role))
+	if permissions := models.RolePermissions(role); len(permissions) > 0 {
+		text += "🔑 Права: " + strings.Join(permissions, ", ") + "\n"
+	}
+
+	if h.isConfigOwner(target.TelegramID) {
+		text += "\nВладелец задан в ADMIN_TELEGRAM_IDS, роль меняется только в конфигурации."
+		return h.send(c, text, h.rolesBackMenu())
+	}
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	var row []telego.InlineKeyboardButton
+	for _, r := range models.AdminRoles {
+		label := models.GetRoleText(r)
+		if r == role {
+			label = "✅ " + label
+		}
+		row = append(row, telego.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: fmt.Sprintf("admin:role_set:%d:%s", target.TelegramID, r),
+		})
+		if len(row) == 2 {
+			keyboardRows = append(keyboardRows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboardRows = append(keyboardRows, row)
+	}
+	if role != "" {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "🚫 Отозвать роль", CallbackData: fmt.Sprintf("admin:role_set:%d:%s", target.TelegramID, roleRevoke)},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:roles"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// rolesBackMenu клавиатура возврата к списку сотрудников
+func (h *AdminHandler) rolesBackMenu() *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "🔙 Назад", CallbackData: "admin:roles"}},
+	}}
+}
+
+// isConfigOwner проверяет, указан ли пользователь в ADMIN_TELEGRAM_IDS
+func (h *AdminHandler) isConfigOwner(telegramID int64) bool {
+	for _, id := range h.config.Admin.TelegramIDs {
+		if id == telegramID {
+			return true
+		}
+	}
+	return false
+}
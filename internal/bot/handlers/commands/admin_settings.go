@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/mymmrac/telego"
+)
+
+// StepSettingValue шаг ввода нового значения настройки
+const StepSettingValue = "admin:setting_value"
+
+// settingHistoryLimit количество последних изменений в карточке настройки
+const settingHistoryLimit = 5
+
+// settingGroupTitles заголовки разделов настроек
+var settingGroupTitles = map[string]string{
+	services.SettingGroupBot:      "🤖 Настройки бота",
+	services.SettingGroupPayments: "💳 Цены и способы оплаты",
+	services.SettingGroupPromo:    "🎁 Бонусы и партнерская программа",
+	services.SettingGroupNotify:   "📢 Настройки уведомлений",
+}
+
+// ShowSettingsGroup показывает настройки раздела с текущими значениями
+func (h *AdminHandler) ShowSettingsGroup(c *router.Context, group string) error {
+	title, ok := settingGroupTitles[group]
+	if !ok {
+		return h.send(c, "❌ Раздел настроек не найден", h.settingsBackMenu("admin:settings"))
+	}
+
+	text := title + "\n\n"
+	text += "Изменения применяются сразу и сохраняются в базе данных.\n"
+	text += "✏️ — значение изменено из админ-панели"
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, definition := range h.settingsService.Definitions(group) {
+		label := fmt.Sprintf("%s: %s", definition.Title, settingValueText(&definition, h.settingsService.Value(definition.Key)))
+		if h.settingsService.IsOverridden(definition.Key) {
+			label = "✏️ " + label
+		}
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: "admin:set:" + definition.Key},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:settings"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ShowSetting показывает карточку настройки с историей изменений
+func (h *AdminHandler) ShowSetting(c *router.Context, key string) error {
+	definition, err := h.settingsService.Definition(key)
+	if err != nil {
+		return h.send(c, "❌ Настройка не найдена", h.settingsBackMenu("admin:settings"))
+	}
+
+	return h.send(c, h.settingText(definition), h.settingActionsMenu(definition))
+}
+
+// ToggleSetting переключает логическую настройку или выбирает следующий вариант
+func (h *AdminHandler) ToggleSetting(c *router.Context, key string) error {
+	definition, err := h.settingsService.Definition(key)
+	if err != nil {
+		return h.send(c, "❌ Настройка не найдена", h.settingsBackMenu("admin:settings"))
+	}
+
+	current := h.settingsService.Value(key)
+	var next string
+	switch definition.Type {
+	case services.SettingTypeBool:
+		next = "true"
+		if current == "true" {
+			next = "false"
+		}
+	case services.SettingTypeChoice:
+		next = definition.Options[0]
+		for i, option := range definition.Options {
+			if option == current {
+				next = definition.Options[(i+1)%len(definition.Options)]
+			}
+		}
+	default:
+		return h.StartEditSetting(c, key)
+	}
+
+	if _, err := h.settingsService.Set(key, next, c.User.ID); err != nil {
+		return h.sendSettingError(c, definition, err)
+	}
+	h.auditSetting(c, definition, current, "")
+
+	return h.send(c, "✅ Настройка сохранена\n\n"+h.settingText(definition), h.settingActionsMenu(definition))
+}
+
+// StartEditSetting запрашивает новое значение числовой настройки
+func (h *AdminHandler) StartEditSetting(c *router.Context, key string) error {
+	definition, err := h.settingsService.Definition(key)
+	if err != nil {
+		return h.send(c, "❌ Настройка не найдена", h.settingsBackMenu("admin:settings"))
+	}
+
+	if err := c.StartStep(StepSettingValue); err != nil {
+		return err
+	}
+	c.Session.Set("key", key)
+
+	text := fmt.Sprintf("✏️ %s\n\n", definition.Title)
+	text += fmt.Sprintf("Текущее значение: %s\n", settingValueText(definition, h.settingsService.Value(key)))
+	text += fmt.Sprintf("Допустимо от %g до %g.\n\n", definition.Min, definition.Max)
+	text += "Отправьте новое значение.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:set:"+key))
+}
+
+// HandleSettingValueStep сохраняет введенное значение настройки
+func (h *AdminHandler) HandleSettingValueStep(c *router.Context) error {
+	if !h.can(c, models.PermSettingsManage) {
+		return c.FinishStep()
+	}
+
+	definition, err := h.settingsService.Definition(c.Session.Get("key"))
+	if err != nil {
+		c.FinishStep()
+		return h.send(c, "❌ Диалог поврежден, начните заново", h.settingsBackMenu("admin:settings"))
+	}
+
+	current := h.settingsService.Value(definition.Key)
+	if _, err := h.settingsService.Set(definition.Key, c.Text(), c.User.ID); err != nil {
+		if errors.Is(err, services.ErrInvalidSettingValue) {
+			return h.send(c, fmt.Sprintf("❌ %v. Попробуйте еще раз или /cancel", err), nil)
+		}
+		c.FinishStep()
+		return h.sendSettingError(c, definition, err)
+	}
+
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	h.auditSetting(c, definition, current, "")
+
+	return h.send(c, "✅ Настройка сохранена\n\n"+h.settingText(definition), h.settingActionsMenu(definition))
+}
+
+// ResetSetting возвращает настройке значение из окружения
+func (h *AdminHandler) ResetSetting(c *router.Context, key string) error {
+	definition, err := h.settingsService.Definition(key)
+	if err != nil {
+		return h.send(c, "❌ Настройка не найдена", h.settingsBackMenu("admin:settings"))
+	}
+
+	current := h.settingsService.Value(key)
+	if err := h.settingsService.Reset(key, c.User.ID); err != nil {
+		return h.sendSettingError(c, definition, err)
+	}
+	h.auditSetting(c, definition, current, "сброс к значению из окружения")
+
+	return h.send(c, "♻️ Восстановлено значение из окружения\n\n"+h.settingText(definition), h.settingActionsMenu(definition))
+}
+
+// auditSetting записывает изменение настройки в журнал аудита
+func (h *AdminHandler) auditSetting(c *router.Context, definition *services.SettingDefinition, before, reason string) {
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditSettingChange,
+		TargetType: "setting",
+		TargetID:   definition.Key,
+		Before:     map[string]string{"value": before},
+		After:      map[string]string{"value": h.settingsService.Value(definition.Key)},
+		Reason:     reason,
+	})
+}
+
+// settingText формирует карточку настройки
+func (h *AdminHandler) settingText(definition *services.SettingDefinition) string {
+	text := fmt.Sprintf("⚙️ %s\n\n", definition.Title)
+	text += fmt.Sprintf("🔑 Ключ: %s\n", definition.Key)
+	text += fmt.Sprintf("📌 Значение: %s\n", settingValueText(definition, h.settingsService.Value(definition.Key)))
+	text += fmt.Sprintf("🌍 Из окружения: %s\n", settingValueText(definition, h.settingsService.Default(definition.Key)))
+
+	changes, err := h.settingsService.History(definition.Key, settingHistoryLimit)
+	if err != nil || len(changes) == 0 {
+		return text
+	}
+
+	text += "\n🕓 Последние изменения:\n"
+	for _, change := range changes {
+		author := "неизвестно"
+		if change.ChangedByUser != nil {
+			author = change.ChangedByUser.GetFullName()
+		}
+		action := fmt.Sprintf("%s → %s", settingValueText(definition, change.OldValue), settingValueText(definition, change.NewValue))
+		if change.Reset {
+			action += " (сброс)"
+		}
+		text += fmt.Sprintf("• %s %s: %s\n", change.CreatedAt.Format("02.01.2006 15:04"), author, action)
+	}
+
+	return text
+}
+
+// settingActionsMenu кнопки действий с настройкой
+func (h *AdminHandler) settingActionsMenu(definition *services.SettingDefinition) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
+
+	switch definition.Type {
+	case services.SettingTypeBool, services.SettingTypeChoice:
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "🔄 Переключить", CallbackData: "admin:set_toggle:" + definition.Key},
+		})
+	default:
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "✏️ Изменить", CallbackData: "admin:set_edit:" + definition.Key},
+		})
+	}
+
+	if h.settingsService.IsOverridden(definition.Key) {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "♻️ Вернуть значение из окружения", CallbackData: "admin:set_reset:" + definition.Key},
+		})
+	}
+
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:settings_" + definition.Group},
+	})
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
+}
+
+// settingsBackMenu клавиатура возврата к настройкам
+func (h *AdminHandler) settingsBackMenu(backData string) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "🔙 Назад", CallbackData: backData}},
+	}}
+}
+
+// sendSettingError сообщает об ошибке сохранения настройки
+func (h *AdminHandler) sendSettingError(c *router.Context, definition *services.SettingDefinition, err error) error {
+	text := "❌ Не удалось сохранить настройку. Попробуйте позже."
+	if errors.Is(err, services.ErrInvalidSettingValue) {
+		text = fmt.Sprintf("❌ %v", err)
+	}
+	return h.send(c, text, h.settingActionsMenu(definition))
+}
+
+// settingValueText форматирует значение настройки для админ-панели
+func settingValueText(definition *services.SettingDefinition, value string) string {
+	if definition.Type == services.SettingTypeBool {
+		if value == "true" {
+			return "✅ вкл"
+		}
+		return "❌ выкл"
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/mymmrac/telego"
+)
+
+// ShowStats показывает статистику за период в сравнении с предыдущим
+func (h *AdminHandler) ShowStats(c *router.Context, periodKey string) error {
+	periodKey = strings.TrimSpace(periodKey)
+	if periodKey == "" {
+		periodKey = services.DefaultStatsPeriod
+	}
+
+	dashboard, err := h.statsService.Dashboard(periodKey, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownStatsPeriod) {
+			return h.send(c, "❌ Неизвестный период. Доступно: day, week, month, year", nil)
+		}
+		return h.send(c, "❌ Ошибка при получении статистики", nil)
+	}
+
+	return h.send(c, statsText(dashboard), h.statsMenu(dashboard.Period.Key))
+}
+
+// statsText форматирует статистику за период
+func statsText(dashboard *services.StatsDashboard) string {
+	current, previous := &dashboard.Current, &dashboard.Previous
+	metrics, prevMetrics := current.Metrics, previous.Metrics
+
+	text := fmt.Sprintf("📊 Статистика: %s\n", dashboard.Period.Title)
+	text += fmt.Sprintf("%s — %s, в скобках — изменение к предыдущему периоду\n\n",
+		current.From.Format("02.01.2006 15:04"), current.To.Format("02.01.2006 15:04"))
+
+	text += fmt.Sprintf("💰 Выручка: %.2f₽ (%s)\n", current.Revenue, changeText(current.Revenue, previous.Revenue))
+	text += fmt.Sprintf("🧾 Платежей: %d (%s)\n", current.Payments, changeText(float64(current.Payments), float64(previous.Payments)))
+	for _, method := range current.Methods {
+		text += fmt.Sprintf("  • %s: %.2f₽ (%d)\n", models.PaymentMethodText(method.Method), method.Amount, method.Count)
+	}
+
+	if current.Revenue > 0 {
+		text += "\n📈 Выручка по интервалам:\n"
+		for _, point := range current.Points {
+			if point.Count == 0 {
+				continue
+			}
+			text += fmt.Sprintf("  %s: %.2f₽\n", statsPointLabel(dashboard.Period.Step, point.Start), point.Total)
+		}
+	}
+
+	text += "\n👥 Пользователи\n"
+	text += fmt.Sprintf("Новые: %d (%s)\n", metrics.NewUsers, changeText(float64(metrics.NewUsers), float64(prevMetrics.NewUsers)))
+	text += fmt.Sprintf("Платящие: %d (%s)\n", metrics.PayingUsers, changeText(float64(metrics.PayingUsers), float64(prevMetrics.PayingUsers)))
+	text += fmt.Sprintf("С подпиской: %d (%s)\n", metrics.ActiveUsers, changeText(float64(metrics.ActiveUsers), float64(prevMetrics.ActiveUsers)))
+	text += fmt.Sprintf("Конверсия пробного периода: %.1f%% (%d из %d), было %.1f%%\n",
+		current.Conversion(), metrics.TrialConverted, metrics.TrialStarted, previous.Conversion())
+	text += fmt.Sprintf("Отток: %.1f%% (%d из %d), было %.1f%%\n",
+		current.ChurnRate(), metrics.Churned, metrics.ActiveAtStart, previous.ChurnRate())
+	text += fmt.Sprintf("ARPU: %.2f₽ (%s)\n", current.ARPU(), changeText(current.ARPU(), previous.ARPU()))
+	text += fmt.Sprintf("ARPPU: %.2f₽ (%s)\n", current.ARPPU(), changeText(current.ARPPU(), previous.ARPPU()))
+
+	text += "\n🤝 Рефералы\n"
+	text += fmt.Sprintf("Приглашено: %d (%s)\n", metrics.ReferredUsers, changeText(float64(metrics.ReferredUsers), float64(prevMetrics.ReferredUsers)))
+	text += fmt.Sprintf("Выручка от приглашенных: %.2f₽ (%.1f%% выручки)\n", metrics.ReferralRevenue, current.ReferralShare())
+
+	text += "\n🔒 Активные подписки по тарифам\n"
+	if len(dashboard.ActiveByTariff) == 0 {
+		text += "Нет активных подписок\n"
+	}
+	for _, tariff := range dashboard.ActiveByTariff {
+		text += fmt.Sprintf("  • %s: %d\n", tariff.PlanName, tariff.Count)
+	}
+
+	return text
+}
+
+// statsPointLabel подпись интервала выручки
+func statsPointLabel(step string, start time.Time) string {
+	switch step {
+	case services.StatsStepHour:
+		return start.Format("15:00")
+	case services.StatsStepDay:
+		return start.Format("02.01")
+	case services.StatsStepWeek:
+		return start.Format("02.01") + "–" + start.AddDate(0, 0, 6).Format("02.01")
+	default:
+		return start.Format("01.2006")
+	}
+}
+
+// changeText форматирует изменение значения относительно предыдущего периода
+func changeText(current, previous float64) string {
+	if previous == 0 {
+		if current == 0 {
+			return "без изменений"
+		}
+		return "было 0"
+	}
+	change := (current - previous) / previous * 100
+	if change >= 0 {
+		return fmt.Sprintf("+%.0f%%", change)
+	}
+	return fmt.Sprintf("%.0f%%", change)
+}
+
+// statsMenu клавиатура выбора периода статистики
+func (h *AdminHandler) statsMenu(periodKey string) *telego.InlineKeyboardMarkup {
+	var periodRow []telego.InlineKeyboardButton
+	for _, period := range services.StatsPeriods {
+		label := period.Title
+		if period.Key == periodKey {
+			label = "✅ " + label
+		}
+		periodRow = append(periodRow, telego.InlineKeyboardButton{Text: label, CallbackData: "admin:stats:" + period.Key})
+	}
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		periodRow,
+		{{Text: "📈 Графики", CallbackData: "admin:stats_chart:revenue:" + periodKey}},
+		{{Text: "🔙 Назад", CallbackData: "admin:main"}},
+	}}
+}
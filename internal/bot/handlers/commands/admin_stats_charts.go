@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/charts"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// statsCharts графики статистики в порядке отображения
+var statsCharts = []struct {
+	key   string
+	title string
+}{
+	{"revenue", "💰 Выручка"},
+	{"users", "👥 Регистрации"},
+	{"subs", "🔒 Подписки"},
+	{"methods", "💳 Способы оплаты"},
+}
+
+// ShowStatsChart отправляет график статистики фотографией. data — <график>:<период>
+func (h *AdminHandler) ShowStatsChart(c *router.Context, data string) error {
+	chartKey, periodKey, _ := strings.Cut(data, ":")
+	if periodKey == "" {
+		periodKey = services.DefaultStatsPeriod
+	}
+
+	series, err := h.statsService.Series(periodKey, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownStatsPeriod) {
+			return h.send(c, "❌ Неизвестный период. Доступно: day, week, month, year", nil)
+		}
+		return h.send(c, "❌ Ошибка при получении статистики", nil)
+	}
+
+	var image []byte
+	var caption string
+	switch chartKey {
+	case "users":
+		image, caption, err = registrationsChart(series)
+	case "subs":
+		image, caption, err = subscriptionsChart(series)
+	case "methods":
+		image, caption, err = methodsChart(series)
+	default:
+		chartKey = "revenue"
+		image, caption, err = revenueChart(series)
+	}
+
+	keyboard := h.statsChartMenu(chartKey, series.Period.Key)
+	if errors.Is(err, charts.ErrNoData) {
+		return h.send(c, "📭 Нет данных за выбранный период", keyboard)
+	}
+	if err != nil {
+		return h.send(c, "❌ Ошибка при построении графика", nil)
+	}
+
+	caption = fmt.Sprintf("%s\n📅 %s — %s", caption,
+		series.Revenue.From.Format("02.01.2006"), series.Revenue.To.Format("02.01.2006"))
+	photo := telegram.FileFromBytes(fmt.Sprintf("stats_%s_%s.png", chartKey, series.Period.Key), image)
+	return c.RespondPhoto(photo, caption, telegram.Plain(keyboard))
+}
+
+// revenueChart строит выручку по интервалам с разбивкой по способам оплаты
+func revenueChart(series *services.StatsSeries) ([]byte, string, error) {
+	revenue := series.Revenue
+	caption := fmt.Sprintf("💰 Выручка: %s — %.2f₽\n", series.Period.Title, revenue.Revenue)
+
+	var chartSeries []charts.Series
+	for i, method := range revenue.Methods {
+		values := make([]float64, len(revenue.Points))
+		for j, point := range revenue.Points {
+			values[j] = point.ByMethod[method.Method]
+		}
+		chartSeries = append(chartSeries, charts.Series{Values: values, Color: chartColor(i)})
+		caption += fmt.Sprintf("%s %s: %.2f₽\n", chartEmoji(i), models.PaymentMethodText(method.Method), method.Amount)
+	}
+	if len(chartSeries) == 0 {
+		chartSeries = []charts.Series{{Color: chartColor(0)}}
+	}
+
+	image, err := charts.Bars(chartLabels(series), chartSeries)
+	return image, strings.TrimSuffix(caption, "\n"), err
+}
+
+// registrationsChart строит регистрации по интервалам
+func registrationsChart(series *services.StatsSeries) ([]byte, string, error) {
+	values := make([]float64, len(series.Registrations))
+	var total int64
+	for i, count := range series.Registrations {
+		values[i] = float64(count)
+		total += count
+	}
+
+	image, err := charts.Bars(chartLabels(series), []charts.Series{{Values: values, Color: chartColor(0)}})
+	caption := fmt.Sprintf("👥 Регистрации: %s — %d", series.Period.Title, total)
+	return image, caption, err
+}
+
+// subscriptionsChart строит количество действующих подписок на конец интервалов
+func subscriptionsChart(series *services.StatsSeries) ([]byte, string, error) {
+	values := make([]float64, len(series.ActiveSubscriptions))
+	for i, count := range series.ActiveSubscriptions {
+		values[i] = float64(count)
+	}
+
+	var current int64
+	if n := len(series.ActiveSubscriptions); n > 0 {
+		current = series.ActiveSubscriptions[n-1]
+	}
+
+	image, err := charts.Line(chartLabels(series), charts.Series{Values: values, Color: chartColor(1)})
+	caption := fmt.Sprintf("🔒 Действующие подписки: %s, сейчас %d", series.Period.Title, current)
+	return image, caption, err
+}
+
+// methodsChart строит доли способов оплаты в выручке
+func methodsChart(series *services.StatsSeries) ([]byte, string, error) {
+	revenue := series.Revenue
+	caption := fmt.Sprintf("💳 Способы оплаты: %s\n", series.Period.Title)
+
+	var slices []charts.Slice
+	for i, method := range revenue.Methods {
+		slices = append(slices, charts.Slice{Value: method.Amount, Color: chartColor(i)})
+		share := 0.0
+		if revenue.Revenue > 0 {
+			share = method.Amount / revenue.Revenue * 100
+		}
+		caption += fmt.Sprintf("%s %s: %.2f₽ (%.1f%%, платежей: %d)\n",
+			chartEmoji(i), models.PaymentMethodText(method.Method), method.Amount, share, method.Count)
+	}
+
+	image, err := charts.Pie(slices)
+	return image, strings.TrimSuffix(caption, "\n"), err
+}
+
+// chartLabels подписи интервалов по оси X. Шрифт графиков содержит только
+// цифры и знаки препинания, поэтому подписи — числовые даты
+func chartLabels(series *services.StatsSeries) []string {
+	labels := make([]string, len(series.Revenue.Points))
+	for i, point := range series.Revenue.Points {
+		switch series.Period.Step {
+		case services.StatsStepHour:
+			labels[i] = point.Start.Format("15:00")
+		case services.StatsStepMonth:
+			labels[i] = point.Start.Format("01.2006")
+		default:
+			labels[i] = point.Start.Format("02.01")
+		}
+	}
+	return labels
+}
+
+// chartColor цвет ряда по порядковому номеру
+func chartColor(i int) color.RGBA {
+	return charts.Palette[i%len(charts.Palette)]
+}
+
+// chartEmoji эмодзи цвета ряда для легенды
+func chartEmoji(i int) string {
+	return charts.PaletteEmoji[i%len(charts.PaletteEmoji)]
+}
+
+// statsChartMenu клавиатура выбора графика и периода
+func (h *AdminHandler) statsChartMenu(chartKey, periodKey string) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
+	var row []telego.InlineKeyboardButton
+	for _, chart := range statsCharts {
+		label := chart.title
+		if chart.key == chartKey {
+			label = "✅ " + label
+		}
+		row = append(row, telego.InlineKeyboardButton{Text: label, CallbackData: fmt.Sprintf("admin:stats_chart:%s:%s", chart.key, periodKey)})
+		if len(row) == 2 {
+			keyboardRows = append(keyboardRows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboardRows = append(keyboardRows, row)
+	}
+
+	var periodRow []telego.InlineKeyboardButton
+	for _, period := range services.StatsPeriods {
+		label := period.Title
+		if period.Key == periodKey {
+			label = "✅ " + label
+		}
+		periodRow = append(periodRow, telego.InlineKeyboardButton{Text: label, CallbackData: fmt.Sprintf("admin:stats_chart:%s:%s", chartKey, period.Key)})
+	}
+	keyboardRows = append(keyboardRows, periodRow)
+
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "📊 Сводка", CallbackData: "admin:stats:" + periodKey},
+		{Text: "🔙 Назад", CallbackData: "admin:main"},
+	})
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
+}
@@ -0,0 +1,614 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/mymmrac/telego"
+)
+
+// Шаги диалогов карточки пользователя
+const (
+	StepUserBalance      = "admin:user_balance"
+	StepSubscriptionDays = "admin:usub_days"
+	StepUserMessage      = "admin:user_message"
+)
+
+const (
+	// userCardPageSize количество подписок и платежей на странице карточки
+	userCardPageSize = 5
+	// userCardActivityLimit количество последних действий в карточке
+	userCardActivityLimit = 5
+	// userReferralsLimit количество рефералов первого уровня в дереве
+	userReferralsLimit = 20
+)
+
+// ShowUserCard показывает карточку пользователя по Telegram ID
+func (h *AdminHandler) ShowUserCard(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+	return h.showUserCard(c, targetUser, "")
+}
+
+// showUserCard формирует карточку пользователя с действиями. notice — результат
+// последнего действия, выводится над карточкой
+func (h *AdminHandler) showUserCard(c *router.Context, targetUser *models.User, notice string) error {
+	text := ""
+	if notice != "" {
+		text = notice + "\n\n"
+	}
+	text += h.userCardText(c, targetUser)
+
+	return h.send(c, text, h.adminKeyboard.CreateUserActionsMenu(c.Locale, targetUser))
+}
+
+// userCardText собирает профиль, баланс, сводку подписок, платежей и рефералов
+func (h *AdminHandler) userCardText(c *router.Context, targetUser *models.User) string {
+	text := "👤 Карточка пользователя\n\n"
+	text += fmt.Sprintf("🆔 ID: %d\n", targetUser.TelegramID)
+	text += fmt.Sprintf("👤 Имя: %s\n", targetUser.GetFullName())
+	if targetUser.Username != "" {
+		text += fmt.Sprintf("📱 Username: @%s\n", targetUser.Username)
+	}
+	text += fmt.Sprintf("🌐 Язык: %s\n", targetUser.LanguageCode)
+	if role := targetUser.EffectiveRole(); role != "" {
+		text += fmt.Sprintf("🎭 Роль: %s\n", models.GetRoleText(role))
+	}
+	if targetUser.IsBlocked {
+		text += "🚫 Заблокирован\n"
+	}
+	text += fmt.Sprintf("📅 Регистрация: %s\n\n", targetUser.CreatedAt.Format("02.01.2006 15:04"))
+
+	text += fmt.Sprintf("💰 Баланс: %.2f₽\n", targetUser.Balance)
+
+	if subscriptions, err := h.subscriptionService.GetUserSubscriptions(targetUser.ID); err == nil {
+		active := 0
+		trialUsed := false
+		var nearest *models.Subscription
+		for i := range subscriptions {
+			subscription := &subscriptions[i]
+			if subscription.PlanID == 0 {
+				trialUsed = true
+			}
+			if !subscription.IsActive() {
+				continue
+			}
+			active++
+			if nearest == nil || subscription.ExpiresAt.Before(nearest.ExpiresAt) {
+				nearest = subscription
+			}
+		}
+		text += fmt.Sprintf("🔒 Подписки: активных %d из %d\n", active, len(subscriptions))
+		if nearest != nil {
+			text += fmt.Sprintf("⏳ Ближайшая: %s до %s\n", nearest.PlanName, nearest.ExpiresAt.Format("02.01.2006"))
+		}
+		if trialUsed {
+			text += "🧪 Пробный период: использован\n"
+		} else {
+			text += "🧪 Пробный период: доступен\n"
+		}
+	}
+
+	if payments, err := h.paymentService.GetUserPayments(targetUser.ID); err == nil {
+		var paid float64
+		for _, payment := range payments {
+			if payment.IsCompleted() {
+				paid += payment.Amount
+			}
+		}
+		text += fmt.Sprintf("💳 Платежей: %d, оплачено %.2f₽\n", len(payments), paid)
+	}
+
+	if referrals, err := h.userService.GetReferrals(targetUser.ID); err == nil {
+		text += fmt.Sprintf("👥 Приглашено: %d, бонусы %.2f₽\n", len(referrals), targetUser.ReferralBonusEarned)
+	}
+	if targetUser.ReferredBy != nil {
+		if referrer, err := h.userService.GetUserByID(*targetUser.ReferredBy); err == nil && referrer != nil {
+			text += fmt.Sprintf("🔗 Пригласил: %s (%d)\n", referrer.GetFullName(), referrer.TelegramID)
+		}
+	}
+
+	activity, err := h.activityLogService.GetUserActivity(targetUser.ID, userCardActivityLimit, 0)
+	if err == nil && len(activity) > 0 {
+		text += "\n🕓 Последние действия:\n"
+		for _, entry := range activity {
+			text += fmt.Sprintf("• %s %s\n", entry.CreatedAt.Format("02.01 15:04"), activityText(entry))
+		}
+	}
+
+	return text
+}
+
+// ShowUserSubscriptions показывает подписки пользователя постранично. data — <telegram_id>:<страница>
+func (h *AdminHandler) ShowUserSubscriptions(c *router.Context, data string) error {
+	telegramIDStr, page := parseCardPage(data)
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	subscriptions, err := h.subscriptionService.GetUserSubscriptions(targetUser.ID)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении подписок", h.userCardBackMenu(targetUser.TelegramID))
+	}
+
+	text := fmt.Sprintf("🔒 Подписки: %s\n\n", targetUser.GetFullName())
+	if len(subscriptions) == 0 {
+		text += "Подписок нет. Выдайте тариф из карточки пользователя."
+	}
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	from, to := pageBounds(len(subscriptions), page)
+	for _, subscription := range subscriptions[from:to] {
+		text += fmt.Sprintf("• %s · %s · до %s", subscription.PlanName, subscription.GetStatusText(c.Locale), subscription.ExpiresAt.Format("02.01.2006"))
+		if subscription.IsActive() {
+			text += fmt.Sprintf(" (%s)", c.N("units.days", subscription.GetDaysLeft()))
+		}
+		text += "\n"
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{
+				Text:         fmt.Sprintf("⏱ %s до %s", subscription.PlanName, subscription.ExpiresAt.Format("02.01.2006")),
+				CallbackData: "admin:usub_days:" + subscription.ID.String(),
+			},
+		})
+	}
+	if len(subscriptions) > 0 {
+		text += "\nНажмите на подписку, чтобы продлить или сократить ее."
+	}
+
+	prefix := fmt.Sprintf("admin:user_subscriptions:%d", targetUser.TelegramID)
+	if navigation := pageNavigation(prefix, page, len(subscriptions)); len(navigation) > 0 {
+		keyboardRows = append(keyboardRows, navigation)
+	}
+	keyboardRows = append(keyboardRows, h.userCardBackRow(targetUser.TelegramID))
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ShowUserPayments показывает платежи пользователя постранично. data — <telegram_id>:<страница>
+func (h *AdminHandler) ShowUserPayments(c *router.Context, data string) error {
+	telegramIDStr, page := parseCardPage(data)
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	payments, err := h.paymentService.GetUserPayments(targetUser.ID)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении платежей", h.userCardBackMenu(targetUser.TelegramID))
+	}
+
+	text := fmt.Sprintf("💳 Платежи: %s\n\n", targetUser.GetFullName())
+	if len(payments) == 0 {
+		text += "Платежей нет."
+	}
+
+	from, to := pageBounds(len(payments), page)
+	for _, payment := range payments[from:to] {
+		text += fmt.Sprintf("• %s · %.2f %s · %s · %s\n",
+			payment.CreatedAt.Format("02.01.2006 15:04"),
+			payment.Amount,
+			payment.Currency,
+			payment.GetPaymentMethodText(),
+			payment.GetStatusText(c.Locale),
+		)
+		if payment.Description != "" {
+			text += fmt.Sprintf("  %s\n", payment.Description)
+		}
+	}
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	prefix := fmt.Sprintf("admin:user_payments:%d", targetUser.TelegramID)
+	if navigation := pageNavigation(prefix, page, len(payments)); len(navigation) > 0 {
+		keyboardRows = append(keyboardRows, navigation)
+	}
+	keyboardRows = append(keyboardRows, h.userCardBackRow(targetUser.TelegramID))
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ShowUserReferrals показывает реферальное дерево пользователя на два уровня
+func (h *AdminHandler) ShowUserReferrals(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	referrals, err := h.userService.GetReferrals(targetUser.ID)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении рефералов", h.userCardBackMenu(targetUser.TelegramID))
+	}
+
+	text := fmt.Sprintf("👥 Рефералы: %s\n\n", targetUser.GetFullName())
+	if targetUser.ReferredBy != nil {
+		if referrer, err := h.userService.GetUserByID(*targetUser.ReferredBy); err == nil && referrer != nil {
+			text += fmt.Sprintf("🔗 Пригласил: %s (%d)\n\n", referrer.GetFullName(), referrer.TelegramID)
+		}
+	}
+
+	if len(referrals) == 0 {
+		text += "Пользователь никого не пригласил."
+		return h.send(c, text, h.userCardBackMenu(targetUser.TelegramID))
+	}
+
+	secondLevel := 0
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for i, referral := range referrals {
+		if i == userReferralsLimit {
+			text += fmt.Sprintf("… и еще %d\n", len(referrals)-userReferralsLimit)
+			break
+		}
+		subReferrals, _ := h.userService.GetReferrals(referral.ID)
+		secondLevel += len(subReferrals)
+
+		text += fmt.Sprintf("• %s (%d)", referral.GetFullName(), referral.TelegramID)
+		if len(subReferrals) > 0 {
+			text += fmt.Sprintf(" → пригласил %d", len(subReferrals))
+		}
+		text += "\n"
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: referral.GetFullName(), CallbackData: fmt.Sprintf("admin:user_info:%d", referral.TelegramID)},
+		})
+	}
+	text += fmt.Sprintf("\n1-й уровень: %d, 2-й уровень: %d", len(referrals), secondLevel)
+	if len(referrals) > userReferralsLimit {
+		text += " (2-й уровень — по показанным)"
+	}
+
+	keyboardRows = append(keyboardRows, h.userCardBackRow(targetUser.TelegramID))
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ToggleUserBlock блокирует или разблокирует пользователя из карточки
+func (h *AdminHandler) ToggleUserBlock(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+	if targetUser.ID == c.User.ID {
+		return h.showUserCard(c, targetUser, "❌ Нельзя заблокировать самого себя")
+	}
+
+	text, _ := h.applyBlock(c, targetUser, !targetUser.IsBlocked, "")
+	return h.showUserCard(c, targetUser, text)
+}
+
+// StartUserBalance запрашивает сумму и причину изменения баланса
+func (h *AdminHandler) StartUserBalance(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	if err := c.StartStep(StepUserBalance); err != nil {
+		return err
+	}
+	c.Session.Set("telegram_id", strconv.FormatInt(targetUser.TelegramID, 10))
+
+	text := fmt.Sprintf("💰 Баланс: %s\n\n", targetUser.GetFullName())
+	text += fmt.Sprintf("Текущий баланс: %.2f₽\n\n", targetUser.Balance)
+	text += "Отправьте сумму и причину, например:\n"
+	text += "500 компенсация за простой\n"
+	text += "-200 возврат ошибочного пополнения\n\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, fmt.Sprintf("admin:user_info:%d", targetUser.TelegramID)))
+}
+
+// HandleUserBalanceStep изменяет баланс на введенную сумму с причиной
+func (h *AdminHandler) HandleUserBalanceStep(c *router.Context) error {
+	if !h.can(c, models.PermBalanceWrite) {
+		return c.FinishStep()
+	}
+
+	amountStr, reason, _ := strings.Cut(strings.TrimSpace(c.Text()), " ")
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(amountStr, ",", "."), 64)
+	if err != nil || amount == 0 {
+		return h.send(c, "❌ Введите ненулевую сумму. Попробуйте еще раз или /cancel", nil)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return h.send(c, "❌ Укажите причину после суммы. Попробуйте еще раз или /cancel", nil)
+	}
+
+	targetUser, ok := h.cardUser(c.Session.Get("telegram_id"))
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	text, _ := h.changeBalance(c, targetUser, amount, reason)
+	return h.showUserCard(c, targetUser, text)
+}
+
+// StartSubscriptionDays запрашивает количество дней для изменения срока подписки
+func (h *AdminHandler) StartSubscriptionDays(c *router.Context, id string) error {
+	subscription, ok := h.cardSubscription(id)
+	if !ok {
+		return h.send(c, "❌ Подписка не найдена", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+	targetUser, err := h.userService.GetUserByID(subscription.UserID)
+	if err != nil || targetUser == nil {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	if err := c.StartStep(StepSubscriptionDays); err != nil {
+		return err
+	}
+	c.Session.Set("subscription_id", subscription.ID.String())
+	c.Session.Set("telegram_id", strconv.FormatInt(targetUser.TelegramID, 10))
+
+	text := fmt.Sprintf("⏱ Подписка %s: %s\n\n", subscription.PlanName, targetUser.GetFullName())
+	text += fmt.Sprintf("Действует до: %s\n\n", subscription.ExpiresAt.Format("02.01.2006 15:04"))
+	text += "Отправьте количество дней и причину, например:\n"
+	text += "7 компенсация\n"
+	text += "-3 ошибочное продление\n\n"
+	text += "Для отмены используйте /cancel"
+
+	back := fmt.Sprintf("admin:user_subscriptions:%d:0", targetUser.TelegramID)
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, back))
+}
+
+// HandleSubscriptionDaysStep продлевает или сокращает подписку
+func (h *AdminHandler) HandleSubscriptionDaysStep(c *router.Context) error {
+	if !h.can(c, models.PermSubscriptionsEdit) {
+		return c.FinishStep()
+	}
+
+	daysStr, reason, _ := strings.Cut(strings.TrimSpace(c.Text()), " ")
+	days, err := strconv.Atoi(strings.TrimPrefix(daysStr, "+"))
+	if err != nil || days == 0 {
+		return h.send(c, "❌ Введите ненулевое число дней. Попробуйте еще раз или /cancel", nil)
+	}
+
+	subscription, ok := h.cardSubscription(c.Session.Get("subscription_id"))
+	targetUser, userFound := h.cardUser(c.Session.Get("telegram_id"))
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	if !ok || !userFound {
+		return h.send(c, "❌ Подписка не найдена", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+	expiresBefore := subscription.ExpiresAt
+
+	updated, err := h.subscriptionService.AdjustDays(subscription.ID, days)
+	if err != nil {
+		return h.showUserCard(c, targetUser, "❌ Не удалось изменить срок подписки")
+	}
+
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditSubscriptionDays,
+		TargetUser: targetUser,
+		Before:     map[string]interface{}{"subscription_id": subscription.ID, "expires_at": expiresBefore},
+		After:      map[string]interface{}{"subscription_id": updated.ID, "expires_at": updated.ExpiresAt, "days": days},
+		Reason:     strings.TrimSpace(reason),
+	})
+
+	text := fmt.Sprintf("✅ Подписка %s действует до %s", updated.PlanName, updated.ExpiresAt.Format("02.01.2006 15:04"))
+	return h.showUserCard(c, targetUser, text)
+}
+
+// ShowGrantTariff показывает тарифы для выдачи пользователю
+func (h *AdminHandler) ShowGrantTariff(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	text := fmt.Sprintf("🎁 Выдача тарифа: %s\n\n", targetUser.GetFullName())
+	text += "Подписка будет создана без списания с баланса."
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, tariff := range models.Tariffs() {
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{
+				Text:         fmt.Sprintf("%s %s · %s", tariff.Emoji, tariff.Name, c.N("units.days", tariff.DurationDays)),
+				CallbackData: fmt.Sprintf("admin:user_grant:%d:%s", targetUser.TelegramID, tariff.Key),
+			},
+		})
+	}
+	keyboardRows = append(keyboardRows, h.userCardBackRow(targetUser.TelegramID))
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// GrantTariff выдает пользователю подписку по тарифу. data — <telegram_id>:<тариф>
+func (h *AdminHandler) GrantTariff(c *router.Context, data string) error {
+	telegramIDStr, tariffKey, _ := strings.Cut(data, ":")
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	tariff, ok := models.GetTariff(tariffKey)
+	if !ok {
+		return h.showUserCard(c, targetUser, "❌ Тариф не найден")
+	}
+
+	subscription, err := h.subscriptionService.GrantTariff(targetUser.ID, *tariff)
+	if err != nil {
+		return h.showUserCard(c, targetUser, "❌ Не удалось выдать тариф")
+	}
+
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditTariffGrant,
+		TargetUser: targetUser,
+		After: map[string]interface{}{
+			"subscription_id": subscription.ID,
+			"tariff":          tariff.Key,
+			"expires_at":      subscription.ExpiresAt,
+		},
+	})
+
+	text := fmt.Sprintf("✅ Выдан тариф %s до %s", tariff.Name, subscription.ExpiresAt.Format("02.01.2006"))
+	return h.showUserCard(c, targetUser, text)
+}
+
+// ResetUserTrial разрешает пользователю повторно получить пробный период
+func (h *AdminHandler) ResetUserTrial(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	removed, err := h.subscriptionService.ResetTrial(targetUser.ID)
+	if err != nil {
+		text := "❌ Не удалось сбросить пробный период"
+		if errors.Is(err, services.ErrTrialActive) || errors.Is(err, services.ErrTrialNotUsed) {
+			text = "❌ " + err.Error()
+		}
+		return h.showUserCard(c, targetUser, text)
+	}
+
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditTrialReset,
+		TargetUser: targetUser,
+		Before:     map[string]interface{}{"trial_used": true, "trial_subscriptions": removed},
+		After:      map[string]interface{}{"trial_used": false},
+	})
+
+	return h.showUserCard(c, targetUser, "✅ Пробный период сброшен")
+}
+
+// StartUserMessage запрашивает текст сообщения пользователю
+func (h *AdminHandler) StartUserMessage(c *router.Context, telegramIDStr string) error {
+	targetUser, ok := h.cardUser(telegramIDStr)
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	if err := c.StartStep(StepUserMessage); err != nil {
+		return err
+	}
+	c.Session.Set("telegram_id", strconv.FormatInt(targetUser.TelegramID, 10))
+
+	text := fmt.Sprintf("✉️ Сообщение для %s\n\n", targetUser.GetFullName())
+	text += "Отправьте текст, бот перешлет его пользователю от имени администрации.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, fmt.Sprintf("admin:user_info:%d", targetUser.TelegramID)))
+}
+
+// HandleUserMessageStep отправляет сообщение пользователю
+func (h *AdminHandler) HandleUserMessageStep(c *router.Context) error {
+	if !h.can(c, models.PermUserMessage) {
+		return c.FinishStep()
+	}
+
+	message := strings.TrimSpace(c.Text())
+	if message == "" {
+		return h.send(c, "❌ Сообщение не может быть пустым. Попробуйте еще раз или /cancel", nil)
+	}
+
+	targetUser, ok := h.cardUser(c.Session.Get("telegram_id"))
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	if !ok {
+		return h.send(c, "❌ Пользователь не найден", h.adminKeyboard.CreateUserManagementMenu(c.Locale))
+	}
+
+	if err := h.notificationService.SendToUser(targetUser.ID, "admin_message", "notifications.admin_message", "text", message); err != nil {
+		return h.showUserCard(c, targetUser, "❌ Не удалось отправить сообщение")
+	}
+
+	h.RecordAudit(c, services.AuditEntry{
+		Action:     models.AuditUserMessage,
+		TargetUser: targetUser,
+		After:      map[string]string{"message": message},
+	})
+
+	return h.showUserCard(c, targetUser, "✅ Сообщение отправлено")
+}
+
+// cardUser находит пользователя по Telegram ID из callback'а или сессии
+func (h *AdminHandler) cardUser(telegramIDStr string) (*models.User, bool) {
+	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	targetUser, err := h.userService.GetUser(telegramID)
+	if err != nil || targetUser == nil {
+		return nil, false
+	}
+	return targetUser, true
+}
+
+// cardSubscription получает подписку по строковому ID
+func (h *AdminHandler) cardSubscription(id string) (*models.Subscription, bool) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, false
+	}
+	subscription, err := h.subscriptionService.GetSubscription(subscriptionID)
+	if err != nil || subscription == nil {
+		return nil, false
+	}
+	return subscription, true
+}
+
+// userCardBackRow кнопка возврата к карточке пользователя
+func (h *AdminHandler) userCardBackRow(telegramID int64) []telego.InlineKeyboardButton {
+	return []telego.InlineKeyboardButton{
+		{Text: "🔙 К карточке", CallbackData: fmt.Sprintf("admin:user_info:%d", telegramID)},
+	}
+}
+
+// userCardBackMenu клавиатура возврата к карточке пользователя
+func (h *AdminHandler) userCardBackMenu(telegramID int64) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{h.userCardBackRow(telegramID)}}
+}
+
+// parseCardPage разбирает <telegram_id>:<страница>
+func parseCardPage(data string) (string, int) {
+	telegramIDStr, pageStr, _ := strings.Cut(data, ":")
+	page, _ := strconv.Atoi(pageStr)
+	if page < 0 {
+		page = 0
+	}
+	return telegramIDStr, page
+}
+
+// pageBounds возвращает границы страницы в списке из total элементов
+func pageBounds(total, page int) (int, int) {
+	from := page * userCardPageSize
+	if from > total {
+		from = total
+	}
+	to := from + userCardPageSize
+	if to > total {
+		to = total
+	}
+	return from, to
+}
+
+// pageNavigation кнопки перехода между страницами списка карточки
+func pageNavigation(prefix string, page, total int) []telego.InlineKeyboardButton {
+	var navigation []telego.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, telego.InlineKeyboardButton{Text: "◀️", CallbackData: fmt.Sprintf("%s:%d", prefix, page-1)})
+	}
+	if (page+1)*userCardPageSize < total {
+		navigation = append(navigation, telego.InlineKeyboardButton{Text: "▶️", CallbackData: fmt.Sprintf("%s:%d", prefix, page+1)})
+	}
+	return navigation
+}
+
+// activityText краткое описание записи журнала активности
+func activityText(entry models.ActivityLog) string {
+	if entry.Data == "" {
+		return entry.Action
+	}
+	return fmt.Sprintf("%s %s", entry.Action, auditValueText(entry.Data))
+}
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/mymmrac/telego"
+)
+
+// StepWithdrawalReject шаг ввода причины отклонения заявки на вывод
+const StepWithdrawalReject = "admin:wd_reject"
+
+// withdrawalQueuePageSize количество заявок на странице очереди
+const withdrawalQueuePageSize = 10
+
+// ShowWithdrawals показывает очередь заявок на вывод
+func (h *AdminHandler) ShowWithdrawals(c *router.Context) error {
+	requests, err := h.withdrawalService.GetQueue(withdrawalQueuePageSize, 0)
+	if err != nil {
+		return h.send(c, "❌ Ошибка при получении заявок", nil)
+	}
+	total, err := h.withdrawalService.CountQueue()
+	if err != nil {
+		total = int64(len(requests))
+	}
+
+	if len(requests) == 0 {
+		return h.send(c, "💸 Заявки на вывод\n\nОчередь пуста", nil)
+	}
+
+	text := "💸 Заявки на вывод\n\n"
+	text += fmt.Sprintf("В очереди: %d\n", total)
+	if total > int64(len(requests)) {
+		text += fmt.Sprintf("Показаны первые %d, самые старые сверху\n", len(requests))
+	}
+
+	var keyboardRows [][]telego.InlineKeyboardButton
+	for _, request := range requests {
+		label := fmt.Sprintf("%s · %.2f₽ · %s", request.User.GetFullName(), request.Amount, request.GetStatusText(c.Locale))
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: label, CallbackData: "admin:wd:" + request.ID.String()},
+		})
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 Назад", CallbackData: "admin:main"},
+	})
+
+	return h.send(c, text, &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows})
+}
+
+// ShowWithdrawal показывает карточку заявки с доступными действиями
+func (h *AdminHandler) ShowWithdrawal(c *router.Context, id string) error {
+	request, err := h.getWithdrawal(id)
+	if err != nil {
+		return h.send(c, "❌ Заявка не найдена", h.withdrawalBackMenu())
+	}
+
+	return h.send(c, withdrawalInfoText(request, c.Locale), h.withdrawalActionsMenu(request))
+}
+
+// ApproveWithdrawal одобряет заявку на вывод
+func (h *AdminHandler) ApproveWithdrawal(c *router.Context, id string) error {
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return h.send(c, "❌ Заявка не найдена", h.withdrawalBackMenu())
+	}
+
+	fromStatus := h.withdrawalStatus(requestID)
+	request, err := h.withdrawalService.Approve(requestID, c.User.ID)
+	if err != nil {
+		return h.sendWithdrawalError(c, err)
+	}
+	h.auditWithdrawal(c, models.AuditWithdrawalApprove, request, fromStatus, "")
+
+	return h.send(c, "✅ Заявка одобрена\n\n"+withdrawalInfoText(request, c.Locale), h.withdrawalActionsMenu(request))
+}
+
+// MarkWithdrawalPaid отмечает заявку выплаченной
+func (h *AdminHandler) MarkWithdrawalPaid(c *router.Context, id string) error {
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return h.send(c, "❌ Заявка не найдена", h.withdrawalBackMenu())
+	}
+
+	fromStatus := h.withdrawalStatus(requestID)
+	request, err := h.withdrawalService.MarkPaid(requestID, c.User.ID)
+	if err != nil {
+		return h.sendWithdrawalError(c, err)
+	}
+	h.auditWithdrawal(c, models.AuditWithdrawalPaid, request, fromStatus, "")
+
+	return h.send(c, "✅ Заявка отмечена выплаченной\n\n"+withdrawalInfoText(request, c.Locale), h.withdrawalActionsMenu(request))
+}
+
+// StartRejectWithdrawal запрашивает причину отклонения заявки
+func (h *AdminHandler) StartRejectWithdrawal(c *router.Context, id string) error {
+	request, err := h.getWithdrawal(id)
+	if err != nil {
+		return h.send(c, "❌ Заявка не найдена", h.withdrawalBackMenu())
+	}
+	if !request.IsFrozen() {
+		return h.sendWithdrawalError(c, services.ErrWithdrawalInvalidTransition)
+	}
+
+	// Начинаем новый диалог и сохраняем в нем ID заявки
+	if err := c.StartStep(StepWithdrawalReject); err != nil {
+		return err
+	}
+	c.Session.Set("request_id", request.ID.String())
+	if err := c.NextStep(StepWithdrawalReject); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("❌ Отклонение заявки на %.2f₽\n\n", request.Amount)
+	text += "Отправьте причину отклонения, ее увидит пользователь.\n"
+	text += "Для отмены используйте /cancel"
+
+	return h.send(c, text, h.adminKeyboard.CreateCancelMenu(c.Locale, "admin:wd:"+request.ID.String()))
+}
+
+// HandleWithdrawalRejectStep отклоняет заявку с введенной причиной
+func (h *AdminHandler) HandleWithdrawalRejectStep(c *router.Context) error {
+	if !h.can(c, models.PermWithdrawalsManage) {
+		return c.FinishStep()
+	}
+
+	reason := strings.TrimSpace(c.Text())
+	if reason == "" {
+		return h.send(c, "❌ Причина не может быть пустой. Попробуйте еще раз или /cancel", nil)
+	}
+
+	requestID, err := uuid.Parse(c.Session.Get("request_id"))
+	if err := c.FinishStep(); err != nil {
+		return err
+	}
+	if err != nil {
+		return h.send(c, "❌ Диалог поврежден, начните заново", h.withdrawalBackMenu())
+	}
+
+	fromStatus := h.withdrawalStatus(requestID)
+	request, err := h.withdrawalService.Reject(requestID, c.User.ID, reason)
+	if err != nil {
+		return h.sendWithdrawalError(c, err)
+	}
+	h.auditWithdrawal(c, models.AuditWithdrawalReject, request, fromStatus, reason)
+
+	return h.send(c, "✅ Заявка отклонена, средства разморожены\n\n"+withdrawalInfoText(request, c.Locale), h.withdrawalBackMenu())
+}
+
+// withdrawalStatus возвращает текущий статус заявки для журнала аудита
+func (h *AdminHandler) withdrawalStatus(requestID uuid.UUID) string {
+	request, err := h.withdrawalService.GetRequest(requestID)
+	if err != nil || request == nil {
+		return ""
+	}
+	return request.Status
+}
+
+// auditWithdrawal записывает обработку заявки на вывод в журнал аудита
+func (h *AdminHandler) auditWithdrawal(c *router.Context, action string, request *models.WithdrawalRequest, fromStatus, reason string) {
+	entry := services.AuditEntry{
+		Action:     action,
+		TargetType: "withdrawal",
+		TargetID:   request.ID.String(),
+		Before:     map[string]interface{}{"status": fromStatus, "amount": request.Amount},
+		After:      map[string]interface{}{"status": request.Status, "amount": request.Amount},
+		Reason:     reason,
+	}
+	if request.User.ID != uuid.Nil {
+		entry.TargetUser = &request.User
+	}
+	h.RecordAudit(c, entry)
+}
+
+// getWithdrawal получает заявку по строковому ID
+func (h *AdminHandler) getWithdrawal(id string) (*models.WithdrawalRequest, error) {
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return h.withdrawalService.GetRequest(requestID)
+}
+
+// sendWithdrawalError сообщает об ошибке обработки заявки
+func (h *AdminHandler) sendWithdrawalError(c *router.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrWithdrawalNotFound):
+		return h.send(c, "❌ Заявка не найдена", h.withdrawalBackMenu())
+	case errors.Is(err, services.ErrWithdrawalInvalidTransition):
+		return h.send(c, "⚠️ Заявка уже обработана другим администратором", h.withdrawalBackMenu())
+	default:
+		return h.send(c, "❌ Ошибка при обработке заявки", h.withdrawalBackMenu())
+	}
+}
+
+// withdrawalActionsMenu создает клавиатуру действий для заявки
+func (h *AdminHandler) withdrawalActionsMenu(request *models.WithdrawalRequest) *telego.InlineKeyboardMarkup {
+	id := request.ID.String()
+	var keyboardRows [][]telego.InlineKeyboardButton
+
+	switch request.Status {
+	case models.WithdrawalStatusPending:
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "✅ Одобрить", CallbackData: "admin:wd_approve:" + id},
+			{Text: "❌ Отклонить", CallbackData: "admin:wd_reject:" + id},
+		})
+	case models.WithdrawalStatusApproved:
+		keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+			{Text: "💸 Выплачено", CallbackData: "admin:wd_paid:" + id},
+			{Text: "❌ Отклонить", CallbackData: "admin:wd_reject:" + id},
+		})
+	}
+
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: "🔙 К заявкам", CallbackData: "admin:withdrawals"},
+	})
+
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
+}
+
+// withdrawalBackMenu создает клавиатуру возврата к очереди заявок
+func (h *AdminHandler) withdrawalBackMenu() *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: "🔙 К заявкам", CallbackData: "admin:withdrawals"}},
+	}}
+}
+
+// withdrawalInfoText форматирует карточку заявки на вывод
+func withdrawalInfoText(request *models.WithdrawalRequest, l *i18n.Localizer) string {
+	text := "💸 Заявка на вывод\n\n"
+	text += fmt.Sprintf("🆔 %s\n", request.ID)
+	text += fmt.Sprintf("👤 %s", request.User.GetFullName())
+	if request.User.Username != "" {
+		text += fmt.Sprintf(" (@%s)", request.User.Username)
+	}
+	if request.User.TelegramID != 0 {
+		text += fmt.Sprintf(", ID: %d", request.User.TelegramID)
+	}
+	text += "\n"
+	text += fmt.Sprintf("💰 Сумма: %.2f₽\n", request.Amount)
+	text += fmt.Sprintf("💳 Способ: %s\n", request.GetMethodText(l))
+	text += fmt.Sprintf("📝 Реквизиты: %s\n", request.Details)
+	text += fmt.Sprintf("📊 Статус: %s\n", request.GetStatusText(l))
+	text += fmt.Sprintf("📅 Создана: %s\n", request.CreatedAt.Format("02.01.2006 15:04"))
+	if request.Comment != "" {
+		text += fmt.Sprintf("💬 Комментарий: %s\n", request.Comment)
+	}
+
+	return text
+}
@@ -1,58 +1,103 @@
 package commands
 
 import (
-	"fmt"
 	"remnawave-tg-shop/internal/bot/keyboards"
-	"remnawave-tg-shop/internal/bot/utils"
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
-	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/logger"
 	"remnawave-tg-shop/internal/services"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"remnawave-tg-shop/internal/telegram"
+	"strings"
 )
 
 // StartHandler обрабатывает команду /start
 type StartHandler struct {
 	config              *config.Config
+	logger              logger.Logger
 	userService         services.UserService
 	subscriptionService services.SubscriptionService
+	referralService     services.IReferralService
+	partnerService      services.IPartnerService
+	giftService         services.IGiftService
 	keyboard            *keyboards.MainMenuKeyboard
 }
 
 // NewStartHandler создает новый StartHandler
 func NewStartHandler(
 	config *config.Config,
+	log logger.Logger,
 	userService services.UserService,
 	subscriptionService services.SubscriptionService,
+	referralService services.IReferralService,
+	partnerService services.IPartnerService,
+	giftService services.IGiftService,
 ) *StartHandler {
 	return &StartHandler{
 		config:              config,
+		logger:              log,
 		userService:         userService,
 		subscriptionService: subscriptionService,
+		referralService:     referralService,
+		partnerService:      partnerService,
+		giftService:         giftService,
 		keyboard:            keyboards.NewMainMenuKeyboard(config, subscriptionService),
 	}
 }
 
 // Handle обрабатывает команду /start
-func (h *StartHandler) Handle(message *tgbotapi.Message, user *models.User, args string) error {
-	// Обработка реферального кода
-	if args != "" {
-		referralUser, err := h.userService.GetUserByReferralCode(args)
-		if err == nil && referralUser != nil && referralUser.ID != user.ID {
-			user.ReferredBy = &referralUser.ID
-			h.userService.UpdateUser(user)
-			h.userService.AddBalance(referralUser.ID, 50)
+func (h *StartHandler) Handle(c *router.Context) error {
+	user := c.User
+
+	// Активация подарка по ссылке вида /start gift_<код>
+	if c.Command == "start" && strings.HasPrefix(c.Args, services.GiftStartPrefix) {
+		return h.redeemGift(c)
+	}
+
+	// Обработка реферальной ссылки вида /start ref_<код>
+	if c.Command == "start" && c.Args != "" {
+		if err := h.partnerService.TrackClick(c.Args, user.TelegramID); err != nil {
+			h.logger.Error("Failed to track referral click", "error", err)
+		}
+		if _, err := h.referralService.AttachReferrer(user, c.Args); err != nil {
+			h.logger.Info("Referral link ignored", "user_id", user.ID, "reason", err)
 		}
 	}
 
 	// Формируем приветствие с именем пользователя
-	username := user.GetDisplayName()
-	text := fmt.Sprintf("Привет, %s👋\n\n", username)
-	text += "Что бы вы хотели сделать?"
+	text := c.T("menu.greeting", "name", user.GetDisplayName()) + "\n\n"
+	text += c.T("menu.prompt")
 
 	// Создаем клавиатуру
-	keyboard := h.keyboard.Create(user)
+	keyboard := h.keyboard.Create(user, c.Locale)
 
 	// Отправляем сообщение
-	return utils.SendMessageWithTelegoKeyboard(message.Chat.ID, text, keyboard, h.config.BotToken)
+	return c.Respond(text, telegram.HTML(keyboard))
+}
+
+// redeemGift активирует подарочную подписку и показывает главное меню
+func (h *StartHandler) redeemGift(c *router.Context) error {
+	user := c.User
+
+	var text string
+	usage, err := h.giftService.RedeemGift(user, c.Args)
+	if err != nil {
+		reason := c.T("common.error")
+		if key := services.PromoCodeErrorKey(err); key != "" {
+			h.logger.Info("Gift redemption failed", "user_id", user.ID, "reason", err)
+			reason = c.T(key)
+		} else {
+			h.logger.Error("Failed to redeem gift", "user_id", user.ID, "error", err)
+		}
+		text = c.T("start.gift_failed", "reason", reason) + "\n\n"
+	} else {
+		text = c.T("start.gift_received") + "\n\n"
+		text += c.T("start.gift_tariff", "plan", usage.PromoCode.PlanName, "duration", c.N("units.days", int(usage.PromoCode.Value))) + "\n"
+		if usage.Subscription != nil {
+			text += c.T("start.gift_expires", "date", usage.Subscription.ExpiresAt.Format("02.01.2006")) + "\n"
+		}
+		text += "\n"
+	}
+	text += c.T("menu.prompt")
+
+	return c.Respond(text, telegram.HTML(h.keyboard.Create(user, c.Locale)))
 }
@@ -1,10 +1,8 @@
 package messages
 
 import (
+	"remnawave-tg-shop/internal/bot/router"
 	"remnawave-tg-shop/internal/config"
-	"remnawave-tg-shop/internal/models"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // TextHandler обрабатывает текстовые сообщения
@@ -19,9 +17,8 @@ func NewTextHandler(config *config.Config) *TextHandler {
 	}
 }
 
-// Handle обрабатывает текстовые сообщения
-func (h *TextHandler) Handle(message *tgbotapi.Message, user *models.User) error {
-	// Пока что просто логируем
-	// В будущем здесь можно добавить обработку промокодов, поиск и т.д.
+// Handle обрабатывает текстовые сообщения вне диалогов.
+// Текст внутри диалога роутер передает обработчику активного шага
+func (h *TextHandler) Handle(c *router.Context) error {
 	return nil
 }
@@ -1,9 +1,12 @@
 package keyboards
 
 import (
+	"fmt"
+
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/models"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mymmrac/telego"
 )
 
 // AdminMenuKeyboard создает клавиатуру админ-панели
@@ -15,207 +18,260 @@ func NewAdminMenuKeyboard() *AdminMenuKeyboard {
 }
 
 // CreateMainMenu создает главное меню админ-панели
-func (k *AdminMenuKeyboard) CreateMainMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateMainMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Статистика и пользователи
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статистика", "admin:stats"),
-		tgbotapi.NewInlineKeyboardButtonData("👥 Пользователи", "admin:users"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.stats"), CallbackData: "admin:stats"},
+		{Text: l.T("admin.menu.users"), CallbackData: "admin:users"},
 	})
 
 	// Управление пользователями
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔍 Найти пользователя", "admin:find_user"),
-		tgbotapi.NewInlineKeyboardButtonData("💰 Управление балансом", "admin:balance"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.find_user"), CallbackData: "admin:find_user"},
+		{Text: l.T("admin.menu.balance"), CallbackData: "admin:balance"},
 	})
 
 	// Промокоды и уведомления
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🎟️ Промокоды", "admin:promo"),
-		tgbotapi.NewInlineKeyboardButtonData("📢 Уведомления", "admin:notify"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.promo"), CallbackData: "admin:promo"},
+		{Text: l.T("admin.menu.notify"), CallbackData: "admin:notify"},
 	})
 
 	// Логи и настройки
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📋 Логи", "admin:logs"),
-		tgbotapi.NewInlineKeyboardButtonData("⚙️ Настройки", "admin:settings"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.logs"), CallbackData: "admin:logs"},
+		{Text: l.T("admin.menu.settings"), CallbackData: "admin:settings"},
+	})
+
+	// Заявки на вывод и роли сотрудников
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.withdrawals"), CallbackData: "admin:withdrawals"},
+		{Text: l.T("admin.menu.roles"), CallbackData: "admin:roles"},
+	})
+
+	// Выгрузки данных
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.export"), CallbackData: "admin:export"},
 	})
 
 	// Назад в главное меню
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "start"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.main_menu"), CallbackData: "start"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateUserManagementMenu создает меню управления пользователями
-func (k *AdminMenuKeyboard) CreateUserManagementMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateUserManagementMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Поиск и список
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск по ID", "admin:search_user_id"),
-		tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск по username", "admin:search_username"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.search_user_id"), CallbackData: "admin:search_user_id"},
+		{Text: l.T("admin.menu.search_username"), CallbackData: "admin:search_username"},
 	})
 
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📋 Список пользователей", "admin:list_users"),
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статистика пользователей", "admin:user_stats"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.list_users"), CallbackData: "admin:list_users"},
+		{Text: l.T("admin.menu.user_stats"), CallbackData: "admin:user_stats"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateUserActionsMenu создает меню действий с пользователем
-func (k *AdminMenuKeyboard) CreateUserActionsMenu(user *models.User) tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateUserActionsMenu(l *i18n.Localizer, user *models.User) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
+	id := user.TelegramID
 
 	// Основная информация
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("ℹ️ Информация", "admin:user_info"),
-		tgbotapi.NewInlineKeyboardButtonData("💰 Баланс", "admin:user_balance"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.user_info"), CallbackData: fmt.Sprintf("admin:user_info:%d", id)},
+		{Text: l.T("admin.menu.user_balance"), CallbackData: fmt.Sprintf("admin:user_balance:%d", id)},
+	})
+
+	// Подписки и платежи
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.user_subscriptions"), CallbackData: fmt.Sprintf("admin:user_subscriptions:%d:0", id)},
+		{Text: l.T("admin.menu.user_payments"), CallbackData: fmt.Sprintf("admin:user_payments:%d:0", id)},
+	})
+
+	// Тарифы и пробный период
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.user_tariff"), CallbackData: fmt.Sprintf("admin:user_tariff:%d", id)},
+		{Text: l.T("admin.menu.user_trial_reset"), CallbackData: fmt.Sprintf("admin:user_trial_reset:%d", id)},
+	})
+
+	// Рефералы и сообщение
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.user_referrals"), CallbackData: fmt.Sprintf("admin:user_referrals:%d", id)},
+		{Text: l.T("admin.menu.user_message"), CallbackData: fmt.Sprintf("admin:user_message:%d", id)},
 	})
 
 	// Блокировка/разблокировка
-	blockText := "✅ Разблокировать"
+	blockText := l.T("admin.menu.unblock")
 	if !user.IsBlocked {
-		blockText = "🚫 Заблокировать"
+		blockText = l.T("admin.menu.block")
 	}
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData(blockText, "admin:toggle_block"),
-	})
-
-	// Подписки и платежи
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔒 Подписки", "admin:user_subscriptions"),
-		tgbotapi.NewInlineKeyboardButtonData("💳 Платежи", "admin:user_payments"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: blockText, CallbackData: fmt.Sprintf("admin:toggle_block:%d", id)},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:users"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:users"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreatePromoCodeMenu создает меню управления промокодами
-func (k *AdminMenuKeyboard) CreatePromoCodeMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreatePromoCodeMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Создание и просмотр
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("➕ Создать промокод", "admin:promo_create"),
-		tgbotapi.NewInlineKeyboardButtonData("📋 Список промокодов", "admin:promo_list"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.promo_create"), CallbackData: "admin:promo_create"},
+		{Text: l.T("admin.menu.promo_list"), CallbackData: "admin:promo_list"},
 	})
 
-	// Статистика
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статистика промокодов", "admin:promo_stats"),
+	// Пакеты и статистика кампаний
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.promo_batch"), CallbackData: "admin:promo_batch"},
+		{Text: l.T("admin.menu.promo_stats"), CallbackData: "admin:promo_stats"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateBalanceMenu создает меню управления балансом
-func (k *AdminMenuKeyboard) CreateBalanceMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateBalanceMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Операции с балансом
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("➕ Пополнить", "admin:balance_add"),
-		tgbotapi.NewInlineKeyboardButtonData("➖ Списать", "admin:balance_subtract"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.balance_add"), CallbackData: "admin:balance_add"},
+		{Text: l.T("admin.menu.balance_subtract"), CallbackData: "admin:balance_subtract"},
 	})
 
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔢 Установить сумму", "admin:balance_set"),
-		tgbotapi.NewInlineKeyboardButtonData("📊 История операций", "admin:balance_history"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.balance_set"), CallbackData: "admin:balance_set"},
+		{Text: l.T("admin.menu.balance_history"), CallbackData: "admin:balance_history"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateNotificationMenu создает меню уведомлений
-func (k *AdminMenuKeyboard) CreateNotificationMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateNotificationMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Типы уведомлений
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📢 Всем пользователям", "admin:notify_all"),
-		tgbotapi.NewInlineKeyboardButtonData("👤 Конкретному пользователю", "admin:notify_user"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.notify_all"), CallbackData: "admin:notify_all"},
+		{Text: l.T("admin.menu.notify_user"), CallbackData: "admin:notify_user"},
 	})
 
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статистика уведомлений", "admin:notify_stats"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.notify_stats"), CallbackData: "admin:notify_stats"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateLogsMenu создает меню логов
-func (k *AdminMenuKeyboard) CreateLogsMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateLogsMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Типы логов
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📋 Все логи", "admin:logs_all"),
-		tgbotapi.NewInlineKeyboardButtonData("👤 Логи пользователя", "admin:logs_user"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.logs_all"), CallbackData: "admin:logs_all"},
+		{Text: l.T("admin.menu.logs_user"), CallbackData: "admin:logs_user"},
 	})
 
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск по действию", "admin:logs_search"),
-		tgbotapi.NewInlineKeyboardButtonData("📊 Статистика логов", "admin:logs_stats"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.logs_search"), CallbackData: "admin:logs_search"},
+		{Text: l.T("admin.menu.logs_stats"), CallbackData: "admin:logs_stats"},
+	})
+
+	// Журнал действий администраторов
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.audit"), CallbackData: "admin:audit:all:0"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
 }
 
 // CreateSettingsMenu создает меню настроек
-func (k *AdminMenuKeyboard) CreateSettingsMenu() tgbotapi.InlineKeyboardMarkup {
-	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+func (k *AdminMenuKeyboard) CreateSettingsMenu(l *i18n.Localizer, maintenance bool) *telego.InlineKeyboardMarkup {
+	var keyboardRows [][]telego.InlineKeyboardButton
+
+	// Режим технических работ
+	maintenanceText := l.T("admin.menu.maintenance_on")
+	if maintenance {
+		maintenanceText = l.T("admin.menu.maintenance_off")
+	}
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: maintenanceText, CallbackData: "admin:maintenance_toggle"},
+	})
 
 	// Настройки бота
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🤖 Настройки бота", "admin:settings_bot"),
-		tgbotapi.NewInlineKeyboardButtonData("💳 Платежные системы", "admin:settings_payments"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.settings_bot"), CallbackData: "admin:settings_bot"},
+		{Text: l.T("admin.menu.settings_payments"), CallbackData: "admin:settings_payments"},
 	})
 
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🎟️ Настройки промокодов", "admin:settings_promo"),
-		tgbotapi.NewInlineKeyboardButtonData("📢 Настройки уведомлений", "admin:settings_notify"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.settings_promo"), CallbackData: "admin:settings_promo"},
+		{Text: l.T("admin.menu.settings_notify"), CallbackData: "admin:settings_notify"},
 	})
 
 	// Назад
-	keyboardRows = append(keyboardRows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "admin:main"),
+	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
+		{Text: l.T("admin.menu.back"), CallbackData: "admin:main"},
 	})
 
-	return tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: keyboardRows}
+}
+
+// CreateBackMenu создает клавиатуру возврата в админ-панель
+func (k *AdminMenuKeyboard) CreateBackMenu(l *i18n.Localizer) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: l.T("admin.menu.back"), CallbackData: "admin:main"}},
+	}}
+}
+
+// CreateCancelMenu создает клавиатуру отмены диалога с возвратом в раздел
+func (k *AdminMenuKeyboard) CreateCancelMenu(l *i18n.Localizer, backData string) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{
+		{{Text: l.T("admin.menu.cancel"), CallbackData: backData}},
+	}}
 }
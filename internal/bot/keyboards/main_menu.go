@@ -1,8 +1,8 @@
 package keyboards
 
 import (
-	"fmt"
 	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/services"
 
@@ -23,12 +23,12 @@ func NewMainMenuKeyboard(config *config.Config, subscriptionService services.Sub
 	}
 }
 
-// Create создает клавиатуру главного меню
-func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarkup {
+// Create создает клавиатуру главного меню на языке пользователя
+func (k *MainMenuKeyboard) Create(user *models.User, l *i18n.Localizer) *telego.InlineKeyboardMarkup {
 	var keyboardRows [][]telego.InlineKeyboardButton
 
 	// Баланс
-	balanceText := fmt.Sprintf("💰 Баланс %.0f₽", user.Balance)
+	balanceText := l.T("menu.balance", "balance", user.Balance)
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
 			Text:         balanceText,
@@ -36,21 +36,25 @@ func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarku
 		},
 	})
 
-	// Купить
+	// Купить и Подарить
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
-			Text:         "🚀 Купить",
+			Text:         l.T("menu.buy"),
 			CallbackData: "buy_subscription",
 		},
+		{
+			Text:         l.T("menu.gift"),
+			CallbackData: "gift",
+		},
 	})
 
 	// Пробный период (если включен и пользователь еще не использовал)
-	if k.config.Trial.Enabled {
+	if k.config.Settings().Trial.Enabled {
 		hasUsedTrial, err := k.subscriptionService.HasUsedTrial(user.ID)
 		if err == nil && !hasUsedTrial {
 			keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 				{
-					Text:         "🎁 Пробный период",
+					Text:         l.T("menu.trial"),
 					CallbackData: "trial",
 				},
 			})
@@ -60,7 +64,7 @@ func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarku
 	// Моя подписка - Mini App кнопка
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
-			Text: "🔒 Моя подписка",
+			Text: l.T("menu.subscription"),
 			WebApp: &telego.WebAppInfo{
 				URL: k.config.MiniApp.URL,
 			},
@@ -70,11 +74,11 @@ func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarku
 	// Рефералы и Промокод
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
-			Text:         "🎁 Рефералы",
+			Text:         l.T("menu.referrals"),
 			CallbackData: "referrals",
 		},
 		{
-			Text:         "🎟️ Промокод",
+			Text:         l.T("menu.promo_code"),
 			CallbackData: "promo_code:menu",
 		},
 	})
@@ -82,11 +86,11 @@ func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarku
 	// Язык и Статус
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
-			Text:         "🌐 Язык",
+			Text:         l.T("menu.language"),
 			CallbackData: "language",
 		},
 		{
-			Text:         "📊 Статус",
+			Text:         l.T("menu.status"),
 			CallbackData: "status",
 		},
 	})
@@ -94,7 +98,7 @@ func (k *MainMenuKeyboard) Create(user *models.User) *telego.InlineKeyboardMarku
 	// Поддержка
 	keyboardRows = append(keyboardRows, []telego.InlineKeyboardButton{
 		{
-			Text:         "🆘 Поддержка",
+			Text:         l.T("menu.support"),
 			CallbackData: "support",
 		},
 	})
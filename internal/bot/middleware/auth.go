@@ -1,58 +1,61 @@
 package middleware
 
 import (
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/logger"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/services"
-
-	"gopkg.in/telebot.v3"
 )
 
 // AuthMiddleware создает middleware для аутентификации
 type AuthMiddleware struct {
 	userService services.UserService
+	bundle      *i18n.Bundle
 	logger      logger.Logger
 }
 
 // NewAuthMiddleware создает новый AuthMiddleware
-func NewAuthMiddleware(userService services.UserService, logger logger.Logger) *AuthMiddleware {
+func NewAuthMiddleware(userService services.UserService, bundle *i18n.Bundle, logger logger.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
 		userService: userService,
+		bundle:      bundle,
 		logger:      logger,
 	}
 }
 
 // Handle обрабатывает middleware для аутентификации
-func (m *AuthMiddleware) Handle(next telebot.HandlerFunc) telebot.HandlerFunc {
-	return func(c telebot.Context) error {
-		// Логируем входящее сообщение
-		if c.Message() != nil {
-			m.logger.Info("Received message", 
-				"chat_id", c.Message().Chat.ID, 
-				"user_id", c.Message().Sender.ID,
-				"text", c.Message().Text)
+func (m *AuthMiddleware) Handle(next router.HandlerFunc) router.HandlerFunc {
+	return func(c *router.Context) error {
+		// Логируем входящее обновление
+		switch {
+		case c.Message != nil:
+			m.logger.Info("Handling message", "chat_id", c.ChatID(), "text", c.Message.Text)
+		case c.Callback != nil:
+			m.logger.Info("Handling callback query", "chat_id", c.ChatID(), "data", c.Data)
 		}
 
-		// Получаем пользователя из контекста
-		user := m.getUserFromContext(c)
+		// Получаем пользователя
+		user := m.getUser(c)
 		if user == nil {
-			return c.Send("❌ Ошибка получения данных пользователя")
+			locale := m.bundle.Default()
+			if from := c.From(); from != nil {
+				locale = m.bundle.For(from.LanguageCode)
+			}
+			return c.Send(locale.T("common.user_error"), nil)
 		}
 
-		// Сохраняем пользователя в контексте
-		c.Set("user", user)
+		// Сохраняем пользователя и его язык в контексте
+		c.User = user
+		c.Locale = m.bundle.For(user.PreferredLanguages()...)
 
 		return next(c)
 	}
 }
 
-// getUserFromContext извлекает пользователя из контекста
-func (m *AuthMiddleware) getUserFromContext(c telebot.Context) *models.User {
-	if c.Message() == nil {
-		return nil
-	}
-
-	from := c.Message().Sender
+// getUser получает или создает пользователя отправителя обновления
+func (m *AuthMiddleware) getUser(c *router.Context) *models.User {
+	from := c.From()
 	if from == nil {
 		return nil
 	}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"remnawave-tg-shop/internal/bot/router"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/services"
+)
+
+// MaintenanceMiddleware закрывает бот для пользователей на время технических работ
+type MaintenanceMiddleware struct {
+	maintenanceService services.IMaintenanceService
+	userService        services.UserService
+	bundle             *i18n.Bundle
+}
+
+// NewMaintenanceMiddleware создает новый MaintenanceMiddleware
+func NewMaintenanceMiddleware(maintenanceService services.IMaintenanceService, userService services.UserService, bundle *i18n.Bundle) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		maintenanceService: maintenanceService,
+		userService:        userService,
+		bundle:             bundle,
+	}
+}
+
+// Handle пропускает администраторов, остальным отвечает сообщением о работах.
+// Стоит перед аутентификацией, чтобы отвечать и при недоступной базе данных:
+// администраторы из ADMIN_TELEGRAM_IDS проверяются без обращения к ней
+func (m *MaintenanceMiddleware) Handle(next router.HandlerFunc) router.HandlerFunc {
+	return func(c *router.Context) error {
+		if !m.maintenanceService.IsEnabled() {
+			return next(c)
+		}
+
+		from := c.From()
+		if from == nil || m.userService.IsAdmin(from.ID) {
+			return next(c)
+		}
+		// Звезды уже списаны Telegram, поэтому оплату зачисляем и во время работ
+		if c.Message != nil && c.Message.SuccessfulPayment != nil {
+			return next(c)
+		}
+
+		text := m.maintenanceService.Message(m.bundle.For(from.LanguageCode).T("maintenance.message"))
+		if c.Callback != nil {
+			return c.Answer(text, true)
+		}
+		// Запрос оплаты приходит без чата, и Telegram ждет ответа на него
+		if query := c.Update.PreCheckoutQuery; query != nil {
+			return c.Messenger.AnswerPreCheckoutQuery(query.ID, false, text)
+		}
+		return c.Send(text, nil)
+	}
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"time"
+
+	"remnawave-tg-shop/internal/bot/fsm"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// Context контекст обработки одного обновления
+type Context struct {
+	Update    telego.Update
+	Message   *telego.Message
+	Callback  *telego.CallbackQuery
+	User      *models.User
+	Messenger telegram.Messenger
+	// Locale переводчик на язык пользователя, заполняется middleware
+	Locale *i18n.Localizer
+
+	// Command команда без слеша (только для команд)
+	Command string
+	// Args аргументы команды или часть callback data после префикса
+	Args string
+	// Data полные данные callback'а
+	Data string
+	// Session активный диалог пользователя или nil
+	Session *fsm.Session
+
+	sessions       fsm.Store
+	sessionTimeout time.Duration
+	answered       bool
+}
+
+// newContext создает контекст для обновления
+func newContext(update telego.Update, messenger telegram.Messenger) *Context {
+	c := &Context{
+		Update:    update,
+		Messenger: messenger,
+	}
+
+	switch {
+	case update.Message != nil:
+		c.Message = update.Message
+		c.Command, c.Args = parseCommand(update.Message.Text)
+	case update.CallbackQuery != nil:
+		c.Callback = update.CallbackQuery
+		c.Data = update.CallbackQuery.Data
+	}
+
+	return c
+}
+
+// From возвращает отправителя обновления
+func (c *Context) From() *telego.User {
+	switch {
+	case c.Message != nil:
+		return c.Message.From
+	case c.Callback != nil:
+		return &c.Callback.From
+	case c.Update.PreCheckoutQuery != nil:
+		return &c.Update.PreCheckoutQuery.From
+	}
+	return nil
+}
+
+// ChatID возвращает идентификатор чата обновления
+func (c *Context) ChatID() int64 {
+	return ChatID(c.Update)
+}
+
+// T переводит ключ каталога на язык пользователя
+func (c *Context) T(key string, args ...any) string {
+	return c.Locale.T(key, args...)
+}
+
+// N переводит ключ с множественными формами на язык пользователя
+func (c *Context) N(key string, count int, args ...any) string {
+	return c.Locale.N(key, count, args...)
+}
+
+// Text возвращает текст сообщения
+func (c *Context) Text() string {
+	if c.Message == nil {
+		return ""
+	}
+	return c.Message.Text
+}
+
+// Send отправляет новое сообщение в чат обновления
+func (c *Context) Send(text string, opts *telegram.MessageOptions) error {
+	_, err := c.Messenger.SendMessage(c.ChatID(), text, opts)
+	return err
+}
+
+// SendDocument отправляет документ в чат обновления
+func (c *Context) SendDocument(document telego.InputFile, caption string, opts *telegram.MessageOptions) error {
+	_, err := c.Messenger.SendDocument(c.ChatID(), document, caption, opts)
+	return err
+}
+
+// RespondPhoto отвечает на обновление фотографией: для callback'а заменяет
+// фотографию исходного сообщения, а если это невозможно — отправляет новую
+func (c *Context) RespondPhoto(photo telego.InputFile, caption string, opts *telegram.MessageOptions) error {
+	if messageID := c.callbackMessageID(); messageID != 0 {
+		_, err := c.Messenger.EditMessagePhoto(c.ChatID(), messageID, photo, caption, opts)
+		if err == nil || telegram.IsMessageNotModified(err) {
+			return nil
+		}
+	}
+
+	_, err := c.Messenger.SendPhoto(c.ChatID(), photo, caption, opts)
+	return err
+}
+
+// Respond отвечает на обновление: для callback'а редактирует исходное
+// сообщение, а если это невозможно или это не callback — отправляет новое
+func (c *Context) Respond(text string, opts *telegram.MessageOptions) error {
+	if messageID := c.callbackMessageID(); messageID != 0 {
+		_, err := c.Messenger.EditMessageText(c.ChatID(), messageID, text, opts)
+		if err == nil || telegram.IsMessageNotModified(err) {
+			return nil
+		}
+	}
+
+	return c.Send(text, opts)
+}
+
+// EditKeyboard заменяет клавиатуру исходного сообщения callback'а.
+// Если редактирование невозможно, отправляет текст новым сообщением
+func (c *Context) EditKeyboard(text string, keyboard *telego.InlineKeyboardMarkup) error {
+	if messageID := c.callbackMessageID(); messageID != 0 {
+		err := c.Messenger.EditMessageReplyMarkup(c.ChatID(), messageID, keyboard)
+		if err == nil || telegram.IsMessageNotModified(err) {
+			return nil
+		}
+	}
+
+	return c.Send(text, telegram.Plain(keyboard))
+}
+
+// Answer отвечает на callback query. Повторные вызовы ничего не делают
+func (c *Context) Answer(text string, showAlert bool) error {
+	if c.Callback == nil || c.answered {
+		return nil
+	}
+
+	c.answered = true
+	return c.Messenger.AnswerCallbackQuery(c.Callback.ID, text, showAlert)
+}
+
+// StartStep начинает новый диалог с указанного шага
+func (c *Context) StartStep(step string) error {
+	from := c.From()
+	if c.sessions == nil || from == nil {
+		return errSessionsDisabled
+	}
+
+	c.Session = fsm.NewSession(from.ID, step, c.sessionTimeout)
+	return c.sessions.Save(c.Session)
+}
+
+// NextStep переводит активный диалог на следующий шаг, сохраняя собранные данные
+func (c *Context) NextStep(step string) error {
+	if c.Session == nil {
+		return c.StartStep(step)
+	}
+
+	c.Session.Next(step, c.sessionTimeout)
+	return c.sessions.Save(c.Session)
+}
+
+// FinishStep завершает активный диалог
+func (c *Context) FinishStep() error {
+	if c.Session == nil {
+		return nil
+	}
+
+	c.Session = nil
+	from := c.From()
+	if c.sessions == nil || from == nil {
+		return nil
+	}
+	return c.sessions.Delete(from.ID)
+}
+
+// callbackMessageID возвращает ID редактируемого сообщения callback'а или 0
+func (c *Context) callbackMessageID() int {
+	if c.Callback == nil || c.Callback.Message == nil || !c.Callback.Message.IsAccessible() {
+		return 0
+	}
+	return c.Callback.Message.GetMessageID()
+}
+
+// ChatID возвращает идентификатор чата для обновления или 0
+func ChatID(update telego.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		if update.CallbackQuery.Message != nil {
+			return update.CallbackQuery.Message.GetChat().ID
+		}
+		return update.CallbackQuery.From.ID
+	case update.PreCheckoutQuery != nil:
+		return update.PreCheckoutQuery.From.ID
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/fsm"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+)
+
+// errSessionsDisabled возвращается при попытке начать диалог без хранилища сессий
+var errSessionsDisabled = errors.New("dialog sessions are not configured")
+
+// HandlerFunc единая сигнатура обработчика обновлений
+type HandlerFunc func(c *Context) error
+
+// Middleware оборачивает обработчик
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// prefixRoute маршрут callback'а по префиксу
+type prefixRoute struct {
+	prefix  string
+	handler HandlerFunc
+}
+
+// Router маршрутизирует обновления Telegram на обработчики
+type Router struct {
+	messenger   telegram.Messenger
+	logger      logger.Logger
+	middlewares []Middleware
+
+	commands       map[string]HandlerFunc
+	callbacks      map[string]HandlerFunc
+	prefixes       []prefixRoute
+	steps          map[string]HandlerFunc
+	text           HandlerFunc
+	unknownCommand HandlerFunc
+
+	preCheckout       HandlerFunc
+	successfulPayment HandlerFunc
+
+	sessions       fsm.Store
+	sessionTimeout time.Duration
+}
+
+// New создает новый Router
+func New(messenger telegram.Messenger, log logger.Logger) *Router {
+	return &Router{
+		messenger: messenger,
+		logger:    log,
+		commands:  make(map[string]HandlerFunc),
+		callbacks: make(map[string]HandlerFunc),
+		steps:     make(map[string]HandlerFunc),
+	}
+}
+
+// Sessions подключает хранилище диалогов с указанным временем жизни
+func (r *Router) Sessions(store fsm.Store, timeout time.Duration) {
+	r.sessions = store
+	r.sessionTimeout = timeout
+}
+
+// Use добавляет middleware ко всем маршрутам
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Command регистрирует обработчик команды без слеша
+func (r *Router) Command(name string, handler HandlerFunc) {
+	r.commands[name] = handler
+}
+
+// Callback регистрирует обработчик callback'а с точным совпадением данных
+func (r *Router) Callback(data string, handler HandlerFunc) {
+	r.callbacks[data] = handler
+}
+
+// CallbackPrefix регистрирует обработчик callback'ов по префиксу
+func (r *Router) CallbackPrefix(prefix string, handler HandlerFunc) {
+	r.prefixes = append(r.prefixes, prefixRoute{prefix: prefix, handler: handler})
+}
+
+// Text регистрирует обработчик обычных текстовых сообщений
+func (r *Router) Text(handler HandlerFunc) {
+	r.text = handler
+}
+
+// Step регистрирует обработчик текста, введенного на шаге диалога
+func (r *Router) Step(step string, handler HandlerFunc) {
+	r.steps[step] = handler
+}
+
+// UnknownCommand регистрирует обработчик неизвестных команд
+func (r *Router) UnknownCommand(handler HandlerFunc) {
+	r.unknownCommand = handler
+}
+
+// PreCheckout регистрирует обработчик подтверждения оплаты перед списанием
+func (r *Router) PreCheckout(handler HandlerFunc) {
+	r.preCheckout = handler
+}
+
+// SuccessfulPayment регистрирует обработчик сообщений об успешной оплате счета
+func (r *Router) SuccessfulPayment(handler HandlerFunc) {
+	r.successfulPayment = handler
+}
+
+// HandleUpdate обрабатывает одно обновление
+func (r *Router) HandleUpdate(update telego.Update) error {
+	c := newContext(update, r.messenger)
+	c.sessions = r.sessions
+	c.sessionTimeout = r.sessionTimeout
+
+	// На callback всегда отвечаем, чтобы убрать индикатор загрузки с кнопки
+	defer func() {
+		if err := c.Answer("", false); err != nil {
+			r.logger.Error("Failed to answer callback query", "error", err)
+		}
+	}()
+
+	if err := r.loadSession(c); err != nil {
+		r.logger.Error("Failed to load dialog session", "error", err)
+	}
+
+	start := time.Now()
+	name, handler := r.match(c)
+	if handler == nil {
+		metrics.ObserveUpdate(updateType(update), unhandled, time.Since(start), nil)
+		return nil
+	}
+
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+
+	err := handler(c)
+	metrics.ObserveUpdate(updateType(update), name, time.Since(start), err)
+	return err
+}
+
+// unhandled имя маршрута в метриках для обновлений без обработчика
+const unhandled = "none"
+
+// match подбирает обработчик для контекста и возвращает имя маршрута для метрик.
+// Имя не содержит данных пользователя, чтобы число рядов метрик было ограничено
+func (r *Router) match(c *Context) (string, HandlerFunc) {
+	switch {
+	case c.Update.PreCheckoutQuery != nil:
+		return "pre_checkout", r.preCheckout
+	case c.Message != nil && c.Message.SuccessfulPayment != nil:
+		return "successful_payment", r.successfulPayment
+	case c.Message != nil:
+		if c.Command != "" {
+			if handler, ok := r.commands[c.Command]; ok {
+				return "command:" + c.Command, handler
+			}
+			return "unknown_command", r.unknownCommand
+		}
+		if c.Session != nil {
+			if handler, ok := r.steps[c.Session.Step]; ok {
+				return "step:" + c.Session.Step, handler
+			}
+			r.interruptSession(c)
+		}
+		return "text", r.text
+	case c.Callback != nil:
+		if handler, ok := r.callbacks[c.Data]; ok {
+			return "callback:" + c.Data, handler
+		}
+		for _, route := range r.prefixes {
+			if strings.HasPrefix(c.Data, route.prefix) {
+				c.Args = strings.TrimPrefix(c.Data, route.prefix)
+				return "callback:" + route.prefix + "*", route.handler
+			}
+		}
+		r.logger.Info("Unknown callback data", "data", c.Data)
+	}
+	return unhandled, nil
+}
+
+// updateType тип обновления для метрик
+func updateType(update telego.Update) string {
+	switch {
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.PreCheckoutQuery != nil:
+		return "pre_checkout_query"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	default:
+		return "other"
+	}
+}
+
+// loadSession загружает активный диалог пользователя. Команды и нажатия
+// кнопок прерывают диалог: обработчик при необходимости начнет новый,
+// а c.Session остается доступной, чтобы /cancel знал, что было прервано
+func (r *Router) loadSession(c *Context) error {
+	from := c.From()
+	if r.sessions == nil || from == nil || (c.Message == nil && c.Callback == nil) {
+		return nil
+	}
+
+	session, err := r.sessions.Get(from.ID)
+	if err != nil || session == nil {
+		return err
+	}
+	c.Session = session
+
+	if c.Command != "" || c.Callback != nil {
+		r.interruptSession(c)
+	}
+	return nil
+}
+
+// interruptSession удаляет диалог из хранилища
+func (r *Router) interruptSession(c *Context) {
+	if err := r.sessions.Delete(c.Session.UserID); err != nil {
+		r.logger.Error("Failed to interrupt dialog session", "error", err)
+	}
+}
+
+// parseCommand выделяет команду и аргументы из текста сообщения
+func parseCommand(text string) (string, string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+
+	command, args, _ := strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return command, strings.TrimSpace(args)
+}
@@ -0,0 +1,237 @@
+package router
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/bot/fsm"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messageUpdate(text string) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		Chat: telego.Chat{ID: 10},
+		From: &telego.User{ID: 10},
+		Text: text,
+	}}
+}
+
+func callbackUpdate(data string) telego.Update {
+	return telego.Update{CallbackQuery: &telego.CallbackQuery{
+		ID:      "cb",
+		From:    telego.User{ID: 10},
+		Message: &telego.Message{MessageID: 5, Chat: telego.Chat{ID: 10}},
+		Data:    data,
+	}}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		command string
+		args    string
+	}{
+		{"/start", "start", ""},
+		{"/start ref_abc", "start", "ref_abc"},
+		{"/admin@shop_bot balance 1 100", "admin", "balance 1 100"},
+		{"hello", "", ""},
+	}
+
+	for _, tt := range tests {
+		command, args := parseCommand(tt.text)
+		assert.Equal(t, tt.command, command, tt.text)
+		assert.Equal(t, tt.args, args, tt.text)
+	}
+}
+
+func TestRouter_HandleUpdate(t *testing.T) {
+	r := New(telegram.NewFakeMessenger(), logger.New("error"))
+
+	var called []string
+	r.Command("start", func(c *Context) error {
+		called = append(called, "start:"+c.Args)
+		return nil
+	})
+	r.UnknownCommand(func(c *Context) error {
+		called = append(called, "unknown:"+c.Command)
+		return nil
+	})
+	r.Text(func(c *Context) error {
+		called = append(called, "text:"+c.Text())
+		return nil
+	})
+	r.Callback("balance", func(c *Context) error {
+		called = append(called, "balance")
+		return nil
+	})
+	r.CallbackPrefix("admin:", func(c *Context) error {
+		called = append(called, "admin:"+c.Args)
+		return nil
+	})
+
+	require.NoError(t, r.HandleUpdate(messageUpdate("/start ref_abc")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("/nope")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("hi")))
+	require.NoError(t, r.HandleUpdate(callbackUpdate("balance")))
+	require.NoError(t, r.HandleUpdate(callbackUpdate("admin:stats")))
+	require.NoError(t, r.HandleUpdate(callbackUpdate("unknown")))
+
+	assert.Equal(t, []string{"start:ref_abc", "unknown:nope", "text:hi", "balance", "admin:stats"}, called)
+}
+
+func TestRouter_Middleware(t *testing.T) {
+	r := New(telegram.NewFakeMessenger(), logger.New("error"))
+
+	var order []string
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			order = append(order, "outer")
+			return next(c)
+		}
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			order = append(order, "inner")
+			return next(c)
+		}
+	})
+	r.Command("help", func(c *Context) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	require.NoError(t, r.HandleUpdate(messageUpdate("/help")))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestChatID(t *testing.T) {
+	assert.Equal(t, int64(10), ChatID(messageUpdate("hi")))
+	assert.Equal(t, int64(10), ChatID(callbackUpdate("balance")))
+	assert.Equal(t, int64(0), ChatID(telego.Update{}))
+}
+
+func TestContext_Respond_EditsCallbackMessage(t *testing.T) {
+	messenger := telegram.NewFakeMessenger()
+	r := New(messenger, logger.New("error"))
+	r.Callback("status", func(c *Context) error {
+		return c.Respond("status", nil)
+	})
+
+	require.NoError(t, r.HandleUpdate(callbackUpdate("status")))
+
+	sent := messenger.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "editMessageText", sent[0].Method)
+	assert.Equal(t, 5, sent[0].MessageID)
+	assert.Equal(t, "answerCallbackQuery", sent[1].Method)
+	assert.Equal(t, "cb", sent[1].CallbackQueryID)
+}
+
+func TestContext_Respond_FallsBackToNewMessage(t *testing.T) {
+	messenger := telegram.NewFakeMessenger()
+	messenger.EditErr = errors.New("message can't be edited")
+	r := New(messenger, logger.New("error"))
+	r.Callback("status", func(c *Context) error {
+		return c.Respond("status", nil)
+	})
+
+	require.NoError(t, r.HandleUpdate(callbackUpdate("status")))
+
+	sent := messenger.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "sendMessage", sent[0].Method)
+	assert.Equal(t, "answerCallbackQuery", sent[1].Method)
+}
+
+func TestRouter_AnswersCallbackOnce(t *testing.T) {
+	messenger := telegram.NewFakeMessenger()
+	r := New(messenger, logger.New("error"))
+	r.Callback("trial", func(c *Context) error {
+		return c.Answer("already used", true)
+	})
+
+	require.NoError(t, r.HandleUpdate(callbackUpdate("trial")))
+	require.NoError(t, r.HandleUpdate(callbackUpdate("unknown")))
+
+	sent := messenger.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "already used", sent[0].Text)
+	assert.True(t, sent[0].ShowAlert)
+	assert.Equal(t, "", sent[1].Text)
+}
+
+func TestRouter_Steps(t *testing.T) {
+	store := fsm.NewMemoryStore()
+	r := New(telegram.NewFakeMessenger(), logger.New("error"))
+	r.Sessions(store, time.Minute)
+
+	var called []string
+	r.Callback("promo_code:input", func(c *Context) error {
+		return c.StartStep("promo")
+	})
+	r.Step("promo", func(c *Context) error {
+		called = append(called, "step:"+c.Text())
+		c.Session.Set("code", c.Text())
+		return c.NextStep("confirm")
+	})
+	r.Step("confirm", func(c *Context) error {
+		called = append(called, "confirm:"+c.Session.Get("code"))
+		return c.FinishStep()
+	})
+	r.Command("cancel", func(c *Context) error {
+		called = append(called, "cancel:"+c.Session.Step)
+		return nil
+	})
+	r.Text(func(c *Context) error {
+		called = append(called, "text:"+c.Text())
+		return nil
+	})
+
+	require.NoError(t, r.HandleUpdate(callbackUpdate("promo_code:input")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("BONUS50")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("yes")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("hi")))
+
+	// Команда прерывает диалог, но обработчик видит прерванный шаг
+	require.NoError(t, r.HandleUpdate(callbackUpdate("promo_code:input")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("/cancel")))
+	require.NoError(t, r.HandleUpdate(messageUpdate("after")))
+
+	assert.Equal(t, []string{"step:BONUS50", "confirm:BONUS50", "text:hi", "cancel:promo", "text:after"}, called)
+
+	session, err := store.Get(10)
+	require.NoError(t, err)
+	assert.Nil(t, session)
+}
+
+func TestRouter_Payments(t *testing.T) {
+	r := New(telegram.NewFakeMessenger(), logger.New("error"))
+
+	var called []string
+	r.Text(func(c *Context) error {
+		called = append(called, "text")
+		return nil
+	})
+	r.PreCheckout(func(c *Context) error {
+		called = append(called, "pre_checkout:"+c.Update.PreCheckoutQuery.InvoicePayload)
+		return nil
+	})
+	r.SuccessfulPayment(func(c *Context) error {
+		called = append(called, "paid:"+c.Message.SuccessfulPayment.InvoicePayload)
+		return nil
+	})
+
+	require.NoError(t, r.HandleUpdate(telego.Update{PreCheckoutQuery: &telego.PreCheckoutQuery{
+		ID: "q", From: telego.User{ID: 10}, InvoicePayload: "topup:1",
+	}}))
+	paid := messageUpdate("")
+	paid.Message.SuccessfulPayment = &telego.SuccessfulPayment{InvoicePayload: "topup:1"}
+	require.NoError(t, r.HandleUpdate(paid))
+
+	assert.Equal(t, []string{"pre_checkout:topup:1", "paid:topup:1"}, called)
+}
@@ -0,0 +1,383 @@
+// Package charts рисует графики статистики в PNG без внешних сервисов и браузера
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Размер изображения графика в пикселях
+const (
+	Width  = 800
+	Height = 450
+)
+
+// Отступы области построения и разметка осей
+const (
+	marginLeft   = 80
+	marginRight  = 24
+	marginTop    = 24
+	marginBottom = 44
+	yTicks       = 5
+	maxXLabels   = 8
+)
+
+var (
+	backgroundColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	gridColor       = color.RGBA{R: 226, G: 230, B: 236, A: 255}
+	axisColor       = color.RGBA{R: 120, G: 128, B: 140, A: 255}
+	textColor       = color.RGBA{R: 60, G: 64, B: 72, A: 255}
+)
+
+// Palette цвета рядов и долей диаграмм
+var Palette = []color.RGBA{
+	{R: 52, G: 120, B: 246, A: 255},
+	{R: 52, G: 199, B: 89, A: 255},
+	{R: 255, G: 149, B: 0, A: 255},
+	{R: 175, G: 82, B: 222, A: 255},
+	{R: 255, G: 59, B: 48, A: 255},
+	{R: 255, G: 204, B: 0, A: 255},
+}
+
+// PaletteEmoji эмодзи цветов Palette для легенды в подписи к графику
+var PaletteEmoji = []string{"🟦", "🟩", "🟧", "🟪", "🟥", "🟨"}
+
+// ErrNoData нет данных для построения графика
+var ErrNoData = errors.New("нет данных для графика")
+
+// Series ряд значений графика
+type Series struct {
+	Values []float64
+	Color  color.RGBA
+}
+
+// Slice доля кольцевой диаграммы
+type Slice struct {
+	Value float64
+	Color color.RGBA
+}
+
+// Bars рисует столбчатую диаграмму. Несколько рядов складываются в один столбец
+func Bars(labels []string, series []Series) ([]byte, error) {
+	if len(labels) == 0 || len(series) == 0 {
+		return nil, ErrNoData
+	}
+
+	totals := make([]float64, len(labels))
+	for _, s := range series {
+		for i := range labels {
+			totals[i] += valueAt(s.Values, i)
+		}
+	}
+
+	img := newCanvas()
+	plot := newPlot(img, labels, maxOf(totals))
+
+	barWidth := int(math.Max(1, plot.slotWidth()*0.7))
+	for i := range labels {
+		x := int(plot.slotCenter(i)) - barWidth/2
+		base := 0.0
+		for _, s := range series {
+			value := valueAt(s.Values, i)
+			if value <= 0 {
+				continue
+			}
+			top, bottom := plot.y(base+value), plot.y(base)
+			fillRect(img, x, top, barWidth, bottom-top, s.Color)
+			base += value
+		}
+	}
+
+	return encode(img)
+}
+
+// Line рисует линейный график с заливкой под линией
+func Line(labels []string, series Series) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, ErrNoData
+	}
+
+	values := make([]float64, len(labels))
+	for i := range labels {
+		values[i] = valueAt(series.Values, i)
+	}
+
+	img := newCanvas()
+	plot := newPlot(img, labels, maxOf(values))
+	fill := lighten(series.Color, 0.8)
+
+	// Заливка под линией между соседними точками
+	for i := 0; i+1 < len(values); i++ {
+		x0, x1 := int(plot.slotCenter(i)), int(plot.slotCenter(i+1))
+		for x := x0; x <= x1; x++ {
+			t := float64(x-x0) / float64(x1-x0)
+			y := plot.y(values[i] + (values[i+1]-values[i])*t)
+			fillRect(img, x, y, 1, plot.bottom-y, fill)
+		}
+	}
+
+	for i := 0; i+1 < len(values); i++ {
+		drawLine(img, int(plot.slotCenter(i)), plot.y(values[i]), int(plot.slotCenter(i+1)), plot.y(values[i+1]), series.Color)
+	}
+	if len(values) <= 31 {
+		for i, value := range values {
+			fillRect(img, int(plot.slotCenter(i))-3, plot.y(value)-3, 7, 7, series.Color)
+		}
+	}
+
+	return encode(img)
+}
+
+// Pie рисует кольцевую диаграмму долей с подписями процентов
+func Pie(slices []Slice) ([]byte, error) {
+	total := 0.0
+	for _, slice := range slices {
+		if slice.Value > 0 {
+			total += slice.Value
+		}
+	}
+	if total == 0 {
+		return nil, ErrNoData
+	}
+
+	img := newCanvas()
+	cx, cy := float64(Width)/2, float64(Height)/2
+	outer := float64(Height)/2 - 24
+	inner := outer * 0.55
+
+	for py := int(cy - outer); py <= int(cy+outer); py++ {
+		for px := int(cx - outer); px <= int(cx+outer); px++ {
+			dx, dy := float64(px)-cx, float64(py)-cy
+			distance := math.Hypot(dx, dy)
+			if distance > outer || distance < inner {
+				continue
+			}
+			// Угол по часовой стрелке от верхней точки
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			share := angle / (2 * math.Pi) * total
+			img.SetRGBA(px, py, sliceAt(slices, share))
+		}
+	}
+
+	// Подписи долей посередине кольца
+	start := 0.0
+	for _, slice := range slices {
+		if slice.Value <= 0 {
+			continue
+		}
+		share := slice.Value / total
+		if share >= 0.04 {
+			angle := (start + share/2) * 2 * math.Pi
+			radius := (outer + inner) / 2
+			label := fmt.Sprintf("%.0f%%", share*100)
+			x := int(cx+math.Sin(angle)*radius) - textWidth(label)/2
+			y := int(cy-math.Cos(angle)*radius) - textHeight/2
+			drawText(img, x, y, label, backgroundColor)
+		}
+		start += share
+	}
+
+	return encode(img)
+}
+
+// FormatValue сокращает значение для подписи оси: 1500 → 1.5k
+func FormatValue(value float64) string {
+	switch {
+	case math.Abs(value) >= 1e6:
+		return trimZero(fmt.Sprintf("%.1f", value/1e6)) + "M"
+	case math.Abs(value) >= 1e3:
+		return trimZero(fmt.Sprintf("%.1f", value/1e3)) + "k"
+	default:
+		return trimZero(fmt.Sprintf("%.1f", value))
+	}
+}
+
+// plot область построения графика с осями
+type plot struct {
+	left, top, right, bottom int
+	slots                    int
+	max                      float64
+}
+
+// newPlot рисует сетку, подписи осей и возвращает область построения
+func newPlot(img *image.RGBA, labels []string, maxValue float64) *plot {
+	step := niceStep(maxValue / yTicks)
+	p := &plot{
+		left:   marginLeft,
+		top:    marginTop,
+		right:  Width - marginRight,
+		bottom: Height - marginBottom,
+		slots:  len(labels),
+		max:    math.Max(step, math.Ceil(maxValue/step)*step),
+	}
+
+	for value := 0.0; value <= p.max+step/2; value += step {
+		y := p.y(value)
+		fillRect(img, p.left, y, p.right-p.left, 1, gridColor)
+		label := FormatValue(value)
+		drawText(img, p.left-10-textWidth(label), y-textHeight/2, label, textColor)
+	}
+	fillRect(img, p.left, p.top, 1, p.bottom-p.top+1, axisColor)
+	fillRect(img, p.left, p.bottom, p.right-p.left, 1, axisColor)
+
+	every := (len(labels) + maxXLabels - 1) / maxXLabels
+	for i, label := range labels {
+		if i%every != 0 {
+			continue
+		}
+		x := int(p.slotCenter(i)) - textWidth(label)/2
+		drawText(img, x, p.bottom+12, label, textColor)
+	}
+
+	return p
+}
+
+// slotWidth ширина интервала одной подписи по оси X
+func (p *plot) slotWidth() float64 {
+	return float64(p.right-p.left) / float64(p.slots)
+}
+
+// slotCenter координата X середины интервала
+func (p *plot) slotCenter(i int) float64 {
+	return float64(p.left) + p.slotWidth()*(float64(i)+0.5)
+}
+
+// y координата Y значения
+func (p *plot) y(value float64) int {
+	return p.bottom - int(math.Round(value/p.max*float64(p.bottom-p.top)))
+}
+
+// niceStep округляет шаг сетки до 1, 2 или 5, умноженных на степень десяти
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch fraction := raw / magnitude; {
+	case fraction <= 1:
+		return magnitude
+	case fraction <= 2:
+		return 2 * magnitude
+	case fraction <= 5:
+		return 5 * magnitude
+	default:
+		return 10 * magnitude
+	}
+}
+
+// sliceAt возвращает цвет доли, в которую попадает накопленное значение
+func sliceAt(slices []Slice, share float64) color.RGBA {
+	last := backgroundColor
+	for _, slice := range slices {
+		if slice.Value <= 0 {
+			continue
+		}
+		last = slice.Color
+		if share < slice.Value {
+			return slice.Color
+		}
+		share -= slice.Value
+	}
+	return last
+}
+
+// newCanvas создает изображение, залитое цветом фона
+func newCanvas() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fillRect(img, 0, 0, Width, Height, backgroundColor)
+	return img
+}
+
+// fillRect закрашивает прямоугольник, обрезая его по границам изображения
+func fillRect(img *image.RGBA, x, y, width, height int, c color.RGBA) {
+	rect := image.Rect(x, y, x+width, y+height).Intersect(img.Bounds())
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+// drawLine рисует линию толщиной 3 пикселя по алгоритму Брезенхэма
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		fillRect(img, x0-1, y0-1, 3, 3, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// lighten смешивает цвет с белым в доле amount
+func lighten(c color.RGBA, amount float64) color.RGBA {
+	mix := func(v uint8) uint8 {
+		return uint8(float64(v) + (255-float64(v))*amount)
+	}
+	return color.RGBA{R: mix(c.R), G: mix(c.G), B: mix(c.B), A: 255}
+}
+
+// encode кодирует изображение в PNG
+func encode(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// valueAt возвращает значение ряда или 0, если ряд короче
+func valueAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+// maxOf возвращает наибольшее значение
+func maxOf(values []float64) float64 {
+	result := 0.0
+	for _, value := range values {
+		result = math.Max(result, value)
+	}
+	return result
+}
+
+// trimZero убирает нулевую дробную часть
+func trimZero(value string) string {
+	return strings.TrimSuffix(value, ".0")
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,68 @@
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+	return img
+}
+
+func rgba(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+func TestBars(t *testing.T) {
+	labels := []string{"01.03", "02.03", "03.03", "04.03"}
+	data, err := Bars(labels, []Series{
+		{Values: []float64{100, 0, 300, 50}, Color: Palette[0]},
+		{Values: []float64{100, 0, 0, 50}, Color: Palette[1]},
+	})
+	require.NoError(t, err)
+	img := decode(t, data)
+
+	// Нижняя часть третьего столбца — первый ряд, верх первого столбца — второй
+	p := &plot{left: marginLeft, top: marginTop, right: Width - marginRight, bottom: Height - marginBottom, slots: len(labels), max: 300}
+	assert.Equal(t, Palette[0], rgba(img.At(int(p.slotCenter(2)), p.y(150))))
+	assert.Equal(t, Palette[1], rgba(img.At(int(p.slotCenter(0)), p.y(150))))
+	assert.Equal(t, backgroundColor, rgba(img.At(int(p.slotCenter(1)), p.y(150))))
+}
+
+func TestLineAndPie(t *testing.T) {
+	data, err := Line([]string{"10:00", "11:00", "12:00"}, Series{Values: []float64{1, 5, 3}, Color: Palette[2]})
+	require.NoError(t, err)
+	decode(t, data)
+
+	data, err = Pie([]Slice{{Value: 75, Color: Palette[0]}, {Value: 25, Color: Palette[1]}})
+	require.NoError(t, err)
+	img := decode(t, data)
+
+	// Правая половина кольца принадлежит первой доле, левый верхний сектор — второй
+	assert.Equal(t, Palette[0], rgba(img.At(Width/2+150, Height/2)))
+	assert.Equal(t, Palette[1], rgba(img.At(Width/2-150, Height/2-30)))
+}
+
+func TestNoData(t *testing.T) {
+	_, err := Bars(nil, nil)
+	assert.ErrorIs(t, err, ErrNoData)
+	_, err = Pie([]Slice{{Value: 0, Color: Palette[0]}})
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "0", FormatValue(0))
+	assert.Equal(t, "2.5", FormatValue(2.5))
+	assert.Equal(t, "1.5k", FormatValue(1500))
+	assert.Equal(t, "2M", FormatValue(2000000))
+}
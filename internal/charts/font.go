@@ -0,0 +1,69 @@
+package charts
+
+import (
+	"image"
+	"image/color"
+)
+
+// Растровый шрифт 3x5 для подписей осей. В образе нет системных шрифтов,
+// поэтому на графиках выводятся только числа и даты, а названия и легенда
+// передаются в подписи к фотографии
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	fontScale   = 2
+	// glyphAdvance ширина символа вместе с промежутком в пикселях
+	glyphAdvance = (glyphWidth + 1) * fontScale
+	// textHeight высота строки текста в пикселях
+	textHeight = glyphHeight * fontScale
+)
+
+// glyphs символы шрифта построчно, # — закрашенная точка
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	',': {"...", "...", "...", ".#.", "#.."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'k': {"#..", "#.#", "##.", "#.#", "#.#"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+// textWidth возвращает ширину текста в пикселях
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*glyphAdvance - fontScale
+}
+
+// drawText рисует текст с левым верхним углом в (x, y). Символы вне шрифта пропускаются
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, line := range glyph {
+				for col, dot := range line {
+					if dot == '#' {
+						fillRect(img, x+col*fontScale, y+row*fontScale, fontScale, fontScale, c)
+					}
+				}
+			}
+		}
+		x += glyphAdvance
+	}
+}
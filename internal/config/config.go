@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,9 +14,15 @@ import (
 // Config содержит все конфигурационные параметры приложения
 type Config struct {
 	// Telegram Bot
-	BotToken       string
-	BotWebhookURL  string
-	BotWebhookPort int
+	BotToken         string
+	BotWebhookURL    string
+	BotWebhookPort   int
+	BotWebhookSecret string
+	BotRateLimit     int
+	BotUpdateWorkers int
+	// BotSessionStore хранилище многошаговых диалогов: memory или postgres
+	BotSessionStore   string
+	BotSessionTimeout time.Duration
 
 	// Database
 	Database DatabaseConfig
@@ -47,6 +54,9 @@ type Config struct {
 	// Referral System
 	Referral ReferralConfig
 
+	// Partner Program
+	Partner PartnerConfig
+
 	// Notifications
 	Notifications NotificationConfig
 
@@ -56,9 +66,15 @@ type Config struct {
 	// Localization
 	Localization LocalizationConfig
 
+	// Data Export
+	Export ExportConfig
+
 	// Environment
 	Environment string
 	LogLevel    string
+
+	// settings значения, измененные из админ-панели, см. Settings
+	settings settingsSnapshot
 }
 
 type DatabaseConfig struct {
@@ -81,10 +97,11 @@ type PaymentConfig struct {
 	YooKassa YooKassaConfig
 
 	// Payment Method Toggles
-	StarsEnabled     bool
-	TributeEnabled   bool
-	YooKassaEnabled  bool
-	CryptoPayEnabled bool
+	StarsEnabled   bool
+	TributeEnabled bool
+
+	// StarsRate стоимость одной звезды Telegram в рублях при пополнении баланса
+	StarsRate float64
 
 	// Subscription Prices
 	Price1Month   int
@@ -112,16 +129,46 @@ type AdminConfig struct {
 	TelegramIDs           []int64
 	MaintenanceMode       bool
 	MaintenanceAutoEnable bool
+	// MaintenanceMessage текст для пользователей, пустой — перевод из каталога
+	MaintenanceMessage string
+	// MaintenanceFailureThreshold число неудачных проверок подряд до автоматического включения
+	MaintenanceFailureThreshold int
+	// SettingsRefreshInterval как часто реплика проверяет изменения настроек в базе данных
+	SettingsRefreshInterval time.Duration
+	// AuditChatID чат, куда дублируются критичные действия администраторов. 0 — не дублировать
+	AuditChatID int64
+}
+
+// ExportConfig настройки выгрузок данных из админ-панели
+type ExportConfig struct {
+	// Dir каталог для файлов выгрузок
+	Dir string
+	// PublicURL внешний адрес HTTP сервера для ссылок на файлы, превышающие MaxUploadSize
+	PublicURL string
+	// MaxUploadSize наибольший размер файла в байтах, отправляемого документом в Telegram
+	MaxUploadSize int64
+	// LinkTTL срок действия ссылки на файл выгрузки
+	LinkTTL time.Duration
 }
 
 type SecurityConfig struct {
 	JWTSecret     string
 	EncryptionKey string
+	// APIUsername и APIPassword учетные данные для получения токена REST API.
+	// API включается, только если заданы JWTSecret и APIPassword
+	APIUsername string
+	APIPassword string
+	// APITokenTTL срок действия токена REST API
+	APITokenTTL time.Duration
 }
 
 type MonitoringConfig struct {
 	HealthCheckInterval  time.Duration
 	StatsCleanupInterval time.Duration
+	// MetricsEnabled включает эндпоинт /metrics для Prometheus
+	MetricsEnabled bool
+	// MetricsToken если задан, /metrics требует заголовок Authorization: Bearer <token>
+	MetricsToken string
 }
 
 type TrialConfig struct {
@@ -133,16 +180,36 @@ type TrialConfig struct {
 
 type MiniAppConfig struct {
 	URL string
+	// InitDataTTL срок действия initData, которым Mini App подписывает запросы к API
+	InitDataTTL time.Duration
+	// MinTopUp и MaxTopUp границы суммы пополнения баланса из Mini App в рублях
+	MinTopUp float64
+	MaxTopUp float64
 }
 
 // ReferralConfig настройки реферальной системы
 type ReferralConfig struct {
-	Enabled       bool
+	Enabled bool
+	// RewardType вид награды за первую оплату приглашенного: balance или days
+	RewardType    string
 	BonusDays     int
 	ReferrerBonus int
 	ReferredBonus int
 }
 
+// PartnerConfig настройки партнерской программы
+type PartnerConfig struct {
+	Enabled bool
+	// Level1Percent процент с платежей пользователей, приглашенных партнером
+	Level1Percent float64
+	// Level2Percent процент с платежей пользователей, приглашенных рефералами партнера
+	Level2Percent float64
+	// HoldDays через сколько дней начисление становится доступным к выводу
+	HoldDays int
+	// MinWithdrawal минимальная сумма заявки на вывод
+	MinWithdrawal float64
+}
+
 // NotificationConfig настройки уведомлений
 type NotificationConfig struct {
 	Enabled            bool
@@ -183,6 +250,11 @@ func Load() (*Config, error) {
 	cfg.BotToken = getEnv("BOT_TOKEN", "")
 	cfg.BotWebhookURL = getEnv("BOT_WEBHOOK_URL", "")
 	cfg.BotWebhookPort = getEnvAsInt("BOT_WEBHOOK_PORT", 8080)
+	cfg.BotWebhookSecret = getEnv("BOT_WEBHOOK_SECRET", "")
+	cfg.BotRateLimit = getEnvAsInt("BOT_RATE_LIMIT", 25)
+	cfg.BotUpdateWorkers = getEnvAsInt("BOT_UPDATE_WORKERS", 8)
+	cfg.BotSessionStore = getEnv("BOT_SESSION_STORE", "memory")
+	cfg.BotSessionTimeout = getEnvAsDuration("BOT_SESSION_TIMEOUT", "10m")
 
 	// Database
 	cfg.Database.Host = getEnv("DB_HOST", "localhost")
@@ -207,8 +279,7 @@ func Load() (*Config, error) {
 	// Payment Method Toggles
 	cfg.Payments.StarsEnabled = getEnvAsBool("STARS_ENABLED", true)
 	cfg.Payments.TributeEnabled = getEnvAsBool("TRIBUTE_ENABLED", true)
-	cfg.Payments.YooKassaEnabled = getEnvAsBool("YOOKASSA_ENABLED", true)
-	cfg.Payments.CryptoPayEnabled = getEnvAsBool("CRYPTOPAY_ENABLED", false)
+	cfg.Payments.StarsRate = getEnvAsFloat("STARS_RATE", 1.5)
 
 	// Subscription Prices
 	cfg.Payments.Price1Month = getEnvAsInt("RUB_PRICE_1_MONTH", 150)
@@ -223,6 +294,10 @@ func Load() (*Config, error) {
 	cfg.Admin.TelegramIDs = getEnvAsInt64Slice("ADMIN_TELEGRAM_IDS", []int64{})
 	cfg.Admin.MaintenanceMode = getEnvAsBool("MAINTENANCE_MODE", false)
 	cfg.Admin.MaintenanceAutoEnable = getEnvAsBool("MAINTENANCE_AUTO_ENABLE", true)
+	cfg.Admin.MaintenanceMessage = getEnv("MAINTENANCE_MESSAGE", "")
+	cfg.Admin.MaintenanceFailureThreshold = getEnvAsInt("MAINTENANCE_FAILURE_THRESHOLD", 3)
+	cfg.Admin.SettingsRefreshInterval = getEnvAsDuration("SETTINGS_REFRESH_INTERVAL", "5s")
+	cfg.Admin.AuditChatID = getEnvAsInt64("ADMIN_AUDIT_CHAT_ID", 0)
 
 	// Отладочная информация для админа
 	fmt.Printf("DEBUG: ADMIN_TELEGRAM_IDS loaded: %v\n", cfg.Admin.TelegramIDs)
@@ -232,10 +307,15 @@ func Load() (*Config, error) {
 	// Security
 	cfg.Security.JWTSecret = getEnv("JWT_SECRET", "")
 	cfg.Security.EncryptionKey = getEnv("ENCRYPTION_KEY", "")
+	cfg.Security.APIUsername = getEnv("API_USERNAME", "admin")
+	cfg.Security.APIPassword = getEnv("API_PASSWORD", "")
+	cfg.Security.APITokenTTL = getEnvAsDuration("API_TOKEN_TTL", "12h")
 
 	// Monitoring
 	cfg.Monitoring.HealthCheckInterval = getEnvAsDuration("HEALTH_CHECK_INTERVAL", "30s")
 	cfg.Monitoring.StatsCleanupInterval = getEnvAsDuration("STATS_CLEANUP_INTERVAL", "24h")
+	cfg.Monitoring.MetricsEnabled = getEnvAsBool("METRICS_ENABLED", true)
+	cfg.Monitoring.MetricsToken = getEnv("METRICS_TOKEN", "")
 
 	// Trial Settings
 	cfg.Trial.Enabled = getEnvAsBool("TRIAL_ENABLED", true)
@@ -247,13 +327,24 @@ func Load() (*Config, error) {
 	cfg.MiniApp.URL = getEnv("SUBSCRIPTION_MINI_APP_URL", "")
 	fmt.Printf("DEBUG: SUBSCRIPTION_MINI_APP_URL loaded: '%s'\n", cfg.MiniApp.URL)
 	fmt.Printf("DEBUG: MiniApp.URL length: %d\n", len(cfg.MiniApp.URL))
+	cfg.MiniApp.InitDataTTL = getEnvAsDuration("MINI_APP_INIT_DATA_TTL", "24h")
+	cfg.MiniApp.MinTopUp = getEnvAsFloat("MINI_APP_MIN_TOP_UP", 50)
+	cfg.MiniApp.MaxTopUp = getEnvAsFloat("MINI_APP_MAX_TOP_UP", 50000)
 
 	// Referral System
 	cfg.Referral.Enabled = getEnvAsBool("REFERRAL_ENABLED", true)
+	cfg.Referral.RewardType = getEnv("REFERRAL_REWARD_TYPE", "balance")
 	cfg.Referral.BonusDays = getEnvAsInt("REFERRAL_BONUS_DAYS", 7)
 	cfg.Referral.ReferrerBonus = getEnvAsInt("REFERRAL_REFERRER_BONUS", 50)
 	cfg.Referral.ReferredBonus = getEnvAsInt("REFERRAL_REFERRED_BONUS", 30)
 
+	// Partner Program
+	cfg.Partner.Enabled = getEnvAsBool("PARTNER_ENABLED", false)
+	cfg.Partner.Level1Percent = getEnvAsFloat("PARTNER_LEVEL1_PERCENT", 10)
+	cfg.Partner.Level2Percent = getEnvAsFloat("PARTNER_LEVEL2_PERCENT", 0)
+	cfg.Partner.HoldDays = getEnvAsInt("PARTNER_HOLD_DAYS", 14)
+	cfg.Partner.MinWithdrawal = getEnvAsFloat("PARTNER_MIN_WITHDRAWAL", 500)
+
 	// Notifications
 	cfg.Notifications.Enabled = getEnvAsBool("NOTIFICATIONS_ENABLED", true)
 	cfg.Notifications.ExpiringDaysBefore = getEnvAsInt("NOTIFICATIONS_EXPIRING_DAYS_BEFORE", 3)
@@ -269,6 +360,12 @@ func Load() (*Config, error) {
 	cfg.Localization.DefaultLanguage = getEnv("DEFAULT_LANGUAGE", "ru")
 	cfg.Localization.SupportedLanguages = getEnvAsStringSlice("SUPPORTED_LANGUAGES", []string{"ru", "en"})
 
+	// Data Export
+	cfg.Export.Dir = getEnv("EXPORT_DIR", filepath.Join(os.TempDir(), "remnawave-exports"))
+	cfg.Export.PublicURL = strings.TrimSuffix(getEnv("EXPORT_PUBLIC_URL", ""), "/")
+	cfg.Export.MaxUploadSize = int64(getEnvAsInt("EXPORT_MAX_UPLOAD_MB", 50)) << 20
+	cfg.Export.LinkTTL = getEnvAsDuration("EXPORT_LINK_TTL", "24h")
+
 	// Environment
 	cfg.Environment = getEnv("ENVIRONMENT", "development")
 	cfg.LogLevel = getEnv("LOG_LEVEL", "info")
@@ -301,13 +398,30 @@ func (c *Config) Validate() error {
 	if c.MiniApp.URL == "" {
 		return fmt.Errorf("SUBSCRIPTION_MINI_APP_URL is required")
 	}
+	if c.Payments.StarsRate <= 0 {
+		return fmt.Errorf("STARS_RATE must be positive")
+	}
+	if c.MiniApp.MinTopUp <= 0 || c.MiniApp.MaxTopUp < c.MiniApp.MinTopUp {
+		return fmt.Errorf("MINI_APP_MIN_TOP_UP must be positive and not exceed MINI_APP_MAX_TOP_UP")
+	}
+	if c.Referral.RewardType != "balance" && c.Referral.RewardType != "days" {
+		return fmt.Errorf("REFERRAL_REWARD_TYPE must be balance or days")
+	}
+	if c.Partner.Level1Percent < 0 || c.Partner.Level2Percent < 0 || c.Partner.Level1Percent+c.Partner.Level2Percent > 100 {
+		return fmt.Errorf("PARTNER_LEVEL1_PERCENT and PARTNER_LEVEL2_PERCENT must be non-negative and sum up to at most 100")
+	}
+	if c.Partner.MinWithdrawal < 0 {
+		return fmt.Errorf("PARTNER_MIN_WITHDRAWAL must be non-negative")
+	}
+	if c.BotSessionStore != "memory" && c.BotSessionStore != "postgres" {
+		return fmt.Errorf("BOT_SESSION_STORE must be memory or postgres")
+	}
 	return nil
 }
 
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
-	fmt.Printf("DEBUG getEnv: key='%s', value='%s', empty=%t\n", key, value, value == "")
 	if value != "" {
 		return value
 	}
@@ -323,6 +437,24 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
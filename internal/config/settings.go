@@ -0,0 +1,49 @@
+package config
+
+import "sync/atomic"
+
+// Settings значения конфигурации, которые меняются из админ-панели без
+// перезапуска. Опубликованный снимок не изменяется: сервис настроек собирает
+// новую копию и публикует ее целиком, поэтому читатели видят согласованные
+// значения без блокировок
+type Settings struct {
+	Trial         TrialConfig
+	Payments      PaymentConfig
+	Referral      ReferralConfig
+	Partner       PartnerConfig
+	Notifications NotificationConfig
+
+	MaintenanceFailureThreshold int
+}
+
+// settingsSnapshot действующий снимок настроек
+type settingsSnapshot struct {
+	current atomic.Pointer[Settings]
+}
+
+// Settings возвращает действующий снимок настроек. Пока сервис настроек
+// ничего не опубликовал, действуют значения из окружения
+func (c *Config) Settings() *Settings {
+	if settings := c.settings.current.Load(); settings != nil {
+		return settings
+	}
+	return c.EnvSettings()
+}
+
+// EnvSettings возвращает настройки со значениями из окружения
+func (c *Config) EnvSettings() *Settings {
+	return &Settings{
+		Trial:                       c.Trial,
+		Payments:                    c.Payments,
+		Referral:                    c.Referral,
+		Partner:                     c.Partner,
+		Notifications:               c.Notifications,
+		MaintenanceFailureThreshold: c.Admin.MaintenanceFailureThreshold,
+	}
+}
+
+// PublishSettings публикует новый снимок настроек. Снимок нельзя изменять
+// после публикации
+func (c *Config) PublishSettings(settings *Settings) {
+	c.settings.current.Store(settings)
+}
@@ -88,6 +88,13 @@ func (d *Database) migrate() error {
 		&models.PromoCode{},
 		&models.PromoCodeUsage{},
 		&models.Notification{},
+		&models.BotSession{},
+		&models.PartnerEarning{},
+		&models.ReferralClick{},
+		&models.WithdrawalRequest{},
+		&models.Setting{},
+		&models.SettingChange{},
+		&models.AdminAuditLog{},
 	); err != nil {
 		return fmt.Errorf("failed to auto migrate: %w", err)
 	}
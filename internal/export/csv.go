@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// utf8BOM метка порядка байтов, чтобы Excel открывал CSV в UTF-8
+const utf8BOM = "\ufeff"
+
+// csvWriter запись выгрузки в CSV
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter создает запись CSV с разделителем «;», который Excel в
+// русской локали распознает без настройки импорта
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	return &csvWriter{w: writer}, nil
+}
+
+// WriteHeader записывает строку заголовков
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+// WriteRow записывает строку значений
+func (c *csvWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		text := formatText(value)
+		if _, isText := normalize(value).(string); isText {
+			text = escapeFormula(text)
+		}
+		record[i] = text
+	}
+	return c.w.Write(record)
+}
+
+// Close сбрасывает буфер записи
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula экранирует текст, который табличный редактор принял бы за формулу
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,120 @@
+// Package export записывает табличные выгрузки в CSV и XLSX построчно,
+// не накапливая данные в памяти
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// dateTimeLayout формат даты и времени в CSV
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// ErrUnknownFormat неизвестный формат выгрузки
+var ErrUnknownFormat = errors.New("неизвестный формат выгрузки")
+
+// Writer построчная запись таблицы. Значения строк: string, bool, целые и
+// дробные числа, time.Time, указатели на них и fmt.Stringer; nil — пустая ячейка
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values ...any) error
+	// Close дописывает служебные части файла. Исходный io.Writer не закрывается
+	Close() error
+}
+
+// NewWriter создает Writer для формата format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// IsFormat проверяет, что формат поддерживается
+func IsFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// ContentType возвращает MIME-тип файла формата
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// normalize разыменовывает указатели и приводит числа к int64 или float64
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case string, bool, int64, float64, time.Time:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatText форматирует значение ячейки как текст
+func formatText(value any) string {
+	switch v := normalize(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(dateTimeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
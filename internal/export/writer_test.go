@@ -0,0 +1,90 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTable(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	var empty *time.Time
+	require.NoError(t, w.WriteHeader([]string{"Пользователь", "Сумма", "Активен", "Создан", "Завершен"}))
+	require.NoError(t, w.WriteRow("Иван <admin> & Co", 1250.5, true, createdAt, empty))
+	require.NoError(t, w.WriteRow("=HYPERLINK(\"x\")", 7, false, &createdAt, nil))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	data := string(writeTable(t, FormatCSV))
+
+	assert.True(t, strings.HasPrefix(data, utf8BOM))
+	lines := strings.Split(strings.TrimPrefix(data, utf8BOM), "\n")
+	assert.Equal(t, "Пользователь;Сумма;Активен;Создан;Завершен", lines[0])
+	assert.Equal(t, "Иван <admin> & Co;1250.5;true;2026-03-01 12:30:00;", lines[1])
+	// Текст, похожий на формулу, экранируется
+	assert.Equal(t, `"'=HYPERLINK(""x"")";7;false;2026-03-01 12:30:00;`, lines[2])
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := writeTable(t, FormatXLSX)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		assert.Contains(t, files, name)
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	var parsed struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Style  string `xml:"s,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(sheet), &parsed))
+	require.Len(t, parsed.Rows, 3)
+
+	header, row := parsed.Rows[0].Cells, parsed.Rows[1].Cells
+	assert.Equal(t, "Пользователь", header[0].Inline)
+	assert.Equal(t, "1", header[0].Style)
+	assert.Equal(t, "Иван <admin> & Co", row[0].Inline)
+	assert.Equal(t, "1250.5", row[1].Value)
+	assert.Equal(t, "b", row[2].Type)
+	assert.Equal(t, "1", row[2].Value)
+	assert.Equal(t, "2", row[3].Style)
+	assert.Equal(t, "46082.5208333", row[3].Value[:13])
+	assert.Empty(t, row[4].Value)
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", io.Discard)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Служебные части книги XLSX. Лист пишется потоково в последнюю запись архива
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// Стили ячеек: 0 — обычная, 1 — заголовок, 2 — дата и время
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs></styleSheet>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// Номера стилей ячеек из xlsxStyles
+const (
+	xlsxStyleHeader   = 1
+	xlsxStyleDateTime = 2
+)
+
+// excelEpoch начало отсчета дат Excel
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter запись выгрузки в XLSX
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+// newXLSXWriter создает книгу с одним листом и открывает лист для записи
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	writer := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(sheet)}
+	if _, err := writer.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WriteHeader записывает строку заголовков жирным шрифтом
+func (x *xlsxWriter) WriteHeader(columns []string) error {
+	x.sheet.WriteString("<row>")
+	for _, column := range columns {
+		x.writeString(column, xlsxStyleHeader)
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+// WriteRow записывает строку значений. Числа, флаги и даты сохраняются
+// типизированными ячейками, чтобы с ними можно было считать в таблице
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	x.sheet.WriteString("<row>")
+	for _, value := range values {
+		switch v := normalize(value).(type) {
+		case nil:
+			x.sheet.WriteString("<c/>")
+		case bool:
+			flag := "0"
+			if v {
+				flag = "1"
+			}
+			x.sheet.WriteString(`<c t="b"><v>` + flag + `</v></c>`)
+		case int64:
+			x.sheet.WriteString("<c><v>" + strconv.FormatInt(v, 10) + "</v></c>")
+		case float64:
+			x.sheet.WriteString("<c><v>" + strconv.FormatFloat(v, 'f', -1, 64) + "</v></c>")
+		case time.Time:
+			if v.IsZero() {
+				x.sheet.WriteString("<c/>")
+				continue
+			}
+			x.sheet.WriteString(`<c s="` + strconv.Itoa(xlsxStyleDateTime) + `"><v>` +
+				strconv.FormatFloat(excelSerial(v), 'f', -1, 64) + "</v></c>")
+		default:
+			x.writeString(formatText(v), 0)
+		}
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+// Close завершает лист и архив
+func (x *xlsxWriter) Close() error {
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// writeString записывает текстовую ячейку со встроенной строкой
+func (x *xlsxWriter) writeString(text string, style int) {
+	if style != 0 {
+		x.sheet.WriteString(`<c t="inlineStr" s="` + strconv.Itoa(style) + `"><is><t xml:space="preserve">`)
+	} else {
+		x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	}
+	// Ошибка записи сохраняется в bufio.Writer и возвращается при следующей записи
+	_ = xml.EscapeText(x.sheet, []byte(text))
+	x.sheet.WriteString("</t></is></c>")
+}
+
+// excelSerial переводит время в дробное число дней от начала отсчета Excel.
+// Excel не хранит часовой пояс, поэтому используется местное время значения
+func excelSerial(t time.Time) float64 {
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return local.Sub(excelEpoch).Hours() / 24
+}
@@ -0,0 +1,311 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// placeholderPattern подстановки вида {name} или {name:.2f}
+var placeholderPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)(?::([^{}]+))?\}`)
+
+// message перевод одного ключа: строка или набор форм множественного числа
+type message struct {
+	text  string
+	forms map[string]string
+}
+
+// Bundle каталог переводов всех поддерживаемых языков
+type Bundle struct {
+	defaultLang string
+	supported   []string
+	messages    map[string]map[string]message
+}
+
+// Load загружает встроенный каталог и проверяет, что в каждом
+// поддерживаемом языке есть все ключи
+func Load(defaultLang string, supported []string) (*Bundle, error) {
+	return LoadFS(locales, "locales", defaultLang, supported)
+}
+
+// LoadFS загружает каталог из файлов <dir>/<язык>.yaml
+func LoadFS(fsys fs.FS, dir, defaultLang string, supported []string) (*Bundle, error) {
+	if !contains(supported, defaultLang) {
+		return nil, fmt.Errorf("default language %q is not in supported languages %v", defaultLang, supported)
+	}
+
+	b := &Bundle{
+		defaultLang: defaultLang,
+		supported:   supported,
+		messages:    make(map[string]map[string]message, len(supported)),
+	}
+	for _, lang := range supported {
+		data, err := fs.ReadFile(fsys, path.Join(dir, lang+".yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale %q: %w", lang, err)
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse locale %q: %w", lang, err)
+		}
+		messages := make(map[string]message)
+		if len(root.Content) > 0 {
+			if err := flatten(root.Content[0], "", messages); err != nil {
+				return nil, fmt.Errorf("locale %q: %w", lang, err)
+			}
+		}
+		b.messages[lang] = messages
+	}
+
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Validate проверяет полноту каталога: каждый ключ есть во всех языках,
+// у множественных форм заданы все категории языка, а подстановки совпадают
+// с языком по умолчанию
+func (b *Bundle) Validate() error {
+	keys := make(map[string]struct{})
+	for _, messages := range b.messages {
+		for key := range messages {
+			keys[key] = struct{}{}
+		}
+	}
+
+	var problems []string
+	for _, lang := range b.supported {
+		messages := b.messages[lang]
+		for key := range keys {
+			msg, ok := messages[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing key %q", lang, key))
+				continue
+			}
+			if msg.forms != nil {
+				for _, form := range pluralForms(lang) {
+					if _, ok := msg.forms[form]; !ok {
+						problems = append(problems, fmt.Sprintf("%s: key %q has no plural form %q", lang, key, form))
+					}
+				}
+			}
+			if reference, ok := b.messages[b.defaultLang][key]; ok && lang != b.defaultLang {
+				if want, got := placeholders(reference), placeholders(msg); want != got {
+					problems = append(problems, fmt.Sprintf("%s: key %q has placeholders [%s], want [%s]", lang, key, got, want))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("incomplete translations:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Supported возвращает поддерживаемые языки в порядке конфигурации
+func (b *Bundle) Supported() []string {
+	return b.supported
+}
+
+// IsSupported проверяет, поддерживается ли язык
+func (b *Bundle) IsSupported(lang string) bool {
+	return contains(b.supported, lang)
+}
+
+// Default возвращает переводчик языка по умолчанию
+func (b *Bundle) Default() *Localizer {
+	return &Localizer{bundle: b, lang: b.defaultLang}
+}
+
+// For возвращает переводчик первого поддерживаемого языка из списка.
+// Коды вида en-US сводятся к en; если ни один не подошел, используется
+// язык по умолчанию
+func (b *Bundle) For(langs ...string) *Localizer {
+	for _, lang := range langs {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if base, _, ok := strings.Cut(lang, "-"); ok {
+			lang = base
+		}
+		if b.IsSupported(lang) {
+			return &Localizer{bundle: b, lang: lang}
+		}
+	}
+	return b.Default()
+}
+
+// Plural значение подстановки, которое переводится на язык сообщения
+// формой ключа Key для числа Count. Нужен, когда текст собирается для
+// другого пользователя, например в уведомлениях
+type Plural struct {
+	Key   string
+	Count int
+}
+
+// Localizer переводит ключи каталога на один язык
+type Localizer struct {
+	bundle *Bundle
+	lang   string
+}
+
+// Lang возвращает код языка переводчика
+func (l *Localizer) Lang() string {
+	return l.lang
+}
+
+// T переводит ключ. args — пары имя, значение для подстановок {имя}.
+// Отсутствующий ключ берется из языка по умолчанию, а если его нет и там,
+// возвращается сам ключ. Для ключей с множественными формами нужен N
+func (l *Localizer) T(key string, args ...any) string {
+	msg, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	text := msg.text
+	if msg.forms != nil {
+		text = msg.forms[pluralForm(l.lang, 0)]
+	}
+	return l.format(text, args)
+}
+
+// N переводит ключ с множественными формами для числа count.
+// Число доступно в тексте как {count}
+func (l *Localizer) N(key string, count int, args ...any) string {
+	msg, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	text := msg.text
+	if msg.forms != nil {
+		text = msg.forms[pluralForm(l.lang, count)]
+	}
+	return l.format(text, append([]any{"count", count}, args...))
+}
+
+// lookup ищет перевод ключа с откатом на язык по умолчанию
+func (l *Localizer) lookup(key string) (message, bool) {
+	if msg, ok := l.bundle.messages[l.lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := l.bundle.messages[l.bundle.defaultLang][key]
+	return msg, ok
+}
+
+// flatten раскладывает вложенные разделы YAML в ключи вида section.key.
+// Раздел, все ключи которого — категории множественного числа, считается
+// одним переводом с формами
+func flatten(node *yaml.Node, prefix string, out map[string]message) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		out[prefix] = message{text: node.Value}
+		return nil
+	case yaml.MappingNode:
+		if prefix != "" && isPluralNode(node) {
+			forms := make(map[string]string, len(node.Content)/2)
+			for i := 0; i < len(node.Content); i += 2 {
+				forms[node.Content[i].Value] = node.Content[i+1].Value
+			}
+			out[prefix] = message{forms: forms}
+			return nil
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flatten(node.Content[i+1], key, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("key %q: unsupported value at line %d", prefix, node.Line)
+	}
+}
+
+// isPluralNode проверяет, что раздел содержит только формы множественного числа
+func isPluralNode(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if !isPluralCategory(node.Content[i].Value) || node.Content[i+1].Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// format подставляет значения в {имя} и {имя:формат}
+func (l *Localizer) format(text string, args []any) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	values := make(map[string]any, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		if name, ok := args[i].(string); ok {
+			values[name] = args[i+1]
+		}
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := placeholderPattern.FindStringSubmatch(match)
+		value, ok := values[parts[1]]
+		if !ok {
+			return match
+		}
+		if plural, ok := value.(Plural); ok {
+			return l.N(plural.Key, plural.Count)
+		}
+		if parts[2] != "" {
+			return fmt.Sprintf("%"+parts[2], value)
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// placeholders возвращает отсортированные имена подстановок перевода
+func placeholders(msg message) string {
+	texts := []string{msg.text}
+	for _, text := range msg.forms {
+		texts = append(texts, text)
+	}
+
+	seen := make(map[string]struct{})
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			seen[match[1]] = struct{}{}
+		}
+	}
+	// count подставляется автоматически и может отсутствовать в части форм
+	delete(seen, "count")
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// contains проверяет наличие строки в списке
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
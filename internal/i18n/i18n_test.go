@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_EmbeddedCatalogIsComplete(t *testing.T) {
+	bundle, err := Load("ru", []string{"ru", "en"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "🔙 Назад", bundle.For("ru").T("common.back"))
+	assert.Equal(t, "🔙 Back", bundle.For("en").T("common.back"))
+}
+
+func TestLocalizer_PlaceholdersAndPlurals(t *testing.T) {
+	bundle, err := Load("ru", []string{"ru", "en"})
+	require.NoError(t, err)
+	ru, en := bundle.For("ru"), bundle.For("en")
+
+	assert.Equal(t, "💰 Баланс 150₽", ru.T("menu.balance", "balance", 149.6))
+	assert.Equal(t, "💳 К оплате: 719.10₽", ru.T("purchase.to_pay", "total", 719.1))
+
+	cases := map[int]string{1: "1 день", 2: "2 дня", 5: "5 дней", 11: "11 дней", 21: "21 день", 24: "24 дня", 112: "112 дней"}
+	for n, want := range cases {
+		assert.Equal(t, want, ru.N("units.days", n))
+	}
+	assert.Equal(t, "1 day", en.N("units.days", 1))
+	assert.Equal(t, "30 days", en.N("units.days", 30))
+
+	// Plural переводится на язык того, кто получает текст
+	assert.Equal(t, "📅 Duration: 3 days", en.T("purchase.duration", "duration", Plural{Key: "units.days", Count: 3}))
+}
+
+func TestBundle_ForFallsBackToDefault(t *testing.T) {
+	bundle, err := Load("ru", []string{"ru", "en"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", bundle.For("", "en-US").Lang())
+	assert.Equal(t, "ru", bundle.For("de").Lang())
+	assert.Equal(t, "missing.key", bundle.For("en").T("missing.key"))
+}
+
+func TestLoadFS_MissingKeyFails(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/ru.yaml": {Data: []byte("menu:\n  buy: \"Купить {price}\"\n  days:\n    one: \"{count} день\"\n    few: \"{count} дня\"\n    many: \"{count} дней\"\n")},
+		"locales/en.yaml": {Data: []byte("menu:\n  buy: \"Buy {amount}\"\n  days:\n    one: \"{count} day\"\nextra: \"Only in English\"\n")},
+	}
+
+	_, err := LoadFS(fsys, "locales", "ru", []string{"ru", "en"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `ru: missing key "extra"`)
+	assert.Contains(t, err.Error(), `en: key "menu.days" has no plural form "other"`)
+	assert.Contains(t, err.Error(), `en: key "menu.buy" has placeholders [amount], want [price]`)
+
+	_, err = LoadFS(fsys, "locales", "ru", []string{"ru", "de"})
+	assert.Error(t, err)
+}
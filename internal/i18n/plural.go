@@ -0,0 +1,45 @@
+package i18n
+
+// isPluralCategory проверяет, является ли ключ категорией множественного числа CLDR
+func isPluralCategory(key string) bool {
+	switch key {
+	case "zero", "one", "two", "few", "many", "other":
+		return true
+	}
+	return false
+}
+
+// pluralForms возвращает категории, обязательные для языка
+func pluralForms(lang string) []string {
+	switch lang {
+	case "ru", "uk", "be":
+		return []string{"one", "few", "many"}
+	default:
+		return []string{"one", "other"}
+	}
+}
+
+// pluralForm выбирает категорию множественного числа для n
+func pluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case "ru", "uk", "be":
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
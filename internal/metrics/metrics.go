@@ -0,0 +1,219 @@
+// Package metrics собирает метрики Prometheus бота, платежей и внешних API
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace префикс имен всех метрик приложения
+const namespace = "remnawave_shop"
+
+// Registry реестр метрик приложения. Отдельный реестр вместо глобального
+// позволяет не смешивать метрики с зависимостями
+var Registry = prometheus.NewRegistry()
+
+var (
+	updatesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_updates_total",
+		Help:      "Обработанные обновления Telegram по типу и обработчику",
+	}, []string{"type", "handler"})
+
+	updateErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_update_errors_total",
+		Help:      "Обновления Telegram, обработчик которых вернул ошибку",
+	}, []string{"type", "handler"})
+
+	updateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bot_update_duration_seconds",
+		Help:      "Время обработки обновления Telegram",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type", "handler"})
+
+	telegramRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Запросы к Telegram Bot API по методу и результату",
+	}, []string{"method", "result"})
+
+	telegramRateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_rate_limited_total",
+		Help:      "Ответы 429 Too Many Requests от Telegram Bot API",
+	}, []string{"method"})
+
+	remnawaveRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remnawave_requests_total",
+		Help:      "Запросы к API Remnawave по эндпоинту и коду ответа",
+	}, []string{"method", "endpoint", "code"})
+
+	remnawaveRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "remnawave_request_duration_seconds",
+		Help:      "Время ответа API Remnawave",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "endpoint"})
+
+	paymentsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Переходы платежей в статус по способу оплаты",
+	}, []string{"method", "status"})
+
+	revenueTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_rub_total",
+		Help:      "Выручка по завершенным платежам в рублях",
+	}, []string{"method"})
+
+	jobRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Запуски фоновых задач по результату",
+	}, []string{"job", "result"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Время выполнения фоновой задачи",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Время последнего успешного запуска фоновой задачи",
+	}, []string{"job"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		updatesTotal, updateErrorsTotal, updateDuration,
+		telegramRequestsTotal, telegramRateLimitedTotal,
+		remnawaveRequestsTotal, remnawaveRequestDuration,
+		paymentsTotal, revenueTotal,
+		jobRunsTotal, jobDuration, jobLastSuccess,
+	)
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveUpdate учитывает обработку обновления Telegram
+func ObserveUpdate(updateType, handler string, duration time.Duration, err error) {
+	updatesTotal.WithLabelValues(updateType, handler).Inc()
+	updateDuration.WithLabelValues(updateType, handler).Observe(duration.Seconds())
+	if err != nil {
+		updateErrorsTotal.WithLabelValues(updateType, handler).Inc()
+	}
+}
+
+// ObserveTelegramRequest учитывает запрос к Bot API; rateLimited отмечает ответ 429
+func ObserveTelegramRequest(method string, err error, rateLimited bool) {
+	if rateLimited {
+		telegramRateLimitedTotal.WithLabelValues(method).Inc()
+	}
+	telegramRequestsTotal.WithLabelValues(method, result(err)).Inc()
+}
+
+// ObserveRemnawaveRequest учитывает запрос к Remnawave. Код 0 означает,
+// что ответ не получен
+func ObserveRemnawaveRequest(method, endpoint string, code int, duration time.Duration) {
+	endpoint = endpointLabel(endpoint)
+	label := "error"
+	if code > 0 {
+		label = strconv.Itoa(code)
+	}
+	remnawaveRequestsTotal.WithLabelValues(method, endpoint, label).Inc()
+	remnawaveRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
+
+// PaymentStatus учитывает переход платежа в статус
+func PaymentStatus(method, status string) {
+	paymentsTotal.WithLabelValues(method, status).Inc()
+}
+
+// Revenue учитывает выручку по завершенному платежу
+func Revenue(method string, amount float64) {
+	revenueTotal.WithLabelValues(method).Add(amount)
+}
+
+// ObserveJob учитывает запуск фоновой задачи, начатый в start
+func ObserveJob(job string, start time.Time, err error) {
+	jobRunsTotal.WithLabelValues(job, result(err)).Inc()
+	jobDuration.WithLabelValues(job).Observe(time.Since(start).Seconds())
+	if err == nil {
+		jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
+
+// RegisterDB добавляет статистику пула соединений из sql.DB.Stats()
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterActiveSubscriptions добавляет число активных подписок по тарифам,
+// которое запрашивается при каждом сборе метрик
+func RegisterActiveSubscriptions(count func() ([]models.TariffCount, error)) error {
+	return Registry.Register(&activeSubscriptionsCollector{count: count})
+}
+
+// activeSubscriptionsCollector считает активные подписки в момент сбора метрик
+type activeSubscriptionsCollector struct {
+	count func() ([]models.TariffCount, error)
+}
+
+var activeSubscriptionsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_subscriptions"),
+	"Активные подписки по тарифу",
+	[]string{"plan"}, nil,
+)
+
+func (c *activeSubscriptionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeSubscriptionsDesc
+}
+
+func (c *activeSubscriptionsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(activeSubscriptionsDesc, err)
+		return
+	}
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(activeSubscriptionsDesc, prometheus.GaugeValue, float64(count.Count), count.PlanName)
+	}
+}
+
+// numericSegment идентификаторы в пути запроса
+var numericSegment = regexp.MustCompile(`/\d+\b`)
+
+// endpointLabel заменяет идентификаторы в пути на :id, чтобы число рядов
+// метрики не росло с числом пользователей и подписок
+func endpointLabel(endpoint string) string {
+	return numericSegment.ReplaceAllString(endpoint, "/:id")
+}
+
+// result значение метки result по ошибке
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
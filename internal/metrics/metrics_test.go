@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	assert.Equal(t, "/users/by-telegram-id/:id", endpointLabel("/users/by-telegram-id/123456"))
+	assert.Equal(t, "/subscriptions/:id", endpointLabel("/subscriptions/42"))
+	assert.Equal(t, "/servers/:id/plans", endpointLabel("/servers/7/plans"))
+	assert.Equal(t, "/servers", endpointLabel("/servers"))
+}
+
+func TestObserveUpdate(t *testing.T) {
+	before := testutil.ToFloat64(updateErrorsTotal.WithLabelValues("message", "command:test"))
+
+	ObserveUpdate("message", "command:test", time.Millisecond, nil)
+	ObserveUpdate("message", "command:test", time.Millisecond, errors.New("failed"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(updatesTotal.WithLabelValues("message", "command:test")))
+	assert.Equal(t, before+1, testutil.ToFloat64(updateErrorsTotal.WithLabelValues("message", "command:test")))
+}
+
+func TestObserveTelegramRequest(t *testing.T) {
+	ObserveTelegramRequest("testMethod", errors.New("429"), true)
+	ObserveTelegramRequest("testMethod", nil, false)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(telegramRateLimitedTotal.WithLabelValues("testMethod")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(telegramRequestsTotal.WithLabelValues("testMethod", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(telegramRequestsTotal.WithLabelValues("testMethod", "ok")))
+}
+
+func TestObserveRemnawaveRequest(t *testing.T) {
+	ObserveRemnawaveRequest("GET", "/test/1", 0, time.Millisecond)
+	ObserveRemnawaveRequest("GET", "/test/2", 503, time.Millisecond)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(remnawaveRequestsTotal.WithLabelValues("GET", "/test/:id", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(remnawaveRequestsTotal.WithLabelValues("GET", "/test/:id", "503")))
+}
+
+func TestActiveSubscriptionsCollector(t *testing.T) {
+	collector := &activeSubscriptionsCollector{count: func() ([]models.TariffCount, error) {
+		return []models.TariffCount{{PlanName: "basic", Count: 3}, {PlanName: "pro", Count: 1}}, nil
+	}}
+
+	expected := `
+# HELP remnawave_shop_active_subscriptions Активные подписки по тарифу
+# TYPE remnawave_shop_active_subscriptions gauge
+remnawave_shop_active_subscriptions{plan="basic"} 3
+remnawave_shop_active_subscriptions{plan="pro"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	failing := &activeSubscriptionsCollector{count: func() ([]models.TariffCount, error) {
+		return nil, errors.New("db down")
+	}}
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(failing))
+	_, err := registry.Gather()
+	assert.Error(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	Revenue("test", 150)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `remnawave_shop_revenue_rub_total{method="test"} 150`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
@@ -37,6 +37,8 @@ func (al *ActivityLog) GetActionText() string {
 		return "Промокод"
 	case "referral":
 		return "Реферал"
+	case "withdrawal":
+		return "Вывод средств"
 	default:
 		return al.Action
 	}
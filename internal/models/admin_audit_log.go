@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Действия администраторов, записываемые в журнал аудита
+const (
+	AuditBalanceChange      = "balance_change"
+	AuditUserBlock          = "user_block"
+	AuditUserUnblock        = "user_unblock"
+	AuditBroadcast          = "broadcast"
+	AuditRoleChange         = "role_change"
+	AuditSettingChange      = "setting_change"
+	AuditMaintenanceToggle  = "maintenance_toggle"
+	AuditWithdrawalApprove  = "withdrawal_approve"
+	AuditWithdrawalReject   = "withdrawal_reject"
+	AuditWithdrawalPaid     = "withdrawal_paid"
+	AuditPromoCreate        = "promo_create"
+	AuditPromoBatch         = "promo_batch"
+	AuditSubscriptionDays   = "subscription_days"
+	AuditTariffGrant        = "tariff_grant"
+	AuditTrialReset         = "trial_reset"
+	AuditUserMessage        = "user_message"
+	AuditDataExport         = "data_export"
+	AuditUserUpdate         = "user_update"
+	AuditSubscriptionCreate = "subscription_create"
+	AuditSubscriptionCancel = "subscription_cancel"
+	AuditPromoUpdate        = "promo_update"
+	AuditPromoDelete        = "promo_delete"
+	AuditPaymentCreate      = "payment_create"
+)
+
+// AdminAuditLog запись журнала действий администраторов. Хранится отдельно
+// от логов активности пользователей и не удаляется автоматически
+type AdminAuditLog struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ActorID      *uuid.UUID `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	ActorName    string     `gorm:"size:100" json:"actor_name,omitempty"` // учетная запись REST API, если действие выполнено не из бота
+	Action       string     `gorm:"size:50;not null;index" json:"action"`
+	TargetUserID *uuid.UUID `gorm:"type:uuid;index" json:"target_user_id,omitempty"`
+	TargetType   string     `gorm:"size:50" json:"target_type"` // user, setting, withdrawal, promo_code, broadcast
+	TargetID     string     `gorm:"size:100" json:"target_id"`
+	Before       string     `gorm:"type:text" json:"before"` // JSON значения до изменения
+	After        string     `gorm:"type:text" json:"after"`  // JSON значения после изменения
+	Reason       string     `gorm:"type:text" json:"reason"`
+	Critical     bool       `gorm:"not null;default:false" json:"critical"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+
+	// Связи
+	Actor      *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	TargetUser *User `gorm:"foreignKey:TargetUserID" json:"target_user,omitempty"`
+}
+
+// ActorTitle возвращает имя исполнителя действия для журнала
+func (l *AdminAuditLog) ActorTitle() string {
+	if l.Actor != nil {
+		return l.Actor.GetFullName()
+	}
+	return "API " + l.ActorName
+}
+
+// IsCriticalAuditAction проверяет, нужно ли сразу сообщать о действии в чат администраторов
+func IsCriticalAuditAction(action string) bool {
+	switch action {
+	case AuditBalanceChange, AuditUserBlock, AuditUserUnblock, AuditBroadcast,
+		AuditRoleChange, AuditSettingChange, AuditMaintenanceToggle, AuditWithdrawalPaid,
+		AuditSubscriptionDays, AuditTariffGrant:
+		return true
+	}
+	return false
+}
+
+// GetAuditActionText возвращает название действия для админ-панели
+func GetAuditActionText(action string) string {
+	switch action {
+	case AuditBalanceChange:
+		return "💰 Изменение баланса"
+	case AuditUserBlock:
+		return "🚫 Блокировка"
+	case AuditUserUnblock:
+		return "✅ Разблокировка"
+	case AuditBroadcast:
+		return "📢 Рассылка"
+	case AuditRoleChange:
+		return "👮 Смена роли"
+	case AuditSettingChange:
+		return "⚙️ Изменение настройки"
+	case AuditMaintenanceToggle:
+		return "🛠 Техработы"
+	case AuditWithdrawalApprove:
+		return "💸 Одобрение вывода"
+	case AuditWithdrawalReject:
+		return "💸 Отклонение вывода"
+	case AuditWithdrawalPaid:
+		return "💸 Выплата"
+	case AuditPromoCreate:
+		return "🎟️ Создание промокода"
+	case AuditPromoBatch:
+		return "🎟️ Генерация промокодов"
+	case AuditSubscriptionDays:
+		return "⏱ Изменение срока подписки"
+	case AuditTariffGrant:
+		return "🎁 Выдача тарифа"
+	case AuditTrialReset:
+		return "🔄 Сброс пробного периода"
+	case AuditUserMessage:
+		return "✉️ Сообщение пользователю"
+	case AuditDataExport:
+		return "📤 Выгрузка данных"
+	case AuditUserUpdate:
+		return "✏️ Изменение пользователя"
+	case AuditSubscriptionCreate:
+		return "➕ Создание подписки"
+	case AuditSubscriptionCancel:
+		return "⛔ Отмена подписки"
+	case AuditPromoUpdate:
+		return "🎟️ Изменение промокода"
+	case AuditPromoDelete:
+		return "🎟️ Удаление промокода"
+	case AuditPaymentCreate:
+		return "💳 Создание платежа"
+	default:
+		return action
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+// Роли сотрудников в админ-панели
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleSupport   = "support"
+	RoleFinance   = "finance"
+	RoleMarketing = "marketing"
+)
+
+// Права доступа к разделам и действиям админ-панели
+const (
+	PermStatsRead         = "stats.read"
+	PermUsersRead         = "users.read"
+	PermUserBlock         = "user.block"
+	PermUserMessage       = "user.message"
+	PermSubscriptionsEdit = "subscriptions.edit"
+	PermBalanceWrite      = "balance.write"
+	PermBroadcastSend     = "broadcast.send"
+	PermPromoManage       = "promo.manage"
+	PermLogsRead          = "logs.read"
+	PermWithdrawalsManage = "withdrawals.manage"
+	PermSettingsManage    = "settings.manage"
+	PermRolesManage       = "roles.manage"
+	PermDataExport        = "data.export"
+)
+
+// AdminRoles роли в порядке отображения
+var AdminRoles = []string{RoleOwner, RoleAdmin, RoleSupport, RoleFinance, RoleMarketing}
+
+// rolePermissions права каждой роли. Владелец получает все права
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermStatsRead, PermUsersRead, PermUserBlock, PermUserMessage, PermSubscriptionsEdit, PermBalanceWrite,
+		PermBroadcastSend, PermPromoManage, PermLogsRead, PermWithdrawalsManage, PermSettingsManage, PermDataExport,
+	},
+	RoleSupport:   {PermUsersRead, PermUserBlock, PermUserMessage, PermSubscriptionsEdit, PermLogsRead},
+	RoleFinance:   {PermStatsRead, PermUsersRead, PermBalanceWrite, PermWithdrawalsManage, PermLogsRead, PermDataExport},
+	RoleMarketing: {PermStatsRead, PermBroadcastSend, PermPromoManage},
+}
+
+// IsAdminRole проверяет, что роль существует
+func IsAdminRole(role string) bool {
+	for _, r := range AdminRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleHasPermission проверяет, есть ли у роли право
+func RoleHasPermission(role, permission string) bool {
+	if role == RoleOwner {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions возвращает права роли
+func RolePermissions(role string) []string {
+	if role == RoleOwner {
+		return []string{
+			PermStatsRead, PermUsersRead, PermUserBlock, PermUserMessage, PermSubscriptionsEdit, PermBalanceWrite,
+			PermBroadcastSend, PermPromoManage, PermLogsRead, PermWithdrawalsManage, PermSettingsManage, PermRolesManage,
+			PermDataExport,
+		}
+	}
+	return rolePermissions[role]
+}
+
+// GetRoleText возвращает название роли для админ-панели
+func GetRoleText(role string) string {
+	switch role {
+	case RoleOwner:
+		return "👑 Владелец"
+	case RoleAdmin:
+		return "🛡 Администратор"
+	case RoleSupport:
+		return "🆘 Поддержка"
+	case RoleFinance:
+		return "💰 Финансы"
+	case RoleMarketing:
+		return "📣 Маркетинг"
+	default:
+		return "Без роли"
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// BotSession состояние многошагового диалога пользователя с ботом
+type BotSession struct {
+	TelegramID int64     `gorm:"primaryKey;autoIncrement:false" json:"telegram_id"`
+	Step       string    `gorm:"size:100;not null" json:"step"`
+	Data       string    `gorm:"type:jsonb;not null;default:'{}'" json:"data"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
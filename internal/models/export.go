@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserExport строка выгрузки пользователей
+type UserExport struct {
+	TelegramID           int64     `json:"telegram_id"`
+	Username             string    `json:"username"`
+	FirstName            string    `json:"first_name"`
+	LastName             string    `json:"last_name"`
+	LanguageCode         string    `json:"language_code"`
+	Balance              float64   `json:"balance"`
+	IsBlocked            bool      `json:"is_blocked"`
+	ReferralCode         string    `json:"referral_code"`
+	ReferrerTelegramID   *int64    `json:"referrer_telegram_id"`
+	ActiveSubscriptions  int64     `json:"active_subscriptions"`
+	CompletedPaymentsSum float64   `json:"completed_payments_sum"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// PaymentExport строка выгрузки платежей
+type PaymentExport struct {
+	ID            uuid.UUID  `json:"id"`
+	TelegramID    int64      `json:"telegram_id"`
+	Username      string     `json:"username"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	PaymentMethod string     `json:"payment_method"`
+	Status        string     `json:"status"`
+	ExternalID    string     `json:"external_id"`
+	Description   string     `json:"description"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// SubscriptionExport строка выгрузки подписок
+type SubscriptionExport struct {
+	ID         uuid.UUID `json:"id"`
+	TelegramID int64     `json:"telegram_id"`
+	Username   string    `json:"username"`
+	PlanName   string    `json:"plan_name"`
+	ServerName string    `json:"server_name"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PromoUsageExport строка выгрузки использований промокодов
+type PromoUsageExport struct {
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          float64    `json:"value"`
+	CampaignID     string     `json:"campaign_id"`
+	TelegramID     int64      `json:"telegram_id"`
+	Username       string     `json:"username"`
+	UseNumber      int        `json:"use_number"`
+	DiscountAmount float64    `json:"discount_amount"`
+	UsedAt         time.Time  `json:"used_at"`
+	ConsumedAt     *time.Time `json:"consumed_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PartnerEarning начисление партнеру комиссии с платежа приглашенного пользователя
+type PartnerEarning struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartnerID   uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_partner_earnings_payment_partner" json:"partner_id"`
+	ReferralID  uuid.UUID `gorm:"type:uuid;not null;index" json:"referral_id"` // пользователь, совершивший платеж
+	PaymentID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_partner_earnings_payment_partner" json:"payment_id"`
+	Level       int       `gorm:"not null" json:"level"` // 1 — прямой реферал, 2 — реферал реферала
+	Percent     float64   `gorm:"type:decimal(5,2);not null" json:"percent"`
+	Amount      float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	AvailableAt time.Time `gorm:"not null;index" json:"available_at"` // окончание холда
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Связи
+	Partner  User    `gorm:"foreignKey:PartnerID" json:"partner,omitempty"`
+	Referral User    `gorm:"foreignKey:ReferralID" json:"referral,omitempty"`
+	Payment  Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+}
+
+// IsAvailable проверяет, закончился ли холд начисления
+func (e *PartnerEarning) IsAvailable() bool {
+	return !e.AvailableAt.After(time.Now())
+}
+
+// ReferralClick переход по реферальной ссылке
+type ReferralClick struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartnerID  uuid.UUID `gorm:"type:uuid;not null;index" json:"partner_id"`
+	TelegramID int64     `gorm:"not null" json:"telegram_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PartnerStats статистика партнера
+type PartnerStats struct {
+	Clicks        int64   `json:"clicks"`
+	Registrations int64   `json:"registrations"`
+	Conversions   int64   `json:"conversions"`
+	Level2Users   int64   `json:"level2_users"`
+	TotalEarned   float64 `json:"total_earned"`
+	HeldAmount    float64 `json:"held_amount"`
+	Frozen        float64 `json:"frozen"`    // в заявках на вывод
+	Withdrawn     float64 `json:"withdrawn"` // выплачено
+	Available     float64 `json:"available"`
+}
+
+// GetConversionRate возвращает долю зарегистрировавшихся, совершивших оплату, в процентах
+func (s *PartnerStats) GetConversionRate() float64 {
+	if s.Registrations == 0 {
+		return 0
+	}
+	return float64(s.Conversions) / float64(s.Registrations) * 100
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"remnawave-tg-shop/internal/i18n"
+
 	"github.com/google/uuid"
 )
 
@@ -41,24 +43,23 @@ func (p *Payment) IsFailed() bool {
 }
 
 // GetStatusText возвращает текстовое описание статуса
-func (p *Payment) GetStatusText() string {
+func (p *Payment) GetStatusText(l *i18n.Localizer) string {
 	switch p.Status {
-	case "pending":
-		return "Ожидает оплаты"
-	case "completed":
-		return "Завершен"
-	case "failed":
-		return "Неудачен"
-	case "cancelled":
-		return "Отменен"
+	case "pending", "completed", "failed", "cancelled":
+		return l.T("payment.status." + p.Status)
 	default:
-		return "Неизвестно"
+		return l.T("common.unknown_status")
 	}
 }
 
 // GetPaymentMethodText возвращает текстовое описание способа оплаты
 func (p *Payment) GetPaymentMethodText() string {
-	switch p.PaymentMethod {
+	return PaymentMethodText(p.PaymentMethod)
+}
+
+// PaymentMethodText возвращает название способа оплаты
+func PaymentMethodText(method string) string {
+	switch method {
 	case "stars":
 		return "Telegram Stars"
 	case "tribute":
@@ -66,6 +67,6 @@ func (p *Payment) GetPaymentMethodText() string {
 	case "yookassa":
 		return "ЮKassa"
 	default:
-		return p.PaymentMethod
+		return method
 	}
 }
@@ -1,17 +1,24 @@
 package models
 
 import (
+	"math"
+	"strings"
 	"time"
 
+	"remnawave-tg-shop/internal/i18n"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// PromoCodeTypeGift тип подарочного промокода: дни подписки, оплаченные другим пользователем
+const PromoCodeTypeGift = "gift_subscription"
+
 // PromoCode представляет промокод
 type PromoCode struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Code        string         `gorm:"size:50;uniqueIndex;not null" json:"code"`
-	Type        string         `gorm:"size:20;default:'bonus_days'" json:"type"` // bonus_days, discount_percent, discount_amount
+	Type        string         `gorm:"size:20;default:'bonus_days'" json:"type"` // bonus_days, discount_percent, discount_amount, gift_subscription
 	Value       float64        `gorm:"not null" json:"value"`                    // количество дней или размер скидки
 	MaxUses     int            `gorm:"default:0" json:"max_uses"`                // 0 = без ограничений
 	UsedCount   int            `gorm:"default:0" json:"used_count"`
@@ -19,11 +26,21 @@ type PromoCode struct {
 	ValidFrom   time.Time      `json:"valid_from"`
 	ValidUntil  *time.Time     `json:"valid_until,omitempty"`
 	Description string         `gorm:"size:500" json:"description"`
-	CreatedBy   uuid.UUID      `gorm:"type:uuid" json:"created_by"` // ID администратора, создавшего промокод
+	CampaignID  string         `gorm:"size:64;index" json:"campaign_id,omitempty"`
+	PlanName    string         `gorm:"size:50" json:"plan_name,omitempty"`
+	CreatedBy   uuid.UUID      `gorm:"type:uuid" json:"created_by"` // ID администратора, создавшего промокод, или покупателя подарка
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
+	// Условия применения
+	MaxUsesPerUser            int     `gorm:"default:1" json:"max_uses_per_user"`       // 0 = без ограничений
+	NewUsersOnly              bool    `gorm:"default:false" json:"new_users_only"`      // только пользователям без подписок
+	FirstPurchaseOnly         bool    `gorm:"default:false" json:"first_purchase_only"` // только до первой оплаченной подписки
+	AllowedPlans              string  `gorm:"size:255" json:"allowed_plans"`            // тарифы через запятую, пусто = все
+	MinPurchaseAmount         float64 `gorm:"type:decimal(10,2);default:0" json:"min_purchase_amount"`
+	NotCombinableWithReferral bool    `gorm:"default:false" json:"not_combinable_with_referral"`
+
 	// Связи
 	Usages []PromoCodeUsage `gorm:"foreignKey:PromoCodeID" json:"usages,omitempty"`
 }
@@ -61,27 +78,103 @@ func (pc *PromoCode) CanBeUsed() bool {
 }
 
 // GetTypeText возвращает текстовое описание типа промокода
-func (pc *PromoCode) GetTypeText() string {
+func (pc *PromoCode) GetTypeText(l *i18n.Localizer) string {
+	switch pc.Type {
+	case "bonus_days", "discount_percent", "discount_amount", PromoCodeTypeGift:
+		return l.T("promo_code.type." + pc.Type)
+	default:
+		return l.T("common.unknown_status")
+	}
+}
+
+// IsGift проверяет, является ли промокод подарочной подпиской
+func (pc *PromoCode) IsGift() bool {
+	return pc.Type == PromoCodeTypeGift
+}
+
+// IsDiscount проверяет, дает ли промокод скидку на покупку
+func (pc *PromoCode) IsDiscount() bool {
+	return pc.Type == "discount_percent" || pc.Type == "discount_amount"
+}
+
+// Discount возвращает размер скидки для указанной цены, не больше самой цены
+func (pc *PromoCode) Discount(price float64) float64 {
+	var discount float64
 	switch pc.Type {
-	case "bonus_days":
-		return "Бонусные дни"
 	case "discount_percent":
-		return "Скидка в процентах"
+		discount = math.Round(price*pc.Value) / 100
 	case "discount_amount":
-		return "Скидка в рублях"
-	default:
-		return "Неизвестно"
+		discount = pc.Value
 	}
+	return math.Min(discount, price)
+}
+
+// GetAllowedPlans возвращает тарифы, на которые действует промокод; пустой список — все тарифы
+func (pc *PromoCode) GetAllowedPlans() []string {
+	var plans []string
+	for _, plan := range strings.Split(pc.AllowedPlans, ",") {
+		if plan = strings.TrimSpace(plan); plan != "" {
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+// AllowsPlan проверяет, действует ли промокод на тариф
+func (pc *PromoCode) AllowsPlan(plan string) bool {
+	plans := pc.GetAllowedPlans()
+	if len(plans) == 0 {
+		return true
+	}
+	for _, allowed := range plans {
+		if strings.EqualFold(allowed, plan) {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoCampaignStats статистика погашений промокодов одной кампании
+type PromoCampaignStats struct {
+	CampaignID    string    `json:"campaign_id"`
+	Codes         int64     `json:"codes"`          // сгенерировано кодов
+	RedeemedCodes int64     `json:"redeemed_codes"` // кодов, использованных хотя бы раз
+	Uses          int64     `json:"uses"`           // всего использований
+	Users         int64     `json:"users"`          // уникальных пользователей
+	Discounts     float64   `json:"discounts"`      // сумма примененных скидок
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GetRedemptionRate возвращает долю использованных кодов в процентах
+func (s *PromoCampaignStats) GetRedemptionRate() float64 {
+	if s.Codes == 0 {
+		return 0
+	}
+	return float64(s.RedeemedCodes) * 100 / float64(s.Codes)
 }
 
 // PromoCodeUsage представляет использование промокода
 type PromoCodeUsage struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	PromoCodeID uuid.UUID `gorm:"type:uuid;not null;index" json:"promo_code_id"`
-	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
-	UsedAt      time.Time `json:"used_at"`
+	PromoCodeID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_promo_code_usages_user_use,priority:1" json:"promo_code_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_promo_code_usages_user_use,priority:2" json:"user_id"`
+	// UseNumber порядковый номер использования промокода этим пользователем
+	UseNumber int       `gorm:"not null;default:1;uniqueIndex:idx_promo_code_usages_user_use,priority:3" json:"use_number"`
+	UsedAt    time.Time `json:"used_at"`
+	// SubscriptionID подписка, продленная бонусными днями
+	SubscriptionID *uuid.UUID `gorm:"type:uuid" json:"subscription_id,omitempty"`
+	// DiscountAmount скидка, фактически примененная к покупке
+	DiscountAmount float64 `gorm:"type:decimal(10,2);default:0" json:"discount_amount"`
+	// ConsumedAt время покупки, к которой применена скидка; nil — скидка ожидает покупки
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
 
 	// Связи
-	PromoCode PromoCode `gorm:"foreignKey:PromoCodeID" json:"promo_code,omitempty"`
-	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	PromoCode    PromoCode     `gorm:"foreignKey:PromoCodeID" json:"promo_code,omitempty"`
+	User         User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Subscription *Subscription `gorm:"foreignKey:SubscriptionID" json:"subscription,omitempty"`
+}
+
+// IsPendingDiscount проверяет, ожидает ли скидка применения к покупке
+func (u *PromoCodeUsage) IsPendingDiscount() bool {
+	return u.PromoCode.IsDiscount() && u.ConsumedAt == nil
 }
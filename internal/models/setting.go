@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Setting значение настройки, переопределяющее переменную окружения
+type Setting struct {
+	Key       string     `gorm:"primaryKey;size:100" json:"key"`
+	Value     string     `gorm:"type:text;not null" json:"value"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SettingChange запись истории изменения настройки
+type SettingChange struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Key       string     `gorm:"size:100;not null;index" json:"key"`
+	OldValue  string     `gorm:"type:text;not null;default:''" json:"old_value"`
+	NewValue  string     `gorm:"type:text;not null;default:''" json:"new_value"`
+	Reset     bool       `gorm:"not null;default:false" json:"reset"` // возврат к значению из окружения
+	ChangedBy *uuid.UUID `gorm:"type:uuid" json:"changed_by,omitempty"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+
+	// Связи
+	ChangedByUser *User `gorm:"foreignKey:ChangedBy" json:"changed_by_user,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// RevenueBucket выручка по способу оплаты за интервал
+type RevenueBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Method string    `json:"method"`
+	Amount float64   `json:"amount"`
+	Count  int64     `json:"count"`
+}
+
+// CountBucket количество событий за интервал
+type CountBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Count  int64     `json:"count"`
+}
+
+// PeriodMetrics показатели пользователей и подписок за период
+type PeriodMetrics struct {
+	// NewUsers зарегистрировались за период, ReferredUsers — из них по реферальной ссылке
+	NewUsers      int64 `json:"new_users"`
+	ReferredUsers int64 `json:"referred_users"`
+	// PayingUsers пользователи с оплаченными платежами за период
+	PayingUsers int64 `json:"paying_users"`
+	// ReferralRevenue выручка от приглашенных пользователей
+	ReferralRevenue float64 `json:"referral_revenue"`
+	// TrialStarted начали пробный период, TrialConverted — из них купили подписку
+	TrialStarted   int64 `json:"trial_started"`
+	TrialConverted int64 `json:"trial_converted"`
+	// ActiveUsers пользователи с подпиской, действовавшей в периоде
+	ActiveUsers int64 `json:"active_users"`
+	// ActiveAtStart пользователи с подпиской на начало периода, Churned — из них без подписки на конец
+	ActiveAtStart int64 `json:"active_at_start"`
+	Churned       int64 `json:"churned"`
+}
+
+// TariffCount количество активных подписок тарифа
+type TariffCount struct {
+	PlanName string `json:"plan_name"`
+	Count    int64  `json:"count"`
+}
+
+// StatsOverview сводные показатели магазина на текущий момент
+type StatsOverview struct {
+	Users struct {
+		Total int64 `json:"total"`
+		// Active пользователи с действующей подпиской
+		Active   int64 `json:"active"`
+		Blocked  int64 `json:"blocked"`
+		NewToday int64 `json:"new_today"`
+	} `json:"users"`
+	Subscriptions struct {
+		Total     int64 `json:"total"`
+		Active    int64 `json:"active"`
+		Expired   int64 `json:"expired"`
+		Cancelled int64 `json:"cancelled"`
+	} `json:"subscriptions"`
+	// Payments оплаченная выручка за все время, сегодня и в текущем месяце
+	Payments struct {
+		Total     float64 `json:"total"`
+		Today     float64 `json:"today"`
+		ThisMonth float64 `json:"this_month"`
+	} `json:"payments"`
+}
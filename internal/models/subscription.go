@@ -3,10 +3,24 @@ package models
 import (
 	"time"
 
+	"remnawave-tg-shop/internal/i18n"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// BonusPlanName название тарифа подписки, выданной бонусными днями
+const BonusPlanName = "Bonus"
+
+// Источники подписки. Покупкой считаются только подписки с источником purchase
+const (
+	SubscriptionSourcePurchase = "purchase" // куплена пользователем с баланса
+	SubscriptionSourceGift     = "gift"     // получена по подарочному коду
+	SubscriptionSourceBonus    = "bonus"    // выдана бонусными днями
+	SubscriptionSourceTrial    = "trial"    // пробный период
+	SubscriptionSourceAdmin    = "admin"    // выдана администратором
+)
+
 // Subscription представляет подписку пользователя
 type Subscription struct {
 	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -16,6 +30,7 @@ type Subscription struct {
 	PlanID       int       `gorm:"not null" json:"plan_id"`
 	PlanName     string    `gorm:"size:255" json:"plan_name"`
 	Status       string    `gorm:"size:50;default:'active'" json:"status"` // active, expired, cancelled, suspended
+	Source       string    `gorm:"size:20;index" json:"source"`            // см. SubscriptionSource*
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -48,20 +63,16 @@ func (s *Subscription) GetDaysLeft() int {
 }
 
 // GetStatusText возвращает текстовое описание статуса
-func (s *Subscription) GetStatusText() string {
+func (s *Subscription) GetStatusText(l *i18n.Localizer) string {
 	switch s.Status {
 	case "active":
 		if s.IsExpired() {
-			return "Истекла"
+			return l.T("subscription.status.expired")
 		}
-		return "Активна"
-	case "expired":
-		return "Истекла"
-	case "cancelled":
-		return "Отменена"
-	case "suspended":
-		return "Приостановлена"
+		return l.T("subscription.status.active")
+	case "expired", "cancelled", "suspended":
+		return l.T("subscription.status." + s.Status)
 	default:
-		return "Неизвестно"
+		return l.T("common.unknown_status")
 	}
 }
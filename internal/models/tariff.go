@@ -0,0 +1,62 @@
+package models
+
+import (
+	"sync/atomic"
+
+	"remnawave-tg-shop/internal/i18n"
+)
+
+// Tariff тариф подписки, доступный для покупки в боте
+type Tariff struct {
+	Key          string // идентификатор тарифа в callback'ах
+	Name         string // название подписки
+	Emoji        string
+	DurationDays int
+	Price        float64
+}
+
+// defaultTariffs тарифы по умолчанию в порядке отображения
+var defaultTariffs = []Tariff{
+	{Key: "basic", Name: "Basic", Emoji: "📦", DurationDays: 30, Price: 299},
+	{Key: "premium", Name: "Premium", Emoji: "⭐", DurationDays: 90, Price: 799},
+	{Key: "pro", Name: "Pro", Emoji: "💎", DurationDays: 365, Price: 2499},
+}
+
+// tariffs действующий список тарифов. Опубликованный список не изменяется,
+// новые цены публикуются копией через SetTariffs
+var tariffs atomic.Pointer[[]Tariff]
+
+func init() {
+	SetTariffs(defaultTariffs)
+}
+
+// Tariffs возвращает тарифы в порядке отображения. Список общий, изменять его нельзя
+func Tariffs() []Tariff {
+	return *tariffs.Load()
+}
+
+// SetTariffs публикует новый список тарифов
+func SetTariffs(list []Tariff) {
+	list = append([]Tariff(nil), list...)
+	tariffs.Store(&list)
+}
+
+// GetTariff возвращает копию тарифа по ключу
+func GetTariff(key string) (*Tariff, bool) {
+	for _, tariff := range Tariffs() {
+		if tariff.Key == key {
+			return &tariff, true
+		}
+	}
+	return nil, false
+}
+
+// GetButtonText возвращает подпись кнопки тарифа
+func (t *Tariff) GetButtonText(l *i18n.Localizer) string {
+	return l.T("tariff.button",
+		"emoji", t.Emoji,
+		"name", t.Name,
+		"duration", l.N("units.days", t.DurationDays),
+		"price", t.Price,
+	)
+}
@@ -15,21 +15,44 @@ type User struct {
 	FirstName    string    `gorm:"size:255" json:"first_name"`
 	LastName     string    `gorm:"size:255" json:"last_name"`
 	LanguageCode string    `gorm:"size:10;default:'ru'" json:"language_code"`
+	// LanguagePreference язык, выбранный пользователем в боте; пусто — язык Telegram
+	LanguagePreference string `gorm:"size:10;index" json:"language_preference"`
 	IsBlocked    bool      `gorm:"default:false" json:"is_blocked"`
 	IsAdmin      bool      `gorm:"default:false" json:"is_admin"`
+	// AdminRole роль в админ-панели: owner, admin, support, finance, marketing; пусто — без роли
+	AdminRole string `gorm:"size:20;index" json:"admin_role"`
 	Balance      float64   `gorm:"default:0" json:"balance"`
 	ReferralCode string    `gorm:"size:20;uniqueIndex" json:"referral_code"`
 	ReferredBy   *uuid.UUID `gorm:"type:uuid" json:"referred_by"`
+	// ReferralBonusEarned сумма бонусов, заработанных на приглашенных пользователях
+	ReferralBonusEarned float64 `gorm:"type:decimal(10,2);default:0" json:"referral_bonus_earned"`
+	// ReferralRewardedAt время выплаты реферальной награды за этого пользователя
+	ReferralRewardedAt *time.Time `json:"referral_rewarded_at,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
+	// IsNew пользователь создан при обработке текущего обновления
+	IsNew bool `gorm:"-" json:"-"`
+
 	// Связи
 	Subscriptions []Subscription `gorm:"foreignKey:UserID" json:"subscriptions,omitempty"`
 	Payments      []Payment      `gorm:"foreignKey:UserID" json:"payments,omitempty"`
 	Referrals     []User         `gorm:"foreignKey:ReferredBy" json:"referrals,omitempty"`
 }
 
+// EffectiveRole возвращает роль пользователя в админ-панели. Администраторы,
+// назначенные флагом IsAdmin до появления ролей, получают роль admin
+func (u *User) EffectiveRole() string {
+	if u.AdminRole != "" {
+		return u.AdminRole
+	}
+	if u.IsAdmin {
+		return RoleAdmin
+	}
+	return ""
+}
+
 // BeforeCreate выполняется перед созданием пользователя
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
@@ -60,6 +83,12 @@ func (u *User) GetFullName() string {
 	return "Пользователь"
 }
 
+// PreferredLanguages возвращает языки пользователя в порядке приоритета:
+// выбранный в боте, затем язык клиента Telegram
+func (u *User) PreferredLanguages() []string {
+	return []string{u.LanguagePreference, u.LanguageCode}
+}
+
 // GetDisplayName возвращает отображаемое имя пользователя
 func (u *User) GetDisplayName() string {
 	if u.Username != "" {
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"remnawave-tg-shop/internal/i18n"
+
+	"github.com/google/uuid"
+)
+
+// Статусы заявки на вывод
+const (
+	WithdrawalStatusPending  = "pending"
+	WithdrawalStatusApproved = "approved"
+	WithdrawalStatusRejected = "rejected"
+	WithdrawalStatusPaid     = "paid"
+)
+
+// WithdrawalRequest заявка партнера на вывод заработанных средств.
+// Пока заявка в статусе pending или approved, ее сумма заморожена
+type WithdrawalRequest struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount      float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Method      string     `gorm:"size:20;not null" json:"method"` // card, crypto
+	Details     string     `gorm:"size:255;not null" json:"details"`
+	Status      string     `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	AdminID     *uuid.UUID `gorm:"type:uuid" json:"admin_id,omitempty"`
+	Comment     string     `gorm:"size:500" json:"comment"` // причина отклонения или примечание к выплате
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// IsFrozen проверяет, заморожены ли средства заявки
+func (w *WithdrawalRequest) IsFrozen() bool {
+	return w.Status == WithdrawalStatusPending || w.Status == WithdrawalStatusApproved
+}
+
+// GetStatusText возвращает текстовое описание статуса
+func (w *WithdrawalRequest) GetStatusText(l *i18n.Localizer) string {
+	switch w.Status {
+	case WithdrawalStatusPending, WithdrawalStatusApproved, WithdrawalStatusRejected, WithdrawalStatusPaid:
+		return l.T("withdrawal.status." + w.Status)
+	default:
+		return l.T("common.unknown_status")
+	}
+}
+
+// GetMethodText возвращает текстовое описание способа выплаты
+func (w *WithdrawalRequest) GetMethodText(l *i18n.Localizer) string {
+	switch w.Method {
+	case "card", "crypto":
+		return l.T("withdrawal.method." + w.Method)
+	default:
+		return w.Method
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminAuditFilter условия выборки журнала аудита. Пустые поля не ограничивают выборку
+type AdminAuditFilter struct {
+	Actions []string
+	// UserID пользователь, который выполнил действие или над которым оно выполнено
+	UserID *uuid.UUID
+}
+
+// adminAuditRepository реализация AdminAuditRepository
+type adminAuditRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что adminAuditRepository реализует AdminAuditRepository
+var _ AdminAuditRepository = (*adminAuditRepository)(nil)
+
+// NewAdminAuditRepository создает новый репозиторий журнала аудита
+func NewAdminAuditRepository(db *gorm.DB) AdminAuditRepository {
+	return &adminAuditRepository{db: db}
+}
+
+// Create сохраняет запись журнала
+func (r *adminAuditRepository) Create(entry *models.AdminAuditLog) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// List возвращает записи журнала по фильтру, новые сверху
+func (r *adminAuditRepository) List(filter AdminAuditFilter, limit, offset int) ([]models.AdminAuditLog, error) {
+	var entries []models.AdminAuditLog
+	err := r.applyFilter(r.db.Model(&models.AdminAuditLog{}), filter).
+		Preload("Actor").
+		Preload("TargetUser").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	return entries, nil
+}
+
+// Count возвращает количество записей журнала по фильтру
+func (r *adminAuditRepository) Count(filter AdminAuditFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.db.Model(&models.AdminAuditLog{}), filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+	return count, nil
+}
+
+// applyFilter добавляет условия фильтра к запросу
+func (r *adminAuditRepository) applyFilter(query *gorm.DB, filter AdminAuditFilter) *gorm.DB {
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if filter.UserID != nil {
+		query = query.Where("actor_id = ? OR target_user_id = ?", *filter.UserID, *filter.UserID)
+	}
+	return query
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"fmt"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminListFilter условия постраничных списков REST API. Поиск применяется
+// к пользователям, пользователь — к подпискам и платежам, статус — к
+// подпискам и платежам, способ оплаты — к платежам
+type AdminListFilter struct {
+	Search string
+	UserID *uuid.UUID
+	Status string
+	Method string
+}
+
+// adminListRepository реализация AdminListRepository
+type adminListRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что adminListRepository реализует AdminListRepository
+var _ AdminListRepository = (*adminListRepository)(nil)
+
+// NewAdminListRepository создает новый репозиторий списков для REST API
+func NewAdminListRepository(db *gorm.DB) AdminListRepository {
+	return &adminListRepository{db: db}
+}
+
+// ListUsers возвращает страницу пользователей, новые сверху, и общее количество по фильтру
+func (r *adminListRepository) ListUsers(filter AdminListFilter, limit, offset int) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("username ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR CAST(telegram_id AS TEXT) = ?",
+			pattern, pattern, pattern, filter.Search)
+	}
+
+	var users []models.User
+	total, err := listPage(query, limit, offset, &users)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// ListSubscriptions возвращает страницу подписок, новые сверху, и общее количество по фильтру
+func (r *adminListRepository) ListSubscriptions(filter AdminListFilter, limit, offset int) ([]models.Subscription, int64, error) {
+	query := r.db.Model(&models.Subscription{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var subscriptions []models.Subscription
+	total, err := listPage(query, limit, offset, &subscriptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subscriptions, total, nil
+}
+
+// ListPayments возвращает страницу платежей, новые сверху, и общее количество по фильтру
+func (r *adminListRepository) ListPayments(filter AdminListFilter, limit, offset int) ([]models.Payment, int64, error) {
+	query := r.db.Model(&models.Payment{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Method != "" {
+		query = query.Where("payment_method = ?", filter.Method)
+	}
+
+	var payments []models.Payment
+	total, err := listPage(query, limit, offset, &payments)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payments: %w", err)
+	}
+	return payments, total, nil
+}
+
+// ListPromoCodes возвращает страницу промокодов, новые сверху, и их общее количество
+func (r *adminListRepository) ListPromoCodes(limit, offset int) ([]models.PromoCode, int64, error) {
+	var promoCodes []models.PromoCode
+	total, err := listPage(r.db.Model(&models.PromoCode{}), limit, offset, &promoCodes)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	return promoCodes, total, nil
+}
+
+// listPage считает записи запроса и загружает страницу в dest
+func listPage(query *gorm.DB, limit, offset int, dest interface{}) (int64, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// botSessionRepository реализация BotSessionRepository
+type botSessionRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что botSessionRepository реализует BotSessionRepository
+var _ BotSessionRepository = (*botSessionRepository)(nil)
+
+// NewBotSessionRepository создает новый репозиторий сессий диалогов
+func NewBotSessionRepository(db *gorm.DB) BotSessionRepository {
+	return &botSessionRepository{db: db}
+}
+
+// GetByTelegramID получает сессию пользователя
+func (r *botSessionRepository) GetByTelegramID(telegramID int64) (*models.BotSession, error) {
+	var session models.BotSession
+	if err := r.db.First(&session, "telegram_id = ?", telegramID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bot session: %w", err)
+	}
+	return &session, nil
+}
+
+// Save создает или обновляет сессию пользователя
+func (r *botSessionRepository) Save(session *models.BotSession) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"step", "data", "expires_at", "updated_at"}),
+	}).Create(session).Error
+	if err != nil {
+		return fmt.Errorf("failed to save bot session: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет сессию пользователя
+func (r *botSessionRepository) Delete(telegramID int64) error {
+	if err := r.db.Delete(&models.BotSession{}, "telegram_id = ?", telegramID).Error; err != nil {
+		return fmt.Errorf("failed to delete bot session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет сессии, истекшие до указанного времени
+func (r *botSessionRepository) DeleteExpired(before time.Time) error {
+	if err := r.db.Delete(&models.BotSession{}, "expires_at < ?", before).Error; err != nil {
+		return fmt.Errorf("failed to delete expired bot sessions: %w", err)
+	}
+	return nil
+}
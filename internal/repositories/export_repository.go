@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ExportFilter условия выгрузки. Период применяется к дате создания записи
+// (для промокодов — к дате использования), статус — к платежам и подпискам,
+// способ оплаты — к платежам
+type ExportFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Status string
+	Method string
+}
+
+// exportRepository реализация ExportRepository
+type exportRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что exportRepository реализует ExportRepository
+var _ ExportRepository = (*exportRepository)(nil)
+
+// NewExportRepository создает новый репозиторий выгрузок
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// StreamUsers передает пользователей в fn по одному, не загружая выборку целиком
+func (r *exportRepository) StreamUsers(filter ExportFilter, fn func(*models.UserExport) error) error {
+	query := r.db.Table("users u").
+		Select(`u.telegram_id, u.username, u.first_name, u.last_name, u.language_code, u.balance,
+			u.is_blocked, u.referral_code, ref.telegram_id AS referrer_telegram_id,
+			(SELECT COUNT(*) FROM subscriptions s
+			  WHERE s.user_id = u.id AND s.deleted_at IS NULL AND s.status = 'active' AND s.expires_at > NOW()) AS active_subscriptions,
+			(SELECT COALESCE(SUM(p.amount), 0) FROM payments p
+			  WHERE p.user_id = u.id AND p.status = 'completed') AS completed_payments_sum,
+			u.created_at`).
+		Joins("LEFT JOIN users ref ON ref.id = u.referred_by").
+		Where("u.deleted_at IS NULL").
+		Order("u.created_at")
+	query = applyExportPeriod(query, "u.created_at", filter)
+
+	if err := streamRows(query, fn); err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+	return nil
+}
+
+// StreamPayments передает платежи в fn по одному, не загружая выборку целиком
+func (r *exportRepository) StreamPayments(filter ExportFilter, fn func(*models.PaymentExport) error) error {
+	query := r.db.Table("payments p").
+		Select(`p.id, u.telegram_id, u.username, p.amount, p.currency, p.payment_method, p.status,
+			p.external_id, p.description, p.created_at, p.completed_at`).
+		Joins("LEFT JOIN users u ON u.id = p.user_id").
+		Order("p.created_at")
+	query = applyExportPeriod(query, "p.created_at", filter)
+	if filter.Status != "" {
+		query = query.Where("p.status = ?", filter.Status)
+	}
+	if filter.Method != "" {
+		query = query.Where("p.payment_method = ?", filter.Method)
+	}
+
+	if err := streamRows(query, fn); err != nil {
+		return fmt.Errorf("failed to export payments: %w", err)
+	}
+	return nil
+}
+
+// StreamSubscriptions передает подписки в fn по одному, не загружая выборку целиком
+func (r *exportRepository) StreamSubscriptions(filter ExportFilter, fn func(*models.SubscriptionExport) error) error {
+	query := r.db.Table("subscriptions s").
+		Select("s.id, u.telegram_id, u.username, s.plan_name, s.server_name, s.status, s.expires_at, s.created_at").
+		Joins("LEFT JOIN users u ON u.id = s.user_id").
+		Where("s.deleted_at IS NULL").
+		Order("s.created_at")
+	query = applyExportPeriod(query, "s.created_at", filter)
+	if filter.Status != "" {
+		query = query.Where("s.status = ?", filter.Status)
+	}
+
+	if err := streamRows(query, fn); err != nil {
+		return fmt.Errorf("failed to export subscriptions: %w", err)
+	}
+	return nil
+}
+
+// StreamPromoUsages передает использования промокодов в fn по одному, не загружая выборку целиком
+func (r *exportRepository) StreamPromoUsages(filter ExportFilter, fn func(*models.PromoUsageExport) error) error {
+	query := r.db.Table("promo_code_usages pu").
+		Select(`pc.code, pc.type, pc.value, pc.campaign_id, u.telegram_id, u.username,
+			pu.use_number, pu.discount_amount, pu.used_at, pu.consumed_at`).
+		Joins("JOIN promo_codes pc ON pc.id = pu.promo_code_id").
+		Joins("LEFT JOIN users u ON u.id = pu.user_id").
+		Order("pu.used_at")
+	query = applyExportPeriod(query, "pu.used_at", filter)
+
+	if err := streamRows(query, fn); err != nil {
+		return fmt.Errorf("failed to export promo usages: %w", err)
+	}
+	return nil
+}
+
+// applyExportPeriod ограничивает выборку периодом фильтра по колонке column
+func applyExportPeriod(query *gorm.DB, column string, filter ExportFilter) *gorm.DB {
+	if filter.From != nil {
+		query = query.Where(column+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(column+" < ?", *filter.To)
+	}
+	return query
+}
+
+// streamRows читает строки запроса курсором и передает каждую в fn.
+// Ошибка fn прерывает чтение
+func streamRows[T any](query *gorm.DB, fn func(*T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -14,21 +14,27 @@ type UserRepository interface {
 	GetByTelegramID(telegramID int64) (*models.User, error)
 	GetByReferralCode(code string) (*models.User, error)
 	Update(user *models.User) error
+	UpdateLanguage(id uuid.UUID, language string) error
 	Delete(id uuid.UUID) error
 	List(limit, offset int) ([]models.User, error)
 	Search(query string, limit int) ([]models.User, error)
 	GetAll(limit, offset int) ([]models.User, error)
 	GetReferrals(userID uuid.UUID) ([]models.User, error)
 	GetByUsername(username string) (*models.User, error)
+	RewardReferral(reward ReferralReward) (bool, error)
+	ApplyChanges(id uuid.UUID, changes UserChanges) (*models.User, error)
+	GetStaff() ([]models.User, error)
 }
 
 // SubscriptionRepository интерфейс для работы с подписками
 type SubscriptionRepository interface {
 	Create(subscription *models.Subscription) error
+	Purchase(subscription *models.Subscription, price float64, usageID *uuid.UUID, discount float64) (float64, error)
 	GetByID(id uuid.UUID) (*models.Subscription, error)
 	GetByUserID(userID uuid.UUID) ([]models.Subscription, error)
 	GetActiveByUserID(userID uuid.UUID) ([]models.Subscription, error)
 	Update(subscription *models.Subscription) error
+	AddDays(userID uuid.UUID, days int) (*models.Subscription, error)
 	Delete(id uuid.UUID) error
 	List(limit, offset int) ([]models.Subscription, error)
 	GetExpired() ([]models.Subscription, error)
@@ -44,6 +50,7 @@ type PaymentRepository interface {
 	GetByUserID(userID uuid.UUID) ([]models.Payment, error)
 	GetByExternalID(externalID string) (*models.Payment, error)
 	Update(payment *models.Payment) error
+	Complete(id uuid.UUID, completedAt time.Time) (*models.Payment, error)
 	GetByStatus(status string) ([]models.Payment, error)
 	GetByMethod(method string) ([]models.Payment, error)
 	GetByDateRange(startDate, endDate time.Time) ([]models.Payment, error)
@@ -62,6 +69,19 @@ type PromoCodeRepository interface {
 	CreateUsage(usage *models.PromoCodeUsage) error
 	GetUsageByUserAndPromoCode(userID, promoCodeID uuid.UUID) (*models.PromoCodeUsage, error)
 	GetUsageCountByPromoCode(promoCodeID uuid.UUID) (int64, error)
+	Redeem(promoCode *models.PromoCode, usage *models.PromoCodeUsage) error
+	GetPendingDiscount(userID uuid.UUID) (*models.PromoCodeUsage, error)
+	ConsumeDiscount(usageID uuid.UUID, amount float64) (bool, error)
+	CountUserSubscriptions(userID uuid.UUID) (int64, error)
+	CountUserPurchases(userID uuid.UUID) (int64, error)
+	CreateBatch(promoCodes []models.PromoCode) error
+	CreateGift(promoCode *models.PromoCode, price float64) error
+	GetGiftsByBuyer(buyerID uuid.UUID, limit, offset int) ([]models.PromoCode, error)
+	GetExistingCodes(codes []string) ([]string, error)
+	GetByCampaign(campaignID string) ([]models.PromoCode, error)
+	CampaignExists(campaignID string) (bool, error)
+	GetCampaignStats(limit, offset int) ([]models.PromoCampaignStats, error)
+	GetCampaignStatsByID(campaignID string) (*models.PromoCampaignStats, error)
 }
 
 // NotificationRepository интерфейс для работы с уведомлениями
@@ -94,3 +114,77 @@ type ActivityLogRepository interface {
 	CountByAction(action string) (int64, error)
 	DeleteOldLogs(beforeDate time.Time) error
 }
+
+// BotSessionRepository интерфейс для работы с сессиями диалогов бота
+type BotSessionRepository interface {
+	GetByTelegramID(telegramID int64) (*models.BotSession, error)
+	Save(session *models.BotSession) error
+	Delete(telegramID int64) error
+	DeleteExpired(before time.Time) error
+}
+
+// PartnerRepository интерфейс для работы с партнерской программой
+type PartnerRepository interface {
+	CreateEarning(earning *models.PartnerEarning) (bool, error)
+	GetEarningsByPartner(partnerID uuid.UUID, limit, offset int) ([]models.PartnerEarning, error)
+	SumEarnings(partnerID uuid.UUID) (float64, error)
+	SumAvailableEarnings(partnerID uuid.UUID, at time.Time) (float64, error)
+	CreateClick(click *models.ReferralClick) error
+	CountClicks(partnerID uuid.UUID) (int64, error)
+	CountRegistrations(partnerID uuid.UUID) (int64, error)
+	CountConversions(partnerID uuid.UUID) (int64, error)
+	CountSecondLevel(partnerID uuid.UUID) (int64, error)
+}
+
+// WithdrawalRepository интерфейс для работы с заявками на вывод
+type WithdrawalRepository interface {
+	CreateIfAvailable(request *models.WithdrawalRequest, at time.Time) (bool, error)
+	GetByID(id uuid.UUID) (*models.WithdrawalRequest, error)
+	GetByUserID(userID uuid.UUID, limit, offset int) ([]models.WithdrawalRequest, error)
+	GetByStatuses(statuses []string, limit, offset int) ([]models.WithdrawalRequest, error)
+	CountByStatuses(statuses []string) (int64, error)
+	UpdateStatus(request *models.WithdrawalRequest, fromStatuses []string) (bool, error)
+	SumByStatuses(userID uuid.UUID, statuses []string) (float64, error)
+}
+
+// SettingRepository интерфейс для работы с настройками, измененными из админ-панели
+type SettingRepository interface {
+	GetAll() ([]models.Setting, error)
+	Set(setting *models.Setting, change *models.SettingChange) error
+	Delete(key string, change *models.SettingChange) error
+	GetHistory(key string, limit, offset int) ([]models.SettingChange, error)
+	LastChangeAt() (time.Time, error)
+}
+
+// AdminAuditRepository интерфейс для работы с журналом действий администраторов
+type AdminAuditRepository interface {
+	Create(entry *models.AdminAuditLog) error
+	List(filter AdminAuditFilter, limit, offset int) ([]models.AdminAuditLog, error)
+	Count(filter AdminAuditFilter) (int64, error)
+}
+
+// StatsRepository интерфейс для агрегированной статистики по платежам, подпискам и пользователям
+type StatsRepository interface {
+	RevenueByHour(from, to time.Time) ([]models.RevenueBucket, error)
+	PeriodMetrics(from, to time.Time) (*models.PeriodMetrics, error)
+	ActiveByTariff(at time.Time) ([]models.TariffCount, error)
+	RegistrationsByHour(from, to time.Time) ([]models.CountBucket, error)
+	ActiveSubscriptionsAt(moments []time.Time) ([]int64, error)
+	Overview(now, dayStart, monthStart time.Time) (*models.StatsOverview, error)
+}
+
+// ExportRepository интерфейс для потоковой выгрузки данных из админ-панели
+type ExportRepository interface {
+	StreamUsers(filter ExportFilter, fn func(*models.UserExport) error) error
+	StreamPayments(filter ExportFilter, fn func(*models.PaymentExport) error) error
+	StreamSubscriptions(filter ExportFilter, fn func(*models.SubscriptionExport) error) error
+	StreamPromoUsages(filter ExportFilter, fn func(*models.PromoUsageExport) error) error
+}
+
+// AdminListRepository интерфейс постраничных списков с фильтрами для REST API
+type AdminListRepository interface {
+	ListUsers(filter AdminListFilter, limit, offset int) ([]models.User, int64, error)
+	ListSubscriptions(filter AdminListFilter, limit, offset int) ([]models.Subscription, int64, error)
+	ListPayments(filter AdminListFilter, limit, offset int) ([]models.Payment, int64, error)
+	ListPromoCodes(limit, offset int) ([]models.PromoCode, int64, error)
+}
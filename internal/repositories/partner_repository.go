@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// partnerRepository реализация PartnerRepository
+type partnerRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что partnerRepository реализует PartnerRepository
+var _ PartnerRepository = (*partnerRepository)(nil)
+
+// NewPartnerRepository создает новый репозиторий партнерской программы
+func NewPartnerRepository(db *gorm.DB) PartnerRepository {
+	return &partnerRepository{db: db}
+}
+
+// CreateEarning создает начисление. Возвращает false, если начисление
+// с этого платежа этому партнеру уже существует
+func (r *partnerRepository) CreateEarning(earning *models.PartnerEarning) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(earning)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create partner earning: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetEarningsByPartner получает начисления партнера
+func (r *partnerRepository) GetEarningsByPartner(partnerID uuid.UUID, limit, offset int) ([]models.PartnerEarning, error) {
+	var earnings []models.PartnerEarning
+	if err := r.db.Where("partner_id = ?", partnerID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&earnings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get partner earnings: %w", err)
+	}
+	return earnings, nil
+}
+
+// SumEarnings возвращает сумму всех начислений партнера
+func (r *partnerRepository) SumEarnings(partnerID uuid.UUID) (float64, error) {
+	var total float64
+	if err := r.db.Model(&models.PartnerEarning{}).
+		Where("partner_id = ?", partnerID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum partner earnings: %w", err)
+	}
+	return total, nil
+}
+
+// SumAvailableEarnings возвращает сумму начислений, холд которых закончился к моменту at
+func (r *partnerRepository) SumAvailableEarnings(partnerID uuid.UUID, at time.Time) (float64, error) {
+	var total float64
+	if err := r.db.Model(&models.PartnerEarning{}).
+		Where("partner_id = ? AND available_at <= ?", partnerID, at).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum available partner earnings: %w", err)
+	}
+	return total, nil
+}
+
+// CreateClick сохраняет переход по реферальной ссылке
+func (r *partnerRepository) CreateClick(click *models.ReferralClick) error {
+	if err := r.db.Create(click).Error; err != nil {
+		return fmt.Errorf("failed to create referral click: %w", err)
+	}
+	return nil
+}
+
+// CountClicks возвращает количество переходов по ссылке партнера
+func (r *partnerRepository) CountClicks(partnerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.ReferralClick{}).
+		Where("partner_id = ?", partnerID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count referral clicks: %w", err)
+	}
+	return count, nil
+}
+
+// CountRegistrations возвращает количество пользователей, приглашенных партнером
+func (r *partnerRepository) CountRegistrations(partnerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).
+		Where("referred_by = ?", partnerID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count referral registrations: %w", err)
+	}
+	return count, nil
+}
+
+// CountConversions возвращает количество приглашенных пользователей с завершенными платежами
+func (r *partnerRepository) CountConversions(partnerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).
+		Where("referred_by = ?", partnerID).
+		Where("EXISTS (SELECT 1 FROM payments WHERE payments.user_id = users.id AND payments.status = ?)", "completed").
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count referral conversions: %w", err)
+	}
+	return count, nil
+}
+
+// CountSecondLevel возвращает количество пользователей второго уровня
+func (r *partnerRepository) CountSecondLevel(partnerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).
+		Where("referred_by IN (?)", r.db.Model(&models.User{}).Select("id").Where("referred_by = ?", partnerID)).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count second level referrals: %w", err)
+	}
+	return count, nil
+}
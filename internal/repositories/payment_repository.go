@@ -69,6 +69,48 @@ func (r *paymentRepository) Update(payment *models.Payment) error {
 	return nil
 }
 
+// Complete переводит ожидающий платеж в completed и зачисляет сумму на баланс
+// пользователя одной транзакцией. Возвращает nil, если платеж не ожидал оплаты,
+// поэтому повторное уведомление об оплате ничего не зачисляет
+func (r *paymentRepository) Complete(id uuid.UUID, completedAt time.Time) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ?", id, "pending").
+			Updates(map[string]interface{}{
+				"status":       "completed",
+				"completed_at": completedAt,
+				"updated_at":   completedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to complete payment: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.First(&payment, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get completed payment: %w", err)
+		}
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", payment.UserID).
+			Updates(map[string]interface{}{
+				"balance":    gorm.Expr("balance + ?", payment.Amount),
+				"updated_at": completedAt,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to credit payment: %w", err)
+		}
+		return nil
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
 // Delete удаляет платеж
 func (r *paymentRepository) Delete(id uuid.UUID) error {
 	if err := r.db.Delete(&models.Payment{}, "id = ?", id).Error; err != nil {
@@ -1,11 +1,27 @@
 package repositories
 
 import (
+	"errors"
+	"fmt"
 	"remnawave-tg-shop/internal/models"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPromoCodeUnavailable промокод неактивен, истек или исчерпан
+	ErrPromoCodeUnavailable = errors.New("promo code is not available")
+	// ErrPromoCodeUserLimit пользователь исчерпал свой лимит использований промокода
+	ErrPromoCodeUserLimit = errors.New("promo code per-user limit reached")
+	// ErrPromoCodePendingDiscount у пользователя уже есть скидка, ожидающая покупки
+	ErrPromoCodePendingDiscount = errors.New("pending discount already exists")
+	// ErrInsufficientBalance на балансе пользователя недостаточно средств
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrDiscountConsumed скидка по промокоду уже применена к другой покупке
+	ErrDiscountConsumed = errors.New("discount already consumed")
 )
 
 type promoCodeRepository struct {
@@ -96,3 +112,265 @@ func (r *promoCodeRepository) GetUsageCountByPromoCode(promoCodeID uuid.UUID) (i
 	err := r.db.Model(&models.PromoCodeUsage{}).Where("promo_code_id = ?", promoCodeID).Count(&count).Error
 	return count, err
 }
+
+// Redeem погашает промокод в одной транзакции: проверяет лимит на пользователя,
+// условно увеличивает счетчик, записывает использование и для бонусных дней
+// продлевает подписку. Параллельные погашения не могут превысить MaxUses:
+// счетчик растет только пока промокод действителен, а повторная запись
+// с тем же номером использования отбрасывается уникальным индексом.
+// Скидка записывается, только если у пользователя нет другой ожидающей скидки:
+// строка пользователя блокируется, поэтому параллельные применения скидок
+// проверяются по очереди
+func (r *promoCodeRepository) Redeem(promoCode *models.PromoCode, usage *models.PromoCodeUsage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if promoCode.IsDiscount() {
+			var user models.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id").
+				Where("id = ?", usage.UserID).
+				First(&user).Error; err != nil {
+				return fmt.Errorf("failed to lock user: %w", err)
+			}
+
+			var pending int64
+			if err := tx.Model(&models.PromoCodeUsage{}).
+				Joins("JOIN promo_codes ON promo_codes.id = promo_code_usages.promo_code_id").
+				Where("promo_code_usages.user_id = ? AND promo_code_usages.consumed_at IS NULL", usage.UserID).
+				Where("promo_codes.type IN ?", []string{"discount_percent", "discount_amount"}).
+				Count(&pending).Error; err != nil {
+				return fmt.Errorf("failed to count pending discounts: %w", err)
+			}
+			if pending > 0 {
+				return ErrPromoCodePendingDiscount
+			}
+		}
+
+		var used int64
+		if err := tx.Model(&models.PromoCodeUsage{}).
+			Where("promo_code_id = ? AND user_id = ?", promoCode.ID, usage.UserID).
+			Count(&used).Error; err != nil {
+			return fmt.Errorf("failed to count promo code usages: %w", err)
+		}
+		if promoCode.MaxUsesPerUser > 0 && used >= int64(promoCode.MaxUsesPerUser) {
+			return ErrPromoCodeUserLimit
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND is_active = ? AND valid_from <= ?", promoCode.ID, true, now).
+			Where("valid_until IS NULL OR valid_until > ?", now).
+			Where("max_uses = 0 OR used_count < max_uses").
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to increment promo code usage: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoCodeUnavailable
+		}
+
+		// Бонусные дни и подарок продлевают подписку получателя или выдают новую
+		if promoCode.Type == "bonus_days" || promoCode.IsGift() {
+			planName, source := models.BonusPlanName, models.SubscriptionSourceBonus
+			if promoCode.IsGift() {
+				source = models.SubscriptionSourceGift
+				if promoCode.PlanName != "" {
+					planName = promoCode.PlanName
+				}
+			}
+			subscription, err := addSubscriptionDays(tx, usage.UserID, int(promoCode.Value), planName, source)
+			if err != nil {
+				return err
+			}
+			usage.SubscriptionID = &subscription.ID
+			usage.Subscription = subscription
+		}
+
+		usage.UseNumber = int(used) + 1
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(usage)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create promo code usage: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPromoCodeUserLimit
+		}
+		return nil
+	})
+}
+
+// CountUserSubscriptions возвращает количество подписок пользователя, включая пробные и удаленные
+func (r *promoCodeRepository) CountUserSubscriptions(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Subscription{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// CountUserPurchases возвращает количество подписок, купленных пользователем.
+// Подарки, бонусные дни, пробный период и выданные администратором не считаются
+func (r *promoCodeRepository) CountUserPurchases(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Subscription{}).
+		Where("user_id = ? AND source = ?", userID, models.SubscriptionSourcePurchase).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateGift списывает стоимость подарка с баланса покупателя и создает
+// подарочный промокод одной транзакцией
+func (r *promoCodeRepository) CreateGift(promoCode *models.PromoCode, price float64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND balance >= ?", promoCode.CreatedBy, price).
+			Updates(map[string]interface{}{
+				"balance":    gorm.Expr("balance - ?", price),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to charge gift: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientBalance
+		}
+
+		if err := tx.Create(promoCode).Error; err != nil {
+			return fmt.Errorf("failed to create gift promo code: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetGiftsByBuyer получает подарки, купленные пользователем, начиная с новых
+func (r *promoCodeRepository) GetGiftsByBuyer(buyerID uuid.UUID, limit, offset int) ([]models.PromoCode, error) {
+	var promoCodes []models.PromoCode
+	err := r.db.Where("type = ? AND created_by = ?", models.PromoCodeTypeGift, buyerID).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&promoCodes).Error
+	return promoCodes, err
+}
+
+// promoBatchInsertSize размер пачки при вставке сгенерированных промокодов
+const promoBatchInsertSize = 500
+
+// CreateBatch создает пакет промокодов одной транзакцией: при конфликте
+// кода не создается ни один промокод пакета
+func (r *promoCodeRepository) CreateBatch(promoCodes []models.PromoCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(promoCodes, promoBatchInsertSize).Error; err != nil {
+			return fmt.Errorf("failed to create promo code batch: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetExistingCodes возвращает коды из списка, которые уже заняты, включая удаленные промокоды
+func (r *promoCodeRepository) GetExistingCodes(codes []string) ([]string, error) {
+	var existing []string
+	for start := 0; start < len(codes); start += promoBatchInsertSize {
+		end := min(start+promoBatchInsertSize, len(codes))
+
+		var chunk []string
+		if err := r.db.Unscoped().Model(&models.PromoCode{}).
+			Where("code IN ?", codes[start:end]).
+			Pluck("code", &chunk).Error; err != nil {
+			return nil, fmt.Errorf("failed to check promo codes: %w", err)
+		}
+		existing = append(existing, chunk...)
+	}
+	return existing, nil
+}
+
+// GetByCampaign получает все промокоды кампании
+func (r *promoCodeRepository) GetByCampaign(campaignID string) ([]models.PromoCode, error) {
+	var promoCodes []models.PromoCode
+	err := r.db.Where("campaign_id = ?", campaignID).Order("code ASC").Find(&promoCodes).Error
+	return promoCodes, err
+}
+
+// CampaignExists проверяет, есть ли промокоды с этим ID кампании, включая удаленные
+func (r *promoCodeRepository) CampaignExists(campaignID string) (bool, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&models.PromoCode{}).
+		Where("campaign_id = ?", campaignID).
+		Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check promo campaign: %w", err)
+	}
+	return count > 0, nil
+}
+
+// campaignStatsQuery собирает статистику кампаний по промокодам и их использованиям
+func (r *promoCodeRepository) campaignStatsQuery() *gorm.DB {
+	return r.db.Model(&models.PromoCode{}).
+		Select(`promo_codes.campaign_id,
+			COUNT(*) AS codes,
+			COUNT(*) FILTER (WHERE promo_codes.used_count > 0) AS redeemed_codes,
+			COALESCE(SUM(promo_codes.used_count), 0) AS uses,
+			(SELECT COUNT(DISTINCT u.user_id) FROM promo_code_usages u
+				JOIN promo_codes p ON p.id = u.promo_code_id
+				WHERE p.campaign_id = promo_codes.campaign_id) AS users,
+			(SELECT COALESCE(SUM(u.discount_amount), 0) FROM promo_code_usages u
+				JOIN promo_codes p ON p.id = u.promo_code_id
+				WHERE p.campaign_id = promo_codes.campaign_id AND u.consumed_at IS NOT NULL) AS discounts,
+			MIN(promo_codes.created_at) AS created_at`).
+		Where("promo_codes.campaign_id <> ''").
+		Group("promo_codes.campaign_id")
+}
+
+// GetCampaignStats получает статистику кампаний, начиная с самых новых
+func (r *promoCodeRepository) GetCampaignStats(limit, offset int) ([]models.PromoCampaignStats, error) {
+	var stats []models.PromoCampaignStats
+	if err := r.campaignStatsQuery().
+		Order("MIN(promo_codes.created_at) DESC").
+		Limit(limit).Offset(offset).
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get promo campaign stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetCampaignStatsByID получает статистику одной кампании или nil, если ее нет
+func (r *promoCodeRepository) GetCampaignStatsByID(campaignID string) (*models.PromoCampaignStats, error) {
+	var stats []models.PromoCampaignStats
+	if err := r.campaignStatsQuery().
+		Where("promo_codes.campaign_id = ?", campaignID).
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get promo campaign stats: %w", err)
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+	return &stats[0], nil
+}
+
+// GetPendingDiscount получает последнюю скидку пользователя, еще не примененную к покупке
+func (r *promoCodeRepository) GetPendingDiscount(userID uuid.UUID) (*models.PromoCodeUsage, error) {
+	var usage models.PromoCodeUsage
+	err := r.db.Preload("PromoCode").
+		Joins("JOIN promo_codes ON promo_codes.id = promo_code_usages.promo_code_id").
+		Where("promo_code_usages.user_id = ? AND promo_code_usages.consumed_at IS NULL", userID).
+		Where("promo_codes.type IN ?", []string{"discount_percent", "discount_amount"}).
+		Order("promo_code_usages.used_at DESC").
+		First(&usage).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending discount: %w", err)
+	}
+	return &usage, nil
+}
+
+// ConsumeDiscount отмечает скидку примененной. Возвращает false, если скидка уже использована
+func (r *promoCodeRepository) ConsumeDiscount(usageID uuid.UUID, amount float64) (bool, error) {
+	result := r.db.Model(&models.PromoCodeUsage{}).
+		Where("id = ? AND consumed_at IS NULL", usageID).
+		Updates(map[string]interface{}{
+			"consumed_at":     time.Now(),
+			"discount_amount": amount,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to consume discount: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// settingRepository реализация SettingRepository
+type settingRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что settingRepository реализует SettingRepository
+var _ SettingRepository = (*settingRepository)(nil)
+
+// NewSettingRepository создает новый репозиторий настроек
+func NewSettingRepository(db *gorm.DB) SettingRepository {
+	return &settingRepository{db: db}
+}
+
+// GetAll возвращает все сохраненные настройки
+func (r *settingRepository) GetAll() ([]models.Setting, error) {
+	var settings []models.Setting
+	if err := r.db.Find(&settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Set сохраняет значение настройки и запись истории в одной транзакции
+func (r *settingRepository) Set(setting *models.Setting, change *models.SettingChange) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+		}).Create(setting).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save setting %s: %w", setting.Key, err)
+	}
+	return nil
+}
+
+// Delete удаляет значение настройки, возвращая значение из окружения, и пишет историю
+func (r *settingRepository) Delete(key string, change *models.SettingChange) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Setting{}, "key = ?", key).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetHistory возвращает историю изменений настройки, новые сверху
+func (r *settingRepository) GetHistory(key string, limit, offset int) ([]models.SettingChange, error) {
+	var changes []models.SettingChange
+	err := r.db.Preload("ChangedByUser").
+		Where("key = ?", key).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setting history: %w", err)
+	}
+	return changes, nil
+}
+
+// LastChangeAt возвращает время последнего изменения любой настройки.
+// Нулевое время означает, что настройки еще не менялись
+func (r *settingRepository) LastChangeAt() (time.Time, error) {
+	var last *time.Time
+	if err := r.db.Model(&models.SettingChange{}).
+		Select("MAX(created_at)").
+		Scan(&last).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last setting change: %w", err)
+	}
+	if last == nil {
+		return time.Time{}, nil
+	}
+	return *last, nil
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// statsRepository реализация StatsRepository
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что statsRepository реализует StatsRepository
+var _ StatsRepository = (*statsRepository)(nil)
+
+// NewStatsRepository создает новый репозиторий статистики
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// RevenueByHour возвращает оплаченную выручку по часам и способам оплаты.
+// Группировка по часу не зависит от часового пояса базы, интервалы
+// нужной длины собираются из часов на стороне приложения
+func (r *statsRepository) RevenueByHour(from, to time.Time) ([]models.RevenueBucket, error) {
+	var rows []models.RevenueBucket
+	err := r.db.Raw(`
+		SELECT date_trunc('hour', COALESCE(completed_at, created_at)) AS bucket,
+		       payment_method AS method,
+		       SUM(amount) AS amount,
+		       COUNT(*) AS count
+		FROM payments
+		WHERE status = 'completed'
+		  AND COALESCE(completed_at, created_at) >= @from
+		  AND COALESCE(completed_at, created_at) < @to
+		GROUP BY bucket, method
+		ORDER BY bucket`,
+		map[string]interface{}{"from": from, "to": to},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue: %w", err)
+	}
+	return rows, nil
+}
+
+// PeriodMetrics считает показатели пользователей и подписок за период
+func (r *statsRepository) PeriodMetrics(from, to time.Time) (*models.PeriodMetrics, error) {
+	params := map[string]interface{}{"from": from, "to": to}
+	var metrics models.PeriodMetrics
+
+	err := r.db.Raw(`
+		SELECT COUNT(*) AS new_users,
+		       COUNT(CASE WHEN referred_by IS NOT NULL THEN 1 END) AS referred_users
+		FROM users
+		WHERE deleted_at IS NULL AND created_at >= @from AND created_at < @to`,
+		params,
+	).Scan(&metrics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count new users: %w", err)
+	}
+
+	var paying struct {
+		PayingUsers     int64
+		ReferralRevenue float64
+	}
+	err = r.db.Raw(`
+		SELECT COUNT(DISTINCT p.user_id) AS paying_users,
+		       COALESCE(SUM(CASE WHEN u.referred_by IS NOT NULL THEN p.amount END), 0) AS referral_revenue
+		FROM payments p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.status = 'completed'
+		  AND COALESCE(p.completed_at, p.created_at) >= @from
+		  AND COALESCE(p.completed_at, p.created_at) < @to`,
+		params,
+	).Scan(&paying).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count paying users: %w", err)
+	}
+	metrics.PayingUsers = paying.PayingUsers
+	metrics.ReferralRevenue = paying.ReferralRevenue
+
+	// Пробная подписка имеет plan_id = 0, конверсия — любая платная подписка после нее
+	var trial struct {
+		TrialStarted   int64
+		TrialConverted int64
+	}
+	err = r.db.Raw(`
+		SELECT COUNT(DISTINCT t.user_id) AS trial_started,
+		       COUNT(DISTINCT CASE WHEN EXISTS (
+		           SELECT 1 FROM subscriptions p
+		           WHERE p.user_id = t.user_id AND p.plan_id <> 0
+		             AND p.deleted_at IS NULL AND p.created_at > t.created_at
+		       ) THEN t.user_id END) AS trial_converted
+		FROM subscriptions t
+		WHERE t.plan_id = 0 AND t.deleted_at IS NULL
+		  AND t.created_at >= @from AND t.created_at < @to`,
+		params,
+	).Scan(&trial).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count trial conversion: %w", err)
+	}
+	metrics.TrialStarted = trial.TrialStarted
+	metrics.TrialConverted = trial.TrialConverted
+
+	var churn struct {
+		ActiveUsers   int64
+		ActiveAtStart int64
+		Churned       int64
+	}
+	err = r.db.Raw(`
+		WITH active AS (
+		    SELECT user_id,
+		           BOOL_OR(created_at < @from AND expires_at >= @from) AS at_start,
+		           BOOL_OR(expires_at >= @to) AS at_end
+		    FROM subscriptions
+		    WHERE deleted_at IS NULL AND status <> 'cancelled'
+		      AND created_at < @to AND expires_at >= @from
+		    GROUP BY user_id
+		)
+		SELECT COUNT(*) AS active_users,
+		       COUNT(CASE WHEN at_start THEN 1 END) AS active_at_start,
+		       COUNT(CASE WHEN at_start AND NOT at_end THEN 1 END) AS churned
+		FROM active`,
+		params,
+	).Scan(&churn).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count churn: %w", err)
+	}
+	metrics.ActiveUsers = churn.ActiveUsers
+	metrics.ActiveAtStart = churn.ActiveAtStart
+	metrics.Churned = churn.Churned
+
+	return &metrics, nil
+}
+
+// ActiveByTariff возвращает количество активных подписок по тарифам на момент at
+func (r *statsRepository) ActiveByTariff(at time.Time) ([]models.TariffCount, error) {
+	var rows []models.TariffCount
+	err := r.db.Raw(`
+		SELECT plan_name, COUNT(*) AS count
+		FROM subscriptions
+		WHERE deleted_at IS NULL AND status = 'active' AND expires_at > ?
+		GROUP BY plan_name
+		ORDER BY count DESC`,
+		at,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active subscriptions: %w", err)
+	}
+	return rows, nil
+}
+
+// RegistrationsByHour возвращает количество регистраций по часам
+func (r *statsRepository) RegistrationsByHour(from, to time.Time) ([]models.CountBucket, error) {
+	var rows []models.CountBucket
+	err := r.db.Raw(`
+		SELECT date_trunc('hour', created_at) AS bucket, COUNT(*) AS count
+		FROM users
+		WHERE deleted_at IS NULL AND created_at >= ? AND created_at < ?
+		GROUP BY bucket
+		ORDER BY bucket`,
+		from, to,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrations: %w", err)
+	}
+	return rows, nil
+}
+
+// ActiveSubscriptionsAt возвращает количество действующих подписок на каждый
+// из моментов в том же порядке
+func (r *statsRepository) ActiveSubscriptionsAt(moments []time.Time) ([]int64, error) {
+	counts := make([]int64, len(moments))
+	if len(moments) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		At    time.Time
+		Count int64
+	}
+	err := r.db.Raw(`
+		SELECT m.at AS at, COUNT(s.id) AS count
+		FROM unnest(ARRAY[?]::timestamptz[]) AS m(at)
+		LEFT JOIN subscriptions s
+		       ON s.deleted_at IS NULL AND s.status <> 'cancelled'
+		      AND s.created_at <= m.at AND s.expires_at > m.at
+		GROUP BY m.at`,
+		moments,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active subscriptions: %w", err)
+	}
+
+	byMoment := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		byMoment[row.At.UnixMicro()] = row.Count
+	}
+	for i, moment := range moments {
+		counts[i] = byMoment[moment.UnixMicro()]
+	}
+	return counts, nil
+}
+
+// Overview считает сводные показатели. dayStart и monthStart — начало
+// текущих суток и месяца в часовом поясе приложения
+func (r *statsRepository) Overview(now, dayStart, monthStart time.Time) (*models.StatsOverview, error) {
+	params := map[string]interface{}{"now": now, "day": dayStart, "month": monthStart}
+	var overview models.StatsOverview
+
+	err := r.db.Raw(`
+		SELECT COUNT(*) AS total,
+		       COUNT(CASE WHEN is_blocked THEN 1 END) AS blocked,
+		       COUNT(CASE WHEN created_at >= @day THEN 1 END) AS new_today,
+		       (SELECT COUNT(DISTINCT user_id) FROM subscriptions
+		         WHERE deleted_at IS NULL AND status = 'active' AND expires_at > @now) AS active
+		FROM users
+		WHERE deleted_at IS NULL`,
+		params,
+	).Scan(&overview.Users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users overview: %w", err)
+	}
+
+	err = r.db.Raw(`
+		SELECT COUNT(*) AS total,
+		       COUNT(CASE WHEN status = 'active' AND expires_at > @now THEN 1 END) AS active,
+		       COUNT(CASE WHEN status <> 'cancelled' AND expires_at <= @now THEN 1 END) AS expired,
+		       COUNT(CASE WHEN status = 'cancelled' THEN 1 END) AS cancelled
+		FROM subscriptions
+		WHERE deleted_at IS NULL`,
+		params,
+	).Scan(&overview.Subscriptions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions overview: %w", err)
+	}
+
+	err = r.db.Raw(`
+		SELECT COALESCE(SUM(amount), 0) AS total,
+		       COALESCE(SUM(CASE WHEN COALESCE(completed_at, created_at) >= @day THEN amount END), 0) AS today,
+		       COALESCE(SUM(CASE WHEN COALESCE(completed_at, created_at) >= @month THEN amount END), 0) AS this_month
+		FROM payments
+		WHERE status = 'completed'`,
+		params,
+	).Scan(&overview.Payments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments overview: %w", err)
+	}
+
+	return &overview, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // subscriptionRepository реализация SubscriptionRepository
@@ -31,6 +32,58 @@ func (r *subscriptionRepository) Create(subscription *models.Subscription) error
 	return nil
 }
 
+// Purchase оплачивает подписку с баланса одной транзакцией: списывает price,
+// если на балансе достаточно средств, погашает скидку usageID и создает
+// подписку. Возвращает баланс после списания
+func (r *subscriptionRepository) Purchase(subscription *models.Subscription, price float64, usageID *uuid.UUID, discount float64) (float64, error) {
+	var balance float64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND balance >= ?", subscription.UserID, price).
+			Updates(map[string]interface{}{
+				"balance":    gorm.Expr("balance - ?", price),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to charge purchase: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientBalance
+		}
+
+		if usageID != nil {
+			result := tx.Model(&models.PromoCodeUsage{}).
+				Where("id = ? AND consumed_at IS NULL", *usageID).
+				Updates(map[string]interface{}{
+					"consumed_at":     time.Now(),
+					"discount_amount": discount,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to consume discount: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return ErrDiscountConsumed
+			}
+		}
+
+		if err := tx.Create(subscription).Error; err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", subscription.UserID).
+			Select("balance").
+			Scan(&balance).Error; err != nil {
+			return fmt.Errorf("failed to get balance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
 // GetByID получает подписку по ID
 func (r *subscriptionRepository) GetByID(id uuid.UUID) (*models.Subscription, error) {
 	var subscription models.Subscription
@@ -127,3 +180,59 @@ func (r *subscriptionRepository) GetUsersWithExpiredSubscriptions() ([]models.Us
 		Find(&users).Error
 	return users, err
 }
+
+// AddDays продлевает подписку пользователя, которая истекает позже всех,
+// а если активной подписки нет — создает бонусную
+func (r *subscriptionRepository) AddDays(userID uuid.UUID, days int) (*models.Subscription, error) {
+	var subscription *models.Subscription
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		subscription, err = addSubscriptionDays(tx, userID, days, models.BonusPlanName, models.SubscriptionSourceBonus)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// addSubscriptionDays выполняет продление в переданной транзакции, чтобы его
+// можно было объединить с другими изменениями (например, с погашением промокода).
+// planName задает название подписки, если ее приходится создавать
+func addSubscriptionDays(tx *gorm.DB, userID uuid.UUID, days int, planName, source string) (*models.Subscription, error) {
+	now := time.Now()
+
+	var latest models.Subscription
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, "active", now).
+		Order("expires_at DESC").
+		First(&latest).Error
+	if err == nil {
+		latest.ExpiresAt = latest.ExpiresAt.AddDate(0, 0, days)
+		latest.UpdatedAt = now
+		if err := tx.Save(&latest).Error; err != nil {
+			return nil, fmt.Errorf("failed to extend subscription: %w", err)
+		}
+		return &latest, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get active subscription: %w", err)
+	}
+
+	subscription := &models.Subscription{
+		UserID:     userID,
+		ServerID:   1, // По умолчанию сервер 1
+		ServerName: "Default Server",
+		PlanID:     1, // По умолчанию план 1
+		PlanName:   planName,
+		Status:     "active",
+		Source:     source,
+		ExpiresAt:  now.AddDate(0, 0, days),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := tx.Create(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create bonus subscription: %w", err)
+	}
+	return subscription, nil
+}
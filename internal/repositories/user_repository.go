@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"fmt"
+	"time"
 
 	"remnawave-tg-shop/internal/models"
 
@@ -74,6 +75,15 @@ func (r *userRepository) Update(user *models.User) error {
 	return nil
 }
 
+// UpdateLanguage сохраняет только язык интерфейса, не затрагивая баланс
+// и остальные поля, которые могли измениться после загрузки пользователя
+func (r *userRepository) UpdateLanguage(id uuid.UUID, language string) error {
+	if err := r.db.Model(&models.User{}).Where("id = ?", id).Update("language_preference", language).Error; err != nil {
+		return fmt.Errorf("failed to update user language: %w", err)
+	}
+	return nil
+}
+
 // Delete удаляет пользователя
 func (r *userRepository) Delete(id uuid.UUID) error {
 	if err := r.db.Delete(&models.User{}, "id = ?", id).Error; err != nil {
@@ -145,3 +155,154 @@ func (r *userRepository) GetAll(limit, offset int) ([]models.User, error) {
 
 	return users, nil
 }
+
+// ReferralReward награды за первую оплаченную покупку приглашенного пользователя
+type ReferralReward struct {
+	UserID     uuid.UUID
+	ReferrerID uuid.UUID
+	// ReferrerBalance и ReferredBalance рубли на баланс сторон
+	ReferrerBalance float64
+	ReferredBalance float64
+	// BonusDays дни подписки обеим сторонам
+	BonusDays int
+	// Earned прибавка к referral_bonus_earned пригласившего
+	Earned float64
+}
+
+// RewardReferral отмечает выплату награды за пользователя и начисляет ее одной
+// транзакцией: отметка ставится условным UPDATE, поэтому награда выплачивается
+// ровно один раз, а при ошибке начисления отметка откатывается.
+// Возвращает false, если награда уже была выплачена
+func (r *userRepository) RewardReferral(reward ReferralReward) (bool, error) {
+	rewarded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND referred_by IS NOT NULL AND referral_rewarded_at IS NULL", reward.UserID).
+			Update("referral_rewarded_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark referral rewarded: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", reward.ReferrerID).
+			Updates(map[string]interface{}{
+				"balance":               gorm.Expr("balance + ?", reward.ReferrerBalance),
+				"referral_bonus_earned": gorm.Expr("referral_bonus_earned + ?", reward.Earned),
+				"updated_at":            now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to pay referrer reward: %w", err)
+		}
+		if reward.ReferredBalance > 0 {
+			if err := tx.Model(&models.User{}).
+				Where("id = ?", reward.UserID).
+				Updates(map[string]interface{}{
+					"balance":    gorm.Expr("balance + ?", reward.ReferredBalance),
+					"updated_at": now,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to pay referred reward: %w", err)
+			}
+		}
+
+		if reward.BonusDays > 0 {
+			if _, err := addSubscriptionDays(tx, reward.ReferrerID, reward.BonusDays, models.BonusPlanName, models.SubscriptionSourceBonus); err != nil {
+				return fmt.Errorf("failed to add referrer bonus days: %w", err)
+			}
+			if _, err := addSubscriptionDays(tx, reward.UserID, reward.BonusDays, models.BonusPlanName, models.SubscriptionSourceBonus); err != nil {
+				return fmt.Errorf("failed to add referred bonus days: %w", err)
+			}
+		}
+
+		rewarded = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return rewarded, nil
+}
+
+// UserChanges изменения пользователя из REST API. Поля nil не меняются
+type UserChanges struct {
+	FirstName *string
+	LastName  *string
+	IsBlocked *bool
+	// BalanceDelta прибавка к балансу, отрицательная — списание
+	BalanceDelta float64
+}
+
+// ApplyChanges применяет изменения одной транзакцией и возвращает пользователя
+// после изменений. Списание выполняется условным UPDATE и не проходит, если баланс
+// станет отрицательным: тогда возвращается ErrInsufficientBalance и ничего не меняется
+func (r *userRepository) ApplyChanges(id uuid.UUID, changes UserChanges) (*models.User, error) {
+	var user models.User
+	found := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		found = true
+
+		now := time.Now()
+		updates := map[string]interface{}{}
+		if changes.FirstName != nil {
+			updates["first_name"] = *changes.FirstName
+		}
+		if changes.LastName != nil {
+			updates["last_name"] = *changes.LastName
+		}
+		if changes.IsBlocked != nil {
+			updates["is_blocked"] = *changes.IsBlocked
+		}
+		if len(updates) > 0 {
+			updates["updated_at"] = now
+			if err := tx.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		if changes.BalanceDelta != 0 {
+			result := tx.Model(&models.User{}).
+				Where("id = ? AND balance + ? >= 0", id, changes.BalanceDelta).
+				Updates(map[string]interface{}{
+					"balance":    gorm.Expr("balance + ?", changes.BalanceDelta),
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to change balance: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return ErrInsufficientBalance
+			}
+		}
+
+		if err := tx.First(&user, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+// GetStaff возвращает пользователей с ролью в админ-панели
+func (r *userRepository) GetStaff() ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Where("admin_role <> '' OR is_admin = ?", true).
+		Order("created_at").
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get staff: %w", err)
+	}
+	return users, nil
+}
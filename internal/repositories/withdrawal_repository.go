@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// frozenWithdrawalStatuses статусы заявок, сумма которых вычитается из доступных средств
+var frozenWithdrawalStatuses = []string{
+	models.WithdrawalStatusPending,
+	models.WithdrawalStatusApproved,
+	models.WithdrawalStatusPaid,
+}
+
+// withdrawalRepository реализация WithdrawalRepository
+type withdrawalRepository struct {
+	db *gorm.DB
+}
+
+// Убеждаемся, что withdrawalRepository реализует WithdrawalRepository
+var _ WithdrawalRepository = (*withdrawalRepository)(nil)
+
+// NewWithdrawalRepository создает новый репозиторий заявок на вывод
+func NewWithdrawalRepository(db *gorm.DB) WithdrawalRepository {
+	return &withdrawalRepository{db: db}
+}
+
+// CreateIfAvailable создает заявку, если у пользователя достаточно доступных средств.
+// Строка пользователя блокируется на время транзакции, поэтому параллельные заявки
+// не могут заморозить больше, чем заработано
+func (r *withdrawalRepository) CreateIfAvailable(request *models.WithdrawalRequest, at time.Time) (bool, error) {
+	created := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&user, "id = ?", request.UserID).Error; err != nil {
+			return err
+		}
+
+		var earned float64
+		if err := tx.Model(&models.PartnerEarning{}).
+			Where("partner_id = ? AND available_at <= ?", request.UserID, at).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&earned).Error; err != nil {
+			return err
+		}
+
+		var frozen float64
+		if err := tx.Model(&models.WithdrawalRequest{}).
+			Where("user_id = ? AND status IN ?", request.UserID, frozenWithdrawalStatuses).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&frozen).Error; err != nil {
+			return err
+		}
+
+		if request.Amount > earned-frozen {
+			return nil
+		}
+
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create withdrawal request: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID получает заявку по ID
+func (r *withdrawalRepository) GetByID(id uuid.UUID) (*models.WithdrawalRequest, error) {
+	var request models.WithdrawalRequest
+	if err := r.db.Preload("User").First(&request, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get withdrawal request: %w", err)
+	}
+	return &request, nil
+}
+
+// GetByUserID получает заявки пользователя
+func (r *withdrawalRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]models.WithdrawalRequest, error) {
+	var requests []models.WithdrawalRequest
+	if err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user withdrawal requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetByStatuses получает заявки с указанными статусами, начиная со старых
+func (r *withdrawalRepository) GetByStatuses(statuses []string, limit, offset int) ([]models.WithdrawalRequest, error) {
+	var requests []models.WithdrawalRequest
+	if err := r.db.Preload("User").
+		Where("status IN ?", statuses).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal requests: %w", err)
+	}
+	return requests, nil
+}
+
+// CountByStatuses возвращает количество заявок с указанными статусами
+func (r *withdrawalRepository) CountByStatuses(statuses []string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.WithdrawalRequest{}).
+		Where("status IN ?", statuses).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count withdrawal requests: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateStatus сохраняет новый статус заявки, только если текущий статус
+// входит в fromStatuses. Возвращает false, если заявку уже обработали
+func (r *withdrawalRepository) UpdateStatus(request *models.WithdrawalRequest, fromStatuses []string) (bool, error) {
+	result := r.db.Model(&models.WithdrawalRequest{}).
+		Where("id = ? AND status IN ?", request.ID, fromStatuses).
+		Updates(map[string]interface{}{
+			"status":       request.Status,
+			"admin_id":     request.AdminID,
+			"comment":      request.Comment,
+			"processed_at": request.ProcessedAt,
+			"paid_at":      request.PaidAt,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update withdrawal request: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SumByStatuses возвращает сумму заявок пользователя с указанными статусами
+func (r *withdrawalRepository) SumByStatuses(userID uuid.UUID, statuses []string) (float64, error) {
+	var total float64
+	if err := r.db.Model(&models.WithdrawalRequest{}).
+		Where("user_id = ? AND status IN ?", userID, statuses).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum withdrawal requests: %w", err)
+	}
+	return total, nil
+}
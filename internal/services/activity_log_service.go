@@ -115,6 +115,18 @@ func (s *ActivityLogService) LogReferral(userID uuid.UUID, referredUserID uuid.U
 	return s.LogActivity(userID, "referral", data, ipAddress, userAgent)
 }
 
+// LogWithdrawal логирует изменение статуса заявки на вывод
+func (s *ActivityLogService) LogWithdrawal(userID uuid.UUID, requestID uuid.UUID, fromStatus, toStatus, comment string, ipAddress, userAgent string) error {
+	data := map[string]interface{}{
+		"withdrawal_id": requestID,
+		"from_status":   fromStatus,
+		"to_status":     toStatus,
+		"comment":       comment,
+	}
+
+	return s.LogActivity(userID, "withdrawal", data, ipAddress, userAgent)
+}
+
 // GetUserActivity получает активность пользователя
 func (s *ActivityLogService) GetUserActivity(userID uuid.UUID, limit, offset int) ([]models.ActivityLog, error) {
 	return s.repo.GetByUserID(userID, limit, offset)
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+)
+
+// AdminListService постраничные списки пользователей, подписок, платежей и
+// промокодов с фильтрами для REST API
+type AdminListService struct {
+	repo   repositories.AdminListRepository
+	logger logger.Logger
+}
+
+// NewAdminListService создает новый сервис списков
+func NewAdminListService(repo repositories.AdminListRepository, logger logger.Logger) *AdminListService {
+	return &AdminListService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListUsers возвращает страницу пользователей и их общее количество по фильтру
+func (s *AdminListService) ListUsers(filter repositories.AdminListFilter, limit, offset int) ([]models.User, int64, error) {
+	users, total, err := s.repo.ListUsers(filter, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list users", "error", err)
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// ListSubscriptions возвращает страницу подписок и их общее количество по фильтру
+func (s *AdminListService) ListSubscriptions(filter repositories.AdminListFilter, limit, offset int) ([]models.Subscription, int64, error) {
+	subscriptions, total, err := s.repo.ListSubscriptions(filter, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list subscriptions", "error", err)
+		return nil, 0, err
+	}
+	return subscriptions, total, nil
+}
+
+// ListPayments возвращает страницу платежей и их общее количество по фильтру
+func (s *AdminListService) ListPayments(filter repositories.AdminListFilter, limit, offset int) ([]models.Payment, int64, error) {
+	payments, total, err := s.repo.ListPayments(filter, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list payments", "error", err)
+		return nil, 0, err
+	}
+	return payments, total, nil
+}
+
+// ListPromoCodes возвращает страницу промокодов и их общее количество
+func (s *AdminListService) ListPromoCodes(limit, offset int) ([]models.PromoCode, int64, error) {
+	promoCodes, total, err := s.repo.ListPromoCodes(limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list promo codes", "error", err)
+		return nil, 0, err
+	}
+	return promoCodes, total, nil
+}
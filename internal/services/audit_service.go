@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/telegram"
+)
+
+// AuditEntry действие администратора для записи в журнал аудита
+type AuditEntry struct {
+	Actor      *models.User
+	ActorName  string // учетная запись REST API, если действие выполнено не из бота
+	Action     string
+	TargetUser *models.User
+	// TargetType и TargetID описывают объект действия, если это не пользователь
+	TargetType string
+	TargetID   string
+	Before     interface{}
+	After      interface{}
+	Reason     string
+}
+
+// AuditService ведет журнал действий администраторов. Записи хранятся отдельно
+// от логов активности и не удаляются при очистке старых логов
+type AuditService struct {
+	repo      repositories.AdminAuditRepository
+	messenger telegram.Messenger
+	config    *config.Config
+	logger    logger.Logger
+}
+
+// NewAuditService создает новый сервис журнала аудита
+func NewAuditService(repo repositories.AdminAuditRepository, messenger telegram.Messenger, config *config.Config, logger logger.Logger) *AuditService {
+	return &AuditService{
+		repo:      repo,
+		messenger: messenger,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Record сохраняет действие в журнал и дублирует критичные действия в чат аудита
+func (s *AuditService) Record(entry AuditEntry) error {
+	if entry.Actor == nil && entry.ActorName == "" {
+		return fmt.Errorf("audit entry without actor: %s", entry.Action)
+	}
+
+	log := &models.AdminAuditLog{
+		ActorName:  entry.ActorName,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     auditValue(entry.Before),
+		After:      auditValue(entry.After),
+		Reason:     entry.Reason,
+		Critical:   models.IsCriticalAuditAction(entry.Action),
+		CreatedAt:  time.Now(),
+	}
+	if entry.Actor != nil {
+		log.ActorID = &entry.Actor.ID
+	}
+	if entry.TargetUser != nil {
+		log.TargetUserID = &entry.TargetUser.ID
+		if log.TargetType == "" {
+			log.TargetType = "user"
+			log.TargetID = fmt.Sprintf("%d", entry.TargetUser.TelegramID)
+		}
+	}
+
+	if err := s.repo.Create(log); err != nil {
+		s.logger.Error("Failed to write audit log", "action", entry.Action, "actor_id", log.ActorID, "actor_name", log.ActorName, "error", err)
+		return err
+	}
+
+	if log.Critical {
+		s.mirror(log, entry)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала по фильтру и их общее количество
+func (s *AuditService) List(filter repositories.AdminAuditFilter, limit, offset int) ([]models.AdminAuditLog, int64, error) {
+	total, err := s.repo.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	entries, err := s.repo.List(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// mirror отправляет критичное действие в чат аудита
+func (s *AuditService) mirror(log *models.AdminAuditLog, entry AuditEntry) {
+	if s.config.Admin.AuditChatID == 0 {
+		return
+	}
+
+	text := fmt.Sprintf("🛡 Аудит: %s\n\n", models.GetAuditActionText(log.Action))
+	if entry.Actor != nil {
+		text += fmt.Sprintf("👮 Кто: %s (%d)\n", entry.Actor.GetFullName(), entry.Actor.TelegramID)
+	} else {
+		text += fmt.Sprintf("👮 Кто: API %s\n", entry.ActorName)
+	}
+	if entry.TargetUser != nil {
+		text += fmt.Sprintf("👤 Над кем: %s (%d)\n", entry.TargetUser.GetFullName(), entry.TargetUser.TelegramID)
+	} else if log.TargetID != "" {
+		text += fmt.Sprintf("📦 Объект: %s %s\n", log.TargetType, log.TargetID)
+	}
+	if log.Before != "" {
+		text += fmt.Sprintf("📌 Было: %s\n", log.Before)
+	}
+	if log.After != "" {
+		text += fmt.Sprintf("📌 Стало: %s\n", log.After)
+	}
+	if log.Reason != "" {
+		text += fmt.Sprintf("💬 Причина: %s\n", log.Reason)
+	}
+	text += fmt.Sprintf("🕒 %s", log.CreatedAt.Format("02.01.2006 15:04:05"))
+
+	if _, err := s.messenger.SendMessage(s.config.Admin.AuditChatID, text, telegram.Plain(nil)); err != nil {
+		s.logger.Warn("Failed to mirror audit log", "action", log.Action, "chat_id", s.config.Admin.AuditChatID, "error", err)
+	}
+}
+
+// auditValue сериализует значение до или после изменения в JSON
+func auditValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAdminAuditRepository мок для AdminAuditRepository
+type MockAdminAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminAuditRepository) Create(entry *models.AdminAuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAdminAuditRepository) List(filter repositories.AdminAuditFilter, limit, offset int) ([]models.AdminAuditLog, error) {
+	args := m.Called(filter, limit, offset)
+	return args.Get(0).([]models.AdminAuditLog), args.Error(1)
+}
+
+func (m *MockAdminAuditRepository) Count(filter repositories.AdminAuditFilter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newTestAuditService(chatID int64) (*AuditService, *MockAdminAuditRepository, *telegram.FakeMessenger) {
+	cfg := &config.Config{}
+	cfg.Admin.AuditChatID = chatID
+
+	repo := new(MockAdminAuditRepository)
+	messenger := telegram.NewFakeMessenger()
+	return NewAuditService(repo, messenger, cfg, logger.New("error")), repo, messenger
+}
+
+func TestAuditService_RecordCriticalMirrorsToChat(t *testing.T) {
+	service, repo, messenger := newTestAuditService(-100500)
+	actor := &models.User{ID: uuid.New(), TelegramID: 1, FirstName: "Админ"}
+	target := &models.User{ID: uuid.New(), TelegramID: 2, FirstName: "Иван"}
+
+	var saved *models.AdminAuditLog
+	repo.On("Create", mock.AnythingOfType("*models.AdminAuditLog")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.AdminAuditLog)
+	}).Return(nil)
+
+	err := service.Record(AuditEntry{
+		Actor:      actor,
+		Action:     models.AuditBalanceChange,
+		TargetUser: target,
+		Before:     map[string]float64{"balance": 100},
+		After:      map[string]float64{"balance": 150},
+		Reason:     "компенсация",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, &actor.ID, saved.ActorID)
+	assert.Equal(t, &target.ID, saved.TargetUserID)
+	assert.Equal(t, "user", saved.TargetType)
+	assert.Equal(t, "2", saved.TargetID)
+	assert.Equal(t, `{"balance":100}`, saved.Before)
+	assert.Equal(t, `{"balance":150}`, saved.After)
+	assert.Equal(t, "компенсация", saved.Reason)
+	assert.True(t, saved.Critical)
+
+	sent, ok := messenger.Last()
+	require.True(t, ok)
+	assert.Equal(t, int64(-100500), sent.ChatID)
+	assert.Contains(t, sent.Text, "Изменение баланса")
+	assert.Contains(t, sent.Text, "Причина: компенсация")
+}
+
+func TestAuditService_RecordNonCriticalNotMirrored(t *testing.T) {
+	service, repo, messenger := newTestAuditService(-100500)
+	repo.On("Create", mock.Anything).Return(nil)
+
+	err := service.Record(AuditEntry{
+		Actor:      &models.User{ID: uuid.New()},
+		Action:     models.AuditPromoCreate,
+		TargetType: "promo_code",
+		TargetID:   "SALE",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, messenger.Sent())
+}
+
+func TestAuditService_RecordWithoutChatNotMirrored(t *testing.T) {
+	service, repo, messenger := newTestAuditService(0)
+	repo.On("Create", mock.Anything).Return(nil)
+
+	err := service.Record(AuditEntry{Actor: &models.User{ID: uuid.New()}, Action: models.AuditUserBlock, TargetUser: &models.User{ID: uuid.New()}})
+	require.NoError(t, err)
+	assert.Empty(t, messenger.Sent())
+}
+
+func TestAuditService_RecordRequiresActor(t *testing.T) {
+	service, repo, _ := newTestAuditService(0)
+
+	err := service.Record(AuditEntry{Action: models.AuditUserBlock})
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuditService_RecordAPIActor(t *testing.T) {
+	service, repo, messenger := newTestAuditService(-100500)
+
+	var saved *models.AdminAuditLog
+	repo.On("Create", mock.AnythingOfType("*models.AdminAuditLog")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.AdminAuditLog)
+	}).Return(nil)
+
+	err := service.Record(AuditEntry{
+		ActorName:  "admin",
+		Action:     models.AuditUserBlock,
+		TargetUser: &models.User{ID: uuid.New(), TelegramID: 2},
+		Reason:     "спам",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Nil(t, saved.ActorID)
+	assert.Equal(t, "admin", saved.ActorName)
+	assert.Equal(t, "API admin", saved.ActorTitle())
+
+	sent, ok := messenger.Last()
+	require.True(t, ok)
+	assert.Contains(t, sent.Text, "Кто: API admin")
+}
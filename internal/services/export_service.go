@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/export"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+)
+
+// Наборы данных для выгрузки
+const (
+	ExportUsers         = "users"
+	ExportPayments      = "payments"
+	ExportSubscriptions = "subscriptions"
+	ExportPromoUsages   = "promo"
+)
+
+var (
+	// ErrUnknownExportDataset неизвестный набор данных
+	ErrUnknownExportDataset = errors.New("неизвестный набор данных для выгрузки")
+	// ErrExportTooLarge файл больше лимита Telegram, а адрес для ссылок не настроен
+	ErrExportTooLarge = errors.New("файл выгрузки превышает лимит Telegram, а EXPORT_PUBLIC_URL не задан")
+	// ErrExportNotFound файл выгрузки не найден или ссылка истекла
+	ErrExportNotFound = errors.New("файл выгрузки не найден или ссылка истекла")
+)
+
+// ExportRequest параметры выгрузки
+type ExportRequest struct {
+	Dataset string
+	Format  string
+	Filter  repositories.ExportFilter
+}
+
+// ExportResult готовый файл выгрузки
+type ExportResult struct {
+	FileName string
+	Path     string
+	Size     int64
+	Rows     int64
+	// URL ссылка на скачивание, если файл больше лимита загрузки в Telegram
+	URL       string
+	ExpiresAt time.Time
+}
+
+// ExportService формирует выгрузки данных в файлы. Строки читаются из базы
+// курсором и сразу пишутся в файл, поэтому размер выгрузки не ограничен памятью
+type ExportService struct {
+	repo   repositories.ExportRepository
+	config *config.Config
+	logger logger.Logger
+}
+
+// NewExportService создает новый сервис выгрузок
+func NewExportService(repo repositories.ExportRepository, config *config.Config, logger logger.Logger) *ExportService {
+	return &ExportService{
+		repo:   repo,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Export формирует файл выгрузки. Файл, превышающий лимит загрузки в
+// Telegram, остается в каталоге выгрузок и доступен по ссылке до ExpiresAt
+func (s *ExportService) Export(request ExportRequest) (*ExportResult, error) {
+	if !export.IsFormat(request.Format) {
+		return nil, export.ErrUnknownFormat
+	}
+	if !isExportDataset(request.Dataset) {
+		return nil, ErrUnknownExportDataset
+	}
+
+	if err := os.MkdirAll(s.config.Export.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export dir: %w", err)
+	}
+	s.removeExpired()
+
+	token, err := exportToken()
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("%s_%s.%s", request.Dataset, time.Now().Format("20060102_150405"), request.Format)
+	result := &ExportResult{
+		FileName: fileName,
+		Path:     filepath.Join(s.config.Export.Dir, token+"_"+fileName),
+	}
+
+	result.Rows, err = s.writeFile(result.Path, request)
+	if err != nil {
+		os.Remove(result.Path)
+		s.logger.Error("Failed to export data", "error", err, "dataset", request.Dataset, "format", request.Format)
+		return nil, err
+	}
+
+	info, err := os.Stat(result.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat export file: %w", err)
+	}
+	result.Size = info.Size()
+
+	if result.Size > s.config.Export.MaxUploadSize {
+		if s.config.Export.PublicURL == "" {
+			os.Remove(result.Path)
+			return nil, ErrExportTooLarge
+		}
+		result.URL = s.config.Export.PublicURL + "/exports/" + token
+		result.ExpiresAt = info.ModTime().Add(s.config.Export.LinkTTL)
+	}
+
+	s.logger.Info("Data exported", "dataset", request.Dataset, "format", request.Format, "rows", result.Rows, "size", result.Size)
+	return result, nil
+}
+
+// Open возвращает путь и имя файла выгрузки по токену ссылки
+func (s *ExportService) Open(token string) (string, string, error) {
+	if _, err := hex.DecodeString(token); err != nil || len(token) != 32 {
+		return "", "", ErrExportNotFound
+	}
+
+	matches, err := filepath.Glob(filepath.Join(s.config.Export.Dir, token+"_*"))
+	if err != nil || len(matches) == 0 {
+		return "", "", ErrExportNotFound
+	}
+
+	path := matches[0]
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > s.config.Export.LinkTTL {
+		return "", "", ErrExportNotFound
+	}
+
+	return path, strings.TrimPrefix(filepath.Base(path), token+"_"), nil
+}
+
+// Discard удаляет файл выгрузки, отправленный документом
+func (s *ExportService) Discard(result *ExportResult) {
+	if err := os.Remove(result.Path); err != nil && !os.IsNotExist(err) {
+		s.logger.Warn("Failed to remove export file", "error", err, "path", result.Path)
+	}
+}
+
+// writeFile записывает выгрузку в файл и возвращает количество строк
+func (s *ExportService) writeFile(path string, request ExportRequest) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	writer, err := export.NewWriter(request.Format, buffered)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.writeRows(writer, request)
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish export file: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	return rows, file.Close()
+}
+
+// writeRows записывает заголовок и строки набора данных
+func (s *ExportService) writeRows(writer export.Writer, request ExportRequest) (int64, error) {
+	var rows int64
+	filter := request.Filter
+
+	switch request.Dataset {
+	case ExportUsers:
+		if err := writer.WriteHeader([]string{
+			"Telegram ID", "Username", "Имя", "Фамилия", "Язык", "Баланс", "Заблокирован",
+			"Реферальный код", "Пригласил (Telegram ID)", "Активных подписок", "Сумма оплат", "Зарегистрирован",
+		}); err != nil {
+			return 0, err
+		}
+		err := s.repo.StreamUsers(filter, func(u *models.UserExport) error {
+			rows++
+			return writer.WriteRow(u.TelegramID, u.Username, u.FirstName, u.LastName, u.LanguageCode, u.Balance,
+				u.IsBlocked, u.ReferralCode, u.ReferrerTelegramID, u.ActiveSubscriptions, u.CompletedPaymentsSum, u.CreatedAt)
+		})
+		return rows, err
+	case ExportPayments:
+		if err := writer.WriteHeader([]string{
+			"ID", "Telegram ID", "Username", "Сумма", "Валюта", "Способ оплаты", "Статус",
+			"Внешний ID", "Описание", "Создан", "Оплачен",
+		}); err != nil {
+			return 0, err
+		}
+		err := s.repo.StreamPayments(filter, func(p *models.PaymentExport) error {
+			rows++
+			return writer.WriteRow(p.ID, p.TelegramID, p.Username, p.Amount, p.Currency, p.PaymentMethod, p.Status,
+				p.ExternalID, p.Description, p.CreatedAt, p.CompletedAt)
+		})
+		return rows, err
+	case ExportSubscriptions:
+		if err := writer.WriteHeader([]string{
+			"ID", "Telegram ID", "Username", "Тариф", "Сервер", "Статус", "Действует до", "Создана",
+		}); err != nil {
+			return 0, err
+		}
+		err := s.repo.StreamSubscriptions(filter, func(sub *models.SubscriptionExport) error {
+			rows++
+			return writer.WriteRow(sub.ID, sub.TelegramID, sub.Username, sub.PlanName, sub.ServerName, sub.Status,
+				sub.ExpiresAt, sub.CreatedAt)
+		})
+		return rows, err
+	default:
+		if err := writer.WriteHeader([]string{
+			"Промокод", "Тип", "Значение", "Кампания", "Telegram ID", "Username",
+			"Номер использования", "Скидка", "Использован", "Скидка применена",
+		}); err != nil {
+			return 0, err
+		}
+		err := s.repo.StreamPromoUsages(filter, func(u *models.PromoUsageExport) error {
+			rows++
+			return writer.WriteRow(u.Code, u.Type, u.Value, u.CampaignID, u.TelegramID, u.Username,
+				u.UseNumber, u.DiscountAmount, u.UsedAt, u.ConsumedAt)
+		})
+		return rows, err
+	}
+}
+
+// removeExpired удаляет файлы выгрузок с истекшими ссылками
+func (s *ExportService) removeExpired() {
+	entries, err := os.ReadDir(s.config.Export.Dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || time.Since(info.ModTime()) <= s.config.Export.LinkTTL {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.config.Export.Dir, entry.Name())); err != nil {
+			s.logger.Warn("Failed to remove expired export", "error", err, "file", entry.Name())
+		}
+	}
+}
+
+// isExportDataset проверяет, что набор данных поддерживается
+func isExportDataset(dataset string) bool {
+	switch dataset {
+	case ExportUsers, ExportPayments, ExportSubscriptions, ExportPromoUsages:
+		return true
+	default:
+		return false
+	}
+}
+
+// exportToken создает случайный токен ссылки на файл
+func exportToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate export token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
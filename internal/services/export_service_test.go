@@ -0,0 +1,120 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockExportRepository мок для ExportRepository
+type MockExportRepository struct {
+	mock.Mock
+	payments []models.PaymentExport
+}
+
+func (m *MockExportRepository) StreamUsers(filter repositories.ExportFilter, fn func(*models.UserExport) error) error {
+	return m.Called(filter).Error(0)
+}
+
+func (m *MockExportRepository) StreamPayments(filter repositories.ExportFilter, fn func(*models.PaymentExport) error) error {
+	for i := range m.payments {
+		if err := fn(&m.payments[i]); err != nil {
+			return err
+		}
+	}
+	return m.Called(filter).Error(0)
+}
+
+func (m *MockExportRepository) StreamSubscriptions(filter repositories.ExportFilter, fn func(*models.SubscriptionExport) error) error {
+	return m.Called(filter).Error(0)
+}
+
+func (m *MockExportRepository) StreamPromoUsages(filter repositories.ExportFilter, fn func(*models.PromoUsageExport) error) error {
+	return m.Called(filter).Error(0)
+}
+
+func newTestExportService(t *testing.T, maxUpload int64, publicURL string) (*ExportService, *MockExportRepository) {
+	repo := &MockExportRepository{}
+	for i := 0; i < 3; i++ {
+		repo.payments = append(repo.payments, models.PaymentExport{
+			ID: uuid.New(), TelegramID: int64(100 + i), Amount: 199, Currency: "RUB",
+			PaymentMethod: "stars", Status: "completed", CreatedAt: time.Now(),
+		})
+	}
+
+	cfg := &config.Config{}
+	cfg.Export.Dir = t.TempDir()
+	cfg.Export.MaxUploadSize = maxUpload
+	cfg.Export.PublicURL = publicURL
+	cfg.Export.LinkTTL = time.Hour
+
+	return NewExportService(repo, cfg, logger.New("error")), repo
+}
+
+func TestExportService_ExportDocument(t *testing.T) {
+	service, repo := newTestExportService(t, 50<<20, "")
+	filter := repositories.ExportFilter{Status: "completed", Method: "stars"}
+	repo.On("StreamPayments", filter).Return(nil)
+
+	result, err := service.Export(ExportRequest{Dataset: ExportPayments, Format: "csv", Filter: filter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Rows)
+	assert.Empty(t, result.URL)
+	assert.True(t, strings.HasPrefix(result.FileName, "payments_"))
+
+	data, err := os.ReadFile(result.Path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 4)
+
+	service.Discard(result)
+	assert.NoFileExists(t, result.Path)
+}
+
+func TestExportService_ExportLink(t *testing.T) {
+	service, repo := newTestExportService(t, 10, "https://bot.example.com")
+	repo.On("StreamPayments", mock.Anything).Return(nil)
+
+	result, err := service.Export(ExportRequest{Dataset: ExportPayments, Format: "xlsx"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(result.URL, "https://bot.example.com/exports/"))
+
+	path, name, err := service.Open(strings.TrimPrefix(result.URL, "https://bot.example.com/exports/"))
+	require.NoError(t, err)
+	assert.Equal(t, result.Path, path)
+	assert.Equal(t, result.FileName, name)
+
+	_, _, err = service.Open("../../etc/passwd")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func TestExportService_ExportTooLarge(t *testing.T) {
+	service, repo := newTestExportService(t, 10, "")
+	repo.On("StreamPayments", mock.Anything).Return(nil)
+
+	_, err := service.Export(ExportRequest{Dataset: ExportPayments, Format: "csv"})
+	assert.ErrorIs(t, err, ErrExportTooLarge)
+
+	entries, err := os.ReadDir(service.config.Export.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestExportService_ExportValidation(t *testing.T) {
+	service, _ := newTestExportService(t, 50<<20, "")
+
+	_, err := service.Export(ExportRequest{Dataset: "orders", Format: "csv"})
+	assert.ErrorIs(t, err, ErrUnknownExportDataset)
+	_, err = service.Export(ExportRequest{Dataset: ExportUsers, Format: "pdf"})
+	assert.Error(t, err)
+}
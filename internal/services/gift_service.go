@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// GiftStartPrefix префикс параметра /start в ссылке на подарок
+const GiftStartPrefix = "gift_"
+
+// giftCodeLength длина кода подарка
+const giftCodeLength = 12
+
+var (
+	// ErrGiftUnknownTariff тариф подарка не найден
+	ErrGiftUnknownTariff = errors.New("тариф не найден")
+	// ErrGiftInsufficientBalance на балансе покупателя недостаточно средств
+	ErrGiftInsufficientBalance = errors.New("недостаточно средств на балансе")
+	// ErrGiftNotFound подарочный код не найден
+	ErrGiftNotFound = errors.New("подарок не найден")
+)
+
+// GiftService продает подарочные подписки и активирует их у получателей
+type GiftService struct {
+	promoCodeRepo       repositories.PromoCodeRepository
+	promoCodeService    IPromoCodeService
+	notificationService INotificationService
+	activityLogService  IActivityLogService
+	config              *config.Config
+	logger              logger.Logger
+	botUsername         string
+}
+
+// NewGiftService создает новый GiftService
+func NewGiftService(
+	promoCodeRepo repositories.PromoCodeRepository,
+	promoCodeService IPromoCodeService,
+	notificationService INotificationService,
+	activityLogService IActivityLogService,
+	config *config.Config,
+	log logger.Logger,
+	botUsername string,
+) *GiftService {
+	return &GiftService{
+		promoCodeRepo:       promoCodeRepo,
+		promoCodeService:    promoCodeService,
+		notificationService: notificationService,
+		activityLogService:  activityLogService,
+		config:              config,
+		logger:              log,
+		botUsername:         botUsername,
+	}
+}
+
+// PurchaseGift оплачивает подарочную подписку с баланса покупателя и
+// возвращает одноразовый подарочный промокод
+func (s *GiftService) PurchaseGift(buyerID uuid.UUID, tariffKey string) (*models.PromoCode, error) {
+	tariff, ok := models.GetTariff(tariffKey)
+	if !ok {
+		return nil, ErrGiftUnknownTariff
+	}
+
+	code, err := s.uniqueGiftCode()
+	if err != nil {
+		return nil, err
+	}
+
+	promoCode := &models.PromoCode{
+		Code:           code,
+		Type:           models.PromoCodeTypeGift,
+		Value:          float64(tariff.DurationDays),
+		PlanName:       tariff.Name,
+		MaxUses:        1,
+		MaxUsesPerUser: 1,
+		IsActive:       true,
+		ValidFrom:      time.Now(),
+		Description:    fmt.Sprintf("Подарок: %s на %d дней", tariff.Name, tariff.DurationDays),
+		CreatedBy:      buyerID,
+	}
+
+	if err := s.promoCodeRepo.CreateGift(promoCode, tariff.Price); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientBalance) {
+			return nil, ErrGiftInsufficientBalance
+		}
+		return nil, fmt.Errorf("failed to purchase gift: %w", err)
+	}
+
+	if s.activityLogService != nil {
+		s.activityLogService.LogActivity(buyerID, "gift_purchase", map[string]interface{}{
+			"promo_code_id": promoCode.ID,
+			"tariff":        tariff.Key,
+			"price":         tariff.Price,
+		}, "", "")
+	}
+
+	s.logger.Info("Gift purchased", "buyer_id", buyerID, "promo_code_id", promoCode.ID, "tariff", tariff.Key, "price", tariff.Price)
+	return promoCode, nil
+}
+
+// RedeemGift активирует подарок по параметру /start вида gift_<код>.
+// Возвращает nil без ошибки, если параметр не относится к подарку
+func (s *GiftService) RedeemGift(user *models.User, startParam string) (*models.PromoCodeUsage, error) {
+	code, ok := strings.CutPrefix(startParam, GiftStartPrefix)
+	if !ok {
+		return nil, nil
+	}
+
+	promoCode, err := s.promoCodeService.GetPromoCode(strings.ToUpper(code))
+	if err != nil || promoCode == nil || !promoCode.IsGift() {
+		return nil, ErrGiftNotFound
+	}
+
+	usage, err := s.promoCodeService.ApplyPromoCode(user.ID, promoCode.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	s.NotifyRedeemed(usage, user)
+	return usage, nil
+}
+
+// NotifyRedeemed сообщает покупателю, что его подарок активирован.
+// Для обычных промокодов ничего не делает
+func (s *GiftService) NotifyRedeemed(usage *models.PromoCodeUsage, recipient *models.User) {
+	if usage == nil || !usage.PromoCode.IsGift() || s.notificationService == nil {
+		return
+	}
+
+	err := s.notificationService.SendToUser(usage.PromoCode.CreatedBy, "gift", "notifications.gift_redeemed",
+		"name", recipient.GetDisplayName(),
+		"plan", usage.PromoCode.PlanName,
+		"duration", i18n.Plural{Key: "units.days", Count: int(usage.PromoCode.Value)},
+	)
+	if err != nil {
+		s.logger.Error("Failed to notify gift buyer", "error", err, "promo_code_id", usage.PromoCode.ID)
+	}
+}
+
+// GetUserGifts получает подарки, купленные пользователем
+func (s *GiftService) GetUserGifts(buyerID uuid.UUID, limit, offset int) ([]models.PromoCode, error) {
+	return s.promoCodeRepo.GetGiftsByBuyer(buyerID, limit, offset)
+}
+
+// GiftLink возвращает ссылку активации подарка
+func (s *GiftService) GiftLink(promoCode *models.PromoCode) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", s.botUsername, GiftStartPrefix, promoCode.Code)
+}
+
+// uniqueGiftCode генерирует код подарка, которого еще нет среди промокодов
+func (s *GiftService) uniqueGiftCode() (string, error) {
+	for attempt := 0; attempt < promoBatchMaxAttempts; attempt++ {
+		code, err := randomCode(giftCodeLength)
+		if err != nil {
+			return "", err
+		}
+		existing, err := s.promoCodeRepo.GetExistingCodes([]string{code})
+		if err != nil {
+			return "", err
+		}
+		if len(existing) == 0 {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate unique gift code")
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGiftService(repo *MockPromoCodeRepository, recipient *models.User, notifications *MockNotificationService) *GiftService {
+	promoCodeService := newTestPromoCodeService(repo, recipient)
+	return NewGiftService(repo, promoCodeService, notifications, nil, &config.Config{}, logger.New("error"), "shop_bot")
+}
+
+func TestGiftService_PurchaseGift(t *testing.T) {
+	buyerID := uuid.New()
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetExistingCodes", mock.Anything).Return([]string{}, nil)
+		repo.On("CreateGift", mock.AnythingOfType("*models.PromoCode"), 799.0).Return(repositories.ErrInsufficientBalance)
+
+		_, err := newTestGiftService(repo, &models.User{ID: uuid.New()}, nil).PurchaseGift(buyerID, "premium")
+		assert.ErrorIs(t, err, ErrGiftInsufficientBalance)
+	})
+
+	t.Run("unknown tariff", func(t *testing.T) {
+		_, err := newTestGiftService(new(MockPromoCodeRepository), &models.User{ID: uuid.New()}, nil).PurchaseGift(buyerID, "ultra")
+		assert.ErrorIs(t, err, ErrGiftUnknownTariff)
+	})
+
+	t.Run("purchased", func(t *testing.T) {
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetExistingCodes", mock.Anything).Return([]string{}, nil)
+		repo.On("CreateGift", mock.AnythingOfType("*models.PromoCode"), 799.0).Return(nil)
+		service := newTestGiftService(repo, &models.User{ID: uuid.New()}, nil)
+
+		promoCode, err := service.PurchaseGift(buyerID, "premium")
+		require.NoError(t, err)
+		assert.True(t, promoCode.IsGift())
+		assert.Len(t, promoCode.Code, giftCodeLength)
+		assert.Equal(t, "Premium", promoCode.PlanName)
+		assert.Equal(t, 90.0, promoCode.Value)
+		assert.Equal(t, 1, promoCode.MaxUses)
+		assert.Equal(t, buyerID, promoCode.CreatedBy)
+		assert.Equal(t, "https://t.me/shop_bot?start=gift_"+promoCode.Code, service.GiftLink(promoCode))
+	})
+}
+
+func TestGiftService_RedeemGift(t *testing.T) {
+	buyerID := uuid.New()
+	recipient := &models.User{ID: uuid.New(), FirstName: "Анна"}
+	gift := &models.PromoCode{ID: uuid.New(), Code: "ABCDEFGH2345", Type: models.PromoCodeTypeGift, Value: 90, PlanName: "Premium", MaxUses: 1, MaxUsesPerUser: 1, IsActive: true, CreatedBy: buyerID}
+
+	t.Run("redeemed and buyer notified", func(t *testing.T) {
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "ABCDEFGH2345").Return(gift, nil)
+		repo.On("Redeem", gift, mock.AnythingOfType("*models.PromoCodeUsage")).Return(nil)
+		notifications := new(MockNotificationService)
+		notifications.On("SendToUser", buyerID, "gift", mock.Anything, mock.Anything).Return(nil)
+
+		usage, err := newTestGiftService(repo, recipient, notifications).RedeemGift(recipient, "gift_abcdefgh2345")
+		require.NoError(t, err)
+		assert.Equal(t, gift.ID, usage.PromoCodeID)
+		notifications.AssertExpectations(t)
+	})
+
+	t.Run("own gift", func(t *testing.T) {
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "ABCDEFGH2345").Return(gift, nil)
+		buyer := &models.User{ID: buyerID}
+
+		_, err := newTestGiftService(repo, buyer, nil).RedeemGift(buyer, "gift_ABCDEFGH2345")
+		assert.ErrorIs(t, err, ErrPromoCodeOwnGift)
+		repo.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+	})
+
+	t.Run("not a gift link", func(t *testing.T) {
+		usage, err := newTestGiftService(new(MockPromoCodeRepository), recipient, nil).RedeemGift(recipient, "ref_abc")
+		assert.NoError(t, err)
+		assert.Nil(t, usage)
+	})
+}
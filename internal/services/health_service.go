@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+	"remnawave-tg-shop/internal/telegram"
+)
+
+// Статусы компонентов и сервиса в целом
+const (
+	HealthUp       = "up"
+	HealthDown     = "down"
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// healthCheckTimeout время, после которого зависшая проверка считается неудачной
+const healthCheckTimeout = 5 * time.Second
+
+// errHealthCheckTimeout проверка не уложилась в healthCheckTimeout
+var errHealthCheckTimeout = errors.New("health check timed out")
+
+// ComponentHealth результат проверки одного компонента
+type ComponentHealth struct {
+	Status    string    `json:"status"`
+	LatencyMS float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	Critical  bool      `json:"critical"`
+	Cached    bool      `json:"cached"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport состояние всех компонентов. Status равен down, если недоступен
+// критичный компонент, и degraded, если недоступен некритичный
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// Ready проверяет, может ли сервис принимать запросы
+func (r HealthReport) Ready() bool {
+	return r.Status != HealthDown
+}
+
+// HealthService проверяет зависимости для /health/ready и периодически
+// сообщает администраторам об отказе и восстановлении компонентов
+type HealthService struct {
+	checks    []HealthCheck
+	messenger telegram.Messenger
+	config    *config.Config
+	logger    logger.Logger
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]ComponentHealth
+	// alerted последний статус компонента, о котором знают администраторы
+	alerted map[string]string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHealthService создает новый сервис проверки состояния
+func NewHealthService(checks []HealthCheck, messenger telegram.Messenger, config *config.Config, log logger.Logger) *HealthService {
+	return &HealthService{
+		checks:    checks,
+		messenger: messenger,
+		config:    config,
+		logger:    log,
+		now:       time.Now,
+		cache:     make(map[string]ComponentHealth),
+		alerted:   make(map[string]string),
+	}
+}
+
+// Check проверяет все компоненты параллельно. Результаты проверок с CacheTTL
+// переиспользуются, чтобы частые запросы проб не нагружали внешние сервисы
+func (s *HealthService) Check() HealthReport {
+	return s.check(true)
+}
+
+// SelfCheck проверяет компоненты без кэша и уведомляет администраторов
+// о компонентах, изменивших статус
+func (s *HealthService) SelfCheck() HealthReport {
+	report := s.check(false)
+
+	s.mu.Lock()
+	var messages []string
+	for _, check := range s.checks {
+		component := report.Components[check.Name]
+		previous, known := s.alerted[check.Name]
+		s.alerted[check.Name] = component.Status
+		switch {
+		case component.Status == HealthDown && previous != HealthDown:
+			messages = append(messages, fmt.Sprintf("⚠️ %s недоступен: %s", check.Name, component.Error))
+		case component.Status == HealthUp && known && previous == HealthDown:
+			messages = append(messages, fmt.Sprintf("✅ %s снова доступен", check.Name))
+		}
+	}
+	s.mu.Unlock()
+
+	for _, message := range messages {
+		s.logger.Warn("Component health changed", "message", message)
+		s.notifyAdmins(message)
+	}
+	return report
+}
+
+// check выполняет проверки; useCache разрешает брать свежие результаты из кэша
+func (s *HealthService) check(useCache bool) HealthReport {
+	now := s.now()
+	report := HealthReport{Status: HealthOK, CheckedAt: now, Components: make(map[string]ComponentHealth, len(s.checks))}
+
+	results := make([]ComponentHealth, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		if useCache {
+			if cached, ok := s.cached(check, now); ok {
+				results[i] = cached
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = s.run(check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, check := range s.checks {
+		component := results[i]
+		report.Components[check.Name] = component
+		if component.Status == HealthDown {
+			if check.Critical {
+				report.Status = HealthDown
+			} else if report.Status == HealthOK {
+				report.Status = HealthDegraded
+			}
+		}
+	}
+	return report
+}
+
+// run выполняет одну проверку с ограничением по времени и сохраняет результат в кэш
+func (s *HealthService) run(check HealthCheck) ComponentHealth {
+	start := s.now()
+	result := make(chan error, 1)
+	go func() { result <- check.Check() }()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-time.After(healthCheckTimeout):
+		err = errHealthCheckTimeout
+	}
+
+	component := ComponentHealth{
+		Status:    HealthUp,
+		LatencyMS: math.Round(float64(s.now().Sub(start).Microseconds())) / 1000,
+		Critical:  check.Critical,
+		CheckedAt: start,
+	}
+	if err != nil {
+		component.Status = HealthDown
+		component.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.cache[check.Name] = component
+	s.mu.Unlock()
+	return component
+}
+
+// cached возвращает результат проверки, если он моложе CacheTTL
+func (s *HealthService) cached(check HealthCheck, now time.Time) (ComponentHealth, bool) {
+	if check.CacheTTL <= 0 {
+		return ComponentHealth{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	component, ok := s.cache[check.Name]
+	if !ok || now.Sub(component.CheckedAt) >= check.CacheTTL {
+		return ComponentHealth{}, false
+	}
+	component.Cached = true
+	return component, true
+}
+
+// Start запускает самопроверку с интервалом HEALTH_CHECK_INTERVAL
+func (s *HealthService) Start() {
+	if len(s.checks) == 0 || s.config.Monitoring.HealthCheckInterval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.config.Monitoring.HealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				start := time.Now()
+				s.SelfCheck()
+				metrics.ObserveJob("health_self_check", start, nil)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop останавливает самопроверку
+func (s *HealthService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+// notifyAdmins отправляет сообщение всем администраторам из конфигурации
+func (s *HealthService) notifyAdmins(text string) {
+	for _, adminID := range s.config.Admin.TelegramIDs {
+		if _, err := s.messenger.SendMessage(adminID, text, telegram.Plain(nil)); err != nil {
+			s.logger.Warn("Failed to notify admin about component health", "admin_id", adminID, "error", err)
+		}
+	}
+}
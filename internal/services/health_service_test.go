@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHealthService(checks []HealthCheck) (*HealthService, *telegram.FakeMessenger) {
+	cfg := &config.Config{}
+	cfg.Admin.TelegramIDs = []int64{1001}
+
+	messenger := telegram.NewFakeMessenger()
+	return NewHealthService(checks, messenger, cfg, logger.New("error")), messenger
+}
+
+func TestHealthService_CheckStatus(t *testing.T) {
+	var dbErr, panelErr error
+	service, _ := newTestHealthService([]HealthCheck{
+		{Name: "database", Check: func() error { return dbErr }, Critical: true},
+		{Name: "remnawave", Check: func() error { return panelErr }},
+	})
+
+	report := service.Check()
+	assert.Equal(t, HealthOK, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, HealthUp, report.Components["database"].Status)
+
+	panelErr = errors.New("connection refused")
+	report = service.Check()
+	assert.Equal(t, HealthDegraded, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, "connection refused", report.Components["remnawave"].Error)
+
+	dbErr = errors.New("ping failed")
+	report = service.Check()
+	assert.Equal(t, HealthDown, report.Status)
+	assert.False(t, report.Ready())
+}
+
+func TestHealthService_Cache(t *testing.T) {
+	calls := 0
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	service, _ := newTestHealthService([]HealthCheck{
+		{Name: "remnawave", Check: func() error { calls++; return nil }, CacheTTL: time.Minute},
+	})
+	service.now = func() time.Time { return now }
+
+	service.Check()
+	report := service.Check()
+	assert.Equal(t, 1, calls)
+	assert.True(t, report.Components["remnawave"].Cached)
+
+	now = now.Add(time.Minute)
+	report = service.Check()
+	assert.Equal(t, 2, calls)
+	assert.False(t, report.Components["remnawave"].Cached)
+
+	// Самопроверка всегда обращается к компоненту
+	service.SelfCheck()
+	assert.Equal(t, 3, calls)
+}
+
+func TestHealthService_SelfCheckAlertsOnTransitions(t *testing.T) {
+	var panelErr error
+	service, messenger := newTestHealthService([]HealthCheck{
+		{Name: "remnawave", Check: func() error { return panelErr }},
+	})
+
+	service.SelfCheck()
+	assert.Empty(t, messenger.Sent(), "исправный компонент при запуске не оповещает")
+
+	panelErr = errors.New("timeout")
+	service.SelfCheck()
+	service.SelfCheck()
+	require.Len(t, messenger.Sent(), 1)
+	assert.Contains(t, messenger.Sent()[0].Text, "remnawave недоступен: timeout")
+
+	panelErr = nil
+	service.SelfCheck()
+	require.Len(t, messenger.Sent(), 2)
+	assert.Contains(t, messenger.Sent()[1].Text, "remnawave снова доступен")
+}
+
+func TestHeartbeat(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	heartbeat := NewHeartbeat()
+	heartbeat.now = func() time.Time { return now }
+
+	heartbeat.Expect("settings_refresh", time.Minute)
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, heartbeat.Check())
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorContains(t, heartbeat.Check(), "settings_refresh")
+
+	heartbeat.Beat("settings_refresh")
+	assert.NoError(t, heartbeat.Check())
+
+	var disabled *Heartbeat
+	disabled.Beat("settings_refresh")
+	assert.NoError(t, disabled.Check())
+}
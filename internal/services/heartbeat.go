@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// heartbeatTolerance во сколько интервалов задача может опоздать, прежде
+// чем планировщик считается зависшим
+const heartbeatTolerance = 3
+
+// Heartbeat отмечает запуски фоновых задач, чтобы проверка готовности
+// заметила остановившийся планировщик. Методы nil-получателя ничего не делают
+type Heartbeat struct {
+	mu   sync.Mutex
+	jobs map[string]*heartbeatJob
+	now  func() time.Time
+}
+
+// heartbeatJob ожидаемый интервал задачи и время ее последнего запуска
+type heartbeatJob struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// NewHeartbeat создает новый Heartbeat
+func NewHeartbeat() *Heartbeat {
+	return &Heartbeat{jobs: make(map[string]*heartbeatJob), now: time.Now}
+}
+
+// Expect регистрирует задачу, запускаемую с интервалом interval. Отсчет
+// идет с момента регистрации, чтобы задача успела запуститься первый раз
+func (h *Heartbeat) Expect(job string, interval time.Duration) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.jobs[job] = &heartbeatJob{interval: interval, last: h.now()}
+}
+
+// Beat отмечает запуск задачи
+func (h *Heartbeat) Beat(job string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if state, ok := h.jobs[job]; ok {
+		state.last = h.now()
+	}
+}
+
+// Check возвращает ошибку, если хотя бы одна задача не запускалась дольше
+// heartbeatTolerance своих интервалов
+func (h *Heartbeat) Check() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	var stale []string
+	for job, state := range h.jobs {
+		if age := now.Sub(state.last); age > heartbeatTolerance*state.interval {
+			stale = append(stale, fmt.Sprintf("%s: no runs for %s", job, age.Round(time.Second)))
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(stale)
+	return fmt.Errorf("scheduler is stale: %s", strings.Join(stale, "; "))
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/services/remnawave"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,14 +15,21 @@ type UserService interface {
 	GetUser(telegramID int64) (*models.User, error)
 	GetUserByReferralCode(code string) (*models.User, error)
 	UpdateUser(user *models.User) error
+	SetLanguage(user *models.User, language string) error
 	BlockUser(telegramID int64) error
 	UnblockUser(telegramID int64) error
 	AddBalance(userID uuid.UUID, amount float64) error
 	SubtractBalance(userID uuid.UUID, amount float64) error
 	DeductBalance(userID uuid.UUID, amount float64) error
+	ApplyChanges(userID uuid.UUID, changes repositories.UserChanges) (*models.User, error)
 	GetReferrals(userID uuid.UUID) ([]models.User, error)
+	GetUserByID(id uuid.UUID) (*models.User, error)
 	SearchUsers(query string, limit int) ([]models.User, error)
 	IsAdmin(telegramID int64) bool
+	GetRole(telegramID int64) string
+	HasPermission(telegramID int64, permission string) bool
+	SetRole(telegramID int64, role string) (*models.User, error)
+	GetStaff() ([]models.User, error)
 }
 
 // SubscriptionService интерфейс для работы с подписками
@@ -29,6 +38,10 @@ type SubscriptionService interface {
 	CreateSubscriptionByPlan(userID uuid.UUID, planName string, durationMonths, price int) error
 	CreateTrialSubscription(userID uuid.UUID, durationDays, trafficLimitGB int, trafficStrategy string) error
 	HasUsedTrial(userID uuid.UUID) (bool, error)
+	AddBonusDays(userID uuid.UUID, days int) (*models.Subscription, error)
+	AdjustDays(subscriptionID uuid.UUID, days int) (*models.Subscription, error)
+	GrantTariff(userID uuid.UUID, tariff models.Tariff) (*models.Subscription, error)
+	ResetTrial(userID uuid.UUID) (int, error)
 	GetUserSubscriptions(userID uuid.UUID) ([]models.Subscription, error)
 	GetActiveSubscriptions(userID uuid.UUID) ([]models.Subscription, error)
 	GetSubscription(id uuid.UUID) (*models.Subscription, error)
@@ -36,6 +49,7 @@ type SubscriptionService interface {
 	CancelSubscription(id uuid.UUID) error
 	GetExpiredSubscriptions() ([]models.Subscription, error)
 	GetExpiringSoon(days int) ([]models.Subscription, error)
+	GetAccess(telegramID int64) (*remnawave.UserAccess, error)
 }
 
 // PaymentService интерфейс для работы с платежами
@@ -63,7 +77,15 @@ type ServerService interface {
 type IPromoCodeService interface {
 	CreatePromoCode(code, promoType string, value float64, maxUses int, validFrom, validUntil *time.Time, description string, createdBy uuid.UUID) (*models.PromoCode, error)
 	GeneratePromoCode(promoType string, value float64, maxUses int, validFrom, validUntil *time.Time, description string, createdBy uuid.UUID) (*models.PromoCode, error)
-	ApplyPromoCode(userID uuid.UUID, code string) (*models.PromoCode, error)
+	GenerateBatch(batch PromoCodeBatch) ([]models.PromoCode, error)
+	GetCampaigns(limit, offset int) ([]models.PromoCampaignStats, error)
+	GetCampaignStats(campaignID string) (*models.PromoCampaignStats, error)
+	ExportCampaignCSV(campaignID string) ([]byte, error)
+	ApplyPromoCode(userID uuid.UUID, code string) (*models.PromoCodeUsage, error)
+	GetPendingDiscount(userID uuid.UUID) (*models.PromoCodeUsage, error)
+	CheckoutDiscount(userID uuid.UUID, plan string, price float64) (*models.PromoCodeUsage, float64, error)
+	ConsumeDiscount(usageID uuid.UUID, amount float64) (bool, error)
+	UpdateRules(promoCode *models.PromoCode) error
 	GetPromoCode(code string) (*models.PromoCode, error)
 	GetPromoCodeByID(id uuid.UUID) (*models.PromoCode, error)
 	GetAllPromoCodes(limit, offset int) ([]models.PromoCode, error)
@@ -72,19 +94,83 @@ type IPromoCodeService interface {
 	GetValidPromoCodes() ([]models.PromoCode, error)
 }
 
+// IGiftService интерфейс подарочных подписок
+type IGiftService interface {
+	PurchaseGift(buyerID uuid.UUID, tariffKey string) (*models.PromoCode, error)
+	RedeemGift(user *models.User, startParam string) (*models.PromoCodeUsage, error)
+	NotifyRedeemed(usage *models.PromoCodeUsage, recipient *models.User)
+	GetUserGifts(buyerID uuid.UUID, limit, offset int) ([]models.PromoCode, error)
+	GiftLink(promoCode *models.PromoCode) string
+}
+
 // INotificationService интерфейс для работы с уведомлениями
 type INotificationService interface {
 	CreateNotification(userID *uuid.UUID, notificationType, title, message string) (*models.Notification, error)
-	SendNotification(notificationID uuid.UUID, botToken string) error
-	SendBulkNotification(notificationType, title, message string, botToken string) error
-	SendToUsersWithActiveSubscriptions(notificationType, title, message string, botToken string) error
-	SendToUsersWithExpiredSubscriptions(notificationType, title, message string, botToken string) error
-	CheckExpiringSubscriptions(botToken string) error
+	SendNotification(notificationID uuid.UUID) error
+	SendBulkNotification(notificationType, title, message string) error
+	SendToUser(userID uuid.UUID, notificationType, key string, args ...any) error
+	SendToUsersWithActiveSubscriptions(notificationType, title, message string) error
+	SendToUsersWithExpiredSubscriptions(notificationType, title, message string) error
+	CheckExpiringSubscriptions() error
 	GetNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]models.Notification, error)
 	MarkAsRead(notificationID uuid.UUID) error
 	GetUnreadCount(userID uuid.UUID) (int64, error)
 }
 
+// IMaintenanceService интерфейс режима технических работ
+type IMaintenanceService interface {
+	IsEnabled() bool
+	Status() MaintenanceStatus
+	Message(defaultText string) string
+	SetManual(enabled bool, adminID uuid.UUID)
+}
+
+// IAuditService интерфейс журнала действий администраторов
+type IAuditService interface {
+	Record(entry AuditEntry) error
+	List(filter repositories.AdminAuditFilter, limit, offset int) ([]models.AdminAuditLog, int64, error)
+}
+
+// ISettingsService интерфейс настроек, изменяемых из админ-панели
+type ISettingsService interface {
+	Definitions(group string) []SettingDefinition
+	Definition(key string) (*SettingDefinition, error)
+	Value(key string) string
+	Default(key string) string
+	IsOverridden(key string) bool
+	Set(key, raw string, adminID uuid.UUID) (string, error)
+	Reset(key string, adminID uuid.UUID) error
+	History(key string, limit int) ([]models.SettingChange, error)
+}
+
+// IReferralService интерфейс реферальной программы
+type IReferralService interface {
+	AttachReferrer(user *models.User, startParam string) (*models.User, error)
+	RewardFirstPurchase(userID uuid.UUID) error
+	ReferralLink(user *models.User) string
+}
+
+// IPartnerService интерфейс партнерской программы
+type IPartnerService interface {
+	IsEnabled() bool
+	TrackClick(startParam string, telegramID int64) error
+	AccrueCommission(payment *models.Payment) error
+	GetStats(partnerID uuid.UUID) (*models.PartnerStats, error)
+}
+
+// IWithdrawalService интерфейс заявок на вывод средств
+type IWithdrawalService interface {
+	MinAmount() float64
+	CreateRequest(userID uuid.UUID, amount float64, method, details string) (*models.WithdrawalRequest, error)
+	GetRequest(id uuid.UUID) (*models.WithdrawalRequest, error)
+	GetUserRequests(userID uuid.UUID, limit, offset int) ([]models.WithdrawalRequest, error)
+	GetQueue(limit, offset int) ([]models.WithdrawalRequest, error)
+	CountQueue() (int64, error)
+	Approve(id, adminID uuid.UUID) (*models.WithdrawalRequest, error)
+	Reject(id, adminID uuid.UUID, reason string) (*models.WithdrawalRequest, error)
+	MarkPaid(id, adminID uuid.UUID) (*models.WithdrawalRequest, error)
+}
+
 // IActivityLogService интерфейс для работы с логами активности
 type IActivityLogService interface {
 	LogActivity(userID uuid.UUID, action string, data interface{}, ipAddress, userAgent string) error
@@ -95,6 +181,7 @@ type IActivityLogService interface {
 	LogSubscription(userID uuid.UUID, subscriptionID uuid.UUID, action string, ipAddress, userAgent string) error
 	LogPromoCode(userID uuid.UUID, promoCodeID uuid.UUID, code string, ipAddress, userAgent string) error
 	LogReferral(userID uuid.UUID, referredUserID uuid.UUID, action string, ipAddress, userAgent string) error
+	LogWithdrawal(userID uuid.UUID, requestID uuid.UUID, fromStatus, toStatus, comment string, ipAddress, userAgent string) error
 	GetUserActivity(userID uuid.UUID, limit, offset int) ([]models.ActivityLog, error)
 	GetActivityByAction(action string, limit, offset int) ([]models.ActivityLog, error)
 	GetAllActivity(limit, offset int) ([]models.ActivityLog, error)
@@ -104,3 +191,40 @@ type IActivityLogService interface {
 	GetActionCount(action string) (int64, error)
 	CleanupOldLogs(daysToKeep int) error
 }
+
+// IStatsService интерфейс статистики для админ-панели
+type IStatsService interface {
+	Dashboard(periodKey string, now time.Time) (*StatsDashboard, error)
+	Series(periodKey string, now time.Time) (*StatsSeries, error)
+	Revenue(periodKey string, from, to *time.Time, now time.Time) (*PeriodStats, error)
+	Overview(now time.Time) (*models.StatsOverview, error)
+}
+
+// IExportService интерфейс выгрузок данных из админ-панели
+type IExportService interface {
+	Export(request ExportRequest) (*ExportResult, error)
+	Open(token string) (string, string, error)
+	Discard(result *ExportResult)
+}
+
+// IAdminListService интерфейс постраничных списков для REST API
+type IAdminListService interface {
+	ListUsers(filter repositories.AdminListFilter, limit, offset int) ([]models.User, int64, error)
+	ListSubscriptions(filter repositories.AdminListFilter, limit, offset int) ([]models.Subscription, int64, error)
+	ListPayments(filter repositories.AdminListFilter, limit, offset int) ([]models.Payment, int64, error)
+	ListPromoCodes(limit, offset int) ([]models.PromoCode, int64, error)
+}
+
+// IPurchaseService интерфейс покупки подписок с баланса
+type IPurchaseService interface {
+	Quote(userID uuid.UUID, tariffKey string) (*PurchaseQuote, error)
+	Purchase(user *models.User, quote *PurchaseQuote) (float64, error)
+}
+
+// ITopUpService интерфейс пополнения баланса через Telegram Stars
+type ITopUpService interface {
+	Stars(amount float64) int
+	CreateInvoice(userID uuid.UUID, amount float64) (*TopUpInvoice, error)
+	CheckInvoice(userID uuid.UUID, payload, currency string, totalAmount int) (*models.Payment, error)
+	CompleteInvoice(userID uuid.UUID, payload, currency string, totalAmount int, chargeID string) (*models.Payment, error)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+)
+
+// HealthCheck именованная проверка зависимости для автоматического режима
+// обслуживания и проверки готовности
+type HealthCheck struct {
+	Name  string
+	Check func() error
+	// CacheTTL сколько результат проверки переиспользуется в /health/ready
+	CacheTTL time.Duration
+	// Critical без компонента сервис не готов принимать запросы
+	Critical bool
+}
+
+// MaintenanceStatus текущее состояние режима обслуживания
+type MaintenanceStatus struct {
+	// Manual включен администратором или через MAINTENANCE_MODE
+	Manual bool
+	// Auto включен автоматически из-за недоступности зависимостей
+	Auto bool
+	// AutoEnable разрешено ли автоматическое включение
+	AutoEnable bool
+	// Failures число подряд неудачных проверок
+	Failures int
+	// LastError последняя ошибка проверки
+	LastError string
+	// CheckedAt время последней проверки
+	CheckedAt time.Time
+}
+
+// Enabled проверяет, закрыт ли бот для пользователей
+func (s MaintenanceStatus) Enabled() bool {
+	return s.Manual || s.Auto
+}
+
+// MaintenanceService управляет режимом технических работ. Состояние хранится
+// в памяти процесса и при запуске берется из MAINTENANCE_MODE
+type MaintenanceService struct {
+	checks             []HealthCheck
+	messenger          telegram.Messenger
+	activityLogService IActivityLogService
+	heartbeat          *Heartbeat
+	config             *config.Config
+	logger             logger.Logger
+
+	mu     sync.RWMutex
+	status MaintenanceStatus
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMaintenanceService создает новый сервис режима обслуживания
+func NewMaintenanceService(checks []HealthCheck, messenger telegram.Messenger, activityLogService IActivityLogService, heartbeat *Heartbeat, config *config.Config, logger logger.Logger) *MaintenanceService {
+	return &MaintenanceService{
+		checks:             checks,
+		messenger:          messenger,
+		activityLogService: activityLogService,
+		heartbeat:          heartbeat,
+		config:             config,
+		logger:             logger,
+		status: MaintenanceStatus{
+			Manual:     config.Admin.MaintenanceMode,
+			AutoEnable: config.Admin.MaintenanceAutoEnable,
+		},
+	}
+}
+
+// IsEnabled проверяет, включен ли режим обслуживания
+func (s *MaintenanceService) IsEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status.Enabled()
+}
+
+// Status возвращает копию текущего состояния
+func (s *MaintenanceService) Status() MaintenanceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+// Message возвращает текст для пользователей: MAINTENANCE_MESSAGE
+// или перевод по умолчанию, если он не задан
+func (s *MaintenanceService) Message(defaultText string) string {
+	if s.config.Admin.MaintenanceMessage != "" {
+		return s.config.Admin.MaintenanceMessage
+	}
+	return defaultText
+}
+
+// SetManual включает или выключает режим обслуживания вручную
+func (s *MaintenanceService) SetManual(enabled bool, adminID uuid.UUID) {
+	s.mu.Lock()
+	changed := s.status.Manual != enabled
+	s.status.Manual = enabled
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	s.logger.Info("Maintenance mode changed by admin", "enabled", enabled, "admin_id", adminID)
+	if s.activityLogService != nil {
+		data := map[string]interface{}{"enabled": enabled}
+		if err := s.activityLogService.LogActivity(adminID, "maintenance_toggle", data, "", ""); err != nil {
+			s.logger.Warn("Failed to log maintenance toggle", "error", err)
+		}
+	}
+}
+
+// CheckHealth выполняет проверки зависимостей. При MAINTENANCE_AUTO_ENABLE
+// режим включается после MAINTENANCE_FAILURE_THRESHOLD неудачных проверок
+// подряд и выключается после первой успешной
+func (s *MaintenanceService) CheckHealth() {
+	var failed []string
+	for _, check := range s.checks {
+		if err := check.Check(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", check.Name, err))
+		}
+	}
+
+	threshold := s.config.Settings().MaintenanceFailureThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	s.mu.Lock()
+	s.status.CheckedAt = time.Now()
+	wasAuto := s.status.Auto
+	if len(failed) > 0 {
+		s.status.Failures++
+		s.status.LastError = strings.Join(failed, "; ")
+		if s.status.AutoEnable && s.status.Failures >= threshold {
+			s.status.Auto = true
+		}
+	} else {
+		s.status.Failures = 0
+		s.status.LastError = ""
+		s.status.Auto = false
+	}
+	status := s.status
+	s.mu.Unlock()
+
+	if len(failed) > 0 {
+		s.logger.Warn("Health check failed", "failures", status.Failures, "error", status.LastError)
+	}
+
+	switch {
+	case status.Auto && !wasAuto:
+		s.logger.Warn("Maintenance mode enabled automatically", "error", status.LastError)
+		s.notifyAdmins(fmt.Sprintf("🛠 Режим технических работ включен автоматически\n\nПроверки не проходят %d раз подряд:\n%s", status.Failures, status.LastError))
+	case !status.Auto && wasAuto:
+		s.logger.Info("Maintenance mode disabled automatically")
+		s.notifyAdmins("✅ Зависимости снова доступны, режим технических работ выключен автоматически")
+	}
+}
+
+// Start запускает периодические проверки с интервалом HEALTH_CHECK_INTERVAL
+func (s *MaintenanceService) Start() {
+	if len(s.checks) == 0 || s.config.Monitoring.HealthCheckInterval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.heartbeat.Expect("health_check", s.config.Monitoring.HealthCheckInterval)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.config.Monitoring.HealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				start := time.Now()
+				s.CheckHealth()
+				metrics.ObserveJob("health_check", start, nil)
+				s.heartbeat.Beat("health_check")
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop останавливает периодические проверки
+func (s *MaintenanceService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+// notifyAdmins отправляет сообщение всем администраторам из конфигурации
+func (s *MaintenanceService) notifyAdmins(text string) {
+	for _, adminID := range s.config.Admin.TelegramIDs {
+		if _, err := s.messenger.SendMessage(adminID, text, telegram.Plain(nil)); err != nil {
+			s.logger.Warn("Failed to notify admin about maintenance", "admin_id", adminID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (m *MockActivityLogService) LogActivity(userID uuid.UUID, action string, data interface{}, ipAddress, userAgent string) error {
+	args := m.Called(userID, action, data, ipAddress, userAgent)
+	return args.Error(0)
+}
+
+func newTestMaintenanceService(autoEnable bool, check func() error) (*MaintenanceService, *telegram.FakeMessenger) {
+	cfg := &config.Config{}
+	cfg.Admin.TelegramIDs = []int64{1001}
+	cfg.Admin.MaintenanceAutoEnable = autoEnable
+	cfg.Admin.MaintenanceFailureThreshold = 3
+
+	messenger := telegram.NewFakeMessenger()
+	checks := []HealthCheck{{Name: "remnawave", Check: check}}
+	return NewMaintenanceService(checks, messenger, nil, nil, cfg, logger.New("error")), messenger
+}
+
+func TestMaintenanceService_AutoEnableAfterThresholdAndRecover(t *testing.T) {
+	var healthErr error
+	service, messenger := newTestMaintenanceService(true, func() error { return healthErr })
+
+	healthErr = errors.New("connection refused")
+	service.CheckHealth()
+	service.CheckHealth()
+	assert.False(t, service.IsEnabled())
+
+	service.CheckHealth()
+	assert.True(t, service.IsEnabled())
+	assert.Equal(t, 3, service.Status().Failures)
+	assert.Contains(t, service.Status().LastError, "remnawave: connection refused")
+	assert.Len(t, messenger.Sent(), 1)
+
+	// Повторные сбои не дублируют уведомление
+	service.CheckHealth()
+	assert.Len(t, messenger.Sent(), 1)
+
+	healthErr = nil
+	service.CheckHealth()
+	assert.False(t, service.IsEnabled())
+	assert.Empty(t, service.Status().LastError)
+	assert.Len(t, messenger.Sent(), 2)
+}
+
+func TestMaintenanceService_NoAutoEnableWhenDisabled(t *testing.T) {
+	service, messenger := newTestMaintenanceService(false, func() error { return errors.New("timeout") })
+
+	for i := 0; i < 5; i++ {
+		service.CheckHealth()
+	}
+
+	assert.False(t, service.IsEnabled())
+	assert.Equal(t, 5, service.Status().Failures)
+	assert.Empty(t, messenger.Sent())
+}
+
+func TestMaintenanceService_ManualToggleIsLogged(t *testing.T) {
+	service, _ := newTestMaintenanceService(true, func() error { return nil })
+	activity := new(MockActivityLogService)
+	service.activityLogService = activity
+	adminID := uuid.New()
+
+	activity.On("LogActivity", adminID, "maintenance_toggle", mock.Anything, "", "").Return(nil).Once()
+
+	service.SetManual(true, adminID)
+	assert.True(t, service.IsEnabled())
+
+	// Успешная проверка не выключает ручной режим
+	service.CheckHealth()
+	assert.True(t, service.IsEnabled())
+
+	// Повторное включение ничего не меняет и не логируется
+	service.SetManual(true, adminID)
+	activity.AssertExpectations(t)
+}
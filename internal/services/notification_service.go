@@ -3,10 +3,11 @@ package services
 import (
 	"fmt"
 	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/telegram"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +15,8 @@ type NotificationService struct {
 	repo             repositories.NotificationRepository
 	userRepo         repositories.UserRepository
 	subscriptionRepo repositories.SubscriptionRepository
+	messenger        telegram.Messenger
+	bundle           *i18n.Bundle
 	config           *config.Config
 }
 
@@ -21,12 +24,16 @@ func NewNotificationService(
 	repo repositories.NotificationRepository,
 	userRepo repositories.UserRepository,
 	subscriptionRepo repositories.SubscriptionRepository,
+	messenger telegram.Messenger,
+	bundle *i18n.Bundle,
 	config *config.Config,
 ) *NotificationService {
 	return &NotificationService{
 		repo:             repo,
 		userRepo:         userRepo,
 		subscriptionRepo: subscriptionRepo,
+		messenger:        messenger,
+		bundle:           bundle,
 		config:           config,
 	}
 }
@@ -46,7 +53,7 @@ func (s *NotificationService) CreateNotification(userID *uuid.UUID, notification
 }
 
 // SendNotification отправляет уведомление пользователю
-func (s *NotificationService) SendNotification(notificationID uuid.UUID, botToken string) error {
+func (s *NotificationService) SendNotification(notificationID uuid.UUID) error {
 	notification, err := s.repo.GetByID(notificationID)
 	if err != nil {
 		return fmt.Errorf("уведомление не найдено: %v", err)
@@ -65,7 +72,7 @@ func (s *NotificationService) SendNotification(notificationID uuid.UUID, botToke
 
 		// Отправляем сообщение пользователю
 		message := fmt.Sprintf("🔔 *%s*\n\n%s", notification.Title, notification.Message)
-		if err := sendMessage(user.TelegramID, message, botToken); err != nil {
+		if err := s.sendMessage(user.TelegramID, message); err != nil {
 			return fmt.Errorf("ошибка отправки сообщения: %v", err)
 		}
 	}
@@ -74,8 +81,47 @@ func (s *NotificationService) SendNotification(notificationID uuid.UUID, botToke
 	return s.repo.MarkAsSent(notificationID)
 }
 
+// SendToUser создает и сразу отправляет уведомление одному пользователю на
+// его языке. Заголовок и текст берутся из ключей <key>.title и <key>.message,
+// args — подстановки для текста
+func (s *NotificationService) SendToUser(userID uuid.UUID, notificationType, key string, args ...any) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if user == nil {
+		return fmt.Errorf("пользователь %s не найден", userID)
+	}
+
+	args = markdownArgs(args)
+	locale := s.bundle.For(user.PreferredLanguages()...)
+	title := locale.T(key+".title", args...)
+	message := locale.T(key+".message", args...)
+
+	notification, err := s.CreateNotification(&userID, notificationType, title, message)
+	if err != nil {
+		return fmt.Errorf("ошибка создания уведомления: %v", err)
+	}
+
+	return s.SendNotification(notification.ID)
+}
+
+// markdownArgs экранирует строковые значения подстановок: уведомления
+// отправляются с разметкой Markdown, а имена пользователей и тексты
+// администраторов могут содержать ее символы
+func markdownArgs(args []any) []any {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		if value, ok := arg.(string); ok && i%2 == 1 {
+			arg = telegram.EscapeMarkdown(value)
+		}
+		escaped[i] = arg
+	}
+	return escaped
+}
+
 // SendBulkNotification отправляет уведомление всем пользователям
-func (s *NotificationService) SendBulkNotification(notificationType, title, message string, botToken string) error {
+func (s *NotificationService) SendBulkNotification(notificationType, title, message string) error {
 	// Получаем всех пользователей
 	users, err := s.userRepo.GetAll(0, 0) // 0, 0 = без лимитов
 	if err != nil {
@@ -103,7 +149,7 @@ func (s *NotificationService) SendBulkNotification(notificationType, title, mess
 	// Отправляем уведомления
 	for _, user := range users {
 		messageText := fmt.Sprintf("🔔 *%s*\n\n%s", title, message)
-		if err := sendMessage(user.TelegramID, messageText, botToken); err != nil {
+		if err := s.sendMessage(user.TelegramID, messageText); err != nil {
 			// Логируем ошибку, но продолжаем отправку остальным
 			fmt.Printf("Ошибка отправки уведомления пользователю %d: %v\n", user.TelegramID, err)
 		}
@@ -113,7 +159,7 @@ func (s *NotificationService) SendBulkNotification(notificationType, title, mess
 }
 
 // SendToUsersWithActiveSubscriptions отправляет уведомление пользователям с активными подписками
-func (s *NotificationService) SendToUsersWithActiveSubscriptions(notificationType, title, message string, botToken string) error {
+func (s *NotificationService) SendToUsersWithActiveSubscriptions(notificationType, title, message string) error {
 	// Получаем пользователей с активными подписками
 	users, err := s.subscriptionRepo.GetUsersWithActiveSubscriptions()
 	if err != nil {
@@ -141,7 +187,7 @@ func (s *NotificationService) SendToUsersWithActiveSubscriptions(notificationTyp
 	// Отправляем уведомления
 	for _, user := range users {
 		messageText := fmt.Sprintf("🔔 *%s*\n\n%s", title, message)
-		if err := sendMessage(user.TelegramID, messageText, botToken); err != nil {
+		if err := s.sendMessage(user.TelegramID, messageText); err != nil {
 			fmt.Printf("Ошибка отправки уведомления пользователю %d: %v\n", user.TelegramID, err)
 		}
 	}
@@ -150,7 +196,7 @@ func (s *NotificationService) SendToUsersWithActiveSubscriptions(notificationTyp
 }
 
 // SendToUsersWithExpiredSubscriptions отправляет уведомление пользователям с истекшими подписками
-func (s *NotificationService) SendToUsersWithExpiredSubscriptions(notificationType, title, message string, botToken string) error {
+func (s *NotificationService) SendToUsersWithExpiredSubscriptions(notificationType, title, message string) error {
 	// Получаем пользователей с истекшими подписками
 	users, err := s.subscriptionRepo.GetUsersWithExpiredSubscriptions()
 	if err != nil {
@@ -178,7 +224,7 @@ func (s *NotificationService) SendToUsersWithExpiredSubscriptions(notificationTy
 	// Отправляем уведомления
 	for _, user := range users {
 		messageText := fmt.Sprintf("🔔 *%s*\n\n%s", title, message)
-		if err := sendMessage(user.TelegramID, messageText, botToken); err != nil {
+		if err := s.sendMessage(user.TelegramID, messageText); err != nil {
 			fmt.Printf("Ошибка отправки уведомления пользователю %d: %v\n", user.TelegramID, err)
 		}
 	}
@@ -187,13 +233,14 @@ func (s *NotificationService) SendToUsersWithExpiredSubscriptions(notificationTy
 }
 
 // CheckExpiringSubscriptions проверяет истекающие подписки и отправляет уведомления
-func (s *NotificationService) CheckExpiringSubscriptions(botToken string) error {
-	if !s.config.Notifications.Enabled {
+func (s *NotificationService) CheckExpiringSubscriptions() error {
+	settings := s.config.Settings().Notifications
+	if !settings.Enabled {
 		return nil
 	}
 
 	// Получаем пользователей с истекающими подписками
-	users, err := s.repo.GetExpiringSubscriptions(s.config.Notifications.ExpiringDaysBefore)
+	users, err := s.repo.GetExpiringSubscriptions(settings.ExpiringDaysBefore)
 	if err != nil {
 		return fmt.Errorf("ошибка получения пользователей с истекающими подписками: %v", err)
 	}
@@ -208,9 +255,10 @@ func (s *NotificationService) CheckExpiringSubscriptions(botToken string) error
 		for _, subscription := range subscriptions {
 			if subscription.IsActive() {
 				daysLeft := subscription.GetDaysLeft()
-				if daysLeft <= s.config.Notifications.ExpiringDaysBefore && daysLeft > 0 {
-					title := "⚠️ Подписка истекает"
-					message := fmt.Sprintf("Ваша подписка истекает через %d дн. Продлите её, чтобы не потерять доступ.", daysLeft)
+				if daysLeft <= settings.ExpiringDaysBefore && daysLeft > 0 {
+					locale := s.bundle.For(user.PreferredLanguages()...)
+					title := locale.T("notifications.subscription_expiring.title")
+					message := locale.T("notifications.subscription_expiring.message", "duration", locale.N("units.days", daysLeft))
 
 					// Создаем уведомление
 					notification := &models.Notification{
@@ -228,7 +276,7 @@ func (s *NotificationService) CheckExpiringSubscriptions(botToken string) error
 
 					// Отправляем уведомление
 					messageText := fmt.Sprintf("🔔 *%s*\n\n%s", title, message)
-					if err := sendMessage(user.TelegramID, messageText, botToken); err != nil {
+					if err := s.sendMessage(user.TelegramID, messageText); err != nil {
 						fmt.Printf("Ошибка отправки уведомления пользователю %d: %v\n", user.TelegramID, err)
 					}
 				}
@@ -254,15 +302,8 @@ func (s *NotificationService) GetUnreadCount(userID uuid.UUID) (int64, error) {
 	return s.repo.CountUnreadByUserID(userID)
 }
 
-// sendMessage отправляет сообщение через tgbotapi
-func sendMessage(chatID int64, text string, botToken string) error {
-	bot, err := tgbotapi.NewBotAPI(botToken)
-	if err != nil {
-		return err
-	}
-
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	_, err = bot.Send(msg)
+// sendMessage отправляет сообщение пользователю
+func (s *NotificationService) sendMessage(chatID int64, text string) error {
+	_, err := s.messenger.SendMessage(chatID, text, telegram.Markdown(nil))
 	return err
 }
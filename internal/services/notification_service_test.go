@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryNotificationRepository хранит уведомления в памяти
+type memoryNotificationRepository struct {
+	repositories.NotificationRepository
+	notifications map[uuid.UUID]*models.Notification
+}
+
+func (r *memoryNotificationRepository) Create(notification *models.Notification) error {
+	notification.ID = uuid.New()
+	r.notifications[notification.ID] = notification
+	return nil
+}
+
+func (r *memoryNotificationRepository) GetByID(id uuid.UUID) (*models.Notification, error) {
+	return r.notifications[id], nil
+}
+
+func (r *memoryNotificationRepository) MarkAsSent(id uuid.UUID) error {
+	r.notifications[id].IsSent = true
+	return nil
+}
+
+func TestNotificationService_SendToUserEscapesArguments(t *testing.T) {
+	bundle, err := i18n.Load("ru", []string{"ru", "en"})
+	require.NoError(t, err)
+
+	user := &models.User{ID: uuid.New(), TelegramID: 42}
+	users := new(MockUserRepository)
+	users.On("GetByID", user.ID).Return(user, nil)
+
+	messenger := telegram.NewFakeMessenger()
+	repo := &memoryNotificationRepository{notifications: make(map[uuid.UUID]*models.Notification)}
+	service := NewNotificationService(repo, users, nil, messenger, bundle, &config.Config{})
+
+	// Текст администратора отправляется как есть, символы разметки экранируются
+	require.NoError(t, service.SendToUser(user.ID, "admin_message", "notifications.admin_message", "text", "Промокод NEW_YEAR *скоро* `[тест]`"))
+
+	sent, ok := messenger.Last()
+	require.True(t, ok)
+	assert.Equal(t, int64(42), sent.ChatID)
+	assert.Contains(t, sent.Text, "Промокод NEW\\_YEAR \\*скоро\\* \\`\\[тест]\\`")
+	assert.Equal(t, "Markdown", sent.Options.ParseMode)
+}
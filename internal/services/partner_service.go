@@ -0,0 +1,179 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+type PartnerService struct {
+	repo                repositories.PartnerRepository
+	withdrawalRepo      repositories.WithdrawalRepository
+	userRepo            repositories.UserRepository
+	notificationService INotificationService
+	config              *config.Config
+	logger              logger.Logger
+}
+
+func NewPartnerService(
+	repo repositories.PartnerRepository,
+	withdrawalRepo repositories.WithdrawalRepository,
+	userRepo repositories.UserRepository,
+	notificationService INotificationService,
+	config *config.Config,
+	log logger.Logger,
+) *PartnerService {
+	return &PartnerService{
+		repo:                repo,
+		withdrawalRepo:      withdrawalRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		config:              config,
+		logger:              log,
+	}
+}
+
+// IsEnabled проверяет, включена ли партнерская программа
+func (s *PartnerService) IsEnabled() bool {
+	return s.config.Partner.Enabled
+}
+
+// TrackClick учитывает переход по реферальной ссылке вида ref_<код>
+func (s *PartnerService) TrackClick(startParam string, telegramID int64) error {
+	code, ok := strings.CutPrefix(startParam, ReferralStartPrefix)
+	if !ok || code == "" || !s.config.Partner.Enabled {
+		return nil
+	}
+
+	partner, err := s.userRepo.GetByReferralCode(code)
+	if err != nil || partner == nil {
+		return err
+	}
+	// Переходы по собственной ссылке не учитываем
+	if partner.TelegramID == telegramID {
+		return nil
+	}
+
+	return s.repo.CreateClick(&models.ReferralClick{
+		PartnerID:  partner.ID,
+		TelegramID: telegramID,
+	})
+}
+
+// AccrueCommission начисляет комиссию партнерам первого и второго уровня
+// с завершенного платежа. Повторный вызов для того же платежа ничего не начисляет
+func (s *PartnerService) AccrueCommission(payment *models.Payment) error {
+	if !s.config.Partner.Enabled || payment == nil || !payment.IsCompleted() || payment.Amount <= 0 {
+		return nil
+	}
+
+	payer, err := s.userRepo.GetByID(payment.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get payer: %w", err)
+	}
+	if payer == nil || payer.ReferredBy == nil {
+		return nil
+	}
+
+	settings := s.config.Settings().Partner
+	levels := []float64{settings.Level1Percent, settings.Level2Percent}
+	availableAt := time.Now().AddDate(0, 0, settings.HoldDays)
+	partnerID := payer.ReferredBy
+
+	for i, percent := range levels {
+		if partnerID == nil || percent <= 0 {
+			break
+		}
+
+		partner, err := s.userRepo.GetByID(*partnerID)
+		if err != nil {
+			return fmt.Errorf("failed to get partner: %w", err)
+		}
+		// Защита от циклов: плательщик не получает комиссию со своих платежей
+		if partner == nil || partner.ID == payer.ID {
+			break
+		}
+
+		earning := &models.PartnerEarning{
+			PartnerID:   partner.ID,
+			ReferralID:  payer.ID,
+			PaymentID:   payment.ID,
+			Level:       i + 1,
+			Percent:     percent,
+			Amount:      math.Round(payment.Amount*percent) / 100,
+			AvailableAt: availableAt,
+		}
+
+		created, err := s.repo.CreateEarning(earning)
+		if err != nil {
+			return err
+		}
+		if created && earning.Amount > 0 {
+			s.logger.Info("Partner commission accrued", "partner_id", partner.ID, "payment_id", payment.ID, "level", earning.Level, "amount", earning.Amount)
+			s.notifyPartner(partner, earning)
+		}
+
+		partnerID = partner.ReferredBy
+	}
+
+	return nil
+}
+
+// GetStats возвращает статистику партнера
+func (s *PartnerService) GetStats(partnerID uuid.UUID) (*models.PartnerStats, error) {
+	stats := &models.PartnerStats{}
+	var err error
+
+	if stats.Clicks, err = s.repo.CountClicks(partnerID); err != nil {
+		return nil, err
+	}
+	if stats.Registrations, err = s.repo.CountRegistrations(partnerID); err != nil {
+		return nil, err
+	}
+	if stats.Conversions, err = s.repo.CountConversions(partnerID); err != nil {
+		return nil, err
+	}
+	if stats.Level2Users, err = s.repo.CountSecondLevel(partnerID); err != nil {
+		return nil, err
+	}
+	if stats.TotalEarned, err = s.repo.SumEarnings(partnerID); err != nil {
+		return nil, err
+	}
+
+	available, err := s.repo.SumAvailableEarnings(partnerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if stats.Frozen, err = s.withdrawalRepo.SumByStatuses(partnerID, []string{models.WithdrawalStatusPending, models.WithdrawalStatusApproved}); err != nil {
+		return nil, err
+	}
+	if stats.Withdrawn, err = s.withdrawalRepo.SumByStatuses(partnerID, []string{models.WithdrawalStatusPaid}); err != nil {
+		return nil, err
+	}
+
+	stats.HeldAmount = stats.TotalEarned - available
+	stats.Available = math.Max(available-stats.Frozen-stats.Withdrawn, 0)
+
+	return stats, nil
+}
+
+// notifyPartner сообщает партнеру о новом начислении
+func (s *PartnerService) notifyPartner(partner *models.User, earning *models.PartnerEarning) {
+	err := s.notificationService.SendToUser(partner.ID, "partner_commission", "notifications.partner_commission",
+		"amount", earning.Amount,
+		"percent", earning.Percent,
+		"level", earning.Level,
+		"date", earning.AvailableAt.Format("02.01.2006"),
+	)
+	if err != nil {
+		s.logger.Error("Failed to notify partner", "error", err, "user_id", partner.ID)
+	}
+}
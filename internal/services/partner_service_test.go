@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPartnerRepository мок для PartnerRepository
+type MockPartnerRepository struct {
+	mock.Mock
+}
+
+func (m *MockPartnerRepository) CreateEarning(earning *models.PartnerEarning) (bool, error) {
+	args := m.Called(earning)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPartnerRepository) GetEarningsByPartner(partnerID uuid.UUID, limit, offset int) ([]models.PartnerEarning, error) {
+	args := m.Called(partnerID, limit, offset)
+	return args.Get(0).([]models.PartnerEarning), args.Error(1)
+}
+
+func (m *MockPartnerRepository) SumEarnings(partnerID uuid.UUID) (float64, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockPartnerRepository) SumAvailableEarnings(partnerID uuid.UUID, at time.Time) (float64, error) {
+	args := m.Called(partnerID, at)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockPartnerRepository) CreateClick(click *models.ReferralClick) error {
+	args := m.Called(click)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) CountClicks(partnerID uuid.UUID) (int64, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPartnerRepository) CountRegistrations(partnerID uuid.UUID) (int64, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPartnerRepository) CountConversions(partnerID uuid.UUID) (int64, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPartnerRepository) CountSecondLevel(partnerID uuid.UUID) (int64, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestPartnerService_AccrueCommission_TwoLevels(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Partner = config.PartnerConfig{Enabled: true, Level1Percent: 10, Level2Percent: 5, HoldDays: 14}
+
+	top := &models.User{ID: uuid.New()}
+	partner := &models.User{ID: uuid.New(), ReferredBy: &top.ID}
+	payer := &models.User{ID: uuid.New(), ReferredBy: &partner.ID}
+	payment := &models.Payment{ID: uuid.New(), UserID: payer.ID, Amount: 299, Status: "completed"}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", payer.ID).Return(payer, nil)
+	userRepo.On("GetByID", partner.ID).Return(partner, nil)
+	userRepo.On("GetByID", top.ID).Return(top, nil)
+
+	var earnings []*models.PartnerEarning
+	repo := new(MockPartnerRepository)
+	repo.On("CreateEarning", mock.AnythingOfType("*models.PartnerEarning")).
+		Run(func(args mock.Arguments) { earnings = append(earnings, args.Get(0).(*models.PartnerEarning)) }).
+		Return(true, nil)
+
+	notifications := new(MockNotificationService)
+	notifications.On("SendToUser", mock.Anything, "partner_commission", mock.Anything, mock.Anything).Return(nil)
+
+	service := NewPartnerService(repo, new(MockWithdrawalRepository), userRepo, notifications, cfg, logger.New("error"))
+	require.NoError(t, service.AccrueCommission(payment))
+
+	require.Len(t, earnings, 2)
+	assert.Equal(t, partner.ID, earnings[0].PartnerID)
+	assert.Equal(t, 1, earnings[0].Level)
+	assert.Equal(t, 29.9, earnings[0].Amount)
+	assert.Equal(t, top.ID, earnings[1].PartnerID)
+	assert.Equal(t, 2, earnings[1].Level)
+	assert.Equal(t, 14.95, earnings[1].Amount)
+	assert.True(t, earnings[0].AvailableAt.After(time.Now().AddDate(0, 0, 13)))
+	notifications.AssertNumberOfCalls(t, "SendToUser", 2)
+}
+
+func TestPartnerService_AccrueCommission_Disabled(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Partner = config.PartnerConfig{Enabled: false, Level1Percent: 10}
+
+	service := NewPartnerService(new(MockPartnerRepository), new(MockWithdrawalRepository), new(MockUserRepository), nil, cfg, logger.New("error"))
+	payment := &models.Payment{ID: uuid.New(), UserID: uuid.New(), Amount: 100, Status: "completed"}
+
+	assert.NoError(t, service.AccrueCommission(payment))
+}
+
+func TestPartnerService_GetStats(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Partner = config.PartnerConfig{Enabled: true, Level1Percent: 10}
+	partnerID := uuid.New()
+
+	repo := new(MockPartnerRepository)
+	repo.On("CountClicks", partnerID).Return(int64(20), nil)
+	repo.On("CountRegistrations", partnerID).Return(int64(8), nil)
+	repo.On("CountConversions", partnerID).Return(int64(2), nil)
+	repo.On("CountSecondLevel", partnerID).Return(int64(1), nil)
+	repo.On("SumEarnings", partnerID).Return(100.0, nil)
+	repo.On("SumAvailableEarnings", partnerID, mock.AnythingOfType("time.Time")).Return(60.0, nil)
+
+	withdrawals := new(MockWithdrawalRepository)
+	withdrawals.On("SumByStatuses", partnerID, []string{models.WithdrawalStatusPending, models.WithdrawalStatusApproved}).Return(15.0, nil)
+	withdrawals.On("SumByStatuses", partnerID, []string{models.WithdrawalStatusPaid}).Return(20.0, nil)
+
+	service := NewPartnerService(repo, withdrawals, new(MockUserRepository), nil, cfg, logger.New("error"))
+	stats, err := service.GetStats(partnerID)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), stats.Clicks)
+	assert.Equal(t, 25.0, stats.GetConversionRate())
+	assert.Equal(t, 40.0, stats.HeldAmount)
+	assert.Equal(t, 15.0, stats.Frozen)
+	assert.Equal(t, 20.0, stats.Withdrawn)
+	assert.Equal(t, 25.0, stats.Available)
+}
@@ -1,29 +1,36 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/repositories"
 
 	"github.com/google/uuid"
 )
 
+// ErrPaymentNotPending платеж уже не ожидает оплаты
+var ErrPaymentNotPending = errors.New("платеж уже обработан")
+
 // paymentService реализация PaymentService
 type paymentService struct {
-	paymentRepo repositories.PaymentRepository
-	userService UserService
-	logger      logger.Logger
+	paymentRepo    repositories.PaymentRepository
+	userService    UserService
+	partnerService IPartnerService
+	logger         logger.Logger
 }
 
 // NewPaymentService создает новый сервис платежей
-func NewPaymentService(paymentRepo repositories.PaymentRepository, userService UserService, log logger.Logger) PaymentService {
+func NewPaymentService(paymentRepo repositories.PaymentRepository, userService UserService, partnerService IPartnerService, log logger.Logger) PaymentService {
 	return &paymentService{
-		paymentRepo: paymentRepo,
-		userService: userService,
-		logger:      log,
+		paymentRepo:    paymentRepo,
+		userService:    userService,
+		partnerService: partnerService,
+		logger:         log,
 	}
 }
 
@@ -44,6 +51,7 @@ func (s *paymentService) CreatePayment(userID uuid.UUID, amount float64, method,
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
+	metrics.PaymentStatus(method, payment.Status)
 	s.logger.Info("Payment created", "user_id", userID, "amount", amount, "method", method)
 	return payment, nil
 }
@@ -57,8 +65,13 @@ func (s *paymentService) GetPayment(id uuid.UUID) (*models.Payment, error) {
 	return payment, nil
 }
 
-// UpdatePaymentStatus обновляет статус платежа
+// UpdatePaymentStatus обновляет статус платежа. Завершить можно только
+// ожидающий платеж, иначе возвращается ErrPaymentNotPending
 func (s *paymentService) UpdatePaymentStatus(id uuid.UUID, status string) error {
+	if status == "completed" {
+		return s.completePayment(id)
+	}
+
 	payment, err := s.paymentRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("failed to get payment: %w", err)
@@ -70,23 +83,34 @@ func (s *paymentService) UpdatePaymentStatus(id uuid.UUID, status string) error
 	payment.Status = status
 	payment.UpdatedAt = time.Now()
 
-	if status == "completed" {
-		now := time.Now()
-		payment.CompletedAt = &now
-	}
-
 	if err := s.paymentRepo.Update(payment); err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
-	// Если платеж завершен, добавляем средства на баланс
-	if status == "completed" {
-		if err := s.userService.AddBalance(payment.UserID, payment.Amount); err != nil {
-			s.logger.Error("Failed to add balance after payment completion", "error", err, "user_id", payment.UserID, "amount", payment.Amount)
-		}
+	metrics.PaymentStatus(payment.PaymentMethod, status)
+	s.logger.Info("Payment status updated", "payment_id", id, "status", status)
+	return nil
+}
+
+// completePayment завершает ожидающий платеж и зачисляет его на баланс.
+// Статус меняется условным обновлением, поэтому при повторном уведомлении
+// об оплате баланс и партнерская комиссия не начисляются второй раз
+func (s *paymentService) completePayment(id uuid.UUID) error {
+	payment, err := s.paymentRepo.Complete(id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to complete payment: %w", err)
+	}
+	if payment == nil {
+		return ErrPaymentNotPending
 	}
 
-	s.logger.Info("Payment status updated", "payment_id", id, "status", status)
+	metrics.PaymentStatus(payment.PaymentMethod, payment.Status)
+	metrics.Revenue(payment.PaymentMethod, payment.Amount)
+	if err := s.partnerService.AccrueCommission(payment); err != nil {
+		s.logger.Error("Failed to accrue partner commission", "error", err, "payment_id", payment.ID)
+	}
+
+	s.logger.Info("Payment completed", "payment_id", id, "user_id", payment.UserID, "amount", payment.Amount)
 	return nil
 }
 
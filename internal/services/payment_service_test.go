@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPaymentRepository мок для PaymentRepository
+type MockPaymentRepository struct {
+	repositories.PaymentRepository
+	mock.Mock
+}
+
+func (m *MockPaymentRepository) Complete(id uuid.UUID, completedAt time.Time) (*models.Payment, error) {
+	args := m.Called(id)
+	payment, _ := args.Get(0).(*models.Payment)
+	return payment, args.Error(1)
+}
+
+// MockPartnerService мок для IPartnerService
+type MockPartnerService struct {
+	IPartnerService
+	mock.Mock
+}
+
+func (m *MockPartnerService) AccrueCommission(payment *models.Payment) error {
+	return m.Called(payment).Error(0)
+}
+
+func TestPaymentService_CompleteOnce(t *testing.T) {
+	payment := &models.Payment{ID: uuid.New(), UserID: uuid.New(), Amount: 300, PaymentMethod: "stars", Status: "completed"}
+
+	repo := new(MockPaymentRepository)
+	repo.On("Complete", payment.ID).Return(payment, nil).Once()
+	repo.On("Complete", payment.ID).Return(nil, nil).Once()
+	partners := new(MockPartnerService)
+	partners.On("AccrueCommission", payment).Return(nil).Once()
+
+	service := NewPaymentService(repo, nil, partners, logger.New("error"))
+
+	require.NoError(t, service.UpdatePaymentStatus(payment.ID, "completed"))
+
+	// Повторное уведомление не зачисляет баланс и комиссию второй раз
+	assert.ErrorIs(t, service.UpdatePaymentStatus(payment.ID, "completed"), ErrPaymentNotPending)
+	repo.AssertExpectations(t)
+	partners.AssertExpectations(t)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// promoCodeAlphabet символы случайной части кода без похожих друг на друга 0/O, 1/I/L
+	promoCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	// promoBatchCodeLength длина случайной части кода в пакете
+	promoBatchCodeLength = 8
+	// promoBatchMaxCount максимальный размер одного пакета
+	promoBatchMaxCount = 10000
+	// promoBatchMaxAttempts количество попыток заменить совпавшие с существующими коды
+	promoBatchMaxAttempts = 5
+	// promoCodeMaxLength максимальная длина кода в базе
+	promoCodeMaxLength = 50
+)
+
+var (
+	promoPrefixPattern   = regexp.MustCompile(`^[A-Z0-9_-]*$`)
+	promoCampaignPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,40}$`)
+)
+
+var (
+	// ErrPromoCampaignNotFound кампания промокодов не найдена
+	ErrPromoCampaignNotFound = errors.New("кампания не найдена")
+	// ErrPromoCampaignExists ID кампании уже используется
+	ErrPromoCampaignExists = errors.New("кампания с таким ID уже существует")
+)
+
+// PromoCodeBatch параметры пакетной генерации промокодов
+type PromoCodeBatch struct {
+	CampaignID  string // пусто — сгенерировать из префикса и даты
+	Prefix      string
+	Count       int
+	Type        string
+	Value       float64
+	MaxUses     int // на каждый код, 1 — одноразовые коды
+	ValidUntil  *time.Time
+	Description string
+	CreatedBy   uuid.UUID
+}
+
+// GenerateBatch создает пакет уникальных промокодов одной кампании
+func (s *PromoCodeService) GenerateBatch(batch PromoCodeBatch) ([]models.PromoCode, error) {
+	batch.Prefix = strings.ToUpper(strings.TrimSpace(batch.Prefix))
+	if err := s.validateBatch(&batch); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.uniqueCodes(batch.Prefix, batch.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	promoCodes := make([]models.PromoCode, 0, len(codes))
+	for _, code := range codes {
+		promoCodes = append(promoCodes, models.PromoCode{
+			Code:           code,
+			Type:           batch.Type,
+			Value:          batch.Value,
+			MaxUses:        batch.MaxUses,
+			MaxUsesPerUser: 1,
+			IsActive:       true,
+			ValidFrom:      now,
+			ValidUntil:     batch.ValidUntil,
+			Description:    batch.Description,
+			CampaignID:     batch.CampaignID,
+			CreatedBy:      batch.CreatedBy,
+		})
+	}
+
+	if err := s.repo.CreateBatch(promoCodes); err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении промокодов: %v", err)
+	}
+	return promoCodes, nil
+}
+
+// GetCampaigns получает статистику кампаний промокодов, начиная с новых
+func (s *PromoCodeService) GetCampaigns(limit, offset int) ([]models.PromoCampaignStats, error) {
+	return s.repo.GetCampaignStats(limit, offset)
+}
+
+// GetCampaignStats получает статистику погашений кампании
+func (s *PromoCodeService) GetCampaignStats(campaignID string) (*models.PromoCampaignStats, error) {
+	stats, err := s.repo.GetCampaignStatsByID(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, ErrPromoCampaignNotFound
+	}
+	return stats, nil
+}
+
+// ExportCampaignCSV выгружает промокоды кампании в CSV
+func (s *PromoCodeService) ExportCampaignCSV(campaignID string) ([]byte, error) {
+	promoCodes, err := s.repo.GetByCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if len(promoCodes) == 0 {
+		return nil, ErrPromoCampaignNotFound
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"code", "type", "value", "max_uses", "used_count", "valid_until", "is_active", "campaign_id"})
+	for _, promoCode := range promoCodes {
+		validUntil := ""
+		if promoCode.ValidUntil != nil {
+			validUntil = promoCode.ValidUntil.Format("2006-01-02")
+		}
+		w.Write([]string{
+			promoCode.Code,
+			promoCode.Type,
+			strconv.FormatFloat(promoCode.Value, 'f', -1, 64),
+			strconv.Itoa(promoCode.MaxUses),
+			strconv.Itoa(promoCode.UsedCount),
+			validUntil,
+			strconv.FormatBool(promoCode.IsActive),
+			promoCode.CampaignID,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// validateBatch проверяет параметры пакета и заполняет ID кампании по умолчанию
+func (s *PromoCodeService) validateBatch(batch *PromoCodeBatch) error {
+	if batch.Count <= 0 || batch.Count > promoBatchMaxCount {
+		return fmt.Errorf("количество кодов должно быть от 1 до %d", promoBatchMaxCount)
+	}
+	if !promoPrefixPattern.MatchString(batch.Prefix) {
+		return fmt.Errorf("префикс может содержать только латинские буквы, цифры, '-' и '_'")
+	}
+	if len(batch.Prefix)+promoBatchCodeLength > promoCodeMaxLength {
+		return fmt.Errorf("префикс не может быть длиннее %d символов", promoCodeMaxLength-promoBatchCodeLength)
+	}
+	if batch.MaxUses < 0 {
+		return fmt.Errorf("количество использований не может быть отрицательным")
+	}
+	if batch.ValidUntil != nil && !batch.ValidUntil.After(time.Now()) {
+		return fmt.Errorf("дата окончания должна быть в будущем")
+	}
+
+	// Длина кода в пакете задается префиксом, поэтому проверяем только тип и значение
+	if err := validatePromoValue(batch.Type, batch.Value); err != nil {
+		return err
+	}
+
+	if batch.CampaignID == "" {
+		campaignID, err := defaultCampaignID(batch.Prefix, time.Now())
+		if err != nil {
+			return err
+		}
+		batch.CampaignID = campaignID
+	}
+	if !promoCampaignPattern.MatchString(batch.CampaignID) {
+		return fmt.Errorf("ID кампании: до 40 символов, латинские буквы, цифры, '-' и '_'")
+	}
+
+	// Новые коды не должны смешаться со статистикой существующей кампании
+	exists, err := s.repo.CampaignExists(batch.CampaignID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке кампании: %v", err)
+	}
+	if exists {
+		return ErrPromoCampaignExists
+	}
+	return nil
+}
+
+// uniqueCodes генерирует count кодов, не совпадающих между собой и с уже существующими
+func (s *PromoCodeService) uniqueCodes(prefix string, count int) ([]string, error) {
+	seen := make(map[string]struct{}, count)
+	codes := make([]string, 0, count)
+
+	for attempt := 0; attempt < promoBatchMaxAttempts; attempt++ {
+		fresh := make([]string, 0, count-len(codes))
+		for len(codes)+len(fresh) < count {
+			random, err := randomCode(promoBatchCodeLength)
+			if err != nil {
+				return nil, err
+			}
+			code := prefix + random
+			if _, ok := seen[code]; ok {
+				continue
+			}
+			seen[code] = struct{}{}
+			fresh = append(fresh, code)
+		}
+
+		existing, err := s.repo.GetExistingCodes(fresh)
+		if err != nil {
+			return nil, err
+		}
+		taken := make(map[string]struct{}, len(existing))
+		for _, code := range existing {
+			taken[code] = struct{}{}
+		}
+		for _, code := range fresh {
+			if _, ok := taken[code]; !ok {
+				codes = append(codes, code)
+			}
+		}
+
+		if len(codes) == count {
+			return codes, nil
+		}
+	}
+
+	return nil, fmt.Errorf("не удалось сгенерировать уникальные коды, попробуйте другой префикс")
+}
+
+// randomCode генерирует криптографически случайный код из promoCodeAlphabet
+func randomCode(length int) (string, error) {
+	limit := big.NewInt(int64(len(promoCodeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random code: %w", err)
+		}
+		code[i] = promoCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// defaultCampaignID формирует ID кампании из префикса, времени создания
+// и случайного суффикса, чтобы пакеты, созданные в одну секунду, не совпали
+func defaultCampaignID(prefix string, at time.Time) (string, error) {
+	name := strings.ToLower(strings.Trim(prefix, "-_"))
+	if name == "" {
+		name = "batch"
+	}
+	if len(name) > 19 {
+		name = name[:19]
+	}
+	suffix, err := randomCode(4)
+	if err != nil {
+		return "", err
+	}
+	return name + "-" + at.Format("20060102-150405") + "-" + strings.ToLower(suffix), nil
+}
@@ -1,8 +1,9 @@
 package services
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
+	"math"
 	"remnawave-tg-shop/internal/config"
 	"remnawave-tg-shop/internal/models"
 	"remnawave-tg-shop/internal/repositories"
@@ -12,15 +13,62 @@ import (
 	"github.com/google/uuid"
 )
 
+// Нарушения условий промокода. Пользователю показывается перевод из каталога,
+// см. PromoCodeErrorKey
+var (
+	ErrPromoCodeNotFound          = errors.New("промокод не найден")
+	ErrPromoCodePendingDiscount   = errors.New("у вас уже есть неиспользованная скидка по промокоду")
+	ErrPromoCodeUnavailable       = errors.New("промокод недействителен, истек или исчерпан")
+	ErrPromoCodeUserLimit         = errors.New("вы уже использовали этот промокод максимальное количество раз")
+	ErrPromoCodeNewUsersOnly      = errors.New("промокод доступен только новым пользователям")
+	ErrPromoCodeFirstPurchaseOnly = errors.New("промокод действует только на первую покупку")
+	ErrPromoCodeReferralConflict  = errors.New("промокод нельзя совмещать с реферальным бонусом")
+	ErrPromoCodePlanNotAllowed    = errors.New("промокод не действует на выбранный тариф")
+	ErrPromoCodeMinAmount         = errors.New("сумма покупки меньше минимальной для промокода")
+	ErrPromoCodeOwnGift           = errors.New("нельзя активировать собственный подарок")
+)
+
+// promoCodeErrorKeys ключи каталога с описанием нарушений условий промокода
+var promoCodeErrorKeys = []struct {
+	err error
+	key string
+}{
+	{ErrPromoCodeNotFound, "promo_code.errors.not_found"},
+	{ErrPromoCodeUnavailable, "promo_code.errors.unavailable"},
+	{ErrPromoCodeUserLimit, "promo_code.errors.user_limit"},
+	{ErrPromoCodeNewUsersOnly, "promo_code.errors.new_users_only"},
+	{ErrPromoCodeFirstPurchaseOnly, "promo_code.errors.first_purchase_only"},
+	{ErrPromoCodeReferralConflict, "promo_code.errors.referral_conflict"},
+	{ErrPromoCodePlanNotAllowed, "promo_code.errors.plan_not_allowed"},
+	{ErrPromoCodeMinAmount, "promo_code.errors.min_amount"},
+	{ErrPromoCodeOwnGift, "promo_code.errors.own_gift"},
+	{ErrPromoCodePendingDiscount, "promo_code.errors.pending_discount"},
+	{ErrGiftNotFound, "promo_code.errors.gift_not_found"},
+}
+
+// PromoCodeErrorKey возвращает ключ каталога с причиной отказа в промокоде или
+// подарке. Для прочих ошибок возвращает пустую строку: их текст не показывается
+// пользователю
+func PromoCodeErrorKey(err error) string {
+	for _, item := range promoCodeErrorKeys {
+		if errors.Is(err, item.err) {
+			return item.key
+		}
+	}
+	return ""
+}
+
 type PromoCodeService struct {
-	repo   repositories.PromoCodeRepository
-	config *config.Config
+	repo     repositories.PromoCodeRepository
+	userRepo repositories.UserRepository
+	config   *config.Config
 }
 
-func NewPromoCodeService(repo repositories.PromoCodeRepository, config *config.Config) *PromoCodeService {
+func NewPromoCodeService(repo repositories.PromoCodeRepository, userRepo repositories.UserRepository, config *config.Config) *PromoCodeService {
 	return &PromoCodeService{
-		repo:   repo,
-		config: config,
+		repo:     repo,
+		userRepo: userRepo,
+		config:   config,
 	}
 }
 
@@ -62,7 +110,10 @@ func (s *PromoCodeService) CreatePromoCode(code, promoType string, value float64
 
 // GeneratePromoCode генерирует случайный промокод
 func (s *PromoCodeService) GeneratePromoCode(promoType string, value float64, maxUses int, validFrom, validUntil *time.Time, description string, createdBy uuid.UUID) (*models.PromoCode, error) {
-	code := s.generateRandomCode()
+	code, err := s.generateRandomCode()
+	if err != nil {
+		return nil, err
+	}
 
 	// Проверяем уникальность
 	for {
@@ -70,48 +121,142 @@ func (s *PromoCodeService) GeneratePromoCode(promoType string, value float64, ma
 		if existing == nil {
 			break
 		}
-		code = s.generateRandomCode()
+		if code, err = s.generateRandomCode(); err != nil {
+			return nil, err
+		}
 	}
 
 	return s.CreatePromoCode(code, promoType, value, maxUses, validFrom, validUntil, description, createdBy)
 }
 
-// ApplyPromoCode применяет промокод к пользователю
-func (s *PromoCodeService) ApplyPromoCode(userID uuid.UUID, code string) (*models.PromoCode, error) {
+// ApplyPromoCode применяет промокод к пользователю. Бонусные дни начисляются сразу,
+// скидка сохраняется и применяется к следующей покупке
+func (s *PromoCodeService) ApplyPromoCode(userID uuid.UUID, code string) (*models.PromoCodeUsage, error) {
 	// Получаем промокод
-	promoCode, err := s.repo.GetByCode(code)
+	promoCode, err := s.repo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
 	if err != nil {
-		return nil, fmt.Errorf("промокод не найден")
+		return nil, ErrPromoCodeNotFound
 	}
 
 	// Проверяем валидность
 	if !promoCode.CanBeUsed() {
-		return nil, fmt.Errorf("промокод недействителен или истек")
+		return nil, ErrPromoCodeUnavailable
 	}
 
-	// Проверяем, не использовал ли уже пользователь этот промокод
-	usage, err := s.repo.GetUsageByUserAndPromoCode(userID, promoCode.ID)
-	if err == nil && usage != nil {
-		return nil, fmt.Errorf("вы уже использовали этот промокод")
+	if promoCode.IsGift() && promoCode.CreatedBy == userID {
+		return nil, ErrPromoCodeOwnGift
 	}
 
-	// Создаем запись об использовании
-	usage = &models.PromoCodeUsage{
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("ошибка при применении промокода: пользователь не найден")
+	}
+	if err := s.checkUserRules(promoCode, user, true); err != nil {
+		return nil, err
+	}
+
+	// Лимиты, счетчик, единственная ожидающая скидка, запись использования
+	// и бонус проверяются и меняются одной транзакцией
+	usage := &models.PromoCodeUsage{
 		PromoCodeID: promoCode.ID,
 		UserID:      userID,
 		UsedAt:      time.Now(),
 	}
 
-	if err := s.repo.CreateUsage(usage); err != nil {
-		return nil, fmt.Errorf("ошибка при применении промокода: %v", err)
+	if err := s.repo.Redeem(promoCode, usage); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrPromoCodeUnavailable):
+			return nil, ErrPromoCodeUnavailable
+		case errors.Is(err, repositories.ErrPromoCodeUserLimit):
+			return nil, ErrPromoCodeUserLimit
+		case errors.Is(err, repositories.ErrPromoCodePendingDiscount):
+			return nil, ErrPromoCodePendingDiscount
+		default:
+			return nil, fmt.Errorf("ошибка при применении промокода: %v", err)
+		}
 	}
 
-	// Увеличиваем счетчик использований
-	if err := s.repo.IncrementUsage(promoCode.ID); err != nil {
-		return nil, fmt.Errorf("ошибка при обновлении счетчика использований: %v", err)
+	usage.PromoCode = *promoCode
+	return usage, nil
+}
+
+// GetPendingDiscount возвращает скидку пользователя, ожидающую покупки, или nil
+func (s *PromoCodeService) GetPendingDiscount(userID uuid.UUID) (*models.PromoCodeUsage, error) {
+	return s.repo.GetPendingDiscount(userID)
+}
+
+// CheckoutDiscount рассчитывает скидку по ожидающему промокоду для покупки тарифа.
+// Если условия промокода не выполнены, возвращает использование, нулевую скидку
+// и причину; скидка при этом остается доступной для следующей покупки
+func (s *PromoCodeService) CheckoutDiscount(userID uuid.UUID, plan string, price float64) (*models.PromoCodeUsage, float64, error) {
+	usage, err := s.repo.GetPendingDiscount(userID)
+	if err != nil || usage == nil {
+		return nil, 0, err
 	}
+	promoCode := &usage.PromoCode
 
-	return promoCode, nil
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return usage, 0, fmt.Errorf("пользователь не найден")
+	}
+	if err := s.checkUserRules(promoCode, user, false); err != nil {
+		return usage, 0, err
+	}
+	if !promoCode.AllowsPlan(plan) {
+		return usage, 0, ErrPromoCodePlanNotAllowed
+	}
+	if promoCode.MinPurchaseAmount > 0 && price < promoCode.MinPurchaseAmount {
+		return usage, 0, fmt.Errorf("%w (от %.0f₽)", ErrPromoCodeMinAmount, promoCode.MinPurchaseAmount)
+	}
+
+	return usage, promoCode.Discount(price), nil
+}
+
+// ConsumeDiscount отмечает скидку примененной к покупке на указанную сумму
+func (s *PromoCodeService) ConsumeDiscount(usageID uuid.UUID, amount float64) (bool, error) {
+	return s.repo.ConsumeDiscount(usageID, amount)
+}
+
+// UpdateRules сохраняет условия применения промокода
+func (s *PromoCodeService) UpdateRules(promoCode *models.PromoCode) error {
+	if promoCode.MaxUsesPerUser < 0 {
+		return fmt.Errorf("лимит на пользователя не может быть отрицательным")
+	}
+	if promoCode.MinPurchaseAmount < 0 {
+		return fmt.Errorf("минимальная сумма покупки не может быть отрицательной")
+	}
+	return s.repo.Update(promoCode)
+}
+
+// checkUserRules проверяет условия промокода, зависящие от пользователя.
+// Условие для новых пользователей проверяется только при погашении
+func (s *PromoCodeService) checkUserRules(promoCode *models.PromoCode, user *models.User, redeeming bool) error {
+	if redeeming && promoCode.NewUsersOnly {
+		count, err := s.repo.CountUserSubscriptions(user.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке промокода: %v", err)
+		}
+		if count > 0 {
+			return ErrPromoCodeNewUsersOnly
+		}
+	}
+
+	if promoCode.FirstPurchaseOnly {
+		count, err := s.repo.CountUserPurchases(user.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке промокода: %v", err)
+		}
+		if count > 0 {
+			return ErrPromoCodeFirstPurchaseOnly
+		}
+	}
+
+	// Реферальный бонус приглашенного выплачивается за первую покупку
+	if promoCode.NotCombinableWithReferral && s.config.Settings().Referral.Enabled && user.ReferredBy != nil && user.ReferralRewardedAt == nil {
+		return ErrPromoCodeReferralConflict
+	}
+
+	return nil
 }
 
 // GetPromoCode получает промокод по коду
@@ -152,6 +297,11 @@ func (s *PromoCodeService) validatePromoCode(code, promoType string, value float
 			s.config.PromoCodes.MinCodeLength, s.config.PromoCodes.MaxCodeLength)
 	}
 
+	return validatePromoValue(promoType, value)
+}
+
+// validatePromoValue валидирует тип и значение промокода
+func validatePromoValue(promoType string, value float64) error {
 	// Проверяем тип промокода
 	validTypes := []string{"bonus_days", "discount_percent", "discount_amount"}
 	if !contains(validTypes, promoType) {
@@ -167,20 +317,19 @@ func (s *PromoCodeService) validatePromoCode(code, promoType string, value float
 		return fmt.Errorf("скидка в процентах не может быть больше 100")
 	}
 
+	if promoType == "bonus_days" && value != math.Trunc(value) {
+		return fmt.Errorf("количество бонусных дней должно быть целым")
+	}
+
 	return nil
 }
 
-// generateRandomCode генерирует случайный код
-func (s *PromoCodeService) generateRandomCode() string {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	length := s.config.PromoCodes.MinCodeLength + rand.Intn(s.config.PromoCodes.MaxCodeLength-s.config.PromoCodes.MinCodeLength+1)
-
-	code := make([]byte, length)
-	for i := range code {
-		code[i] = charset[rand.Intn(len(charset))]
-	}
-
-	return string(code)
+// generateRandomCode генерирует случайный код длиной не меньше
+// promoBatchCodeLength в допустимых настройками пределах
+func (s *PromoCodeService) generateRandomCode() (string, error) {
+	length := max(s.config.PromoCodes.MinCodeLength, promoBatchCodeLength)
+	length = min(length, s.config.PromoCodes.MaxCodeLength)
+	return randomCode(length)
 }
 
 // contains проверяет, содержится ли строка в слайсе
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPromoCodeRepository мок для PromoCodeRepository
+type MockPromoCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromoCodeRepository) Create(promoCode *models.PromoCode) error {
+	args := m.Called(promoCode)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetByCode(code string) (*models.PromoCode, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetByID(id uuid.UUID) (*models.PromoCode, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetAll(limit, offset int) ([]models.PromoCode, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) Update(promoCode *models.PromoCode) error {
+	args := m.Called(promoCode)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) IncrementUsage(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetValidPromoCodes() ([]models.PromoCode, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CreateUsage(usage *models.PromoCodeUsage) error {
+	args := m.Called(usage)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetUsageByUserAndPromoCode(userID, promoCodeID uuid.UUID) (*models.PromoCodeUsage, error) {
+	args := m.Called(userID, promoCodeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PromoCodeUsage), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetUsageCountByPromoCode(promoCodeID uuid.UUID) (int64, error) {
+	args := m.Called(promoCodeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) Redeem(promoCode *models.PromoCode, usage *models.PromoCodeUsage) error {
+	args := m.Called(promoCode, usage)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetPendingDiscount(userID uuid.UUID) (*models.PromoCodeUsage, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PromoCodeUsage), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) ConsumeDiscount(usageID uuid.UUID, amount float64) (bool, error) {
+	args := m.Called(usageID, amount)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CountUserSubscriptions(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CountUserPurchases(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CreateBatch(promoCodes []models.PromoCode) error {
+	args := m.Called(promoCodes)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) CreateGift(promoCode *models.PromoCode, price float64) error {
+	args := m.Called(promoCode, price)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetGiftsByBuyer(buyerID uuid.UUID, limit, offset int) ([]models.PromoCode, error) {
+	args := m.Called(buyerID, limit, offset)
+	return args.Get(0).([]models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetExistingCodes(codes []string) ([]string, error) {
+	args := m.Called(codes)
+	if fn, ok := args.Get(0).(func([]string) []string); ok {
+		return fn(codes), args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CampaignExists(campaignID string) (bool, error) {
+	args := m.Called(campaignID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetByCampaign(campaignID string) ([]models.PromoCode, error) {
+	args := m.Called(campaignID)
+	return args.Get(0).([]models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetCampaignStats(limit, offset int) ([]models.PromoCampaignStats, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.PromoCampaignStats), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) GetCampaignStatsByID(campaignID string) (*models.PromoCampaignStats, error) {
+	args := m.Called(campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PromoCampaignStats), args.Error(1)
+}
+
+func TestPromoCode_Discount(t *testing.T) {
+	percent := &models.PromoCode{Type: "discount_percent", Value: 10}
+	amount := &models.PromoCode{Type: "discount_amount", Value: 500}
+	days := &models.PromoCode{Type: "bonus_days", Value: 7}
+
+	assert.Equal(t, 29.9, percent.Discount(299))
+	assert.Equal(t, 299.0, amount.Discount(299))
+	assert.Equal(t, 0.0, days.Discount(299))
+}
+
+func newTestPromoCodeService(repo *MockPromoCodeRepository, user *models.User) *PromoCodeService {
+	cfg := &config.Config{}
+	cfg.Referral = config.ReferralConfig{Enabled: true}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	return NewPromoCodeService(repo, userRepo, cfg)
+}
+
+func TestPromoCodeService_ApplyPromoCode_Discount(t *testing.T) {
+	user := &models.User{ID: uuid.New()}
+	promoCode := &models.PromoCode{ID: uuid.New(), Code: "SALE10", Type: "discount_percent", Value: 10, IsActive: true}
+
+	repo := new(MockPromoCodeRepository)
+	repo.On("GetByCode", "SALE10").Return(promoCode, nil)
+	repo.On("Redeem", promoCode, mock.AnythingOfType("*models.PromoCodeUsage")).Return(nil)
+
+	service := newTestPromoCodeService(repo, user)
+	usage, err := service.ApplyPromoCode(user.ID, " sale10 ")
+
+	require.NoError(t, err)
+	assert.True(t, usage.IsPendingDiscount())
+	assert.Equal(t, "SALE10", usage.PromoCode.Code)
+	repo.AssertExpectations(t)
+}
+
+func TestPromoCodeService_ApplyPromoCode_PendingDiscountExists(t *testing.T) {
+	user := &models.User{ID: uuid.New()}
+	promoCode := &models.PromoCode{ID: uuid.New(), Code: "SALE10", Type: "discount_percent", Value: 10, IsActive: true}
+
+	// Проверка выполняется в транзакции погашения
+	repo := new(MockPromoCodeRepository)
+	repo.On("GetByCode", "SALE10").Return(promoCode, nil)
+	repo.On("Redeem", promoCode, mock.Anything).Return(repositories.ErrPromoCodePendingDiscount)
+
+	service := newTestPromoCodeService(repo, user)
+	_, err := service.ApplyPromoCode(user.ID, "SALE10")
+
+	assert.ErrorIs(t, err, ErrPromoCodePendingDiscount)
+	repo.AssertNotCalled(t, "GetPendingDiscount", mock.Anything)
+}
+
+func TestPromoCodeService_ApplyPromoCode_Rules(t *testing.T) {
+	referrerID := uuid.New()
+
+	t.Run("per-user limit", func(t *testing.T) {
+		user := &models.User{ID: uuid.New()}
+		promoCode := &models.PromoCode{ID: uuid.New(), Code: "DAYS7", Type: "bonus_days", Value: 7, IsActive: true, MaxUsesPerUser: 1}
+
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "DAYS7").Return(promoCode, nil)
+		repo.On("Redeem", promoCode, mock.Anything).Return(repositories.ErrPromoCodeUserLimit)
+
+		_, err := newTestPromoCodeService(repo, user).ApplyPromoCode(user.ID, "DAYS7")
+		assert.ErrorIs(t, err, ErrPromoCodeUserLimit)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		user := &models.User{ID: uuid.New()}
+		promoCode := &models.PromoCode{ID: uuid.New(), Code: "DAYS7", Type: "bonus_days", Value: 7, IsActive: true}
+
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "DAYS7").Return(promoCode, nil)
+		repo.On("Redeem", promoCode, mock.Anything).Return(repositories.ErrPromoCodeUnavailable)
+
+		_, err := newTestPromoCodeService(repo, user).ApplyPromoCode(user.ID, "DAYS7")
+		assert.ErrorIs(t, err, ErrPromoCodeUnavailable)
+	})
+
+	t.Run("new users only", func(t *testing.T) {
+		user := &models.User{ID: uuid.New()}
+		promoCode := &models.PromoCode{ID: uuid.New(), Code: "WELCOME", Type: "bonus_days", Value: 3, IsActive: true, NewUsersOnly: true}
+
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "WELCOME").Return(promoCode, nil)
+		repo.On("CountUserSubscriptions", user.ID).Return(int64(1), nil)
+
+		_, err := newTestPromoCodeService(repo, user).ApplyPromoCode(user.ID, "WELCOME")
+		assert.ErrorIs(t, err, ErrPromoCodeNewUsersOnly)
+	})
+
+	t.Run("not combinable with referral", func(t *testing.T) {
+		user := &models.User{ID: uuid.New(), ReferredBy: &referrerID}
+		promoCode := &models.PromoCode{ID: uuid.New(), Code: "SALE10", Type: "discount_percent", Value: 10, IsActive: true, NotCombinableWithReferral: true}
+
+		repo := new(MockPromoCodeRepository)
+		repo.On("GetByCode", "SALE10").Return(promoCode, nil)
+
+		_, err := newTestPromoCodeService(repo, user).ApplyPromoCode(user.ID, "SALE10")
+		assert.ErrorIs(t, err, ErrPromoCodeReferralConflict)
+	})
+}
+
+func TestPromoCodeService_CheckoutDiscount(t *testing.T) {
+	user := &models.User{ID: uuid.New()}
+	usage := &models.PromoCodeUsage{
+		ID: uuid.New(),
+		PromoCode: models.PromoCode{
+			Code:              "SALE10",
+			Type:              "discount_percent",
+			Value:             10,
+			AllowedPlans:      "premium, pro",
+			MinPurchaseAmount: 500,
+			FirstPurchaseOnly: true,
+		},
+	}
+
+	repo := new(MockPromoCodeRepository)
+	repo.On("GetPendingDiscount", user.ID).Return(usage, nil)
+	repo.On("CountUserPurchases", user.ID).Return(int64(0), nil)
+	service := newTestPromoCodeService(repo, user)
+
+	_, discount, err := service.CheckoutDiscount(user.ID, "basic", 299)
+	assert.ErrorIs(t, err, ErrPromoCodePlanNotAllowed)
+	assert.Zero(t, discount)
+
+	_, discount, err = service.CheckoutDiscount(user.ID, "premium", 799)
+	require.NoError(t, err)
+	assert.Equal(t, 79.9, discount)
+
+	usage.PromoCode.MinPurchaseAmount = 1000
+	_, _, err = service.CheckoutDiscount(user.ID, "premium", 799)
+	assert.ErrorIs(t, err, ErrPromoCodeMinAmount)
+}
+
+func TestPromoCodeService_GenerateBatch(t *testing.T) {
+	validUntil := time.Now().AddDate(0, 2, 0)
+	var taken string
+
+	repo := new(MockPromoCodeRepository)
+	// Первый сгенерированный код уже занят и должен быть заменен
+	repo.On("GetExistingCodes", mock.Anything).Return(func(codes []string) []string {
+		if taken == "" {
+			taken = codes[0]
+			return []string{taken}
+		}
+		return []string{}
+	}, nil)
+	repo.On("CampaignExists", mock.Anything).Return(false, nil)
+	repo.On("CreateBatch", mock.Anything).Return(nil)
+
+	service := NewPromoCodeService(repo, new(MockUserRepository), &config.Config{})
+	promoCodes, err := service.GenerateBatch(PromoCodeBatch{
+		Prefix:     "blogger-",
+		Count:      50,
+		Type:       "bonus_days",
+		Value:      30,
+		MaxUses:    1,
+		ValidUntil: &validUntil,
+	})
+	require.NoError(t, err)
+	require.Len(t, promoCodes, 50)
+
+	unique := make(map[string]struct{})
+	for _, promoCode := range promoCodes {
+		assert.Regexp(t, `^BLOGGER-[ABCDEFGHJKMNPQRSTUVWXYZ23456789]{8}$`, promoCode.Code)
+		assert.NotEqual(t, taken, promoCode.Code)
+		assert.Equal(t, promoCodes[0].CampaignID, promoCode.CampaignID)
+		unique[promoCode.Code] = struct{}{}
+	}
+	assert.Len(t, unique, 50)
+	assert.Regexp(t, `^blogger-\d{8}-\d{6}-[a-z0-9]{4}$`, promoCodes[0].CampaignID)
+	repo.AssertNumberOfCalls(t, "GetExistingCodes", 2)
+}
+
+func TestPromoCodeService_GenerateBatch_CampaignExists(t *testing.T) {
+	repo := new(MockPromoCodeRepository)
+	repo.On("CampaignExists", "blogger").Return(true, nil)
+
+	service := NewPromoCodeService(repo, new(MockUserRepository), &config.Config{})
+	_, err := service.GenerateBatch(PromoCodeBatch{CampaignID: "blogger", Count: 10, Type: "bonus_days", Value: 30})
+
+	assert.ErrorIs(t, err, ErrPromoCampaignExists)
+	repo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+func TestPromoCodeService_GenerateBatch_Validation(t *testing.T) {
+	service := NewPromoCodeService(new(MockPromoCodeRepository), new(MockUserRepository), &config.Config{})
+	past := time.Now().AddDate(0, 0, -1)
+
+	cases := []PromoCodeBatch{
+		{Count: 0, Type: "bonus_days", Value: 30},
+		{Count: promoBatchMaxCount + 1, Type: "bonus_days", Value: 30},
+		{Count: 10, Prefix: "БЛОГЕР", Type: "bonus_days", Value: 30},
+		{Count: 10, Type: "unknown", Value: 30},
+		{Count: 10, Type: "bonus_days", Value: 30, ValidUntil: &past},
+		{Count: 10, Type: "bonus_days", Value: 30, CampaignID: "bad campaign"},
+	}
+	for _, batch := range cases {
+		_, err := service.GenerateBatch(batch)
+		assert.Error(t, err, "%+v", batch)
+	}
+}
+
+func TestPromoCodeService_ExportCampaignCSV(t *testing.T) {
+	validUntil := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	repo := new(MockPromoCodeRepository)
+	repo.On("GetByCampaign", "blogger").Return([]models.PromoCode{
+		{Code: "BLOGGER-AAAA2222", Type: "bonus_days", Value: 30, MaxUses: 1, UsedCount: 1, IsActive: true, ValidUntil: &validUntil, CampaignID: "blogger"},
+	}, nil)
+	repo.On("GetByCampaign", "missing").Return([]models.PromoCode{}, nil)
+
+	service := NewPromoCodeService(repo, new(MockUserRepository), &config.Config{})
+
+	data, err := service.ExportCampaignCSV("blogger")
+	require.NoError(t, err)
+	assert.Equal(t, "code,type,value,max_uses,used_count,valid_until,is_active,campaign_id\n"+
+		"BLOGGER-AAAA2222,bonus_days,30,1,1,2026-12-31,true,blogger\n", string(data))
+
+	_, err = service.ExportCampaignCSV("missing")
+	assert.ErrorIs(t, err, ErrPromoCampaignNotFound)
+}
+
+func TestPromoCodeErrorKey(t *testing.T) {
+	assert.Equal(t, "promo_code.errors.unavailable", PromoCodeErrorKey(ErrPromoCodeUnavailable))
+	assert.Equal(t, "promo_code.errors.min_amount", PromoCodeErrorKey(fmt.Errorf("%w (от 500₽)", ErrPromoCodeMinAmount)))
+	assert.Equal(t, "promo_code.errors.gift_not_found", PromoCodeErrorKey(ErrGiftNotFound))
+	// Внутренние ошибки не переводятся и не показываются пользователю
+	assert.Empty(t, PromoCodeErrorKey(errors.New("connection refused")))
+}
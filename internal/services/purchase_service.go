@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPurchaseUnknownTariff тариф покупки не найден
+	ErrPurchaseUnknownTariff = errors.New("тариф не найден")
+	// ErrPurchaseInsufficientBalance на балансе недостаточно средств для покупки
+	ErrPurchaseInsufficientBalance = errors.New("недостаточно средств на балансе")
+	// ErrPurchaseDiscountUsed скидка уже применена к другой покупке
+	ErrPurchaseDiscountUsed = errors.New("скидка по промокоду уже использована")
+	// ErrPurchaseCharge не удалось списать средства и создать подписку
+	ErrPurchaseCharge = errors.New("ошибка при списании средств")
+)
+
+// PurchaseQuote расчет стоимости тарифа для пользователя с учетом ожидающей скидки
+type PurchaseQuote struct {
+	Tariff   models.Tariff
+	Discount float64
+	Total    float64
+	// DiscountUsage ожидающая скидка по промокоду, если она есть
+	DiscountUsage *models.PromoCodeUsage
+	// DiscountErr причина, по которой скидка не применена к этому тарифу
+	DiscountErr error
+}
+
+// PurchaseService продает подписки с баланса пользователя
+type PurchaseService struct {
+	subscriptionRepo repositories.SubscriptionRepository
+	promoCodeService IPromoCodeService
+	referralService  IReferralService
+	logger           logger.Logger
+}
+
+// NewPurchaseService создает новый PurchaseService
+func NewPurchaseService(
+	subscriptionRepo repositories.SubscriptionRepository,
+	promoCodeService IPromoCodeService,
+	referralService IReferralService,
+	log logger.Logger,
+) *PurchaseService {
+	return &PurchaseService{
+		subscriptionRepo: subscriptionRepo,
+		promoCodeService: promoCodeService,
+		referralService:  referralService,
+		logger:           log,
+	}
+}
+
+// Quote рассчитывает стоимость тарифа. Если условия промокода не выполнены,
+// скидка не применяется и остается до следующей покупки
+func (s *PurchaseService) Quote(userID uuid.UUID, tariffKey string) (*PurchaseQuote, error) {
+	tariff, ok := models.GetTariff(tariffKey)
+	if !ok {
+		return nil, ErrPurchaseUnknownTariff
+	}
+
+	quote := &PurchaseQuote{Tariff: *tariff, Total: tariff.Price}
+	usage, discount, err := s.promoCodeService.CheckoutDiscount(userID, tariff.Key, tariff.Price)
+	switch {
+	case err != nil && usage == nil:
+		s.logger.Error("Failed to get pending discount", "error", err, "user_id", userID)
+	case err != nil:
+		quote.DiscountUsage = usage
+		quote.DiscountErr = err
+	case usage != nil:
+		quote.DiscountUsage = usage
+		quote.Discount = discount
+		quote.Total = tariff.Price - discount
+	}
+	return quote, nil
+}
+
+// Purchase оплачивает тариф по расчету с баланса пользователя и возвращает
+// баланс после списания. Списание, погашение скидки и создание подписки
+// выполняются одной транзакцией, поэтому параллельные покупки не уводят
+// баланс в минус и не применяют одну скидку дважды
+func (s *PurchaseService) Purchase(user *models.User, quote *PurchaseQuote) (float64, error) {
+	// Срок тарифа переводится в месяцы
+	durationMonths := quote.Tariff.DurationDays / 30
+	if durationMonths < 1 {
+		durationMonths = 1
+	}
+
+	now := time.Now()
+	subscription := &models.Subscription{
+		UserID:     user.ID,
+		ServerID:   1, // По умолчанию сервер 1
+		ServerName: "Default Server",
+		PlanID:     1, // По умолчанию план 1
+		PlanName:   quote.Tariff.Name,
+		Status:     "active",
+		Source:     models.SubscriptionSourcePurchase,
+		ExpiresAt:  now.AddDate(0, durationMonths, 0),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	var usageID *uuid.UUID
+	if quote.Discount > 0 {
+		usageID = &quote.DiscountUsage.ID
+	}
+
+	balance, err := s.subscriptionRepo.Purchase(subscription, quote.Total, usageID, quote.Discount)
+	switch {
+	case errors.Is(err, repositories.ErrInsufficientBalance):
+		return 0, ErrPurchaseInsufficientBalance
+	case errors.Is(err, repositories.ErrDiscountConsumed):
+		return 0, ErrPurchaseDiscountUsed
+	case err != nil:
+		s.logger.Error("Failed to purchase subscription", "error", err, "user_id", user.ID, "plan", quote.Tariff.Key, "total", quote.Total)
+		return 0, fmt.Errorf("%w: %v", ErrPurchaseCharge, err)
+	}
+
+	// Первая оплаченная покупка приглашенного пользователя приносит реферальные награды
+	if err := s.referralService.RewardFirstPurchase(user.ID); err != nil {
+		s.logger.Error("Failed to pay referral reward", "error", err, "user_id", user.ID)
+	}
+
+	s.logger.Info("Subscription purchased", "user_id", user.ID, "plan", quote.Tariff.Key, "total", quote.Total, "balance", balance)
+	return balance, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReferralService мок для IReferralService
+type MockReferralService struct {
+	IReferralService
+	mock.Mock
+}
+
+func (m *MockReferralService) RewardFirstPurchase(userID uuid.UUID) error {
+	return m.Called(userID).Error(0)
+}
+
+func newTestPurchaseService() (*PurchaseService, *MockSubscriptionRepository, *MockReferralService) {
+	repo := new(MockSubscriptionRepository)
+	referrals := new(MockReferralService)
+	return NewPurchaseService(repo, nil, referrals, logger.New("error")), repo, referrals
+}
+
+func TestPurchaseService_Purchase(t *testing.T) {
+	service, repo, referrals := newTestPurchaseService()
+	user := &models.User{ID: uuid.New(), Balance: 500}
+	usage := &models.PromoCodeUsage{ID: uuid.New()}
+	quote := &PurchaseQuote{
+		Tariff:        models.Tariff{Key: "quarter", Name: "Квартал", DurationDays: 90, Price: 400},
+		Discount:      100,
+		Total:         300,
+		DiscountUsage: usage,
+	}
+
+	repo.On("Purchase", mock.MatchedBy(func(subscription *models.Subscription) bool {
+		return subscription.UserID == user.ID && subscription.PlanName == "Квартал" && subscription.Status == "active"
+	}), 300.0, &usage.ID, 100.0).Return(250.0, nil).Once()
+	referrals.On("RewardFirstPurchase", user.ID).Return(nil).Once()
+
+	balance, err := service.Purchase(user, quote)
+	require.NoError(t, err)
+	assert.Equal(t, 250.0, balance, "баланс берется из базы, а не из устаревшего профиля")
+	repo.AssertExpectations(t)
+	referrals.AssertExpectations(t)
+}
+
+func TestPurchaseService_PurchaseWithoutDiscount(t *testing.T) {
+	service, repo, referrals := newTestPurchaseService()
+	user := &models.User{ID: uuid.New(), Balance: 500}
+	quote := &PurchaseQuote{Tariff: models.Tariff{Key: "month", Name: "Месяц", DurationDays: 30, Price: 150}, Total: 150}
+
+	repo.On("Purchase", mock.Anything, 150.0, (*uuid.UUID)(nil), 0.0).Return(350.0, nil).Once()
+	referrals.On("RewardFirstPurchase", user.ID).Return(nil).Once()
+
+	_, err := service.Purchase(user, quote)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestPurchaseService_PurchaseErrors(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Balance: 500}
+	quote := &PurchaseQuote{
+		Tariff:        models.Tariff{Key: "month", Name: "Месяц", DurationDays: 30, Price: 150},
+		Discount:      50,
+		Total:         100,
+		DiscountUsage: &models.PromoCodeUsage{ID: uuid.New()},
+	}
+
+	cases := []struct {
+		repoErr error
+		want    error
+	}{
+		// Параллельная покупка уже списала средства, хотя профиль показывал достаточный баланс
+		{repositories.ErrInsufficientBalance, ErrPurchaseInsufficientBalance},
+		// Скидка применена к другой покупке: транзакция откатывается без списания
+		{repositories.ErrDiscountConsumed, ErrPurchaseDiscountUsed},
+		{errors.New("connection reset"), ErrPurchaseCharge},
+	}
+	for _, tc := range cases {
+		service, repo, referrals := newTestPurchaseService()
+		repo.On("Purchase", mock.Anything, 100.0, mock.Anything, 50.0).Return(0.0, tc.repoErr).Once()
+
+		_, err := service.Purchase(user, quote)
+		assert.ErrorIs(t, err, tc.want)
+		referrals.AssertNotCalled(t, "RewardFirstPurchase", mock.Anything)
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/i18n"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// ReferralStartPrefix префикс параметра /start в реферальной ссылке
+const ReferralStartPrefix = "ref_"
+
+// maxReferralDepth глубина обхода цепочки пригласивших при проверке на цикл
+const maxReferralDepth = 32
+
+var (
+	// ErrReferralNotNewUser реферальная ссылка открыта уже зарегистрированным пользователем
+	ErrReferralNotNewUser = errors.New("referral attribution is only available for new users")
+	// ErrReferrerNotFound реферальный код не найден
+	ErrReferrerNotFound = errors.New("referrer not found")
+	// ErrSelfReferral пользователь открыл собственную реферальную ссылку
+	ErrSelfReferral = errors.New("self-referral is not allowed")
+	// ErrReferralCycle привязка образует цикл в цепочке приглашений
+	ErrReferralCycle = errors.New("referral cycle detected")
+)
+
+type ReferralService struct {
+	userRepo            repositories.UserRepository
+	userService         UserService
+	notificationService INotificationService
+	activityLogService  IActivityLogService
+	config              *config.Config
+	logger              logger.Logger
+	botUsername         string
+}
+
+func NewReferralService(
+	userRepo repositories.UserRepository,
+	userService UserService,
+	notificationService INotificationService,
+	activityLogService IActivityLogService,
+	config *config.Config,
+	log logger.Logger,
+	botUsername string,
+) *ReferralService {
+	return &ReferralService{
+		userRepo:            userRepo,
+		userService:         userService,
+		notificationService: notificationService,
+		activityLogService:  activityLogService,
+		config:              config,
+		logger:              log,
+		botUsername:         botUsername,
+	}
+}
+
+// AttachReferrer привязывает нового пользователя к пригласившему по параметру
+// /start вида ref_<код>. Возвращает nil без ошибки, если параметр не реферальный
+func (s *ReferralService) AttachReferrer(user *models.User, startParam string) (*models.User, error) {
+	code, ok := strings.CutPrefix(startParam, ReferralStartPrefix)
+	if !ok || code == "" || !s.config.Settings().Referral.Enabled {
+		return nil, nil
+	}
+
+	// Привязываем только пользователей, зарегистрированных этим же /start
+	if !user.IsNew || user.ReferredBy != nil {
+		return nil, ErrReferralNotNewUser
+	}
+
+	referrer, err := s.userRepo.GetByReferralCode(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referrer: %w", err)
+	}
+	if referrer == nil {
+		return nil, ErrReferrerNotFound
+	}
+	if referrer.ID == user.ID || referrer.TelegramID == user.TelegramID {
+		return nil, ErrSelfReferral
+	}
+
+	// Проверяем, что пользователь не окажется пригласившим самого себя через цепочку
+	current := referrer
+	for depth := 0; current.ReferredBy != nil && depth < maxReferralDepth; depth++ {
+		if *current.ReferredBy == user.ID {
+			return nil, ErrReferralCycle
+		}
+		current, err = s.userRepo.GetByID(*current.ReferredBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get referral chain: %w", err)
+		}
+		if current == nil {
+			break
+		}
+	}
+
+	user.ReferredBy = &referrer.ID
+	if err := s.userService.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to attach referrer: %w", err)
+	}
+
+	s.activityLogService.LogReferral(referrer.ID, user.ID, "attached", "", "")
+	s.logger.Info("Referral attached", "user_id", user.ID, "referrer_id", referrer.ID)
+	return referrer, nil
+}
+
+// RewardFirstPurchase начисляет награды обеим сторонам после первой оплаченной
+// покупки приглашенного пользователя. Повторные вызовы ничего не делают
+func (s *ReferralService) RewardFirstPurchase(userID uuid.UUID) error {
+	// Все параметры награды берем из одного снимка настроек
+	settings := s.config.Settings().Referral
+	if !settings.Enabled {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.ReferredBy == nil || user.ReferralRewardedAt != nil {
+		return nil
+	}
+
+	referrer, err := s.userRepo.GetByID(*user.ReferredBy)
+	if err != nil {
+		return fmt.Errorf("failed to get referrer: %w", err)
+	}
+	if referrer == nil {
+		s.logger.Warn("Referrer not found for reward", "user_id", userID)
+		return nil
+	}
+
+	var reward repositories.ReferralReward
+	var referrerNotice, referredNotice *rewardNotice
+	if settings.RewardType == "days" {
+		reward, referrerNotice, referredNotice = rewardDays(settings)
+	} else {
+		reward, referrerNotice, referredNotice = rewardBalance(settings)
+	}
+	reward.UserID = user.ID
+	reward.ReferrerID = referrer.ID
+
+	// Отметка и начисление выполняются одной транзакцией, поэтому награда
+	// выплачивается ровно один раз и не теряется при ошибке начисления
+	rewarded, err := s.userRepo.RewardReferral(reward)
+	if err != nil {
+		return fmt.Errorf("failed to pay referral reward: %w", err)
+	}
+	if !rewarded {
+		return nil
+	}
+
+	s.activityLogService.LogReferral(referrer.ID, user.ID, "rewarded", "", "")
+	s.logger.Info("Referral reward paid", "user_id", user.ID, "referrer_id", referrer.ID, "reward_type", settings.RewardType)
+
+	if referrerNotice != nil {
+		if err := s.notificationService.SendToUser(referrer.ID, "referral_bonus", referrerNotice.key, referrerNotice.args...); err != nil {
+			s.logger.Error("Failed to notify referrer", "error", err, "user_id", referrer.ID)
+		}
+	}
+	if referredNotice != nil {
+		if err := s.notificationService.SendToUser(user.ID, "referral_bonus", referredNotice.key, referredNotice.args...); err != nil {
+			s.logger.Error("Failed to notify referred user", "error", err, "user_id", user.ID)
+		}
+	}
+
+	return nil
+}
+
+// ReferralLink возвращает реферальную ссылку пользователя
+func (s *ReferralService) ReferralLink(user *models.User) string {
+	if s.botUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", s.botUsername, ReferralStartPrefix, user.ReferralCode)
+}
+
+// rewardNotice уведомление о начисленной награде: ключ каталога и подстановки
+type rewardNotice struct {
+	key  string
+	args []any
+}
+
+// rewardBalance рассчитывает награды на баланс
+func rewardBalance(settings config.ReferralConfig) (repositories.ReferralReward, *rewardNotice, *rewardNotice) {
+	var reward repositories.ReferralReward
+	var referrerNotice, referredNotice *rewardNotice
+
+	if bonus := float64(settings.ReferrerBonus); bonus > 0 {
+		reward.ReferrerBalance = bonus
+		reward.Earned = bonus
+		referrerNotice = &rewardNotice{key: "notifications.referrer_balance", args: []any{"amount", bonus}}
+	}
+	if bonus := float64(settings.ReferredBonus); bonus > 0 {
+		reward.ReferredBalance = bonus
+		referredNotice = &rewardNotice{key: "notifications.referred_balance", args: []any{"amount", bonus}}
+	}
+	return reward, referrerNotice, referredNotice
+}
+
+// rewardDays рассчитывает бонусные дни подписки обеим сторонам. Заработок
+// пригласившего учитывается по стоимости этих дней
+func rewardDays(settings config.ReferralConfig) (repositories.ReferralReward, *rewardNotice, *rewardNotice) {
+	days := settings.BonusDays
+	if days <= 0 {
+		return repositories.ReferralReward{}, nil, nil
+	}
+
+	reward := repositories.ReferralReward{BonusDays: days, Earned: bonusDaysValue(days)}
+	duration := i18n.Plural{Key: "units.days", Count: days}
+	referrerNotice := &rewardNotice{key: "notifications.referrer_days", args: []any{"duration", duration}}
+	referredNotice := &rewardNotice{key: "notifications.referred_days", args: []any{"duration", duration}}
+	return reward, referrerNotice, referredNotice
+}
+
+// bonusDaysValue оценивает бонусные дни в рублях по цене дня самого короткого тарифа
+func bonusDaysValue(days int) float64 {
+	var shortest *models.Tariff
+	for _, tariff := range models.Tariffs() {
+		if tariff.DurationDays > 0 && (shortest == nil || tariff.DurationDays < shortest.DurationDays) {
+			shortest = &tariff
+		}
+	}
+	if shortest == nil {
+		return 0
+	}
+	return math.Round(shortest.Price/float64(shortest.DurationDays)*float64(days)*100) / 100
+}
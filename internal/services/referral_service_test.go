@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNotificationService мок для INotificationService
+type MockNotificationService struct {
+	mock.Mock
+	INotificationService
+}
+
+func (m *MockNotificationService) SendToUser(userID uuid.UUID, notificationType, key string, params ...any) error {
+	args := m.Called(userID, notificationType, key, params)
+	return args.Error(0)
+}
+
+// MockActivityLogService мок для IActivityLogService
+type MockActivityLogService struct {
+	mock.Mock
+	IActivityLogService
+}
+
+func (m *MockActivityLogService) LogReferral(userID uuid.UUID, referredUserID uuid.UUID, action string, ipAddress, userAgent string) error {
+	args := m.Called(userID, referredUserID, action)
+	return args.Error(0)
+}
+
+func newTestReferralService(repo *MockUserRepository, notifications *MockNotificationService) *ReferralService {
+	cfg := &config.Config{}
+	cfg.Referral = config.ReferralConfig{Enabled: true, RewardType: "balance", ReferrerBonus: 50, ReferredBonus: 30}
+
+	log := logger.New("error")
+	activity := new(MockActivityLogService)
+	activity.On("LogReferral", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	userService := NewUserService(repo, nil, log, cfg)
+	return NewReferralService(repo, userService, notifications, activity, cfg, log, "shop_bot")
+}
+
+func TestReferralService_AttachReferrer(t *testing.T) {
+	referrer := &models.User{ID: uuid.New(), TelegramID: 1, ReferralCode: "abc12345"}
+
+	t.Run("new user", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetByReferralCode", "abc12345").Return(referrer, nil)
+		repo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+		service := newTestReferralService(repo, nil)
+
+		user := &models.User{ID: uuid.New(), TelegramID: 2, IsNew: true}
+		attached, err := service.AttachReferrer(user, "ref_abc12345")
+
+		require.NoError(t, err)
+		assert.Equal(t, referrer.ID, attached.ID)
+		require.NotNil(t, user.ReferredBy)
+		assert.Equal(t, referrer.ID, *user.ReferredBy)
+	})
+
+	t.Run("existing user", func(t *testing.T) {
+		service := newTestReferralService(new(MockUserRepository), nil)
+
+		user := &models.User{ID: uuid.New(), TelegramID: 2}
+		_, err := service.AttachReferrer(user, "ref_abc12345")
+
+		assert.ErrorIs(t, err, ErrReferralNotNewUser)
+		assert.Nil(t, user.ReferredBy)
+	})
+
+	t.Run("self referral", func(t *testing.T) {
+		repo := new(MockUserRepository)
+		repo.On("GetByReferralCode", "abc12345").Return(referrer, nil)
+		service := newTestReferralService(repo, nil)
+
+		user := &models.User{ID: referrer.ID, TelegramID: referrer.TelegramID, IsNew: true}
+		_, err := service.AttachReferrer(user, "ref_abc12345")
+
+		assert.ErrorIs(t, err, ErrSelfReferral)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		user := &models.User{ID: uuid.New(), TelegramID: 2, IsNew: true}
+		cyclic := &models.User{ID: uuid.New(), TelegramID: 3, ReferralCode: "cyc12345", ReferredBy: &user.ID}
+
+		repo := new(MockUserRepository)
+		repo.On("GetByReferralCode", "cyc12345").Return(cyclic, nil)
+		service := newTestReferralService(repo, nil)
+
+		_, err := service.AttachReferrer(user, "ref_cyc12345")
+
+		assert.ErrorIs(t, err, ErrReferralCycle)
+	})
+
+	t.Run("not a referral link", func(t *testing.T) {
+		service := newTestReferralService(new(MockUserRepository), nil)
+
+		attached, err := service.AttachReferrer(&models.User{IsNew: true}, "gift_xyz")
+
+		assert.NoError(t, err)
+		assert.Nil(t, attached)
+	})
+}
+
+func TestReferralService_RewardFirstPurchase(t *testing.T) {
+	referrer := &models.User{ID: uuid.New(), TelegramID: 1}
+	referred := &models.User{ID: uuid.New(), TelegramID: 2, ReferredBy: &referrer.ID}
+	reward := repositories.ReferralReward{
+		UserID:          referred.ID,
+		ReferrerID:      referrer.ID,
+		ReferrerBalance: 50,
+		ReferredBalance: 30,
+		Earned:          50,
+	}
+
+	repo := new(MockUserRepository)
+	repo.On("GetByID", referred.ID).Return(referred, nil)
+	repo.On("GetByID", referrer.ID).Return(referrer, nil)
+	repo.On("RewardReferral", reward).Return(true, nil).Once()
+	repo.On("RewardReferral", reward).Return(false, nil).Once()
+
+	notifications := new(MockNotificationService)
+	notifications.On("SendToUser", referrer.ID, "referral_bonus", mock.Anything, mock.Anything).Return(nil).Once()
+	notifications.On("SendToUser", referred.ID, "referral_bonus", mock.Anything, mock.Anything).Return(nil).Once()
+
+	service := newTestReferralService(repo, notifications)
+
+	require.NoError(t, service.RewardFirstPurchase(referred.ID))
+	// Повторная покупка не приносит наград
+	require.NoError(t, service.RewardFirstPurchase(referred.ID))
+
+	repo.AssertExpectations(t)
+	notifications.AssertExpectations(t)
+}
+
+func TestReferralService_RewardFirstPurchaseDays(t *testing.T) {
+	referrer := &models.User{ID: uuid.New(), TelegramID: 1}
+	referred := &models.User{ID: uuid.New(), TelegramID: 2, ReferredBy: &referrer.ID}
+
+	repo := new(MockUserRepository)
+	repo.On("GetByID", referred.ID).Return(referred, nil)
+	repo.On("GetByID", referrer.ID).Return(referrer, nil)
+	// Бонусные дни учитываются в заработке пригласившего по цене дня самого короткого тарифа
+	repo.On("RewardReferral", repositories.ReferralReward{
+		UserID:     referred.ID,
+		ReferrerID: referrer.ID,
+		BonusDays:  7,
+		Earned:     69.77,
+	}).Return(true, nil).Once()
+
+	notifications := new(MockNotificationService)
+	notifications.On("SendToUser", mock.Anything, "referral_bonus", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	service := newTestReferralService(repo, notifications)
+	service.config.Referral.RewardType = "days"
+	service.config.Referral.BonusDays = 7
+
+	require.NoError(t, service.RewardFirstPurchase(referred.ID))
+	repo.AssertExpectations(t)
+}
+
+func TestReferralService_RewardFirstPurchaseFailure(t *testing.T) {
+	referrer := &models.User{ID: uuid.New(), TelegramID: 1}
+	referred := &models.User{ID: uuid.New(), TelegramID: 2, ReferredBy: &referrer.ID}
+
+	repo := new(MockUserRepository)
+	repo.On("GetByID", referred.ID).Return(referred, nil)
+	repo.On("GetByID", referrer.ID).Return(referrer, nil)
+	repo.On("RewardReferral", mock.Anything).Return(false, errors.New("connection reset")).Once()
+
+	// Транзакция откатилась вместе с отметкой: уведомлений нет, награду можно выплатить повторно
+	service := newTestReferralService(repo, new(MockNotificationService))
+
+	assert.Error(t, service.RewardFirstPurchase(referred.ID))
+	repo.AssertExpectations(t)
+}
+
+func TestReferralService_ReferralLink(t *testing.T) {
+	service := newTestReferralService(new(MockUserRepository), nil)
+
+	link := service.ReferralLink(&models.User{ReferralCode: "abc12345"})
+
+	assert.Equal(t, "https://t.me/shop_bot?start=ref_abc12345", link)
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"remnawave-tg-shop/internal/metrics"
 )
 
 // Client представляет клиент для работы с Remnawave API
@@ -76,6 +78,17 @@ type Subscription struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// UserAccess доступ пользователя к VPN: ссылка подписки и трафик
+type UserAccess struct {
+	SubscriptionURL      string     `json:"subscription_url"`
+	Status               string     `json:"status"`
+	UsedTrafficBytes     int64      `json:"used_traffic_bytes"`
+	TrafficLimitBytes    int64      `json:"traffic_limit_bytes"`
+	TrafficLimitStrategy string     `json:"traffic_limit_strategy"`
+	ExpireAt             *time.Time `json:"expire_at"`
+	OnlineAt             *time.Time `json:"online_at"`
+}
+
 // GetServers получает список серверов
 func (c *Client) GetServers() ([]Server, error) {
 	var response struct {
@@ -94,6 +107,12 @@ func (c *Client) GetServers() ([]Server, error) {
 	return response.Data, nil
 }
 
+// Health проверяет доступность панели Remnawave
+func (c *Client) Health() error {
+	_, err := c.GetServers()
+	return err
+}
+
 // GetPlans получает список тарифных планов
 func (c *Client) GetPlans(serverID int) ([]Plan, error) {
 	var response struct {
@@ -214,6 +233,25 @@ func (c *Client) DeleteSubscription(subscriptionID int) error {
 	return nil
 }
 
+// GetUserAccess получает ссылку подписки и трафик пользователя по Telegram ID
+func (c *Client) GetUserAccess(telegramID int64) (*UserAccess, error) {
+	var response struct {
+		APIResponse
+		Data UserAccess `json:"data"`
+	}
+
+	url := fmt.Sprintf("/users/by-telegram-id/%d", telegramID)
+	if err := c.makeRequest("GET", url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get user access: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("API error: %s", response.Message)
+	}
+
+	return &response.Data, nil
+}
+
 // makeRequest выполняет HTTP запрос к API
 func (c *Client) makeRequest(method, endpoint string, data interface{}, result interface{}) error {
 	var body io.Reader
@@ -243,11 +281,14 @@ func (c *Client) makeRequest(method, endpoint string, data interface{}, result i
 	}
 
 	// Выполняем запрос
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		metrics.ObserveRemnawaveRequest(method, endpoint, 0, time.Since(start))
 		return fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
+	metrics.ObserveRemnawaveRequest(method, endpoint, resp.StatusCode, time.Since(start))
 
 	// Читаем ответ
 	responseBody, err := io.ReadAll(resp.Body)
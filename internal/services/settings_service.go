@@ -0,0 +1,495 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownSetting настройки с таким ключом нет
+	ErrUnknownSetting = errors.New("настройка не найдена")
+	// ErrInvalidSettingValue значение не прошло проверку типа или диапазона
+	ErrInvalidSettingValue = errors.New("некорректное значение")
+)
+
+// Типы значений настроек
+const (
+	SettingTypeInt    = "int"
+	SettingTypeFloat  = "float"
+	SettingTypeBool   = "bool"
+	SettingTypeChoice = "choice"
+)
+
+// Разделы настроек, совпадают с кнопками admin:settings_<раздел>
+const (
+	SettingGroupBot      = "bot"
+	SettingGroupPayments = "payments"
+	SettingGroupPromo    = "promo"
+	SettingGroupNotify   = "notify"
+)
+
+// SettingDefinition описание настройки: ключ, тип, допустимые значения
+// и поле снимка настроек, в которое записывается значение
+type SettingDefinition struct {
+	Key     string
+	Group   string
+	Title   string
+	Type    string
+	Min     float64
+	Max     float64
+	Options []string
+
+	// field возвращает указатель на поле: *int, *float64, *bool или *string
+	field func(v *settingValues) any
+	// validate дополнительная проверка значения вместе с остальными настройками
+	validate func(settings *config.Settings, value string) error
+}
+
+// settingValues копия действующих настроек и тарифов. Значения записываются
+// в копию, после чего она публикуется целиком
+type settingValues struct {
+	settings config.Settings
+	tariffs  []models.Tariff
+}
+
+// partnerPercentSum проверяет, что проценты двух уровней вместе не превышают 100,
+// как и при загрузке конфигурации
+func partnerPercentSum(other func(settings *config.Settings) float64) func(settings *config.Settings, value string) error {
+	return func(settings *config.Settings, value string) error {
+		percent, _ := strconv.ParseFloat(value, 64)
+		if percent+other(settings) > 100 {
+			return fmt.Errorf("%w: сумма процентов двух уровней больше 100", ErrInvalidSettingValue)
+		}
+		return nil
+	}
+}
+
+// tariffPrice возвращает указатель на цену тарифа
+func tariffPrice(key string) func(v *settingValues) any {
+	return func(v *settingValues) any {
+		for i := range v.tariffs {
+			if v.tariffs[i].Key == key {
+				return &v.tariffs[i].Price
+			}
+		}
+		return nil
+	}
+}
+
+// settingDefinitions настройки, которые можно менять из админ-панели
+var settingDefinitions = []SettingDefinition{
+	// Пробный период и работа бота
+	{Key: "trial.enabled", Group: SettingGroupBot, Title: "Пробный период", Type: SettingTypeBool,
+		field: func(v *settingValues) any { return &v.settings.Trial.Enabled }},
+	{Key: "maintenance.failure_threshold", Group: SettingGroupBot, Title: "Сбоев подряд до автовключения техработ", Type: SettingTypeInt, Min: 1, Max: 100,
+		field: func(v *settingValues) any { return &v.settings.MaintenanceFailureThreshold }},
+
+	// Цены и способы оплаты
+	{Key: "tariff.basic.price", Group: SettingGroupPayments, Title: "Цена Basic, ₽", Type: SettingTypeFloat, Min: 1, Max: 1000000,
+		field: tariffPrice("basic")},
+	{Key: "tariff.premium.price", Group: SettingGroupPayments, Title: "Цена Premium, ₽", Type: SettingTypeFloat, Min: 1, Max: 1000000,
+		field: tariffPrice("premium")},
+	{Key: "tariff.pro.price", Group: SettingGroupPayments, Title: "Цена Pro, ₽", Type: SettingTypeFloat, Min: 1, Max: 1000000,
+		field: tariffPrice("pro")},
+	{Key: "payments.stars_enabled", Group: SettingGroupPayments, Title: "Telegram Stars", Type: SettingTypeBool,
+		field: func(v *settingValues) any { return &v.settings.Payments.StarsEnabled }},
+	{Key: "payments.tribute_enabled", Group: SettingGroupPayments, Title: "Tribute", Type: SettingTypeBool,
+		field: func(v *settingValues) any { return &v.settings.Payments.TributeEnabled }},
+
+	// Реферальные бонусы и партнерская программа
+	{Key: "referral.enabled", Group: SettingGroupPromo, Title: "Реферальная программа", Type: SettingTypeBool,
+		field: func(v *settingValues) any { return &v.settings.Referral.Enabled }},
+	{Key: "referral.reward_type", Group: SettingGroupPromo, Title: "Вид реферальной награды", Type: SettingTypeChoice, Options: []string{"balance", "days"},
+		field: func(v *settingValues) any { return &v.settings.Referral.RewardType }},
+	{Key: "referral.bonus_days", Group: SettingGroupPromo, Title: "Бонусные дни обоим", Type: SettingTypeInt, Min: 0, Max: 365,
+		field: func(v *settingValues) any { return &v.settings.Referral.BonusDays }},
+	{Key: "referral.referrer_bonus", Group: SettingGroupPromo, Title: "Бонус пригласившему, ₽", Type: SettingTypeInt, Min: 0, Max: 100000,
+		field: func(v *settingValues) any { return &v.settings.Referral.ReferrerBonus }},
+	{Key: "referral.referred_bonus", Group: SettingGroupPromo, Title: "Бонус приглашенному, ₽", Type: SettingTypeInt, Min: 0, Max: 100000,
+		field: func(v *settingValues) any { return &v.settings.Referral.ReferredBonus }},
+	{Key: "partner.level1_percent", Group: SettingGroupPromo, Title: "Партнерский процент 1-го уровня", Type: SettingTypeFloat, Min: 0, Max: 100,
+		field:    func(v *settingValues) any { return &v.settings.Partner.Level1Percent },
+		validate: partnerPercentSum(func(s *config.Settings) float64 { return s.Partner.Level2Percent })},
+	{Key: "partner.level2_percent", Group: SettingGroupPromo, Title: "Партнерский процент 2-го уровня", Type: SettingTypeFloat, Min: 0, Max: 100,
+		field:    func(v *settingValues) any { return &v.settings.Partner.Level2Percent },
+		validate: partnerPercentSum(func(s *config.Settings) float64 { return s.Partner.Level1Percent })},
+
+	// Уведомления
+	{Key: "notifications.enabled", Group: SettingGroupNotify, Title: "Уведомления", Type: SettingTypeBool,
+		field: func(v *settingValues) any { return &v.settings.Notifications.Enabled }},
+	{Key: "notifications.expiring_days_before", Group: SettingGroupNotify, Title: "Предупреждать об окончании подписки за, дней", Type: SettingTypeInt, Min: 1, Max: 30,
+		field: func(v *settingValues) any { return &v.settings.Notifications.ExpiringDaysBefore }},
+}
+
+// Parse проверяет значение и приводит его к каноническому виду
+func (d *SettingDefinition) Parse(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+
+	switch d.Type {
+	case SettingTypeBool:
+		switch strings.ToLower(raw) {
+		case "true", "on", "1", "да", "вкл":
+			return "true", nil
+		case "false", "off", "0", "нет", "выкл":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%w: ожидается да или нет", ErrInvalidSettingValue)
+	case SettingTypeInt:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return "", fmt.Errorf("%w: ожидается целое число", ErrInvalidSettingValue)
+		}
+		if err := d.checkRange(float64(value)); err != nil {
+			return "", err
+		}
+		return strconv.Itoa(value), nil
+	case SettingTypeFloat:
+		value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: ожидается число", ErrInvalidSettingValue)
+		}
+		if err := d.checkRange(value); err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case SettingTypeChoice:
+		for _, option := range d.Options {
+			if strings.EqualFold(raw, option) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("%w: допустимые значения %s", ErrInvalidSettingValue, strings.Join(d.Options, ", "))
+	}
+
+	return "", fmt.Errorf("%w: неизвестный тип %s", ErrInvalidSettingValue, d.Type)
+}
+
+// checkRange проверяет, что число входит в допустимый диапазон
+func (d *SettingDefinition) checkRange(value float64) error {
+	if value < d.Min || value > d.Max {
+		return fmt.Errorf("%w: допустимо от %s до %s", ErrInvalidSettingValue,
+			strconv.FormatFloat(d.Min, 'f', -1, 64), strconv.FormatFloat(d.Max, 'f', -1, 64))
+	}
+	return nil
+}
+
+// get возвращает значение поля в каноническом виде
+func (d *SettingDefinition) get(v *settingValues) string {
+	switch field := d.field(v).(type) {
+	case *int:
+		return strconv.Itoa(*field)
+	case *float64:
+		return strconv.FormatFloat(*field, 'f', -1, 64)
+	case *bool:
+		return strconv.FormatBool(*field)
+	case *string:
+		return *field
+	}
+	return ""
+}
+
+// set записывает проверенное значение в поле копии настроек
+func (d *SettingDefinition) set(v *settingValues, value string) {
+	switch field := d.field(v).(type) {
+	case *int:
+		*field, _ = strconv.Atoi(value)
+	case *float64:
+		*field, _ = strconv.ParseFloat(value, 64)
+	case *bool:
+		*field = value == "true"
+	case *string:
+		*field = value
+	}
+}
+
+// SettingsService накладывает настройки из базы данных на конфигурацию из
+// окружения. Измененные значения публикуются новым снимком config.Settings и
+// списком тарифов, поэтому все сервисы видят изменения без перезапуска и без
+// гонок с читателями. Другие реплики узнают об изменениях, периодически сверяя
+// время последней записи истории
+type SettingsService struct {
+	repo      repositories.SettingRepository
+	heartbeat *Heartbeat
+	config    *config.Config
+	logger    logger.Logger
+
+	mu         sync.Mutex
+	defaults   map[string]string
+	overrides  map[string]string
+	lastChange time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSettingsService создает новый сервис настроек и запоминает значения
+// из окружения, к которым возвращает сброс настройки
+func NewSettingsService(repo repositories.SettingRepository, heartbeat *Heartbeat, config *config.Config, logger logger.Logger) *SettingsService {
+	values := &settingValues{settings: *config.EnvSettings(), tariffs: models.Tariffs()}
+	defaults := make(map[string]string, len(settingDefinitions))
+	for i := range settingDefinitions {
+		defaults[settingDefinitions[i].Key] = settingDefinitions[i].get(values)
+	}
+
+	return &SettingsService{
+		repo:      repo,
+		heartbeat: heartbeat,
+		config:    config,
+		logger:    logger,
+		defaults:  defaults,
+		overrides: make(map[string]string),
+	}
+}
+
+// current возвращает копию действующих настроек и тарифов
+func (s *SettingsService) current() *settingValues {
+	return &settingValues{
+		settings: *s.config.Settings(),
+		tariffs:  append([]models.Tariff(nil), models.Tariffs()...),
+	}
+}
+
+// publish публикует измененную копию настроек и тарифов
+func (s *SettingsService) publish(values *settingValues) {
+	s.config.PublishSettings(&values.settings)
+	models.SetTariffs(values.tariffs)
+}
+
+// Definitions возвращает настройки раздела в порядке отображения
+func (s *SettingsService) Definitions(group string) []SettingDefinition {
+	var definitions []SettingDefinition
+	for _, definition := range settingDefinitions {
+		if definition.Group == group {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// Definition возвращает описание настройки по ключу
+func (s *SettingsService) Definition(key string) (*SettingDefinition, error) {
+	for i := range settingDefinitions {
+		if settingDefinitions[i].Key == key {
+			return &settingDefinitions[i], nil
+		}
+	}
+	return nil, ErrUnknownSetting
+}
+
+// Value возвращает действующее значение настройки
+func (s *SettingsService) Value(key string) string {
+	definition, err := s.Definition(key)
+	if err != nil {
+		return ""
+	}
+
+	return definition.get(s.current())
+}
+
+// Default возвращает значение настройки из окружения
+func (s *SettingsService) Default(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.defaults[key]
+}
+
+// IsOverridden проверяет, изменена ли настройка из админ-панели
+func (s *SettingsService) IsOverridden(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.overrides[key]
+	return ok
+}
+
+// Set проверяет и сохраняет новое значение настройки
+func (s *SettingsService) Set(key, raw string, adminID uuid.UUID) (string, error) {
+	definition, err := s.Definition(key)
+	if err != nil {
+		return "", err
+	}
+	value, err := definition.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := s.current()
+	if definition.validate != nil {
+		if err := definition.validate(&values.settings, value); err != nil {
+			return "", err
+		}
+	}
+
+	change := &models.SettingChange{
+		Key:       key,
+		OldValue:  definition.get(values),
+		NewValue:  value,
+		ChangedBy: &adminID,
+	}
+	setting := &models.Setting{Key: key, Value: value, UpdatedBy: &adminID, UpdatedAt: time.Now()}
+	if err := s.repo.Set(setting, change); err != nil {
+		return "", err
+	}
+
+	s.overrides[key] = value
+	definition.set(values, value)
+	s.publish(values)
+	s.logger.Info("Setting changed", "key", key, "old_value", change.OldValue, "new_value", value, "admin_id", adminID)
+
+	return value, nil
+}
+
+// Reset удаляет значение из базы данных и возвращает значение из окружения
+func (s *SettingsService) Reset(key string, adminID uuid.UUID) error {
+	definition, err := s.Definition(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := s.current()
+	change := &models.SettingChange{
+		Key:       key,
+		OldValue:  definition.get(values),
+		NewValue:  s.defaults[key],
+		Reset:     true,
+		ChangedBy: &adminID,
+	}
+	if err := s.repo.Delete(key, change); err != nil {
+		return err
+	}
+
+	delete(s.overrides, key)
+	definition.set(values, s.defaults[key])
+	s.publish(values)
+	s.logger.Info("Setting reset", "key", key, "value", change.NewValue, "admin_id", adminID)
+
+	return nil
+}
+
+// History возвращает последние изменения настройки
+func (s *SettingsService) History(key string, limit int) ([]models.SettingChange, error) {
+	return s.repo.GetHistory(key, limit, 0)
+}
+
+// Load применяет все сохраненные настройки. Некорректные значения
+// пропускаются, чтобы ручная правка в базе не остановила бота
+func (s *SettingsService) Load() error {
+	lastChange, err := s.repo.LastChangeAt()
+	if err != nil {
+		return err
+	}
+	settings, err := s.repo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		definition, err := s.Definition(setting.Key)
+		if err != nil {
+			s.logger.Warn("Skipping unknown setting", "key", setting.Key)
+			continue
+		}
+		value, err := definition.Parse(setting.Value)
+		if err != nil {
+			s.logger.Warn("Skipping invalid setting", "key", setting.Key, "value", setting.Value, "error", err)
+			continue
+		}
+		overrides[setting.Key] = value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := s.current()
+	for i := range settingDefinitions {
+		definition := &settingDefinitions[i]
+		value, ok := overrides[definition.Key]
+		if !ok {
+			value = s.defaults[definition.Key]
+		}
+		definition.set(values, value)
+	}
+	s.publish(values)
+	s.overrides = overrides
+	s.lastChange = lastChange
+
+	return nil
+}
+
+// Refresh перечитывает настройки, если после последней загрузки они менялись
+func (s *SettingsService) Refresh() error {
+	lastChange, err := s.repo.LastChangeAt()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	changed := lastChange.After(s.lastChange)
+	s.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return s.Load()
+}
+
+// Start запускает синхронизацию настроек с интервалом SETTINGS_REFRESH_INTERVAL
+func (s *SettingsService) Start() {
+	if s.config.Admin.SettingsRefreshInterval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.heartbeat.Expect("settings_refresh", s.config.Admin.SettingsRefreshInterval)
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.config.Admin.SettingsRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				start := time.Now()
+				err := s.Refresh()
+				metrics.ObserveJob("settings_refresh", start, err)
+				s.heartbeat.Beat("settings_refresh")
+				if err != nil {
+					s.logger.Warn("Failed to refresh settings", "error", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop останавливает синхронизацию настроек
+func (s *SettingsService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSettingRepository мок для SettingRepository
+type MockSettingRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingRepository) GetAll() ([]models.Setting, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Setting), args.Error(1)
+}
+
+func (m *MockSettingRepository) Set(setting *models.Setting, change *models.SettingChange) error {
+	args := m.Called(setting, change)
+	return args.Error(0)
+}
+
+func (m *MockSettingRepository) Delete(key string, change *models.SettingChange) error {
+	args := m.Called(key, change)
+	return args.Error(0)
+}
+
+func (m *MockSettingRepository) GetHistory(key string, limit, offset int) ([]models.SettingChange, error) {
+	args := m.Called(key, limit, offset)
+	return args.Get(0).([]models.SettingChange), args.Error(1)
+}
+
+func (m *MockSettingRepository) LastChangeAt() (time.Time, error) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func newTestSettingsService(t *testing.T) (*SettingsService, *MockSettingRepository, *config.Config) {
+	// Цены тарифов общие для пакета, возвращаем их после теста
+	tariffs := models.Tariffs()
+	t.Cleanup(func() { models.SetTariffs(tariffs) })
+
+	cfg := &config.Config{}
+	cfg.Payments.StarsEnabled = true
+	cfg.Referral.RewardType = "balance"
+	cfg.Referral.ReferrerBonus = 100
+	cfg.Notifications.ExpiringDaysBefore = 3
+
+	repo := new(MockSettingRepository)
+	return NewSettingsService(repo, nil, cfg, logger.New("error")), repo, cfg
+}
+
+func TestSettingsService_SetValidatesAndApplies(t *testing.T) {
+	service, repo, cfg := newTestSettingsService(t)
+	adminID := uuid.New()
+
+	_, err := service.Set("referral.referrer_bonus", "abc", adminID)
+	assert.ErrorIs(t, err, ErrInvalidSettingValue)
+	_, err = service.Set("notifications.expiring_days_before", "31", adminID)
+	assert.ErrorIs(t, err, ErrInvalidSettingValue)
+	_, err = service.Set("bot.token", "x", adminID)
+	assert.ErrorIs(t, err, ErrUnknownSetting)
+	cfg.Partner.Level2Percent = 30
+	_, err = service.Set("partner.level1_percent", "75", adminID)
+	assert.ErrorIs(t, err, ErrInvalidSettingValue)
+
+	repo.On("Set", mock.MatchedBy(func(s *models.Setting) bool {
+		return s.Key == "referral.referrer_bonus" && s.Value == "250" && *s.UpdatedBy == adminID
+	}), mock.MatchedBy(func(c *models.SettingChange) bool {
+		return c.OldValue == "100" && c.NewValue == "250" && *c.ChangedBy == adminID
+	})).Return(nil).Once()
+
+	value, err := service.Set("referral.referrer_bonus", " 250 ", adminID)
+	require.NoError(t, err)
+	assert.Equal(t, "250", value)
+	assert.Equal(t, 250, cfg.Settings().Referral.ReferrerBonus)
+	// Значение из окружения не меняется, публикуется новый снимок
+	assert.Equal(t, 100, cfg.Referral.ReferrerBonus)
+	assert.True(t, service.IsOverridden("referral.referrer_bonus"))
+
+	repo.On("Set", mock.Anything, mock.Anything).Return(nil)
+	published := models.Tariffs()
+	_, err = service.Set("tariff.basic.price", "349,5", adminID)
+	require.NoError(t, err)
+	tariff, _ := models.GetTariff("basic")
+	assert.Equal(t, 349.5, tariff.Price)
+	// Ранее полученный список не меняется
+	assert.Equal(t, 299.0, published[0].Price)
+
+	_, err = service.Set("payments.stars_enabled", "выкл", adminID)
+	require.NoError(t, err)
+	assert.False(t, cfg.Settings().Payments.StarsEnabled)
+
+	repo.AssertExpectations(t)
+}
+
+func TestSettingsService_ResetRestoresEnvironmentValue(t *testing.T) {
+	service, repo, cfg := newTestSettingsService(t)
+	adminID := uuid.New()
+
+	repo.On("Set", mock.Anything, mock.Anything).Return(nil)
+	_, err := service.Set("referral.reward_type", "DAYS", adminID)
+	require.NoError(t, err)
+	assert.Equal(t, "days", cfg.Settings().Referral.RewardType)
+
+	repo.On("Delete", "referral.reward_type", mock.MatchedBy(func(c *models.SettingChange) bool {
+		return c.Reset && c.OldValue == "days" && c.NewValue == "balance"
+	})).Return(nil).Once()
+
+	require.NoError(t, service.Reset("referral.reward_type", adminID))
+	assert.Equal(t, "balance", cfg.Settings().Referral.RewardType)
+	assert.False(t, service.IsOverridden("referral.reward_type"))
+	repo.AssertExpectations(t)
+}
+
+func TestSettingsService_RefreshPicksUpChangesFromOtherReplicas(t *testing.T) {
+	service, repo, cfg := newTestSettingsService(t)
+	first := time.Now().Add(-time.Minute)
+
+	repo.On("LastChangeAt").Return(first, nil).Once()
+	repo.On("GetAll").Return([]models.Setting{
+		{Key: "notifications.expiring_days_before", Value: "7"},
+		{Key: "referral.referrer_bonus", Value: "-5"}, // вне диапазона, пропускается
+		{Key: "removed.setting", Value: "1"},
+	}, nil).Once()
+
+	require.NoError(t, service.Load())
+	assert.Equal(t, 7, cfg.Settings().Notifications.ExpiringDaysBefore)
+	assert.Equal(t, 100, cfg.Settings().Referral.ReferrerBonus)
+
+	// Изменений нет — базу не перечитываем
+	repo.On("LastChangeAt").Return(first, nil).Once()
+	require.NoError(t, service.Refresh())
+
+	// Другая реплика сбросила настройку
+	second := time.Now()
+	repo.On("LastChangeAt").Return(second, nil).Twice()
+	repo.On("GetAll").Return([]models.Setting{}, nil).Once()
+	require.NoError(t, service.Refresh())
+	assert.Equal(t, 3, cfg.Settings().Notifications.ExpiringDaysBefore)
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+)
+
+var (
+	// ErrUnknownStatsPeriod неизвестный период статистики
+	ErrUnknownStatsPeriod = errors.New("неизвестный период статистики")
+	// ErrInvalidStatsRange начало интервала статистики не раньше конца
+	ErrInvalidStatsRange = errors.New("начало интервала должно быть раньше конца")
+)
+
+// Шаг разбивки выручки внутри периода
+const (
+	StatsStepHour  = "hour"
+	StatsStepDay   = "day"
+	StatsStepWeek  = "week"
+	StatsStepMonth = "month"
+)
+
+// StatsPeriod период статистики
+type StatsPeriod struct {
+	Key   string
+	Title string
+	// Step шаг разбивки выручки внутри периода
+	Step string
+}
+
+// StatsPeriods доступные периоды статистики в порядке отображения
+var StatsPeriods = []StatsPeriod{
+	{Key: "day", Title: "Сегодня", Step: StatsStepHour},
+	{Key: "week", Title: "7 дней", Step: StatsStepDay},
+	{Key: "month", Title: "30 дней", Step: StatsStepWeek},
+	{Key: "year", Title: "12 месяцев", Step: StatsStepMonth},
+}
+
+// DefaultStatsPeriod период статистики по умолчанию
+const DefaultStatsPeriod = "week"
+
+// RevenuePoint выручка за один интервал периода
+type RevenuePoint struct {
+	Start    time.Time
+	Total    float64
+	Count    int64
+	ByMethod map[string]float64
+}
+
+// MethodRevenue выручка по способу оплаты
+type MethodRevenue struct {
+	Method string
+	Amount float64
+	Count  int64
+}
+
+// PeriodStats статистика за период
+type PeriodStats struct {
+	From     time.Time
+	To       time.Time
+	Revenue  float64
+	Payments int64
+	// Methods выручка по способам оплаты, по убыванию суммы
+	Methods []MethodRevenue
+	// Points выручка по интервалам периода, включая пустые
+	Points  []RevenuePoint
+	Metrics models.PeriodMetrics
+}
+
+// Conversion доля пробных периодов, перешедших в оплату, в процентах
+func (s *PeriodStats) Conversion() float64 {
+	return percent(float64(s.Metrics.TrialConverted), float64(s.Metrics.TrialStarted))
+}
+
+// ChurnRate доля пользователей с подпиской на начало периода, потерявших ее к концу, в процентах
+func (s *PeriodStats) ChurnRate() float64 {
+	return percent(float64(s.Metrics.Churned), float64(s.Metrics.ActiveAtStart))
+}
+
+// ARPU средняя выручка на пользователя с активной подпиской
+func (s *PeriodStats) ARPU() float64 {
+	if s.Metrics.ActiveUsers == 0 {
+		return 0
+	}
+	return s.Revenue / float64(s.Metrics.ActiveUsers)
+}
+
+// ARPPU средняя выручка на платящего пользователя
+func (s *PeriodStats) ARPPU() float64 {
+	if s.Metrics.PayingUsers == 0 {
+		return 0
+	}
+	return s.Revenue / float64(s.Metrics.PayingUsers)
+}
+
+// ReferralShare доля выручки от приглашенных пользователей в процентах
+func (s *PeriodStats) ReferralShare() float64 {
+	return percent(s.Metrics.ReferralRevenue, s.Revenue)
+}
+
+// StatsDashboard статистика за период в сравнении с предыдущим таким же периодом
+type StatsDashboard struct {
+	Period   StatsPeriod
+	Current  PeriodStats
+	Previous PeriodStats
+	// ActiveByTariff активные подписки по тарифам на текущий момент
+	ActiveByTariff []models.TariffCount
+}
+
+// StatsSeries ряды значений по интервалам периода для графиков
+type StatsSeries struct {
+	Period StatsPeriod
+	// Revenue выручка по интервалам и способам оплаты
+	Revenue PeriodStats
+	// Registrations регистрации за каждый интервал
+	Registrations []int64
+	// ActiveSubscriptions действующие подписки на конец каждого интервала
+	ActiveSubscriptions []int64
+}
+
+// StatsService считает статистику для админ-панели по платежам, подпискам и пользователям
+type StatsService struct {
+	repo   repositories.StatsRepository
+	logger logger.Logger
+}
+
+// NewStatsService создает новый сервис статистики
+func NewStatsService(repo repositories.StatsRepository, logger logger.Logger) *StatsService {
+	return &StatsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetStatsPeriod возвращает период статистики по ключу
+func GetStatsPeriod(key string) (StatsPeriod, error) {
+	for _, period := range StatsPeriods {
+		if period.Key == key {
+			return period, nil
+		}
+	}
+	return StatsPeriod{}, ErrUnknownStatsPeriod
+}
+
+// Dashboard считает статистику за период, заканчивающийся в now, и за
+// предыдущий период такой же длины
+func (s *StatsService) Dashboard(periodKey string, now time.Time) (*StatsDashboard, error) {
+	period, err := GetStatsPeriod(periodKey)
+	if err != nil {
+		return nil, err
+	}
+
+	from := periodStart(period.Key, now)
+	prevFrom, prevTo := previousPeriod(period.Key, from), previousPeriod(period.Key, now)
+
+	current, err := s.periodStats(period, from, now)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.periodStats(period, prevFrom, prevTo)
+	if err != nil {
+		return nil, err
+	}
+
+	tariffs, err := s.repo.ActiveByTariff(now)
+	if err != nil {
+		s.logger.Error("Failed to get active subscriptions by tariff", "error", err)
+		return nil, err
+	}
+
+	return &StatsDashboard{
+		Period:         period,
+		Current:        *current,
+		Previous:       *previous,
+		ActiveByTariff: tariffs,
+	}, nil
+}
+
+// Series возвращает ряды выручки, регистраций и действующих подписок по
+// интервалам периода, заканчивающегося в now
+func (s *StatsService) Series(periodKey string, now time.Time) (*StatsSeries, error) {
+	period, err := GetStatsPeriod(periodKey)
+	if err != nil {
+		return nil, err
+	}
+
+	from := periodStart(period.Key, now)
+	revenue, err := s.revenueStats(period, from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make([]time.Time, len(revenue.Points))
+	for i, point := range revenue.Points {
+		starts[i] = point.Start
+	}
+
+	rows, err := s.repo.RegistrationsByHour(from, now)
+	if err != nil {
+		s.logger.Error("Failed to get registrations", "error", err, "period", period.Key)
+		return nil, err
+	}
+	registrations := make([]int64, len(starts))
+	for _, row := range rows {
+		if i := bucketIndex(starts, row.Bucket.In(from.Location())); i >= 0 {
+			registrations[i] += row.Count
+		}
+	}
+
+	// Подписки считаем на конец интервала, последний интервал — на текущий момент
+	ends := make([]time.Time, len(starts))
+	for i := range starts {
+		if i+1 < len(starts) {
+			ends[i] = starts[i+1]
+		} else {
+			ends[i] = now
+		}
+	}
+	active, err := s.repo.ActiveSubscriptionsAt(ends)
+	if err != nil {
+		s.logger.Error("Failed to get active subscriptions", "error", err, "period", period.Key)
+		return nil, err
+	}
+
+	return &StatsSeries{
+		Period:              period,
+		Revenue:             *revenue,
+		Registrations:       registrations,
+		ActiveSubscriptions: active,
+	}, nil
+}
+
+// Revenue считает выручку с разбивкой по шагу периода. Без from и to
+// интервал совпадает с периодом, заканчивающимся в now
+func (s *StatsService) Revenue(periodKey string, from, to *time.Time, now time.Time) (*PeriodStats, error) {
+	period, err := GetStatsPeriod(periodKey)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := periodStart(period.Key, now), now
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+	if !start.Before(end) {
+		return nil, ErrInvalidStatsRange
+	}
+
+	return s.revenueStats(period, start, end)
+}
+
+// Overview возвращает сводные показатели на момент now
+func (s *StatsService) Overview(now time.Time) (*models.StatsOverview, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	overview, err := s.repo.Overview(now, dayStart, monthStart)
+	if err != nil {
+		s.logger.Error("Failed to get stats overview", "error", err)
+		return nil, err
+	}
+	return overview, nil
+}
+
+// periodStats собирает статистику за интервал [from, to)
+func (s *StatsService) periodStats(period StatsPeriod, from, to time.Time) (*PeriodStats, error) {
+	stats, err := s.revenueStats(period, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.repo.PeriodMetrics(from, to)
+	if err != nil {
+		s.logger.Error("Failed to get period metrics", "error", err, "period", period.Key)
+		return nil, err
+	}
+	stats.Metrics = *metrics
+
+	return stats, nil
+}
+
+// revenueStats собирает выручку за интервал [from, to) по интервалам и способам оплаты
+func (s *StatsService) revenueStats(period StatsPeriod, from, to time.Time) (*PeriodStats, error) {
+	rows, err := s.repo.RevenueByHour(from, to)
+	if err != nil {
+		s.logger.Error("Failed to get revenue", "error", err, "period", period.Key)
+		return nil, err
+	}
+
+	stats := &PeriodStats{From: from, To: to}
+
+	starts := bucketStarts(period.Step, from, to)
+	stats.Points = make([]RevenuePoint, len(starts))
+	for i, start := range starts {
+		stats.Points[i] = RevenuePoint{Start: start, ByMethod: map[string]float64{}}
+	}
+
+	methods := map[string]*MethodRevenue{}
+	for _, row := range rows {
+		stats.Revenue += row.Amount
+		stats.Payments += row.Count
+
+		method, ok := methods[row.Method]
+		if !ok {
+			method = &MethodRevenue{Method: row.Method}
+			methods[row.Method] = method
+		}
+		method.Amount += row.Amount
+		method.Count += row.Count
+
+		if i := bucketIndex(starts, row.Bucket.In(from.Location())); i >= 0 {
+			stats.Points[i].Total += row.Amount
+			stats.Points[i].Count += row.Count
+			stats.Points[i].ByMethod[row.Method] += row.Amount
+		}
+	}
+
+	for _, method := range methods {
+		stats.Methods = append(stats.Methods, *method)
+	}
+	sort.Slice(stats.Methods, func(i, j int) bool {
+		if stats.Methods[i].Amount != stats.Methods[j].Amount {
+			return stats.Methods[i].Amount > stats.Methods[j].Amount
+		}
+		return stats.Methods[i].Method < stats.Methods[j].Method
+	})
+
+	return stats, nil
+}
+
+// periodStart возвращает начало периода, заканчивающегося в now
+func periodStart(key string, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch key {
+	case "day":
+		return today
+	case "week":
+		return today.AddDate(0, 0, -6)
+	case "month":
+		return today.AddDate(0, 0, -29)
+	default:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -11, 0)
+	}
+}
+
+// previousPeriod сдвигает момент на длину периода назад
+func previousPeriod(key string, t time.Time) time.Time {
+	switch key {
+	case "day":
+		return t.AddDate(0, 0, -1)
+	case "week":
+		return t.AddDate(0, 0, -7)
+	case "month":
+		return t.AddDate(0, 0, -30)
+	default:
+		return t.AddDate(-1, 0, 0)
+	}
+}
+
+// bucketStarts возвращает начала интервалов с шагом step, покрывающих [from, to)
+func bucketStarts(step string, from, to time.Time) []time.Time {
+	var starts []time.Time
+	for start := from; start.Before(to); start = nextBucket(step, start) {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// nextBucket возвращает начало следующего интервала
+func nextBucket(step string, t time.Time) time.Time {
+	switch step {
+	case StatsStepHour:
+		return t.Add(time.Hour)
+	case StatsStepDay:
+		return t.AddDate(0, 0, 1)
+	case StatsStepWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+// bucketIndex возвращает индекс интервала, в который попадает момент t
+func bucketIndex(starts []time.Time, t time.Time) int {
+	i := sort.Search(len(starts), func(i int) bool { return starts[i].After(t) })
+	return i - 1
+}
+
+// percent возвращает part от total в процентах
+func percent(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
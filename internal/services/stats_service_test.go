@@ -0,0 +1,160 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStatsRepository мок для StatsRepository
+type MockStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatsRepository) RevenueByHour(from, to time.Time) ([]models.RevenueBucket, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]models.RevenueBucket), args.Error(1)
+}
+
+func (m *MockStatsRepository) PeriodMetrics(from, to time.Time) (*models.PeriodMetrics, error) {
+	args := m.Called(from, to)
+	return args.Get(0).(*models.PeriodMetrics), args.Error(1)
+}
+
+func (m *MockStatsRepository) ActiveByTariff(at time.Time) ([]models.TariffCount, error) {
+	args := m.Called(at)
+	return args.Get(0).([]models.TariffCount), args.Error(1)
+}
+
+func (m *MockStatsRepository) RegistrationsByHour(from, to time.Time) ([]models.CountBucket, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]models.CountBucket), args.Error(1)
+}
+
+func (m *MockStatsRepository) Overview(now, dayStart, monthStart time.Time) (*models.StatsOverview, error) {
+	args := m.Called(now, dayStart, monthStart)
+	return args.Get(0).(*models.StatsOverview), args.Error(1)
+}
+
+func (m *MockStatsRepository) ActiveSubscriptionsAt(moments []time.Time) ([]int64, error) {
+	args := m.Called(moments)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func TestStatsService_Dashboard(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	from := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	prevFrom, prevTo := from.AddDate(0, 0, -7), now.AddDate(0, 0, -7)
+
+	repo := new(MockStatsRepository)
+	repo.On("RevenueByHour", from, now).Return([]models.RevenueBucket{
+		{Bucket: from.Add(10 * time.Hour), Method: "stars", Amount: 100, Count: 1},
+		{Bucket: from.Add(11 * time.Hour), Method: "yookassa", Amount: 300, Count: 2},
+		{Bucket: now.Add(-time.Hour), Method: "stars", Amount: 200, Count: 1},
+	}, nil)
+	repo.On("PeriodMetrics", from, now).Return(&models.PeriodMetrics{
+		NewUsers: 10, PayingUsers: 3, ReferralRevenue: 150,
+		TrialStarted: 4, TrialConverted: 1, ActiveUsers: 6, ActiveAtStart: 5, Churned: 1,
+	}, nil)
+	repo.On("RevenueByHour", prevFrom, prevTo).Return([]models.RevenueBucket{}, nil)
+	repo.On("PeriodMetrics", prevFrom, prevTo).Return(&models.PeriodMetrics{}, nil)
+	repo.On("ActiveByTariff", now).Return([]models.TariffCount{{PlanName: "Месяц", Count: 6}}, nil)
+
+	service := NewStatsService(repo, logger.New("error"))
+	dashboard, err := service.Dashboard("week", now)
+	require.NoError(t, err)
+
+	current := dashboard.Current
+	assert.Equal(t, 600.0, current.Revenue)
+	assert.Equal(t, int64(4), current.Payments)
+	require.Len(t, current.Methods, 2)
+	assert.Equal(t, MethodRevenue{Method: "stars", Amount: 300, Count: 2}, current.Methods[0])
+
+	// Пустые дни тоже попадают в разбивку
+	require.Len(t, current.Points, 7)
+	assert.Equal(t, 400.0, current.Points[0].Total)
+	assert.Equal(t, 300.0, current.Points[0].ByMethod["yookassa"])
+	assert.Equal(t, 200.0, current.Points[6].Total)
+
+	assert.Equal(t, 25.0, current.Conversion())
+	assert.Equal(t, 20.0, current.ChurnRate())
+	assert.Equal(t, 100.0, current.ARPU())
+	assert.Equal(t, 200.0, current.ARPPU())
+	assert.Equal(t, 25.0, current.ReferralShare())
+
+	assert.Zero(t, dashboard.Previous.Revenue)
+	assert.Zero(t, dashboard.Previous.Conversion())
+	assert.Equal(t, prevFrom, dashboard.Previous.From)
+	assert.Len(t, dashboard.ActiveByTariff, 1)
+}
+
+func TestStatsService_DashboardUnknownPeriod(t *testing.T) {
+	service := NewStatsService(new(MockStatsRepository), logger.New("error"))
+	_, err := service.Dashboard("decade", time.Now())
+	assert.ErrorIs(t, err, ErrUnknownStatsPeriod)
+}
+
+func TestBucketStarts(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Len(t, bucketStarts(StatsStepMonth, from, now), 12)
+	assert.Len(t, bucketStarts(StatsStepHour, now.Add(-12*time.Hour), now), 12)
+	assert.Equal(t, from, periodStart("year", now))
+	assert.Len(t, bucketStarts(StatsStepWeek, periodStart("month", now), now), 5)
+}
+
+func TestStatsService_Series(t *testing.T) {
+	now := time.Date(2026, 3, 10, 3, 30, 0, 0, time.UTC)
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	ends := []time.Time{from.Add(time.Hour), from.Add(2 * time.Hour), from.Add(3 * time.Hour), now}
+
+	repo := new(MockStatsRepository)
+	repo.On("RevenueByHour", from, now).Return([]models.RevenueBucket{
+		{Bucket: from.Add(2 * time.Hour), Method: "stars", Amount: 50, Count: 1},
+	}, nil)
+	repo.On("RegistrationsByHour", from, now).Return([]models.CountBucket{
+		{Bucket: from, Count: 2},
+		{Bucket: from.Add(3 * time.Hour), Count: 1},
+	}, nil)
+	repo.On("ActiveSubscriptionsAt", ends).Return([]int64{5, 5, 6, 7}, nil)
+
+	service := NewStatsService(repo, logger.New("error"))
+	series, err := service.Series("day", now)
+	require.NoError(t, err)
+
+	require.Len(t, series.Revenue.Points, 4)
+	assert.Equal(t, 50.0, series.Revenue.Points[2].Total)
+	assert.Equal(t, []int64{2, 0, 0, 1}, series.Registrations)
+	assert.Equal(t, []int64{5, 5, 6, 7}, series.ActiveSubscriptions)
+	repo.AssertNotCalled(t, "PeriodMetrics", mock.Anything, mock.Anything)
+}
+
+func TestStatsService_RevenueRange(t *testing.T) {
+	repo := &MockStatsRepository{}
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	repo.On("RevenueByHour", from, to).Return([]models.RevenueBucket{
+		{Bucket: from.Add(5 * time.Hour), Method: "stars", Amount: 100, Count: 1},
+		{Bucket: from.Add(30 * time.Hour), Method: "yookassa", Amount: 200, Count: 2},
+	}, nil)
+
+	service := NewStatsService(repo, logger.New("error"))
+	stats, err := service.Revenue("week", &from, &to, now)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, stats.Revenue)
+	assert.Equal(t, int64(3), stats.Payments)
+	require.Len(t, stats.Points, 2)
+	assert.Equal(t, 200.0, stats.Points[1].Total)
+
+	_, err = service.Revenue("week", &to, &from, now)
+	assert.ErrorIs(t, err, ErrInvalidStatsRange)
+}
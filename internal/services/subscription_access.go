@@ -0,0 +1,16 @@
+package services
+
+import (
+	"fmt"
+
+	"remnawave-tg-shop/internal/services/remnawave"
+)
+
+// GetAccess получает из Remnawave ссылку подписки и расход трафика пользователя
+func (s *subscriptionService) GetAccess(telegramID int64) (*remnawave.UserAccess, error) {
+	access, err := s.remnawaveClient.GetUserAccess(telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription access: %w", err)
+	}
+	return access, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSubscriptionNotFound подписка не найдена
+	ErrSubscriptionNotFound = errors.New("подписка не найдена")
+	// ErrZeroDays изменение срока на ноль дней
+	ErrZeroDays = errors.New("количество дней не может быть нулевым")
+	// ErrTrialActive пробный период еще действует
+	ErrTrialActive = errors.New("пробная подписка еще активна")
+	// ErrTrialNotUsed пользователь не использовал пробный период
+	ErrTrialNotUsed = errors.New("пробный период не использован")
+)
+
+// AdjustDays продлевает подписку на days дней или сокращает при отрицательном
+// значении. Истекшая подписка продлевается от текущего момента, сокращенная до
+// прошлого — считается истекшей
+func (s *subscriptionService) AdjustDays(subscriptionID uuid.UUID, days int) (*models.Subscription, error) {
+	if days == 0 {
+		return nil, ErrZeroDays
+	}
+
+	subscription, err := s.subscriptionRepo.GetByID(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	now := time.Now()
+	if days > 0 {
+		base := subscription.ExpiresAt
+		if base.Before(now) {
+			base = now
+		}
+		subscription.ExpiresAt = base.AddDate(0, 0, days)
+		if subscription.Status == "expired" {
+			subscription.Status = "active"
+		}
+	} else {
+		subscription.ExpiresAt = subscription.ExpiresAt.AddDate(0, 0, days)
+		if !subscription.ExpiresAt.After(now) && subscription.Status == "active" {
+			subscription.Status = "expired"
+		}
+	}
+	subscription.UpdatedAt = now
+
+	if err := s.subscriptionRepo.Update(subscription); err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	s.logger.Info("Subscription days adjusted", "subscription_id", subscription.ID, "days", days, "expires_at", subscription.ExpiresAt)
+	return subscription, nil
+}
+
+// GrantTariff выдает пользователю подписку по тарифу без оплаты
+func (s *subscriptionService) GrantTariff(userID uuid.UUID, tariff models.Tariff) (*models.Subscription, error) {
+	now := time.Now()
+	subscription := &models.Subscription{
+		UserID:     userID,
+		ServerID:   1, // По умолчанию сервер 1
+		ServerName: "Default Server",
+		PlanID:     1, // По умолчанию план 1
+		PlanName:   tariff.Name,
+		Status:     "active",
+		Source:     models.SubscriptionSourceAdmin,
+		ExpiresAt:  now.AddDate(0, 0, tariff.DurationDays),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.subscriptionRepo.Create(subscription); err != nil {
+		return nil, fmt.Errorf("failed to grant subscription: %w", err)
+	}
+
+	s.logger.Info("Tariff granted", "user_id", userID, "tariff", tariff.Key, "subscription_id", subscription.ID)
+	return subscription, nil
+}
+
+// ResetTrial удаляет завершившиеся пробные подписки, чтобы пользователь мог
+// снова получить пробный период. Возвращает количество удаленных подписок
+func (s *subscriptionService) ResetTrial(userID uuid.UUID) (int, error) {
+	subscriptions, err := s.subscriptionRepo.GetByUserID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user subscriptions: %w", err)
+	}
+
+	var trials []models.Subscription
+	for _, subscription := range subscriptions {
+		if subscription.PlanID != 0 {
+			continue
+		}
+		if subscription.IsActive() {
+			return 0, ErrTrialActive
+		}
+		trials = append(trials, subscription)
+	}
+	if len(trials) == 0 {
+		return 0, ErrTrialNotUsed
+	}
+
+	for _, trial := range trials {
+		if err := s.subscriptionRepo.Delete(trial.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	s.logger.Info("Trial reset", "user_id", userID, "subscriptions", len(trials))
+	return len(trials), nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSubscriptionRepository мок для SubscriptionRepository
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) Create(subscription *models.Subscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) Purchase(subscription *models.Subscription, price float64, usageID *uuid.UUID, discount float64) (float64, error) {
+	args := m.Called(subscription, price, usageID, discount)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetByID(id uuid.UUID) (*models.Subscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetByUserID(userID uuid.UUID) ([]models.Subscription, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetActiveByUserID(userID uuid.UUID) ([]models.Subscription, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Update(subscription *models.Subscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) AddDays(userID uuid.UUID, days int) (*models.Subscription, error) {
+	args := m.Called(userID, days)
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) List(limit, offset int) ([]models.Subscription, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetExpired() ([]models.Subscription, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetExpiringSoon(days int) ([]models.Subscription, error) {
+	args := m.Called(days)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetUsersWithActiveSubscriptions() ([]models.User, error) {
+	args := m.Called()
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetUsersWithExpiredSubscriptions() ([]models.User, error) {
+	args := m.Called()
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func TestSubscriptionService_AdjustDays(t *testing.T) {
+	repo := new(MockSubscriptionRepository)
+	service := NewSubscriptionService(repo, nil, logger.New("error"))
+
+	expiresAt := time.Now().Add(48 * time.Hour)
+	subscription := &models.Subscription{ID: uuid.New(), Status: "active", ExpiresAt: expiresAt}
+	repo.On("GetByID", subscription.ID).Return(subscription, nil)
+	repo.On("Update", subscription).Return(nil)
+
+	updated, err := service.AdjustDays(subscription.ID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt.AddDate(0, 0, 5), updated.ExpiresAt)
+	assert.Equal(t, "active", updated.Status)
+
+	// Сокращение в прошлое завершает подписку
+	updated, err = service.AdjustDays(subscription.ID, -10)
+	require.NoError(t, err)
+	assert.Equal(t, "expired", updated.Status)
+
+	// Продление истекшей подписки считается от текущего момента
+	updated, err = service.AdjustDays(subscription.ID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "active", updated.Status)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 3), updated.ExpiresAt, time.Minute)
+
+	_, err = service.AdjustDays(subscription.ID, 0)
+	assert.ErrorIs(t, err, ErrZeroDays)
+}
+
+func TestSubscriptionService_ResetTrial(t *testing.T) {
+	userID := uuid.New()
+	expiredTrial := models.Subscription{ID: uuid.New(), PlanID: 0, Status: "active", ExpiresAt: time.Now().Add(-time.Hour)}
+	paid := models.Subscription{ID: uuid.New(), PlanID: 1, Status: "active", ExpiresAt: time.Now().Add(time.Hour)}
+
+	repo := new(MockSubscriptionRepository)
+	service := NewSubscriptionService(repo, nil, logger.New("error"))
+	repo.On("GetByUserID", userID).Return([]models.Subscription{expiredTrial, paid}, nil)
+	repo.On("Delete", expiredTrial.ID).Return(nil)
+
+	removed, err := service.ResetTrial(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	repo.AssertNotCalled(t, "Delete", paid.ID)
+}
+
+func TestSubscriptionService_ResetTrialErrors(t *testing.T) {
+	activeTrialUser := uuid.New()
+	noTrialUser := uuid.New()
+
+	repo := new(MockSubscriptionRepository)
+	service := NewSubscriptionService(repo, nil, logger.New("error"))
+	repo.On("GetByUserID", activeTrialUser).Return([]models.Subscription{
+		{ID: uuid.New(), PlanID: 0, Status: "active", ExpiresAt: time.Now().Add(time.Hour)},
+	}, nil)
+	repo.On("GetByUserID", noTrialUser).Return([]models.Subscription{}, nil)
+
+	_, err := service.ResetTrial(activeTrialUser)
+	assert.ErrorIs(t, err, ErrTrialActive)
+
+	_, err = service.ResetTrial(noTrialUser)
+	assert.ErrorIs(t, err, ErrTrialNotUsed)
+	repo.AssertNotCalled(t, "Delete", mock.Anything)
+}
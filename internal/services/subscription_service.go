@@ -77,6 +77,7 @@ func (s *subscriptionService) CreateSubscription(userID uuid.UUID, serverID, pla
 		PlanID:     planID,
 		PlanName:   planName,
 		Status:     "active",
+		Source:     models.SubscriptionSourceAdmin,
 		ExpiresAt:  time.Now().AddDate(0, 0, planDuration),
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
@@ -203,6 +204,7 @@ func (s *subscriptionService) CreateTrialSubscription(userID uuid.UUID, duration
 		PlanID:     0, // 0 для пробной подписки
 		PlanName:   "Trial Plan",
 		Status:     "active",
+		Source:     models.SubscriptionSourceTrial,
 		ExpiresAt:  time.Now().AddDate(0, 0, durationDays),
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
@@ -216,6 +218,22 @@ func (s *subscriptionService) CreateTrialSubscription(userID uuid.UUID, duration
 	return nil
 }
 
+// AddBonusDays продлевает активную подписку на указанное количество дней,
+// а если активной подписки нет — выдает новую
+func (s *subscriptionService) AddBonusDays(userID uuid.UUID, days int) (*models.Subscription, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("bonus days must be positive")
+	}
+
+	subscription, err := s.subscriptionRepo.AddDays(userID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Subscription extended by bonus days", "user_id", userID, "subscription_id", subscription.ID, "days", days)
+	return subscription, nil
+}
+
 // HasUsedTrial проверяет, использовал ли пользователь пробный период
 func (s *subscriptionService) HasUsedTrial(userID uuid.UUID) (bool, error) {
 	subscriptions, err := s.subscriptionRepo.GetByUserID(userID)
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+	"github.com/mymmrac/telego"
+)
+
+// TopUpInvoicePrefix префикс payload счета на пополнение баланса
+const TopUpInvoicePrefix = "topup:"
+
+// starsCurrency валюта счетов в Telegram Stars
+const starsCurrency = "XTR"
+
+var (
+	// ErrTopUpAmount сумма пополнения вне допустимых границ
+	ErrTopUpAmount = errors.New("недопустимая сумма пополнения")
+	// ErrTopUpDisabled оплата звездами отключена
+	ErrTopUpDisabled = errors.New("пополнение через Telegram Stars отключено")
+	// ErrTopUpInvoice счет не найден, уже оплачен или не совпадает с платежом
+	ErrTopUpInvoice = errors.New("счет недействителен")
+)
+
+// TopUpInvoice счет на пополнение баланса в Telegram Stars
+type TopUpInvoice struct {
+	Payment *models.Payment
+	URL     string
+	Stars   int
+}
+
+// TopUpService выставляет счета на пополнение баланса в Telegram Stars и
+// зачисляет оплаченные счета
+type TopUpService struct {
+	paymentService PaymentService
+	messenger      telegram.Messenger
+	config         *config.Config
+	logger         logger.Logger
+}
+
+// NewTopUpService создает новый TopUpService
+func NewTopUpService(paymentService PaymentService, messenger telegram.Messenger, config *config.Config, log logger.Logger) *TopUpService {
+	return &TopUpService{
+		paymentService: paymentService,
+		messenger:      messenger,
+		config:         config,
+		logger:         log,
+	}
+}
+
+// Stars переводит сумму в рублях в звезды с округлением вверх
+func (s *TopUpService) Stars(amount float64) int {
+	return int(math.Ceil(amount / s.config.Settings().Payments.StarsRate))
+}
+
+// CreateInvoice создает платеж в статусе pending и ссылку на счет для него
+func (s *TopUpService) CreateInvoice(userID uuid.UUID, amount float64) (*TopUpInvoice, error) {
+	if !s.config.Settings().Payments.StarsEnabled {
+		return nil, ErrTopUpDisabled
+	}
+	if amount < s.config.MiniApp.MinTopUp || amount > s.config.MiniApp.MaxTopUp {
+		return nil, ErrTopUpAmount
+	}
+
+	payment, err := s.paymentService.CreatePayment(userID, amount, "stars", "Пополнение баланса через Telegram Stars")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create top up payment: %w", err)
+	}
+
+	stars := s.Stars(amount)
+	url, err := s.messenger.CreateInvoiceLink(&telegram.Invoice{
+		Title:       "Пополнение баланса",
+		Description: fmt.Sprintf("Пополнение баланса на %.0f₽", amount),
+		Payload:     TopUpInvoicePrefix + payment.ID.String(),
+		Currency:    starsCurrency,
+		Prices:      []telego.LabeledPrice{{Label: "Пополнение баланса", Amount: stars}},
+	})
+	if err != nil {
+		if updateErr := s.paymentService.UpdatePaymentStatus(payment.ID, "failed"); updateErr != nil {
+			s.logger.Error("Failed to fail top up payment", "error", updateErr, "payment_id", payment.ID)
+		}
+		return nil, fmt.Errorf("failed to create invoice link: %w", err)
+	}
+
+	s.logger.Info("Top up invoice created", "user_id", userID, "payment_id", payment.ID, "amount", amount, "stars", stars)
+	return &TopUpInvoice{Payment: payment, URL: url, Stars: stars}, nil
+}
+
+// CheckInvoice проверяет счет перед списанием звезд: платеж принадлежит
+// пользователю, ожидает оплаты и сумма совпадает со счетом
+func (s *TopUpService) CheckInvoice(userID uuid.UUID, payload, currency string, totalAmount int) (*models.Payment, error) {
+	rawID, ok := strings.CutPrefix(payload, TopUpInvoicePrefix)
+	if !ok || currency != starsCurrency {
+		return nil, ErrTopUpInvoice
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, ErrTopUpInvoice
+	}
+
+	payment, err := s.paymentService.GetPayment(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil || payment.UserID != userID || !payment.IsPending() || s.Stars(payment.Amount) != totalAmount {
+		return nil, ErrTopUpInvoice
+	}
+	return payment, nil
+}
+
+// CompleteInvoice зачисляет оплаченный счет на баланс. Повторное уведомление
+// об оплате того же счета ничего не зачисляет
+func (s *TopUpService) CompleteInvoice(userID uuid.UUID, payload, currency string, totalAmount int, chargeID string) (*models.Payment, error) {
+	payment, err := s.CheckInvoice(userID, payload, currency, totalAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Одновременные уведомления об оплате проходят CheckInvoice оба, но
+	// зачисляет только первое
+	if err := s.paymentService.UpdatePaymentStatus(payment.ID, "completed"); err != nil {
+		if errors.Is(err, ErrPaymentNotPending) {
+			return nil, ErrTopUpInvoice
+		}
+		return nil, fmt.Errorf("failed to complete top up payment: %w", err)
+	}
+	payment.Status = "completed"
+
+	s.logger.Info("Top up invoice paid", "user_id", userID, "payment_id", payment.ID, "amount", payment.Amount, "charge_id", chargeID)
+	return payment, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/telegram"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePaymentService хранит платежи в памяти; остальные методы не используются
+type fakePaymentService struct {
+	PaymentService
+	payments map[uuid.UUID]*models.Payment
+	// beforeUpdate вызывается перед сменой статуса, имитируя параллельный запрос
+	beforeUpdate func()
+}
+
+func (f *fakePaymentService) CreatePayment(userID uuid.UUID, amount float64, method, description string) (*models.Payment, error) {
+	payment := &models.Payment{ID: uuid.New(), UserID: userID, Amount: amount, PaymentMethod: method, Status: "pending"}
+	f.payments[payment.ID] = payment
+	return payment, nil
+}
+
+func (f *fakePaymentService) GetPayment(id uuid.UUID) (*models.Payment, error) {
+	return f.payments[id], nil
+}
+
+func (f *fakePaymentService) UpdatePaymentStatus(id uuid.UUID, status string) error {
+	if f.beforeUpdate != nil {
+		f.beforeUpdate()
+	}
+	if status == "completed" && !f.payments[id].IsPending() {
+		return ErrPaymentNotPending
+	}
+	f.payments[id].Status = status
+	return nil
+}
+
+func newTestTopUpService() (*TopUpService, *telegram.FakeMessenger) {
+	cfg := &config.Config{}
+	cfg.Payments.StarsEnabled = true
+	cfg.Payments.StarsRate = 1.5
+	cfg.MiniApp.MinTopUp = 50
+	cfg.MiniApp.MaxTopUp = 1000
+	messenger := telegram.NewFakeMessenger()
+	payments := &fakePaymentService{payments: map[uuid.UUID]*models.Payment{}}
+	return NewTopUpService(payments, messenger, cfg, logger.New("error")), messenger
+}
+
+func TestTopUpService_CreateInvoice(t *testing.T) {
+	service, messenger := newTestTopUpService()
+	userID := uuid.New()
+
+	_, err := service.CreateInvoice(userID, 10)
+	assert.ErrorIs(t, err, ErrTopUpAmount)
+
+	invoice, err := service.CreateInvoice(userID, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 67, invoice.Stars)
+	assert.Equal(t, "pending", invoice.Payment.Status)
+	assert.Contains(t, invoice.URL, invoice.Payment.ID.String())
+	assert.Len(t, messenger.Sent(), 1)
+
+	service.config.Payments.StarsEnabled = false
+	_, err = service.CreateInvoice(userID, 100)
+	assert.ErrorIs(t, err, ErrTopUpDisabled)
+}
+
+func TestTopUpService_CompleteInvoice(t *testing.T) {
+	service, _ := newTestTopUpService()
+	userID := uuid.New()
+	invoice, err := service.CreateInvoice(userID, 150)
+	require.NoError(t, err)
+	payload := TopUpInvoicePrefix + invoice.Payment.ID.String()
+
+	_, err = service.CheckInvoice(uuid.New(), payload, starsCurrency, 100)
+	assert.ErrorIs(t, err, ErrTopUpInvoice, "чужой счет")
+	_, err = service.CheckInvoice(userID, payload, starsCurrency, 99)
+	assert.ErrorIs(t, err, ErrTopUpInvoice, "сумма не совпадает")
+
+	payment, err := service.CompleteInvoice(userID, payload, starsCurrency, 100, "charge-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", payment.Status)
+
+	_, err = service.CompleteInvoice(userID, payload, starsCurrency, 100, "charge-1")
+	assert.ErrorIs(t, err, ErrTopUpInvoice, "повторное уведомление не зачисляется")
+}
+
+func TestTopUpService_CompleteInvoiceConcurrent(t *testing.T) {
+	service, _ := newTestTopUpService()
+	userID := uuid.New()
+	invoice, err := service.CreateInvoice(userID, 150)
+	require.NoError(t, err)
+	payload := TopUpInvoicePrefix + invoice.Payment.ID.String()
+
+	// Параллельное уведомление завершает платеж после проверки счета, но до
+	// зачисления: второе зачисление отклоняется
+	payments := service.paymentService.(*fakePaymentService)
+	payments.beforeUpdate = func() { payments.payments[invoice.Payment.ID].Status = "completed" }
+
+	_, err = service.CompleteInvoice(userID, payload, starsCurrency, 100, "charge-2")
+	assert.ErrorIs(t, err, ErrTopUpInvoice)
+}
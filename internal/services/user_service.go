@@ -1,7 +1,9 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"remnawave-tg-shop/internal/config"
@@ -13,6 +15,19 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrUnsupportedLanguage язык не входит в SUPPORTED_LANGUAGES
+	ErrUnsupportedLanguage = errors.New("язык не поддерживается")
+	// ErrUnknownRole роль не существует
+	ErrUnknownRole = errors.New("неизвестная роль")
+	// ErrOwnerByConfig роль владельцев из ADMIN_TELEGRAM_IDS нельзя изменить из бота
+	ErrOwnerByConfig = errors.New("владелец задан в ADMIN_TELEGRAM_IDS, роль меняется только в конфигурации")
+	// ErrUserNotFound пользователь не найден
+	ErrUserNotFound = errors.New("пользователь не найден")
+	// ErrUserInsufficientBalance списание больше баланса пользователя
+	ErrUserInsufficientBalance = errors.New("недостаточно средств на балансе")
+)
+
 // userService реализация UserService
 type userService struct {
 	userRepo        repositories.UserRepository
@@ -91,6 +106,7 @@ func (s *userService) CreateOrGetUser(telegramID int64, username, firstName, las
 	}
 
 	s.logger.Info("New user created", "telegram_id", telegramID, "username", username)
+	user.IsNew = true
 	return user, nil
 }
 
@@ -121,6 +137,23 @@ func (s *userService) UpdateUser(user *models.User) error {
 	return nil
 }
 
+// SetLanguage сохраняет язык интерфейса, выбранный пользователем
+func (s *userService) SetLanguage(user *models.User, language string) error {
+	if !slices.Contains(s.config.Localization.SupportedLanguages, language) {
+		return ErrUnsupportedLanguage
+	}
+
+	// Пользователь загружен в начале обработки, поэтому сохраняем только язык:
+	// полная запись затерла бы зачисления, выполненные за это время
+	if err := s.userRepo.UpdateLanguage(user.ID, language); err != nil {
+		return err
+	}
+	user.LanguagePreference = language
+
+	s.logger.Info("User language changed", "user_id", user.ID, "language", language)
+	return nil
+}
+
 // BlockUser блокирует пользователя
 func (s *userService) BlockUser(telegramID int64) error {
 	user, err := s.userRepo.GetByTelegramID(telegramID)
@@ -209,6 +242,24 @@ func (s *userService) SubtractBalance(userID uuid.UUID, amount float64) error {
 	return nil
 }
 
+// ApplyChanges применяет изменения пользователя одной транзакцией и возвращает
+// его новое состояние. Если списание больше баланса, ничего не меняется
+func (s *userService) ApplyChanges(userID uuid.UUID, changes repositories.UserChanges) (*models.User, error) {
+	user, err := s.userRepo.ApplyChanges(userID, changes)
+	if errors.Is(err, repositories.ErrInsufficientBalance) {
+		return nil, ErrUserInsufficientBalance
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply user changes: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	s.logger.Info("User changed", "user_id", userID, "balance_delta", changes.BalanceDelta, "balance", user.Balance)
+	return user, nil
+}
+
 // DeductBalance списывает средства с баланса пользователя (алиас для SubtractBalance)
 func (s *userService) DeductBalance(userID uuid.UUID, amount float64) error {
 	return s.SubtractBalance(userID, amount)
@@ -223,6 +274,15 @@ func (s *userService) GetReferrals(userID uuid.UUID) ([]models.User, error) {
 	return referrals, nil
 }
 
+// GetUserByID получает пользователя по внутреннему ID
+func (s *userService) GetUserByID(id uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
 // SearchUsers ищет пользователей
 func (s *userService) SearchUsers(query string, limit int) ([]models.User, error) {
 	users, err := s.userRepo.Search(query, limit)
@@ -232,28 +292,69 @@ func (s *userService) SearchUsers(query string, limit int) ([]models.User, error
 	return users, nil
 }
 
-// IsAdmin проверяет, является ли пользователь администратором
+// IsAdmin проверяет, есть ли у пользователя роль в админ-панели
 func (s *userService) IsAdmin(telegramID int64) bool {
-	// Добавляем отладочную информацию
-	s.logger.Info("Checking admin rights",
-		"telegram_id", telegramID,
-		"admin_telegram_ids", s.config.Admin.TelegramIDs)
+	return s.GetRole(telegramID) != ""
+}
 
-	// Сначала проверяем ADMIN_TELEGRAM_IDS из конфигурации
-	for _, adminID := range s.config.Admin.TelegramIDs {
-		if adminID == telegramID {
-			s.logger.Info("User is admin by config", "telegram_id", telegramID)
-			return true
-		}
+// GetRole возвращает роль пользователя в админ-панели. Пользователи из
+// ADMIN_TELEGRAM_IDS всегда владельцы, остальным роль назначается в базе данных
+func (s *userService) GetRole(telegramID int64) string {
+	if s.isConfigOwner(telegramID) {
+		return models.RoleOwner
 	}
 
-	// Затем проверяем поле IsAdmin в базе данных
 	user, err := s.userRepo.GetByTelegramID(telegramID)
 	if err != nil || user == nil {
-		s.logger.Info("User not found in database", "telegram_id", telegramID, "error", err)
-		return false
+		return ""
+	}
+
+	return user.EffectiveRole()
+}
+
+// HasPermission проверяет право пользователя на действие в админ-панели
+func (s *userService) HasPermission(telegramID int64, permission string) bool {
+	role := s.GetRole(telegramID)
+	return role != "" && models.RoleHasPermission(role, permission)
+}
+
+// SetRole назначает роль пользователю; пустая роль отзывает доступ к админ-панели
+func (s *userService) SetRole(telegramID int64, role string) (*models.User, error) {
+	if role != "" && !models.IsAdminRole(role) {
+		return nil, ErrUnknownRole
+	}
+	if s.isConfigOwner(telegramID) {
+		return nil, ErrOwnerByConfig
+	}
+
+	user, err := s.userRepo.GetByTelegramID(telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.AdminRole = role
+	user.IsAdmin = role != ""
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	s.logger.Info("User role changed", "telegram_id", telegramID, "role", role)
+	return user, nil
+}
+
+// GetStaff возвращает пользователей с ролями в админ-панели
+func (s *userService) GetStaff() ([]models.User, error) {
+	users, err := s.userRepo.GetStaff()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff: %w", err)
+	}
+	return users, nil
+}
 
-	s.logger.Info("User admin status from DB", "telegram_id", telegramID, "is_admin", user.IsAdmin)
-	return user.IsAdmin
+// isConfigOwner проверяет, указан ли пользователь в ADMIN_TELEGRAM_IDS
+func (s *userService) isConfigOwner(telegramID int64) bool {
+	return slices.Contains(s.config.Admin.TelegramIDs, telegramID)
 }
@@ -7,11 +7,13 @@ import (
 	"remnawave-tg-shop/internal/config"
 	"remnawave-tg-shop/internal/logger"
 	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
 	"remnawave-tg-shop/internal/services/remnawave"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUserRepository мок для UserRepository
@@ -50,6 +52,11 @@ func (m *MockUserRepository) Update(user *models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLanguage(id uuid.UUID, language string) error {
+	args := m.Called(id, language)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -83,6 +90,22 @@ func (m *MockUserRepository) GetAll(limit, offset int) ([]models.User, error) {
 	return args.Get(0).([]models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) RewardReferral(reward repositories.ReferralReward) (bool, error) {
+	args := m.Called(reward)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ApplyChanges(id uuid.UUID, changes repositories.UserChanges) (*models.User, error) {
+	args := m.Called(id, changes)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) GetStaff() ([]models.User, error) {
+	args := m.Called()
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
 // MockLogger мок для Logger
 type MockLogger struct {
 	mock.Mock
@@ -176,6 +199,7 @@ func TestUserService_CreateOrGetUser_NewUser(t *testing.T) {
 	assert.False(t, user.IsBlocked)
 	assert.False(t, user.IsAdmin)
 	assert.Equal(t, 0.0, user.Balance)
+	assert.True(t, user.IsNew)
 
 	mockRepo.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
@@ -302,6 +326,48 @@ func TestUserService_SubtractBalance_InsufficientBalance(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserService_ApplyChanges(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &remnawave.Client{}, logger.New("error"), &config.Config{})
+
+	userID := uuid.New()
+	blocked := true
+	credit := repositories.UserChanges{IsBlocked: &blocked, BalanceDelta: 50}
+	mockRepo.On("ApplyChanges", userID, credit).Return(&models.User{ID: userID, IsBlocked: true, Balance: 150}, nil)
+
+	user, err := service.ApplyChanges(userID, credit)
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, user.Balance)
+
+	debit := repositories.UserChanges{BalanceDelta: -500}
+	mockRepo.On("ApplyChanges", userID, debit).Return(nil, repositories.ErrInsufficientBalance)
+	_, err = service.ApplyChanges(userID, debit)
+	assert.ErrorIs(t, err, ErrUserInsufficientBalance)
+
+	missing := uuid.New()
+	mockRepo.On("ApplyChanges", missing, credit).Return(nil, nil)
+	_, err = service.ApplyChanges(missing, credit)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserService_SetLanguage(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	cfg := &config.Config{}
+	cfg.Localization.SupportedLanguages = []string{"ru", "en"}
+	service := NewUserService(mockRepo, &remnawave.Client{}, logger.New("error"), cfg)
+
+	// Баланс в памяти устарел: сохраняется только язык, полная запись не выполняется
+	user := &models.User{ID: uuid.New(), Balance: 10}
+	mockRepo.On("UpdateLanguage", user.ID, "en").Return(nil).Once()
+
+	require.NoError(t, service.SetLanguage(user, "en"))
+	assert.Equal(t, "en", user.LanguagePreference)
+	assert.ErrorIs(t, service.SetLanguage(user, "de"), ErrUnsupportedLanguage)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
 func TestUserService_IsAdmin(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
@@ -319,7 +385,7 @@ func TestUserService_IsAdmin(t *testing.T) {
 		IsAdmin:    true,
 	}
 
-	mockRepo.On("GetByTelegramID", telegramID).Return(adminUser, nil)
+	mockRepo.On("GetByTelegramID", telegramID).Return(adminUser, nil).Once()
 
 	// Act
 	isAdmin := service.IsAdmin(telegramID)
@@ -333,7 +399,7 @@ func TestUserService_IsAdmin(t *testing.T) {
 		IsAdmin:    false,
 	}
 
-	mockRepo.On("GetByTelegramID", telegramID).Return(regularUser, nil)
+	mockRepo.On("GetByTelegramID", telegramID).Return(regularUser, nil).Once()
 
 	// Act
 	isAdmin = service.IsAdmin(telegramID)
@@ -343,3 +409,61 @@ func TestUserService_IsAdmin(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_RolesAndPermissions(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockConfig := &config.Config{}
+	mockConfig.Admin.TelegramIDs = []int64{1}
+	service := NewUserService(mockRepo, &remnawave.Client{}, new(MockLogger), mockConfig)
+
+	// Владелец из конфигурации не обращается к базе
+	assert.Equal(t, models.RoleOwner, service.GetRole(1))
+	assert.True(t, service.HasPermission(1, models.PermRolesManage))
+
+	support := &models.User{TelegramID: 2, IsAdmin: true, AdminRole: models.RoleSupport}
+	legacyAdmin := &models.User{TelegramID: 3, IsAdmin: true}
+	mockRepo.On("GetByTelegramID", int64(2)).Return(support, nil)
+	mockRepo.On("GetByTelegramID", int64(3)).Return(legacyAdmin, nil)
+	mockRepo.On("GetByTelegramID", int64(4)).Return(nil, nil)
+
+	assert.True(t, service.HasPermission(2, models.PermUserBlock))
+	assert.False(t, service.HasPermission(2, models.PermBalanceWrite))
+	assert.False(t, service.HasPermission(2, models.PermBroadcastSend))
+
+	assert.Equal(t, models.RoleAdmin, service.GetRole(3))
+	assert.True(t, service.HasPermission(3, models.PermBalanceWrite))
+	assert.False(t, service.HasPermission(3, models.PermRolesManage))
+
+	assert.False(t, service.IsAdmin(4))
+	assert.False(t, service.HasPermission(4, models.PermLogsRead))
+}
+
+func TestUserService_SetRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockLogger := new(MockLogger)
+	mockConfig := &config.Config{}
+	mockConfig.Admin.TelegramIDs = []int64{1}
+	service := NewUserService(mockRepo, &remnawave.Client{}, mockLogger, mockConfig)
+
+	_, err := service.SetRole(1, models.RoleSupport)
+	assert.ErrorIs(t, err, ErrOwnerByConfig)
+	_, err = service.SetRole(2, "superuser")
+	assert.ErrorIs(t, err, ErrUnknownRole)
+
+	user := &models.User{TelegramID: 2}
+	mockRepo.On("GetByTelegramID", int64(2)).Return(user, nil)
+	mockRepo.On("Update", user).Return(nil)
+	mockLogger.On("Info", "User role changed", "telegram_id", int64(2), "role", mock.Anything).Return()
+
+	updated, err := service.SetRole(2, models.RoleFinance)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleFinance, updated.AdminRole)
+	assert.True(t, updated.IsAdmin)
+
+	updated, err = service.SetRole(2, "")
+	require.NoError(t, err)
+	assert.Empty(t, updated.AdminRole)
+	assert.False(t, updated.IsAdmin)
+
+	mockRepo.AssertExpectations(t)
+}
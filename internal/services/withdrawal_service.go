@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+	"remnawave-tg-shop/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// withdrawalQueueStatuses статусы заявок, ожидающих действий администратора
+var withdrawalQueueStatuses = []string{models.WithdrawalStatusPending, models.WithdrawalStatusApproved}
+
+// withdrawalTransitions допустимые переходы статусов: новый статус -> из каких статусов
+var withdrawalTransitions = map[string][]string{
+	models.WithdrawalStatusApproved: {models.WithdrawalStatusPending},
+	models.WithdrawalStatusRejected: {models.WithdrawalStatusPending, models.WithdrawalStatusApproved},
+	models.WithdrawalStatusPaid:     {models.WithdrawalStatusApproved},
+}
+
+var (
+	// ErrWithdrawalInvalidMethod неизвестный способ выплаты
+	ErrWithdrawalInvalidMethod = errors.New("invalid withdrawal method")
+	// ErrWithdrawalBelowMinimum сумма меньше минимальной
+	ErrWithdrawalBelowMinimum = errors.New("withdrawal amount is below minimum")
+	// ErrWithdrawalEmptyDetails не указаны реквизиты
+	ErrWithdrawalEmptyDetails = errors.New("withdrawal details are required")
+	// ErrWithdrawalInsufficientFunds недостаточно доступных средств
+	ErrWithdrawalInsufficientFunds = errors.New("insufficient available funds")
+	// ErrWithdrawalNotFound заявка не найдена
+	ErrWithdrawalNotFound = errors.New("withdrawal request not found")
+	// ErrWithdrawalInvalidTransition заявка уже обработана или переход недопустим
+	ErrWithdrawalInvalidTransition = errors.New("withdrawal request cannot be moved to this status")
+)
+
+type WithdrawalService struct {
+	repo                repositories.WithdrawalRepository
+	notificationService INotificationService
+	activityLogService  IActivityLogService
+	config              *config.Config
+	logger              logger.Logger
+}
+
+func NewWithdrawalService(
+	repo repositories.WithdrawalRepository,
+	notificationService INotificationService,
+	activityLogService IActivityLogService,
+	config *config.Config,
+	log logger.Logger,
+) *WithdrawalService {
+	return &WithdrawalService{
+		repo:                repo,
+		notificationService: notificationService,
+		activityLogService:  activityLogService,
+		config:              config,
+		logger:              log,
+	}
+}
+
+// MinAmount возвращает минимальную сумму заявки
+func (s *WithdrawalService) MinAmount() float64 {
+	return s.config.Partner.MinWithdrawal
+}
+
+// CreateRequest создает заявку на вывод и замораживает ее сумму
+func (s *WithdrawalService) CreateRequest(userID uuid.UUID, amount float64, method, details string) (*models.WithdrawalRequest, error) {
+	if method != "card" && method != "crypto" {
+		return nil, ErrWithdrawalInvalidMethod
+	}
+	if amount <= 0 || amount < s.config.Partner.MinWithdrawal {
+		return nil, ErrWithdrawalBelowMinimum
+	}
+	details = strings.TrimSpace(details)
+	if details == "" {
+		return nil, ErrWithdrawalEmptyDetails
+	}
+
+	request := &models.WithdrawalRequest{
+		UserID:  userID,
+		Amount:  amount,
+		Method:  method,
+		Details: details,
+		Status:  models.WithdrawalStatusPending,
+	}
+
+	created, err := s.repo.CreateIfAvailable(request, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrWithdrawalInsufficientFunds
+	}
+
+	s.audit(userID, request, "", "")
+	s.logger.Info("Withdrawal request created", "request_id", request.ID, "user_id", userID, "amount", amount)
+	return request, nil
+}
+
+// GetRequest получает заявку по ID
+func (s *WithdrawalService) GetRequest(id uuid.UUID) (*models.WithdrawalRequest, error) {
+	request, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, ErrWithdrawalNotFound
+	}
+	return request, nil
+}
+
+// GetUserRequests получает заявки пользователя
+func (s *WithdrawalService) GetUserRequests(userID uuid.UUID, limit, offset int) ([]models.WithdrawalRequest, error) {
+	return s.repo.GetByUserID(userID, limit, offset)
+}
+
+// GetQueue получает очередь заявок, ожидающих обработки
+func (s *WithdrawalService) GetQueue(limit, offset int) ([]models.WithdrawalRequest, error) {
+	return s.repo.GetByStatuses(withdrawalQueueStatuses, limit, offset)
+}
+
+// CountQueue возвращает количество заявок в очереди
+func (s *WithdrawalService) CountQueue() (int64, error) {
+	return s.repo.CountByStatuses(withdrawalQueueStatuses)
+}
+
+// Approve одобряет заявку
+func (s *WithdrawalService) Approve(id, adminID uuid.UUID) (*models.WithdrawalRequest, error) {
+	return s.transition(id, adminID, models.WithdrawalStatusApproved, "")
+}
+
+// Reject отклоняет заявку и размораживает ее сумму
+func (s *WithdrawalService) Reject(id, adminID uuid.UUID, reason string) (*models.WithdrawalRequest, error) {
+	return s.transition(id, adminID, models.WithdrawalStatusRejected, strings.TrimSpace(reason))
+}
+
+// MarkPaid отмечает заявку выплаченной
+func (s *WithdrawalService) MarkPaid(id, adminID uuid.UUID) (*models.WithdrawalRequest, error) {
+	return s.transition(id, adminID, models.WithdrawalStatusPaid, "")
+}
+
+// transition переводит заявку в новый статус. Обновление условное, поэтому
+// два администратора не могут обработать одну заявку дважды
+func (s *WithdrawalService) transition(id, adminID uuid.UUID, status, comment string) (*models.WithdrawalRequest, error) {
+	request, err := s.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatuses := withdrawalTransitions[status]
+	fromStatus := request.Status
+
+	now := time.Now()
+	request.Status = status
+	request.AdminID = &adminID
+	if comment != "" {
+		request.Comment = comment
+	}
+	if status == models.WithdrawalStatusPaid {
+		request.PaidAt = &now
+	} else {
+		request.ProcessedAt = &now
+	}
+
+	updated, err := s.repo.UpdateStatus(request, fromStatuses)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrWithdrawalInvalidTransition
+	}
+
+	s.audit(adminID, request, fromStatus, comment)
+	s.logger.Info("Withdrawal request updated", "request_id", request.ID, "from", fromStatus, "to", status, "admin_id", adminID)
+	s.notifyUser(request)
+	return request, nil
+}
+
+// audit записывает изменение статуса в журнал активности
+func (s *WithdrawalService) audit(actorID uuid.UUID, request *models.WithdrawalRequest, fromStatus, comment string) {
+	if err := s.activityLogService.LogWithdrawal(actorID, request.ID, fromStatus, request.Status, comment, "", ""); err != nil {
+		s.logger.Error("Failed to log withdrawal", "error", err, "request_id", request.ID)
+	}
+}
+
+// notifyUser сообщает пользователю об изменении статуса заявки
+func (s *WithdrawalService) notifyUser(request *models.WithdrawalRequest) {
+	key := "notifications.withdrawal_" + request.Status
+	args := []any{"amount", request.Amount}
+	if request.Status == models.WithdrawalStatusRejected && request.Comment != "" {
+		key = "notifications.withdrawal_rejected_reason"
+		args = append(args, "reason", request.Comment)
+	}
+
+	if err := s.notificationService.SendToUser(request.UserID, "withdrawal", key, args...); err != nil {
+		s.logger.Error("Failed to notify user about withdrawal", "error", err, "user_id", request.UserID)
+	}
+}
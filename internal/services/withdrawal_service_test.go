@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remnawave-tg-shop/internal/config"
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWithdrawalRepository мок для WithdrawalRepository
+type MockWithdrawalRepository struct {
+	mock.Mock
+}
+
+func (m *MockWithdrawalRepository) CreateIfAvailable(request *models.WithdrawalRequest, at time.Time) (bool, error) {
+	args := m.Called(request, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) GetByID(id uuid.UUID) (*models.WithdrawalRequest, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WithdrawalRequest), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]models.WithdrawalRequest, error) {
+	args := m.Called(userID, limit, offset)
+	return args.Get(0).([]models.WithdrawalRequest), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) GetByStatuses(statuses []string, limit, offset int) ([]models.WithdrawalRequest, error) {
+	args := m.Called(statuses, limit, offset)
+	return args.Get(0).([]models.WithdrawalRequest), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) CountByStatuses(statuses []string) (int64, error) {
+	args := m.Called(statuses)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) UpdateStatus(request *models.WithdrawalRequest, fromStatuses []string) (bool, error) {
+	args := m.Called(request, fromStatuses)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWithdrawalRepository) SumByStatuses(userID uuid.UUID, statuses []string) (float64, error) {
+	args := m.Called(userID, statuses)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockActivityLogService) LogWithdrawal(userID uuid.UUID, requestID uuid.UUID, fromStatus, toStatus, comment string, ipAddress, userAgent string) error {
+	args := m.Called(userID, requestID, fromStatus, toStatus)
+	return args.Error(0)
+}
+
+func newTestWithdrawalService(repo *MockWithdrawalRepository, notifications *MockNotificationService, activity *MockActivityLogService) *WithdrawalService {
+	cfg := &config.Config{}
+	cfg.Partner = config.PartnerConfig{Enabled: true, MinWithdrawal: 500}
+	return NewWithdrawalService(repo, notifications, activity, cfg, logger.New("error"))
+}
+
+func TestWithdrawalService_CreateRequest(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("validation", func(t *testing.T) {
+		service := newTestWithdrawalService(new(MockWithdrawalRepository), nil, nil)
+
+		_, err := service.CreateRequest(userID, 1000, "paypal", "x")
+		assert.ErrorIs(t, err, ErrWithdrawalInvalidMethod)
+		_, err = service.CreateRequest(userID, 100, "card", "4111")
+		assert.ErrorIs(t, err, ErrWithdrawalBelowMinimum)
+		_, err = service.CreateRequest(userID, 1000, "crypto", "  ")
+		assert.ErrorIs(t, err, ErrWithdrawalEmptyDetails)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		repo := new(MockWithdrawalRepository)
+		repo.On("CreateIfAvailable", mock.AnythingOfType("*models.WithdrawalRequest"), mock.AnythingOfType("time.Time")).Return(false, nil)
+		service := newTestWithdrawalService(repo, nil, nil)
+
+		_, err := service.CreateRequest(userID, 1000, "card", "4111 1111 1111 1111")
+		assert.ErrorIs(t, err, ErrWithdrawalInsufficientFunds)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		repo := new(MockWithdrawalRepository)
+		repo.On("CreateIfAvailable", mock.AnythingOfType("*models.WithdrawalRequest"), mock.AnythingOfType("time.Time")).Return(true, nil)
+		activity := new(MockActivityLogService)
+		activity.On("LogWithdrawal", userID, mock.Anything, "", models.WithdrawalStatusPending).Return(nil)
+		service := newTestWithdrawalService(repo, nil, activity)
+
+		request, err := service.CreateRequest(userID, 1000, "card", " 4111 1111 1111 1111 ")
+		require.NoError(t, err)
+		assert.Equal(t, models.WithdrawalStatusPending, request.Status)
+		assert.Equal(t, "4111 1111 1111 1111", request.Details)
+		activity.AssertExpectations(t)
+	})
+}
+
+func TestWithdrawalService_Transitions(t *testing.T) {
+	adminID := uuid.New()
+	request := &models.WithdrawalRequest{ID: uuid.New(), UserID: uuid.New(), Amount: 1000, Status: models.WithdrawalStatusPending}
+
+	repo := new(MockWithdrawalRepository)
+	repo.On("GetByID", request.ID).Return(request, nil)
+	repo.On("UpdateStatus", request, []string{models.WithdrawalStatusPending}).Return(true, nil).Once()
+	repo.On("UpdateStatus", request, []string{models.WithdrawalStatusApproved}).Return(true, nil).Once()
+
+	activity := new(MockActivityLogService)
+	activity.On("LogWithdrawal", adminID, request.ID, models.WithdrawalStatusPending, models.WithdrawalStatusApproved).Return(nil)
+	activity.On("LogWithdrawal", adminID, request.ID, models.WithdrawalStatusApproved, models.WithdrawalStatusPaid).Return(nil)
+
+	notifications := new(MockNotificationService)
+	notifications.On("SendToUser", request.UserID, "withdrawal", mock.Anything, mock.Anything).Return(nil)
+
+	service := newTestWithdrawalService(repo, notifications, activity)
+
+	approved, err := service.Approve(request.ID, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WithdrawalStatusApproved, approved.Status)
+	require.NotNil(t, approved.ProcessedAt)
+
+	paid, err := service.MarkPaid(request.ID, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WithdrawalStatusPaid, paid.Status)
+	require.NotNil(t, paid.PaidAt)
+
+	activity.AssertExpectations(t)
+	notifications.AssertNumberOfCalls(t, "SendToUser", 2)
+}
+
+func TestWithdrawalService_AlreadyProcessed(t *testing.T) {
+	request := &models.WithdrawalRequest{ID: uuid.New(), UserID: uuid.New(), Amount: 1000, Status: models.WithdrawalStatusRejected}
+
+	repo := new(MockWithdrawalRepository)
+	repo.On("GetByID", request.ID).Return(request, nil)
+	repo.On("UpdateStatus", request, []string{models.WithdrawalStatusApproved}).Return(false, nil)
+
+	service := newTestWithdrawalService(repo, nil, nil)
+
+	_, err := service.MarkPaid(request.ID, uuid.New())
+	assert.ErrorIs(t, err, ErrWithdrawalInvalidTransition)
+}
@@ -0,0 +1,345 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"remnawave-tg-shop/internal/logger"
+	"remnawave-tg-shop/internal/metrics"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRetries максимальное количество повторов при ответе 429
+	maxRetries = 3
+	// defaultRateLimit лимит запросов в секунду по умолчанию
+	defaultRateLimit = 25
+)
+
+// Client реализация Messenger поверх одного долгоживущего telego.Bot
+// с глобальным ограничением частоты запросов
+type Client struct {
+	bot     *telego.Bot
+	self    *telego.User
+	limiter *rate.Limiter
+	logger  logger.Logger
+}
+
+// NewClient создает новый Client и проверяет токен через getMe
+func NewClient(token string, ratePerSecond int, log logger.Logger, opts ...telego.BotOption) (*Client, error) {
+	bot, err := telego.NewBot(token, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
+	}
+
+	if ratePerSecond <= 0 {
+		ratePerSecond = defaultRateLimit
+	}
+
+	client := &Client{
+		bot:     bot,
+		limiter: rate.NewLimiter(rate.Limit(ratePerSecond), ratePerSecond),
+		logger:  log,
+	}
+
+	err = client.do("getMe", func() error {
+		self, err := bot.GetMe()
+		if err != nil {
+			return err
+		}
+		client.self = self
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot info: %w", err)
+	}
+
+	return client, nil
+}
+
+// Bot возвращает нижележащий telego.Bot
+func (c *Client) Bot() *telego.Bot {
+	return c.bot
+}
+
+// Username возвращает username бота
+func (c *Client) Username() string {
+	return c.self.Username
+}
+
+// Health проверяет доступность Bot API запросом getMe
+func (c *Client) Health() error {
+	return c.do("getMe", func() error {
+		_, err := c.bot.GetMe()
+		return err
+	})
+}
+
+// SendMessage отправляет текстовое сообщение
+func (c *Client) SendMessage(chatID int64, text string, opts *MessageOptions) (*telego.Message, error) {
+	params := &telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   text,
+	}
+	if opts != nil {
+		params.ParseMode = opts.ParseMode
+		params.LinkPreviewOptions = linkPreviewOptions(opts)
+		if opts.Keyboard != nil {
+			params.ReplyMarkup = opts.Keyboard
+		}
+	}
+
+	var msg *telego.Message
+	err := c.do("sendMessage", func() (err error) {
+		msg, err = c.bot.SendMessage(params)
+		return err
+	})
+	return msg, err
+}
+
+// EditMessageText редактирует текст сообщения
+func (c *Client) EditMessageText(chatID int64, messageID int, text string, opts *MessageOptions) (*telego.Message, error) {
+	params := &telego.EditMessageTextParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: messageID,
+		Text:      text,
+	}
+	if opts != nil {
+		params.ParseMode = opts.ParseMode
+		params.LinkPreviewOptions = linkPreviewOptions(opts)
+		params.ReplyMarkup = opts.Keyboard
+	}
+
+	var msg *telego.Message
+	err := c.do("editMessageText", func() (err error) {
+		msg, err = c.bot.EditMessageText(params)
+		return err
+	})
+	return msg, err
+}
+
+// EditMessageReplyMarkup заменяет клавиатуру сообщения
+func (c *Client) EditMessageReplyMarkup(chatID int64, messageID int, keyboard *telego.InlineKeyboardMarkup) error {
+	params := &telego.EditMessageReplyMarkupParams{
+		ChatID:      telego.ChatID{ID: chatID},
+		MessageID:   messageID,
+		ReplyMarkup: keyboard,
+	}
+
+	return c.do("editMessageReplyMarkup", func() error {
+		_, err := c.bot.EditMessageReplyMarkup(params)
+		return err
+	})
+}
+
+// AnswerCallbackQuery отвечает на callback query
+func (c *Client) AnswerCallbackQuery(callbackQueryID, text string, showAlert bool) error {
+	params := &telego.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQueryID,
+		Text:            text,
+		ShowAlert:       showAlert,
+	}
+
+	return c.do("answerCallbackQuery", func() error {
+		return c.bot.AnswerCallbackQuery(params)
+	})
+}
+
+// SendPhoto отправляет фотографию
+func (c *Client) SendPhoto(chatID int64, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	params := &telego.SendPhotoParams{
+		ChatID:  telego.ChatID{ID: chatID},
+		Photo:   photo,
+		Caption: caption,
+	}
+	if opts != nil {
+		params.ParseMode = opts.ParseMode
+		if opts.Keyboard != nil {
+			params.ReplyMarkup = opts.Keyboard
+		}
+	}
+
+	var msg *telego.Message
+	err := c.do("sendPhoto", func() (err error) {
+		msg, err = c.bot.SendPhoto(params)
+		return err
+	})
+	return msg, err
+}
+
+// EditMessagePhoto заменяет фотографию и подпись сообщения
+func (c *Client) EditMessagePhoto(chatID int64, messageID int, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	media := &telego.InputMediaPhoto{
+		Type:    telego.MediaTypePhoto,
+		Media:   photo,
+		Caption: caption,
+	}
+	params := &telego.EditMessageMediaParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: messageID,
+		Media:     media,
+	}
+	if opts != nil {
+		media.ParseMode = opts.ParseMode
+		if opts.Keyboard != nil {
+			params.ReplyMarkup = opts.Keyboard
+		}
+	}
+
+	var msg *telego.Message
+	err := c.do("editMessageMedia", func() (err error) {
+		msg, err = c.bot.EditMessageMedia(params)
+		return err
+	})
+	return msg, err
+}
+
+// SendDocument отправляет документ
+func (c *Client) SendDocument(chatID int64, document telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	params := &telego.SendDocumentParams{
+		ChatID:   telego.ChatID{ID: chatID},
+		Document: document,
+		Caption:  caption,
+	}
+	if opts != nil {
+		params.ParseMode = opts.ParseMode
+		if opts.Keyboard != nil {
+			params.ReplyMarkup = opts.Keyboard
+		}
+	}
+
+	var msg *telego.Message
+	err := c.do("sendDocument", func() (err error) {
+		msg, err = c.bot.SendDocument(params)
+		return err
+	})
+	return msg, err
+}
+
+// SendInvoice отправляет счет на оплату
+func (c *Client) SendInvoice(chatID int64, invoice *Invoice) (*telego.Message, error) {
+	params := &telego.SendInvoiceParams{
+		ChatID:        telego.ChatID{ID: chatID},
+		Title:         invoice.Title,
+		Description:   invoice.Description,
+		Payload:       invoice.Payload,
+		ProviderToken: invoice.ProviderToken,
+		Currency:      invoice.Currency,
+		Prices:        invoice.Prices,
+	}
+
+	var msg *telego.Message
+	err := c.do("sendInvoice", func() (err error) {
+		msg, err = c.bot.SendInvoice(params)
+		return err
+	})
+	return msg, err
+}
+
+// CreateInvoiceLink создает ссылку на счет, которую Mini App открывает через openInvoice
+func (c *Client) CreateInvoiceLink(invoice *Invoice) (string, error) {
+	params := &telego.CreateInvoiceLinkParams{
+		Title:         invoice.Title,
+		Description:   invoice.Description,
+		Payload:       invoice.Payload,
+		ProviderToken: invoice.ProviderToken,
+		Currency:      invoice.Currency,
+		Prices:        invoice.Prices,
+	}
+
+	var link *string
+	err := c.do("createInvoiceLink", func() (err error) {
+		link, err = c.bot.CreateInvoiceLink(params)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return *link, nil
+}
+
+// AnswerPreCheckoutQuery подтверждает или отклоняет оплату перед списанием
+func (c *Client) AnswerPreCheckoutQuery(queryID string, ok bool, errorMessage string) error {
+	params := &telego.AnswerPreCheckoutQueryParams{
+		PreCheckoutQueryID: queryID,
+		Ok:                 ok,
+		ErrorMessage:       errorMessage,
+	}
+
+	return c.do("answerPreCheckoutQuery", func() error {
+		return c.bot.AnswerPreCheckoutQuery(params)
+	})
+}
+
+// SetWebhook регистрирует webhook с секретным токеном
+func (c *Client) SetWebhook(webhookURL, secretToken string) error {
+	params := &telego.SetWebhookParams{
+		URL:         webhookURL,
+		SecretToken: secretToken,
+	}
+
+	return c.do("setWebhook", func() error {
+		return c.bot.SetWebhook(params)
+	})
+}
+
+// DeleteWebhook снимает webhook перед переходом на long polling
+func (c *Client) DeleteWebhook() error {
+	return c.do("deleteWebhook", func() error {
+		return c.bot.DeleteWebhook(&telego.DeleteWebhookParams{})
+	})
+}
+
+// do выполняет запрос с учетом лимита и повторяет его при ответе 429
+func (c *Client) do(method string, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(context.Background()); err != nil {
+			return err
+		}
+
+		err := call()
+		retryAfter, limited := retryAfterFromError(err)
+		metrics.ObserveTelegramRequest(method, err, limited)
+		if !limited || attempt >= maxRetries {
+			return err
+		}
+
+		c.logger.Warn("Telegram rate limit exceeded", "method", method, "retry_after", retryAfter, "attempt", attempt+1)
+		time.Sleep(retryAfter)
+	}
+}
+
+// retryAfterFromError извлекает время ожидания из ошибки 429
+func retryAfterFromError(err error) (time.Duration, bool) {
+	var apiErr *telegoapi.Error
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode != 429 {
+		return 0, false
+	}
+
+	retryAfter := time.Second
+	if apiErr.Parameters != nil {
+		retryAfter = time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+	}
+	return retryAfter, true
+}
+
+// linkPreviewOptions формирует параметры превью ссылок
+func linkPreviewOptions(opts *MessageOptions) *telego.LinkPreviewOptions {
+	if !opts.DisableWebPagePreview {
+		return nil
+	}
+	return &telego.LinkPreviewOptions{IsDisabled: true}
+}
+
+// IsMessageNotModified проверяет, что Telegram отклонил редактирование,
+// потому что содержимое сообщения не изменилось
+func IsMessageNotModified(err error) bool {
+	var apiErr *telegoapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message is not modified")
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"remnawave-tg-shop/internal/logger"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "123456789:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+
+// fakeCaller отвечает на запросы к Bot API заранее заданными ответами
+type fakeCaller struct {
+	mu        sync.Mutex
+	calls     []string
+	responses map[string][]*telegoapi.Response
+}
+
+func (c *fakeCaller) Call(url string, _ *telegoapi.RequestData) (*telegoapi.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	method := url[strings.LastIndex(url, "/")+1:]
+	c.calls = append(c.calls, method)
+
+	queue := c.responses[method]
+	if len(queue) == 0 {
+		return &telegoapi.Response{Ok: true, Result: json.RawMessage(`true`)}, nil
+	}
+	resp := queue[0]
+	if len(queue) > 1 {
+		c.responses[method] = queue[1:]
+	}
+	return resp, nil
+}
+
+func (c *fakeCaller) count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, call := range c.calls {
+		if call == method {
+			n++
+		}
+	}
+	return n
+}
+
+func okResponse(result string) *telegoapi.Response {
+	return &telegoapi.Response{Ok: true, Result: json.RawMessage(result)}
+}
+
+func tooManyRequests() *telegoapi.Response {
+	return &telegoapi.Response{
+		Ok: false,
+		Error: &telegoapi.Error{
+			ErrorCode:   429,
+			Description: "Too Many Requests: retry after 0",
+			Parameters:  &telegoapi.ResponseParameters{RetryAfter: 0},
+		},
+	}
+}
+
+func newTestClient(t *testing.T, caller *fakeCaller) *Client {
+	t.Helper()
+
+	caller.responses["getMe"] = []*telegoapi.Response{okResponse(`{"id":1,"is_bot":true,"first_name":"Shop","username":"shop_bot"}`)}
+
+	client, err := NewClient(testToken, 1000, logger.New("error"), telego.WithAPICaller(caller), telego.WithDiscardLogger())
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_Username(t *testing.T) {
+	caller := &fakeCaller{responses: map[string][]*telegoapi.Response{}}
+	client := newTestClient(t, caller)
+
+	assert.Equal(t, "shop_bot", client.Username())
+}
+
+func TestClient_SendMessage_RetriesOnTooManyRequests(t *testing.T) {
+	caller := &fakeCaller{responses: map[string][]*telegoapi.Response{}}
+	client := newTestClient(t, caller)
+
+	caller.responses["sendMessage"] = []*telegoapi.Response{
+		tooManyRequests(),
+		okResponse(`{"message_id":42,"date":0,"chat":{"id":7,"type":"private"},"text":"hi"}`),
+	}
+
+	msg, err := client.SendMessage(7, "hi", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 42, msg.MessageID)
+	assert.Equal(t, 2, caller.count("sendMessage"))
+}
+
+func TestClient_SendMessage_GivesUpAfterMaxRetries(t *testing.T) {
+	caller := &fakeCaller{responses: map[string][]*telegoapi.Response{}}
+	client := newTestClient(t, caller)
+
+	caller.responses["sendMessage"] = []*telegoapi.Response{tooManyRequests()}
+
+	_, err := client.SendMessage(7, "hi", nil)
+	assert.Error(t, err)
+	assert.Equal(t, maxRetries+1, caller.count("sendMessage"))
+}
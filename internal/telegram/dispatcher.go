@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"sync"
+
+	"remnawave-tg-shop/internal/logger"
+
+	"github.com/mymmrac/telego"
+)
+
+// UpdateHandler обрабатывает одно обновление
+type UpdateHandler func(update telego.Update)
+
+// ChatIDFunc возвращает ключ упорядочивания для обновления
+type ChatIDFunc func(update telego.Update) int64
+
+// Dispatcher обрабатывает обновления параллельно, сохраняя порядок
+// внутри одного чата: обновления чата всегда попадают в один и тот же воркер
+type Dispatcher struct {
+	handler UpdateHandler
+	chatID  ChatIDFunc
+	logger  logger.Logger
+	queues  []chan telego.Update
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewDispatcher создает новый Dispatcher с указанным числом воркеров
+func NewDispatcher(workers int, handler UpdateHandler, chatID ChatIDFunc, log logger.Logger) *Dispatcher {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	d := &Dispatcher{
+		handler: handler,
+		chatID:  chatID,
+		logger:  log,
+		queues:  make([]chan telego.Update, workers),
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan telego.Update, 64)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+
+	return d
+}
+
+// Dispatch ставит обновление в очередь воркера его чата
+func (d *Dispatcher) Dispatch(update telego.Update) {
+	key := d.chatID(update)
+	if key < 0 {
+		key = -key
+	}
+	d.queues[key%int64(len(d.queues))] <- update
+}
+
+// Stop дожидается обработки поставленных в очередь обновлений
+func (d *Dispatcher) Stop() {
+	d.once.Do(func() {
+		for _, queue := range d.queues {
+			close(queue)
+		}
+		d.wg.Wait()
+	})
+}
+
+// work последовательно обрабатывает очередь воркера
+func (d *Dispatcher) work(queue <-chan telego.Update) {
+	defer d.wg.Done()
+
+	for update := range queue {
+		d.handle(update)
+	}
+}
+
+// handle обрабатывает обновление, не давая панике остановить воркер
+func (d *Dispatcher) handle(update telego.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Error("Panic while handling update", "update_id", update.UpdateID, "panic", r)
+		}
+	}()
+
+	d.handler(update)
+}
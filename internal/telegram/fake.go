@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"sync"
+
+	"github.com/mymmrac/telego"
+)
+
+// SentMessage запись об отправленном через FakeMessenger сообщении
+type SentMessage struct {
+	Method          string
+	ChatID          int64
+	MessageID       int
+	Text            string
+	Options         *MessageOptions
+	Keyboard        *telego.InlineKeyboardMarkup
+	CallbackQueryID string
+	ShowAlert       bool
+	File            telego.InputFile
+	Invoice         *Invoice
+	// PreCheckoutOK ответ на pre_checkout_query
+	PreCheckoutOK bool
+}
+
+// FakeMessenger реализация Messenger в памяти для тестов
+type FakeMessenger struct {
+	mu            sync.Mutex
+	sent          []SentMessage
+	nextMessageID int
+
+	// Err возвращается всеми методами, если задана
+	Err error
+	// EditErr возвращается методами редактирования, если задана
+	EditErr error
+}
+
+// NewFakeMessenger создает новый FakeMessenger
+func NewFakeMessenger() *FakeMessenger {
+	return &FakeMessenger{}
+}
+
+// Sent возвращает копию всех записанных сообщений
+func (f *FakeMessenger) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sent := make([]SentMessage, len(f.sent))
+	copy(sent, f.sent)
+	return sent
+}
+
+// Last возвращает последнее записанное сообщение
+func (f *FakeMessenger) Last() (SentMessage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.sent) == 0 {
+		return SentMessage{}, false
+	}
+	return f.sent[len(f.sent)-1], true
+}
+
+// Reset очищает записанные сообщения
+func (f *FakeMessenger) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent = nil
+}
+
+// SendMessage записывает отправку текстового сообщения
+func (f *FakeMessenger) SendMessage(chatID int64, text string, opts *MessageOptions) (*telego.Message, error) {
+	return f.record(SentMessage{Method: "sendMessage", ChatID: chatID, Text: text, Options: opts})
+}
+
+// EditMessageText записывает редактирование текста сообщения
+func (f *FakeMessenger) EditMessageText(chatID int64, messageID int, text string, opts *MessageOptions) (*telego.Message, error) {
+	if f.EditErr != nil {
+		return nil, f.EditErr
+	}
+	return f.record(SentMessage{Method: "editMessageText", ChatID: chatID, MessageID: messageID, Text: text, Options: opts})
+}
+
+// EditMessageReplyMarkup записывает замену клавиатуры
+func (f *FakeMessenger) EditMessageReplyMarkup(chatID int64, messageID int, keyboard *telego.InlineKeyboardMarkup) error {
+	if f.EditErr != nil {
+		return f.EditErr
+	}
+	_, err := f.record(SentMessage{Method: "editMessageReplyMarkup", ChatID: chatID, MessageID: messageID, Keyboard: keyboard})
+	return err
+}
+
+// AnswerCallbackQuery записывает ответ на callback query
+func (f *FakeMessenger) AnswerCallbackQuery(callbackQueryID, text string, showAlert bool) error {
+	_, err := f.record(SentMessage{Method: "answerCallbackQuery", CallbackQueryID: callbackQueryID, Text: text, ShowAlert: showAlert})
+	return err
+}
+
+// SendPhoto записывает отправку фотографии
+func (f *FakeMessenger) SendPhoto(chatID int64, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	return f.record(SentMessage{Method: "sendPhoto", ChatID: chatID, Text: caption, Options: opts, File: photo})
+}
+
+// EditMessagePhoto записывает замену фотографии сообщения
+func (f *FakeMessenger) EditMessagePhoto(chatID int64, messageID int, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	if f.EditErr != nil {
+		return nil, f.EditErr
+	}
+	return f.record(SentMessage{Method: "editMessageMedia", ChatID: chatID, MessageID: messageID, Text: caption, Options: opts, File: photo})
+}
+
+// SendDocument записывает отправку документа
+func (f *FakeMessenger) SendDocument(chatID int64, document telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error) {
+	return f.record(SentMessage{Method: "sendDocument", ChatID: chatID, Text: caption, Options: opts, File: document})
+}
+
+// SendInvoice записывает отправку счета
+func (f *FakeMessenger) SendInvoice(chatID int64, invoice *Invoice) (*telego.Message, error) {
+	return f.record(SentMessage{Method: "sendInvoice", ChatID: chatID, Invoice: invoice})
+}
+
+// CreateInvoiceLink записывает создание ссылки на счет и возвращает ссылку с payload
+func (f *FakeMessenger) CreateInvoiceLink(invoice *Invoice) (string, error) {
+	if _, err := f.record(SentMessage{Method: "createInvoiceLink", Invoice: invoice}); err != nil {
+		return "", err
+	}
+	return "https://t.me/$invoice-" + invoice.Payload, nil
+}
+
+// AnswerPreCheckoutQuery записывает ответ на pre_checkout_query
+func (f *FakeMessenger) AnswerPreCheckoutQuery(queryID string, ok bool, errorMessage string) error {
+	_, err := f.record(SentMessage{Method: "answerPreCheckoutQuery", CallbackQueryID: queryID, Text: errorMessage, PreCheckoutOK: ok})
+	return err
+}
+
+// record сохраняет сообщение и возвращает его как отправленное
+func (f *FakeMessenger) record(msg SentMessage) (*telego.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if msg.Options != nil && msg.Keyboard == nil {
+		msg.Keyboard = msg.Options.Keyboard
+	}
+	f.sent = append(f.sent, msg)
+
+	messageID := msg.MessageID
+	if messageID == 0 {
+		f.nextMessageID++
+		messageID = f.nextMessageID
+	}
+
+	return &telego.Message{
+		MessageID: messageID,
+		Chat:      telego.Chat{ID: msg.ChatID},
+		Text:      msg.Text,
+	}, nil
+}
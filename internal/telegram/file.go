@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"io"
+	"os"
+
+	"github.com/mymmrac/telego"
+)
+
+// FileFromBytes создает файл для отправки из данных в памяти.
+// Файл перематывается после прочтения, поэтому переживает повтор запроса при 429
+func FileFromBytes(name string, data []byte) telego.InputFile {
+	return telego.InputFile{File: &bytesFile{name: name, data: data}}
+}
+
+// bytesFile файл в памяти, который можно прочитать несколько раз
+type bytesFile struct {
+	name   string
+	data   []byte
+	offset int
+}
+
+// Read читает данные файла и после конца перематывает его в начало
+func (f *bytesFile) Read(p []byte) (int, error) {
+	if f.offset >= len(f.data) {
+		f.offset = 0
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[f.offset:])
+	f.offset += n
+	return n, nil
+}
+
+// Name возвращает имя файла
+func (f *bytesFile) Name() string {
+	return f.name
+}
+
+// FileFromDisk создает файл для отправки из открытого файла на диске без
+// чтения его в память. Файл перематывается после прочтения, как и FileFromBytes
+func FileFromDisk(name string, file *os.File) telego.InputFile {
+	return telego.InputFile{File: &diskFile{name: name, file: file}}
+}
+
+// diskFile файл на диске, который можно прочитать несколько раз
+type diskFile struct {
+	name string
+	file *os.File
+}
+
+// Read читает файл и после конца перематывает его в начало
+func (f *diskFile) Read(p []byte) (int, error) {
+	n, err := f.file.Read(p)
+	if err == io.EOF {
+		if _, seekErr := f.file.Seek(0, io.SeekStart); seekErr != nil {
+			return n, seekErr
+		}
+	}
+	return n, err
+}
+
+// Name возвращает имя файла
+func (f *diskFile) Name() string {
+	return f.name
+}
@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/mymmrac/telego"
+)
+
+// Messenger интерфейс для отправки сообщений в Telegram
+type Messenger interface {
+	SendMessage(chatID int64, text string, opts *MessageOptions) (*telego.Message, error)
+	EditMessageText(chatID int64, messageID int, text string, opts *MessageOptions) (*telego.Message, error)
+	EditMessageReplyMarkup(chatID int64, messageID int, keyboard *telego.InlineKeyboardMarkup) error
+	AnswerCallbackQuery(callbackQueryID, text string, showAlert bool) error
+	SendPhoto(chatID int64, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error)
+	EditMessagePhoto(chatID int64, messageID int, photo telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error)
+	SendDocument(chatID int64, document telego.InputFile, caption string, opts *MessageOptions) (*telego.Message, error)
+	SendInvoice(chatID int64, invoice *Invoice) (*telego.Message, error)
+	CreateInvoiceLink(invoice *Invoice) (string, error)
+	AnswerPreCheckoutQuery(queryID string, ok bool, errorMessage string) error
+}
+
+// MessageOptions дополнительные параметры сообщения
+type MessageOptions struct {
+	ParseMode             string
+	Keyboard              *telego.InlineKeyboardMarkup
+	DisableWebPagePreview bool
+}
+
+// Invoice параметры счета на оплату
+type Invoice struct {
+	Title         string
+	Description   string
+	Payload       string
+	ProviderToken string
+	Currency      string
+	Prices        []telego.LabeledPrice
+}
+
+// HTML возвращает параметры сообщения с разметкой HTML
+func HTML(keyboard *telego.InlineKeyboardMarkup) *MessageOptions {
+	return &MessageOptions{ParseMode: telego.ModeHTML, Keyboard: keyboard}
+}
+
+// Markdown возвращает параметры сообщения с разметкой Markdown
+func Markdown(keyboard *telego.InlineKeyboardMarkup) *MessageOptions {
+	return &MessageOptions{ParseMode: telego.ModeMarkdown, Keyboard: keyboard}
+}
+
+// Plain возвращает параметры сообщения без разметки
+func Plain(keyboard *telego.InlineKeyboardMarkup) *MessageOptions {
+	return &MessageOptions{Keyboard: keyboard}
+}
+
+// markdownEscaper экранирует символы разметки Markdown
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// EscapeMarkdown экранирует текст для подстановки в сообщение с разметкой
+// Markdown вне сущностей, чтобы Telegram не отклонил его из-за символов _ * ` [
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
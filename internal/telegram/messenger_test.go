@@ -0,0 +1,13 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	assert.Equal(t, `\_john\_doe\_`, EscapeMarkdown("_john_doe_"))
+	assert.Equal(t, "\\*bold\\* \\`code\\` \\[link](url)", EscapeMarkdown("*bold* `code` [link](url)"))
+	assert.Equal(t, "Иван Петров", EscapeMarkdown("Иван Петров"))
+}
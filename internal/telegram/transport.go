@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"remnawave-tg-shop/internal/logger"
+
+	"github.com/mymmrac/telego"
+)
+
+// SecretTokenHeader заголовок с секретом webhook'а
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Transport источник обновлений Telegram
+type Transport interface {
+	Start() error
+	Stop()
+}
+
+// LongPoller получает обновления через getUpdates
+type LongPoller struct {
+	client     *Client
+	dispatcher *Dispatcher
+	logger     logger.Logger
+}
+
+// NewLongPoller создает новый LongPoller
+func NewLongPoller(client *Client, dispatcher *Dispatcher, log logger.Logger) *LongPoller {
+	return &LongPoller{
+		client:     client,
+		dispatcher: dispatcher,
+		logger:     log,
+	}
+}
+
+// Start снимает webhook и обрабатывает обновления до остановки
+func (p *LongPoller) Start() error {
+	if err := p.client.DeleteWebhook(); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	updates, err := p.client.Bot().UpdatesViaLongPolling(&telego.GetUpdatesParams{Timeout: 10})
+	if err != nil {
+		return fmt.Errorf("failed to start long polling: %w", err)
+	}
+
+	p.logger.Info("Telegram long polling started")
+	for update := range updates {
+		p.dispatcher.Dispatch(update)
+	}
+
+	return nil
+}
+
+// Stop останавливает long polling
+func (p *LongPoller) Stop() {
+	p.client.Bot().StopLongPolling()
+}
+
+// Webhook принимает обновления от Telegram по HTTP
+type Webhook struct {
+	client     *Client
+	url        string
+	secret     string
+	dispatcher *Dispatcher
+	logger     logger.Logger
+}
+
+// NewWebhook создает новый Webhook. Если секрет не задан, он выводится
+// из токена бота, чтобы совпадать на всех репликах
+func NewWebhook(client *Client, webhookURL, secret, botToken string, dispatcher *Dispatcher, log logger.Logger) *Webhook {
+	if secret == "" {
+		sum := sha256.Sum256([]byte("webhook:" + botToken))
+		secret = hex.EncodeToString(sum[:])
+	}
+
+	return &Webhook{
+		client:     client,
+		url:        webhookURL,
+		secret:     secret,
+		dispatcher: dispatcher,
+		logger:     log,
+	}
+}
+
+// Path возвращает путь webhook'а из BOT_WEBHOOK_URL
+func (w *Webhook) Path() string {
+	parsed, err := url.Parse(w.url)
+	if err != nil || parsed.Path == "" {
+		return "/webhook"
+	}
+	return parsed.Path
+}
+
+// Start регистрирует webhook через setWebhook
+func (w *Webhook) Start() error {
+	if err := w.client.SetWebhook(w.url, w.secret); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	w.logger.Info("Telegram webhook registered", "url", w.url)
+	return nil
+}
+
+// Stop ничего не делает: webhook остается зарегистрированным для других реплик
+func (w *Webhook) Stop() {}
+
+// ServeHTTP проверяет секрет и передает обновление в Dispatcher
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(w.secret)) != 1 {
+		w.logger.Warn("Rejected Telegram webhook with invalid secret token", "remote_addr", r.RemoteAddr)
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var update telego.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		w.logger.Error("Failed to parse Telegram webhook", "error", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.dispatcher.Dispatch(update)
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"remnawave-tg-shop/internal/logger"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func chatIDOf(update telego.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	return 0
+}
+
+func TestDispatcher_PreservesOrderWithinChat(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[int64][]int)
+
+	d := NewDispatcher(4, func(update telego.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := update.Message.Chat.ID
+		got[chatID] = append(got[chatID], update.UpdateID)
+	}, chatIDOf, logger.New("error"))
+
+	for i := 0; i < 100; i++ {
+		chatID := int64(i % 5)
+		d.Dispatch(telego.Update{UpdateID: i, Message: &telego.Message{Chat: telego.Chat{ID: chatID}}})
+	}
+	d.Stop()
+
+	for chatID, ids := range got {
+		assert.Len(t, ids, 20, "chat %d", chatID)
+		for i := 1; i < len(ids); i++ {
+			assert.Less(t, ids[i-1], ids[i], "chat %d", chatID)
+		}
+	}
+}
+
+func TestWebhook_ServeHTTP(t *testing.T) {
+	caller := &fakeCaller{responses: map[string][]*telegoapi.Response{}}
+	client := newTestClient(t, caller)
+
+	var mu sync.Mutex
+	var handled []int
+	d := NewDispatcher(1, func(update telego.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, update.UpdateID)
+	}, chatIDOf, logger.New("error"))
+
+	webhook := NewWebhook(client, "https://example.com/tg/hook", "s3cret", testToken, d, logger.New("error"))
+	assert.Equal(t, "/tg/hook", webhook.Path())
+
+	body := `{"update_id":7,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"hi"}}`
+
+	req := httptest.NewRequest(http.MethodPost, "/tg/hook", strings.NewReader(body))
+	req.Header.Set(SecretTokenHeader, "wrong")
+	rec := httptest.NewRecorder()
+	webhook.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/tg/hook", strings.NewReader(body))
+	req.Header.Set(SecretTokenHeader, "s3cret")
+	rec = httptest.NewRecorder()
+	webhook.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	d.Stop()
+	assert.Equal(t, []int{7}, handled)
+}
+
+func TestWebhook_Start_RegistersSecret(t *testing.T) {
+	caller := &fakeCaller{responses: map[string][]*telegoapi.Response{}}
+	client := newTestClient(t, caller)
+
+	webhook := NewWebhook(client, "https://example.com/webhook", "", testToken, nil, logger.New("error"))
+	assert.NotEmpty(t, webhook.secret)
+	assert.NoError(t, webhook.Start())
+	assert.Equal(t, 1, caller.count("setWebhook"))
+}
@@ -0,0 +1,12 @@
+-- Bot sessions migration for Remnawave Telegram Shop Bot
+-- Stores the state of multi-step dialogs so that replicas can share it
+
+CREATE TABLE IF NOT EXISTS bot_sessions (
+    telegram_id BIGINT PRIMARY KEY,
+    step VARCHAR(100) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_sessions_expires_at ON bot_sessions(expires_at);
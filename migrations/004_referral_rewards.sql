@@ -0,0 +1,4 @@
+-- Referral rewards migration for Remnawave Telegram Shop Bot
+-- Marks referred users whose first purchase has already been rewarded
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_rewarded_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,28 @@
+-- Partner program migration for Remnawave Telegram Shop Bot
+-- Percentage commissions from referral payments and referral link clicks
+
+CREATE TABLE IF NOT EXISTS partner_earnings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    partner_id UUID NOT NULL REFERENCES users(id),
+    referral_id UUID NOT NULL REFERENCES users(id),
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    level INTEGER NOT NULL,
+    percent DECIMAL(5,2) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_partner_earnings_payment_partner ON partner_earnings(payment_id, partner_id);
+CREATE INDEX IF NOT EXISTS idx_partner_earnings_partner_id ON partner_earnings(partner_id);
+CREATE INDEX IF NOT EXISTS idx_partner_earnings_referral_id ON partner_earnings(referral_id);
+CREATE INDEX IF NOT EXISTS idx_partner_earnings_available_at ON partner_earnings(available_at);
+
+CREATE TABLE IF NOT EXISTS referral_clicks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    partner_id UUID NOT NULL REFERENCES users(id),
+    telegram_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_referral_clicks_partner_id ON referral_clicks(partner_id);
@@ -0,0 +1,9 @@
+-- Promo code effects migration for Remnawave Telegram Shop Bot
+-- Bonus-day redemptions reference the extended subscription; discount redemptions
+-- stay pending until consumed by a purchase
+
+ALTER TABLE promo_code_usages ADD COLUMN IF NOT EXISTS subscription_id UUID REFERENCES subscriptions(id);
+ALTER TABLE promo_code_usages ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10,2) DEFAULT 0;
+ALTER TABLE promo_code_usages ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_promo_code_usages_pending ON promo_code_usages(user_id) WHERE consumed_at IS NULL;
@@ -0,0 +1,14 @@
+-- Promo code rules migration for Remnawave Telegram Shop Bot
+-- Targeting rules for promo codes and a unique usage number per user, so
+-- concurrent redemptions cannot exceed the per-user limit
+
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS max_uses_per_user INTEGER DEFAULT 1;
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS new_users_only BOOLEAN DEFAULT FALSE;
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS first_purchase_only BOOLEAN DEFAULT FALSE;
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS allowed_plans VARCHAR(255);
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS min_purchase_amount DECIMAL(10,2) DEFAULT 0;
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS not_combinable_with_referral BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE promo_code_usages ADD COLUMN IF NOT EXISTS use_number INTEGER NOT NULL DEFAULT 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_code_usages_user_use ON promo_code_usages(promo_code_id, user_id, use_number);
@@ -0,0 +1,6 @@
+-- Promo campaigns migration for Remnawave Telegram Shop Bot
+-- Codes generated in one batch share a campaign ID used for exports and stats
+
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS campaign_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_promo_codes_campaign_id ON promo_codes(campaign_id);
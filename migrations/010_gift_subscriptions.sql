@@ -0,0 +1,7 @@
+-- Gift subscriptions migration for Remnawave Telegram Shop Bot
+-- Gift codes are promo codes of type gift_subscription bought by a user;
+-- plan_name keeps the tariff provisioned to the recipient
+
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS plan_name VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_promo_codes_gifts ON promo_codes(created_by) WHERE type = 'gift_subscription';
@@ -0,0 +1,8 @@
+-- Language preference migration for Remnawave Telegram Shop Bot
+-- An empty language_preference means "use the Telegram client language".
+-- The language switcher did not exist before, so the 'ru' values are column
+-- defaults rather than user choices and are reset
+
+ALTER TABLE users ALTER COLUMN language_preference SET DEFAULT '';
+
+UPDATE users SET language_preference = '' WHERE language_preference = 'ru';
@@ -0,0 +1,23 @@
+-- Runtime settings migration for Remnawave Telegram Shop Bot
+-- settings keeps values overriding environment configuration,
+-- setting_changes keeps the history of who changed what
+
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS setting_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(100) NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    reset BOOLEAN NOT NULL DEFAULT FALSE,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_setting_changes_key ON setting_changes(key, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_setting_changes_created_at ON setting_changes(created_at);
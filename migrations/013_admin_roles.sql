@@ -0,0 +1,9 @@
+-- Admin roles migration for Remnawave Telegram Shop Bot
+-- admin_role replaces the all-or-nothing is_admin flag: owner, admin, support, finance, marketing.
+-- Existing admins keep full access as the admin role
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS admin_role VARCHAR(20) NOT NULL DEFAULT '';
+
+UPDATE users SET admin_role = 'admin' WHERE is_admin = TRUE AND admin_role = '';
+
+CREATE INDEX IF NOT EXISTS idx_users_admin_role ON users(admin_role) WHERE admin_role <> '';
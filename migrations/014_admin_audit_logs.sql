@@ -0,0 +1,22 @@
+-- Admin audit trail migration for Remnawave Telegram Shop Bot
+-- Privileged admin actions with before/after values and reason.
+-- Kept apart from activity_logs so CleanupOldLogs never removes them
+
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID NOT NULL REFERENCES users(id),
+    action VARCHAR(50) NOT NULL,
+    target_user_id UUID REFERENCES users(id),
+    target_type VARCHAR(50),
+    target_id VARCHAR(100),
+    before TEXT,
+    after TEXT,
+    reason TEXT,
+    critical BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_actor_id ON admin_audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_target_user_id ON admin_audit_logs(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_action ON admin_audit_logs(action, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs(created_at DESC);
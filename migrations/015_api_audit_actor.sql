@@ -0,0 +1,7 @@
+-- REST API audit migration for Remnawave Telegram Shop Bot
+-- Actions made through the REST API are performed by an API account rather
+-- than a bot user: actor_id becomes optional and actor_name keeps the API login
+
+ALTER TABLE admin_audit_logs ALTER COLUMN actor_id DROP NOT NULL;
+
+ALTER TABLE admin_audit_logs ADD COLUMN IF NOT EXISTS actor_name VARCHAR(100);
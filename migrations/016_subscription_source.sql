@@ -0,0 +1,23 @@
+-- Subscription source migration for Remnawave Telegram Shop Bot
+-- "First purchase only" promo codes count purchased subscriptions, so every
+-- subscription records how it was obtained. Existing rows are classified by
+-- the previous rules; a subscription created by redeeming a gift code is
+-- recognised by the promo code usage that created it
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_source ON subscriptions(source);
+
+UPDATE subscriptions SET source = 'trial' WHERE source = '' AND plan_id = 0;
+
+UPDATE subscriptions SET source = 'bonus' WHERE source = '' AND plan_name = 'Bonus';
+
+UPDATE subscriptions s SET source = 'gift'
+FROM promo_code_usages u
+JOIN promo_codes p ON p.id = u.promo_code_id
+WHERE s.source = ''
+  AND u.subscription_id = s.id
+  AND p.type = 'gift_subscription'
+  AND s.created_at >= u.used_at;
+
+UPDATE subscriptions SET source = 'purchase' WHERE source = '';